@@ -0,0 +1,132 @@
+// Package additionalconsent parses Google's Additional Consent (AC) strings, which record consent for
+// Google Ad Tech Providers (ATPs) that aren't registered with the IAB's TCF. They travel alongside TC strings.
+// For technical details, see https://support.google.com/admanager/answer/9681920
+//
+// A version 1 string lists the consented ATP IDs, such as "1~1.35.41.101". A version 2 string adds the
+// ATPs which were disclosed to the user, such as "2~1.35.41.101~dv.9.21.81".
+package additionalconsent
+
+import (
+	"fmt"
+	"iter"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	partSeparator = "~"
+	idSeparator   = "."
+	// disclosedPrefix starts the disclosed vendors part of a version 2 string.
+	disclosedPrefix = "dv" + idSeparator
+)
+
+// Consent is a parsed AC string.
+type Consent struct {
+	version   int
+	consented map[int]struct{}
+	disclosed map[int]struct{}
+}
+
+// Parse parses an AC string. It returns an error if the version isn't 1 or 2, or if any ATP ID isn't a
+// positive integer.
+func Parse(ac string) (Consent, error) {
+	parts := strings.Split(ac, partSeparator)
+	version, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Consent{}, fmt.Errorf("AC strings must start with a version, but %q doesn't", ac)
+	}
+
+	var disclosed map[int]struct{}
+	switch {
+	case version == 1 && len(parts) == 2:
+	case version == 2 && len(parts) == 3:
+		if !strings.HasPrefix(parts[2], disclosedPrefix) {
+			return Consent{}, fmt.Errorf("the disclosed vendors of AC string %q must start with %q", ac, disclosedPrefix)
+		}
+		if disclosed, err = parseIDs(strings.TrimPrefix(parts[2], disclosedPrefix)); err != nil {
+			return Consent{}, fmt.Errorf("invalid disclosed vendors in AC string %q: %v", ac, err)
+		}
+	case version == 1 || version == 2:
+		return Consent{}, fmt.Errorf("version %d AC strings have %d parts separated by %q, but %q has %d", version, version+1, partSeparator, ac, len(parts))
+	default:
+		return Consent{}, fmt.Errorf("AC string %q has version %d, but only versions 1 and 2 are supported", ac, version)
+	}
+
+	consented, err := parseIDs(parts[1])
+	if err != nil {
+		return Consent{}, fmt.Errorf("invalid consented vendors in AC string %q: %v", ac, err)
+	}
+	return Consent{
+		version:   version,
+		consented: consented,
+		disclosed: disclosed,
+	}, nil
+}
+
+// parseIDs parses a list of ATP IDs separated by dots. The list may be empty.
+func parseIDs(list string) (map[int]struct{}, error) {
+	ids := make(map[int]struct{})
+	if list == "" {
+		return ids, nil
+	}
+	for _, field := range strings.Split(list, idSeparator) {
+		id, err := strconv.Atoi(field)
+		if err != nil || id < 1 {
+			return nil, fmt.Errorf("%q is not an ATP ID", field)
+		}
+		ids[id] = struct{}{}
+	}
+	return ids, nil
+}
+
+// Version returns the version of the AC string: 1 or 2.
+func (c Consent) Version() int {
+	return c.version
+}
+
+// ATPConsent returns true if the user consented to the ATP with the given ID.
+func (c Consent) ATPConsent(id int) bool {
+	_, ok := c.consented[id]
+	return ok
+}
+
+// ConsentedATPs returns the IDs of the ATPs the user consented to, in ascending order.
+func (c Consent) ConsentedATPs() []int {
+	return sortedIDs(c.consented)
+}
+
+// HasDisclosedATPs returns true if the AC string lists the ATPs disclosed to the user. Only version 2
+// strings do. ATPDisclosed returns false for every ATP when the list is missing, so this tells an
+// undisclosed ATP apart from a string which doesn't say.
+func (c Consent) HasDisclosedATPs() bool {
+	return c.disclosed != nil
+}
+
+// ATPDisclosed returns true if the ATP with the given ID was disclosed to the user.
+func (c Consent) ATPDisclosed(id int) bool {
+	_, ok := c.disclosed[id]
+	return ok
+}
+
+// DisclosedATPs returns an iterator over the IDs of the ATPs disclosed to the user, in ascending order.
+// It yields nothing for version 1 strings.
+func (c Consent) DisclosedATPs() iter.Seq[int] {
+	ids := sortedIDs(c.disclosed)
+	return func(yield func(int) bool) {
+		for _, id := range ids {
+			if !yield(id) {
+				return
+			}
+		}
+	}
+}
+
+func sortedIDs(set map[int]struct{}) []int {
+	ids := make([]int, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
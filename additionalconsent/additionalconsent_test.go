@@ -0,0 +1,186 @@
+package additionalconsent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name              string
+		ac                string
+		expectedVersion   int
+		expectedConsented []int
+	}{
+		{
+			name:              "v1",
+			ac:                "1~1.35.41.101",
+			expectedVersion:   1,
+			expectedConsented: []int{1, 35, 41, 101},
+		},
+		{
+			name:              "v1_unsorted",
+			ac:                "1~101.1.41.35.41",
+			expectedVersion:   1,
+			expectedConsented: []int{1, 35, 41, 101},
+		},
+		{
+			name:              "v1_no_consents",
+			ac:                "1~",
+			expectedVersion:   1,
+			expectedConsented: []int{},
+		},
+		{
+			name:              "v2",
+			ac:                "2~1.35.41.101~dv.9.21.81",
+			expectedVersion:   2,
+			expectedConsented: []int{1, 35, 41, 101},
+		},
+		{
+			name:              "v2_no_consents",
+			ac:                "2~~dv.",
+			expectedVersion:   2,
+			expectedConsented: []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			consent, err := Parse(tt.ac)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedVersion, consent.Version())
+			assert.Equal(t, tt.expectedConsented, consent.ConsentedATPs())
+		})
+	}
+}
+
+func TestATPConsent(t *testing.T) {
+	consent, err := Parse("2~1.35.41.101~dv.9.21.81")
+	assert.NoError(t, err)
+
+	assert.True(t, consent.ATPConsent(1))
+	assert.True(t, consent.ATPConsent(101))
+	assert.False(t, consent.ATPConsent(2))
+	assert.False(t, consent.ATPConsent(9))
+	assert.False(t, consent.ATPConsent(0))
+}
+
+func TestDisclosedATPs(t *testing.T) {
+	consent, err := Parse("2~1.35.41.101~dv.81.9.21")
+	assert.NoError(t, err)
+
+	assert.True(t, consent.HasDisclosedATPs())
+	assert.True(t, consent.ATPDisclosed(9))
+	assert.True(t, consent.ATPDisclosed(81))
+	assert.False(t, consent.ATPDisclosed(1))
+
+	var disclosed []int
+	for id := range consent.DisclosedATPs() {
+		disclosed = append(disclosed, id)
+	}
+	assert.Equal(t, []int{9, 21, 81}, disclosed)
+
+	// Stopping early must not panic.
+	for id := range consent.DisclosedATPs() {
+		assert.Equal(t, 9, id)
+		break
+	}
+}
+
+func TestDisclosedATPsEmpty(t *testing.T) {
+	tests := []struct {
+		name                 string
+		ac                   string
+		expectedHasDisclosed bool
+	}{
+		{
+			name:                 "v1",
+			ac:                   "1~1.35.41.101",
+			expectedHasDisclosed: false,
+		},
+		{
+			name:                 "v2_nothing_disclosed",
+			ac:                   "2~1.35~dv.",
+			expectedHasDisclosed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			consent, err := Parse(tt.ac)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedHasDisclosed, consent.HasDisclosedATPs())
+			assert.False(t, consent.ATPDisclosed(1))
+			for range consent.DisclosedATPs() {
+				t.Error("expected no disclosed ATPs")
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		ac            string
+		expectedError string
+	}{
+		{
+			name:          "empty",
+			ac:            "",
+			expectedError: `AC strings must start with a version, but "" doesn't`,
+		},
+		{
+			name:          "no_version",
+			ac:            "~1.35",
+			expectedError: `AC strings must start with a version, but "~1.35" doesn't`,
+		},
+		{
+			name:          "unsupported_version",
+			ac:            "3~1.35",
+			expectedError: `AC string "3~1.35" has version 3, but only versions 1 and 2 are supported`,
+		},
+		{
+			name:          "v1_missing_consents",
+			ac:            "1",
+			expectedError: `version 1 AC strings have 2 parts separated by "~", but "1" has 1`,
+		},
+		{
+			name:          "v2_missing_disclosed",
+			ac:            "2~1.35",
+			expectedError: `version 2 AC strings have 3 parts separated by "~", but "2~1.35" has 2`,
+		},
+		{
+			name:          "v2_bad_disclosed_prefix",
+			ac:            "2~1.35~9.21",
+			expectedError: `the disclosed vendors of AC string "2~1.35~9.21" must start with "dv."`,
+		},
+		{
+			name:          "bad_consented_id",
+			ac:            "1~1.x.41",
+			expectedError: `invalid consented vendors in AC string "1~1.x.41": "x" is not an ATP ID`,
+		},
+		{
+			name:          "zero_id",
+			ac:            "1~0.35",
+			expectedError: `invalid consented vendors in AC string "1~0.35": "0" is not an ATP ID`,
+		},
+		{
+			name:          "empty_id",
+			ac:            "1~1..35",
+			expectedError: `invalid consented vendors in AC string "1~1..35": "" is not an ATP ID`,
+		},
+		{
+			name:          "bad_disclosed_id",
+			ac:            "2~1.35~dv.9.-21",
+			expectedError: `invalid disclosed vendors in AC string "2~1.35~dv.9.-21": "-21" is not an ATP ID`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.ac)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
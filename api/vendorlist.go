@@ -1,9 +1,17 @@
 package api
 
-import "github.com/prebid/go-gdpr/consentconstants"
+import (
+	"time"
+
+	"github.com/prebid/go-gdpr/consentconstants"
+)
 
 // VendorList is an interface used to fetch information about an IAB Global Vendor list.
 // For the latest version, see: https://vendorlist.consensu.org/vendorlist.json
+//
+// Lists of every generation implement it: version 1.1 lists from the vendorlist package, version 2 lists
+// from vendorlist2 and version 3 lists from vendorlist3. They all implement VendorIDLister too, so code
+// which only relies on these interfaces works with any of them.
 type VendorList interface {
 	// SpecVersion returns the version of the global vendor list specification the list adheres to
 	SpecVersion() uint16
@@ -21,7 +29,26 @@ type VendorList interface {
 	Vendor(vendorID uint16) Vendor
 }
 
+// VendorIDLister is implemented by vendor lists which can enumerate their vendors. Every list in this
+// module does, but other implementations of VendorList may not, so check with a type assertion.
+type VendorIDLister interface {
+	// VendorIDs returns the IDs of every vendor in the list, including deleted ones, in no particular order.
+	VendorIDs() []uint16
+}
+
+// MaxVendorIDReporter is implemented by vendor lists which know their highest vendor ID without
+// enumerating their vendors. The eagerly parsed lists in this module do; the lazy ones don't.
+type MaxVendorIDReporter interface {
+	// MaxVendorID returns the highest ID of any vendor in the list, including deleted ones, or 0 if the
+	// list has no vendors.
+	MaxVendorID() uint16
+}
+
 // Vendor describes which purposes a given vendor claims to use data for, in this vendor list.
+//
+// Older list generations don't declare everything asked about here. A vendor from such a list reports
+// false for anything its generation can't declare: version 1.1 lists have no special purposes or special
+// features, so those methods always return false, and the strict methods return the same as the others.
 type Vendor interface {
 	// Purpose returns true if this vendor claims to use data for the given purpose, or false otherwise
 	Purpose(purposeID consentconstants.Purpose) bool
@@ -40,3 +67,53 @@ type Vendor interface {
 	// SpecialFeature returns true if this vendor claims a need for the given special feature
 	SpecialFeature(featureID consentconstants.SpecialFeature) (hasSpecialFeature bool)
 }
+
+// FlexiblePurposes is implemented by vendors from lists which declare flexible purposes. Vendors from
+// version 2 and 3 lists implement it, while version 1.1 lists have no flexible purposes. Vendors which
+// don't implement it have no flexible purposes:
+//
+//	if flexible, ok := vendor.(api.FlexiblePurposes); ok && flexible.FlexiblePurpose(purposeID) {
+//		// A publisher restriction may change the vendor's legal basis for the purpose.
+//	}
+type FlexiblePurposes interface {
+	// FlexiblePurpose returns true if this vendor declared the given purpose as flexible, meaning a
+	// publisher restriction may switch its legal basis between consent and legitimate interest.
+	FlexiblePurpose(purposeID consentconstants.Purpose) (isFlexible bool)
+}
+
+// VendorDeletion is implemented by vendors from lists which record when vendors were deleted. Vendors
+// from version 2 and 3 lists implement it, while version 1.1 lists never delete vendors. Vendors which
+// don't implement it count as not deleted:
+//
+//	if deletion, ok := vendor.(api.VendorDeletion); ok {
+//		deletedDate, deleted := deletion.DeletedDate()
+//	}
+type VendorDeletion interface {
+	// DeletedDate returns the time this vendor was deleted from the list. isDeleted is false if it wasn't.
+	//
+	// Deleted vendors stay in the list so that older consent strings can still be interpreted, but consent
+	// strings created on or after the deletedDate can't give them consent.
+	DeletedDate() (deletedDate time.Time, isDeleted bool)
+}
+
+// DeviceStorage describes how a vendor stores and accesses information on the user's device, as disclosed
+// in the vendor list. Vendors from version 2 and 3 lists implement it; use a type assertion to reach it:
+//
+//	if storage, ok := vendor.(api.DeviceStorage); ok {
+//		maxAge := storage.CookieMaxAgeSeconds()
+//	}
+//
+// Fields missing from the list report their zero value.
+type DeviceStorage interface {
+	// UsesCookies returns true if the vendor stores data in cookies.
+	UsesCookies() bool
+	// CookieMaxAgeSeconds returns the longest lifetime of the vendor's cookies, in seconds.
+	CookieMaxAgeSeconds() int64
+	// CookieRefresh returns true if the vendor's cookies may be refreshed.
+	CookieRefresh() bool
+	// UsesNonCookieAccess returns true if the vendor accesses the device by means other than cookies.
+	UsesNonCookieAccess() bool
+	// DeviceStorageDisclosureURL returns the URL of the vendor's device storage disclosure, or "" if it
+	// has none.
+	DeviceStorageDisclosureURL() string
+}
@@ -0,0 +1,93 @@
+package bitutils
+
+import (
+	"encoding/binary"
+	"fmt"
+	"iter"
+	"math/bits"
+)
+
+// CountSetBits returns how many of the bitCount bits starting at bitStartIndex are 1s. It reads the data 64
+// bits at a time, so it's much quicker than testing each bit.
+func CountSetBits(data []byte, bitStartIndex uint, bitCount uint) (int, error) {
+	if err := checkBitRange("CountSetBits", data, bitStartIndex, bitCount); err != nil {
+		return 0, err
+	}
+	n := 0
+	for offset := uint(0); offset < bitCount; offset += 64 {
+		n += bits.OnesCount64(readWord(data, bitStartIndex+offset, bitCount-offset))
+	}
+	return n, nil
+}
+
+// ForEachSetBit calls fn with the index of every 1 among the bitCount bits starting at bitStartIndex, in
+// ascending order. The indexes are relative to bitStartIndex. Like CountSetBits, it reads the data 64 bits
+// at a time and skips over runs of 0s.
+func ForEachSetBit(data []byte, bitStartIndex uint, bitCount uint, fn func(index uint)) error {
+	if err := checkBitRange("ForEachSetBit", data, bitStartIndex, bitCount); err != nil {
+		return err
+	}
+	for index := range setBits(data, bitStartIndex, bitCount) {
+		fn(index)
+	}
+	return nil
+}
+
+// SetBits returns an iterator over the same indexes as ForEachSetBit, so that callers can stop early. It
+// checks the range before returning, so the iterator can't fail.
+func SetBits(data []byte, bitStartIndex uint, bitCount uint) (iter.Seq[uint], error) {
+	if err := checkBitRange("SetBits", data, bitStartIndex, bitCount); err != nil {
+		return nil, err
+	}
+	return setBits(data, bitStartIndex, bitCount), nil
+}
+
+// setBits iterates over the indexes of the 1s without checking the range.
+func setBits(data []byte, bitStartIndex uint, bitCount uint) iter.Seq[uint] {
+	return func(yield func(uint) bool) {
+		for offset := uint(0); offset < bitCount; offset += 64 {
+			word := readWord(data, bitStartIndex+offset, bitCount-offset)
+			for word != 0 {
+				index := uint(bits.LeadingZeros64(word))
+				if !yield(offset + index) {
+					return
+				}
+				word &^= 1 << (63 - index)
+			}
+		}
+	}
+}
+
+func checkBitRange(function string, data []byte, bitStartIndex uint, bitCount uint) error {
+	if uint(len(data))*8 < bitStartIndex+bitCount {
+		return fmt.Errorf("%s expected %d bits to start at bit %d, but the data was only %d bytes long", function, bitCount, bitStartIndex, len(data))
+	}
+	return nil
+}
+
+// readWord returns the 64 bits starting at bitIndex, with the first of them in the most significant bit.
+// Only the first remaining bits are kept if there are fewer than 64, and bits past the end of data are 0s.
+func readWord(data []byte, bitIndex uint, remaining uint) uint64 {
+	byteIndex := bitIndex / 8
+	var word uint64
+	if byteIndex+8 <= uint(len(data)) {
+		word = binary.BigEndian.Uint64(data[byteIndex:])
+	} else {
+		for i := byteIndex; i < byteIndex+8; i++ {
+			word <<= 8
+			if i < uint(len(data)) {
+				word |= uint64(data[i])
+			}
+		}
+	}
+	if shift := bitIndex % 8; shift != 0 {
+		word <<= shift
+		if byteIndex+8 < uint(len(data)) {
+			word |= uint64(data[byteIndex+8]) >> (8 - shift)
+		}
+	}
+	if remaining < 64 {
+		word &= ^uint64(0) << (64 - remaining)
+	}
+	return word
+}
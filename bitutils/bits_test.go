@@ -0,0 +1,103 @@
+package bitutils
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestCountSetBits(t *testing.T) {
+	// 0000 0100 1010 0010 0000 0011 1011 0001 0000 0000 0010 1011
+	n, err := CountSetBits(testdata, 0, 48)
+	assertNilError(t, err)
+	assertIntsEqual(t, 14, n)
+
+	n, err = CountSetBits(testdata, 5, 12)
+	assertNilError(t, err)
+	assertIntsEqual(t, 4, n)
+
+	n, err = CountSetBits(testdata, 48, 0)
+	assertNilError(t, err)
+	assertIntsEqual(t, 0, n)
+
+	_, err = CountSetBits(testdata, 40, 9)
+	assertStringsEqual(t, "CountSetBits expected 9 bits to start at bit 40, but the data was only 6 bytes long", err.Error())
+}
+
+func TestForEachSetBit(t *testing.T) {
+	var indexes []uint
+	assertNilError(t, ForEachSetBit(testdata, 3, 20, func(index uint) { indexes = append(indexes, index) }))
+	if !reflect.DeepEqual([]uint{2, 5, 7, 11, 19}, indexes) {
+		t.Errorf("ForEachSetBit found %v", indexes)
+	}
+
+	err := ForEachSetBit(testdata, 0, 49, func(uint) {})
+	assertStringsEqual(t, "ForEachSetBit expected 49 bits to start at bit 0, but the data was only 6 bytes long", err.Error())
+}
+
+func TestSetBits(t *testing.T) {
+	setBits, err := SetBits(testdata, 3, 20)
+	assertNilError(t, err)
+	var indexes []uint
+	for index := range setBits {
+		if index > 7 {
+			break
+		}
+		indexes = append(indexes, index)
+	}
+	if !reflect.DeepEqual([]uint{2, 5, 7}, indexes) {
+		t.Errorf("SetBits found %v", indexes)
+	}
+
+	_, err = SetBits(testdata, 0, 49)
+	assertStringsEqual(t, "SetBits expected 49 bits to start at bit 0, but the data was only 6 bytes long", err.Error())
+}
+
+func TestSetBitsMatchPerBit(t *testing.T) {
+	data := make([]byte, 300)
+	rand.New(rand.NewSource(1)).Read(data)
+	for _, start := range []uint{0, 1, 7, 8, 63, 64, 65, 230} {
+		for _, count := range []uint{0, 1, 63, 64, 65, 1000, uint(len(data))*8 - start} {
+			var expected []uint
+			for i := uint(0); i < count; i++ {
+				if isSet(data, start+i) {
+					expected = append(expected, i)
+				}
+			}
+			var actual []uint
+			assertNilError(t, ForEachSetBit(data, start, count, func(index uint) { actual = append(actual, index) }))
+			if !reflect.DeepEqual(expected, actual) {
+				t.Errorf("ForEachSetBit(%d, %d) found %v, expected %v", start, count, actual, expected)
+			}
+			n, err := CountSetBits(data, start, count)
+			assertNilError(t, err)
+			assertIntsEqual(t, len(expected), n)
+		}
+	}
+}
+
+func BenchmarkCountSetBits(b *testing.B) {
+	// A BitField for 3000 vendors, which starts at the same bit as a TC string's vendor consents.
+	data := make([]byte, 410)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	b.Run("words", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			CountSetBits(data, 230, 3000)
+		}
+	})
+	b.Run("per bit", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			n := 0
+			for j := uint(0); j < 3000; j++ {
+				if isSet(data, 230+j) {
+					n++
+				}
+			}
+		}
+	})
+}
+
+func isSet(data []byte, bitIndex uint) bool {
+	return data[bitIndex/8]&(0x80>>(bitIndex%8)) != 0
+}
@@ -0,0 +1,188 @@
+package bitutils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Fibonacci coding writes an integer as its Zeckendorf representation: a sum of non-consecutive Fibonacci
+// numbers, one bit per term (1, 2, 3, 5, 8, ...), least significant first. An extra 1 bit terminates the
+// code, so every code ends in "11" and codes can be read without knowing their length up front.
+// GPP uses it for offsets in its range fields. For details, see
+// https://github.com/InteractiveAdvertisingBureau/Global-Privacy-Platform/blob/main/Core/Consent%20String%20Specification.md#fibonacci-encoding-
+
+var errFibonacciOverflow = errors.New("fibonacci integer overflows 64 bits")
+
+var errFibonacciRangeOverflow = errors.New("fibonacci range IDs overflow")
+
+// ErrTooManyIDs is returned by ReadFibonacciRange for fields which cover more IDs than the caller allows.
+var ErrTooManyIDs = errors.New("fibonacci range covers too many IDs")
+
+// fibonacciTerms holds every term of the code which fits in 64 bits: 1, 2, 3, 5, 8, ...
+var fibonacciTerms = func() []uint64 {
+	terms := []uint64{1, 2}
+	for {
+		a, b := terms[len(terms)-2], terms[len(terms)-1]
+		if a > math.MaxUint64-b {
+			return terms
+		}
+		terms = append(terms, a+b)
+	}
+}()
+
+// ParseFibonacci parses a Fibonacci encoded integer from the data array, starting at the given index.
+// It returns the integer and the number of bits it used.
+func ParseFibonacci(data []byte, bitStartIndex uint) (uint64, uint, error) {
+	r := Reader{data: data, pos: bitStartIndex}
+	value, err := r.ReadFibonacci()
+	if err != nil {
+		return 0, 0, err
+	}
+	return value, r.pos - bitStartIndex, nil
+}
+
+// ReadFibonacci reads a Fibonacci encoded integer. If it fails, the reader doesn't move.
+func (r *Reader) ReadFibonacci() (uint64, error) {
+	start := r.pos
+	var value uint64
+	lastSet := false
+	for i := 0; ; i++ {
+		set, err := r.ReadBool()
+		if err != nil {
+			r.pos = start
+			return 0, fmt.Errorf("unterminated fibonacci integer: %v", err)
+		}
+		if set && lastSet {
+			return value, nil
+		}
+		if set {
+			if i >= len(fibonacciTerms) || value > math.MaxUint64-fibonacciTerms[i] {
+				r.pos = start
+				return 0, errFibonacciOverflow
+			}
+			value += fibonacciTerms[i]
+		}
+		lastSet = set
+	}
+}
+
+// ReadFibonacciRange reads a "Range (Fibonacci)" field, as used by GPP: a 12-bit entry count followed by the
+// entries. Each entry is a 1-bit IsARange flag and the Fibonacci encoded offset of its first ID from the
+// previous entry's last ID. Ranges then hold the Fibonacci encoded distance from their first ID to their last.
+// It returns every ID covered by the field, in ascending order.
+//
+// A few bits can encode a huge range, so the field is rejected with ErrTooManyIDs if it covers more than
+// maxIDs IDs. Callers reading untrusted input should pass the most IDs they can use.
+func (r *Reader) ReadFibonacciRange(maxIDs int) ([]int, error) {
+	numEntries, err := r.ReadBits(12)
+	if err != nil {
+		return nil, err
+	}
+	// Every entry covers at least one ID.
+	if numEntries > uint64(max(maxIDs, 0)) {
+		return nil, fmt.Errorf("%w: %d entries, but at most %d IDs are allowed", ErrTooManyIDs, numEntries, maxIDs)
+	}
+
+	var ids []int
+	last := 0
+	for i := uint64(0); i < numEntries; i++ {
+		isRange, err := r.ReadBool()
+		if err != nil {
+			return nil, err
+		}
+		offset, err := r.ReadFibonacci()
+		if err != nil {
+			return nil, err
+		}
+		if offset > uint64(math.MaxInt-last) {
+			return nil, errFibonacciRangeOverflow
+		}
+		start := last + int(offset)
+		end := start
+		if isRange {
+			length, err := r.ReadFibonacci()
+			if err != nil {
+				return nil, err
+			}
+			if length > uint64(math.MaxInt-start) {
+				return nil, errFibonacciRangeOverflow
+			}
+			end = start + int(length)
+		}
+		if uint64(end-start) >= uint64(maxIDs-len(ids)) {
+			return nil, fmt.Errorf("%w: at most %d IDs are allowed", ErrTooManyIDs, maxIDs)
+		}
+		for id := start; id <= end; id++ {
+			ids = append(ids, id)
+		}
+		last = end
+	}
+	return ids, nil
+}
+
+// WriteFibonacci writes value as a Fibonacci encoded integer. Fibonacci coding can't represent 0.
+func (w *Writer) WriteFibonacci(value uint64) error {
+	if value == 0 {
+		return errors.New("fibonacci coding can't represent 0")
+	}
+	w.writeFibonacci(value)
+	return nil
+}
+
+// writeFibonacci writes value, which must be at least 1, as a Fibonacci encoded integer.
+func (w *Writer) writeFibonacci(value uint64) {
+	// Pick terms greedily from the largest down, which always yields the Zeckendorf representation.
+	bits := make([]bool, len(fibonacciTerms))
+	highest := -1
+	remaining := value
+	for i := len(fibonacciTerms) - 1; i >= 0; i-- {
+		if fibonacciTerms[i] <= remaining {
+			bits[i] = true
+			remaining -= fibonacciTerms[i]
+			if highest < 0 {
+				highest = i
+			}
+		}
+	}
+
+	for i := 0; i <= highest; i++ {
+		w.WriteBool(bits[i])
+	}
+	w.WriteBool(true)
+}
+
+// WriteFibonacciRange writes ids as a "Range (Fibonacci)" field. The IDs must be positive, sorted and unique.
+// Consecutive IDs are collapsed into range entries.
+func (w *Writer) WriteFibonacciRange(ids []int) error {
+	type entry struct{ start, end int }
+	var entries []entry
+	last := 0
+	for _, id := range ids {
+		if id <= last {
+			return fmt.Errorf("fibonacci ranges need positive, sorted and unique IDs, but %d followed %d", id, last)
+		}
+		if n := len(entries); n > 0 && entries[n-1].end+1 == id {
+			entries[n-1].end = id
+		} else {
+			entries = append(entries, entry{start: id, end: id})
+		}
+		last = id
+	}
+	if len(entries) >= 1<<12 {
+		return fmt.Errorf("fibonacci ranges hold at most %d entries, but %d are needed", 1<<12-1, len(entries))
+	}
+
+	w.WriteBits(uint64(len(entries)), 12)
+	last = 0
+	for _, e := range entries {
+		isRange := e.start != e.end
+		w.WriteBool(isRange)
+		w.writeFibonacci(uint64(e.start - last))
+		if isRange {
+			w.writeFibonacci(uint64(e.end - e.start))
+		}
+		last = e.end
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package bitutils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseFibonacci(t *testing.T) {
+	tests := []struct {
+		data     []byte
+		expected uint64
+		bits     uint
+	}{
+		{[]byte{0xc0}, 1, 2},  // 11
+		{[]byte{0x60}, 2, 3},  // 011
+		{[]byte{0x30}, 3, 4},  // 0011
+		{[]byte{0xb0}, 4, 4},  // 1011
+		{[]byte{0x18}, 5, 5},  // 00011
+		{[]byte{0x98}, 6, 5},  // 10011
+		{[]byte{0x58}, 7, 5},  // 01011
+		{[]byte{0x0c}, 8, 6},  // 000011
+		{[]byte{0xab}, 33, 8}, // 10101011
+	}
+
+	for _, test := range tests {
+		value, bits, err := ParseFibonacci(test.data, 0)
+		assertNilError(t, err)
+		assertIntsEqual(t, int(test.expected), int(value))
+		assertIntsEqual(t, int(test.bits), int(bits))
+	}
+
+	// 0000 1011 => 4, starting at bit 4
+	value, bits, err := ParseFibonacci([]byte{0x0b}, 4)
+	assertNilError(t, err)
+	assertIntsEqual(t, 4, int(value))
+	assertIntsEqual(t, 4, int(bits))
+}
+
+func TestReadFibonacciErrors(t *testing.T) {
+	r := NewReader([]byte{0x01, 0x00})
+	_, err := r.ReadBits(1)
+	assertNilError(t, err)
+	_, err = r.ReadFibonacci()
+	assertStringsEqual(t, "unterminated fibonacci integer: ReadBits expected 1 bits to start at bit 16, but the data was only 2 bytes long", err.Error())
+
+	// A failed read must not move the reader.
+	assertIntsEqual(t, 1, int(r.Position()))
+
+	overflow := bytes.Repeat([]byte{0x55}, 12)
+	overflow = append(overflow, 0x80)
+	_, err = NewReader(overflow).ReadFibonacci()
+	assertStringsEqual(t, "fibonacci integer overflows 64 bits", err.Error())
+}
+
+func TestReadFibonacciRange(t *testing.T) {
+	// 000000000010 => NumEntries 2
+	// 0 011        => single ID, offset 2 => 2
+	// 1 0011 011   => range, offset 3 => 5, length 2 => 7
+	ids, err := NewReader([]byte{0x00, 0x23, 0x9b}).ReadFibonacciRange(4)
+	assertNilError(t, err)
+	assertIntSlicesEqual(t, []int{2, 5, 6, 7}, ids)
+
+	for _, maxIDs := range []int{3, 1, 0, -1} {
+		_, err = NewReader([]byte{0x00, 0x23, 0x9b}).ReadFibonacciRange(maxIDs)
+		if !errors.Is(err, ErrTooManyIDs) {
+			t.Errorf("Expected ErrTooManyIDs with at most %d IDs. Got %v", maxIDs, err)
+		}
+	}
+}
+
+func TestReadFibonacciRangeHuge(t *testing.T) {
+	// These GPP headers encode ranges of billions of IDs in a few bytes, and a range whose IDs overflow.
+	for _, header := range []string{"DRfAAENB-CgAAAAAAAAAAYgAAAAAAA", "DBABsoChSUkw"} {
+		data, err := base64.RawURLEncoding.DecodeString(header)
+		assertNilError(t, err)
+		r := NewReader(data)
+		// Skip the Type and Version.
+		_, err = r.ReadBits(12)
+		assertNilError(t, err)
+		if _, err := r.ReadFibonacciRange(1 << 12); err == nil {
+			t.Errorf("Expected an error for the header %s", header)
+		}
+	}
+
+	var w Writer
+	w.WriteBits(1, 12)
+	w.WriteBool(false)
+	w.WriteFibonacci(math.MaxUint64)
+	_, err := NewReader(w.Bytes()).ReadFibonacciRange(10)
+	assertStringsEqual(t, "fibonacci range IDs overflow", err.Error())
+}
+
+func TestWriteFibonacci(t *testing.T) {
+	for value := uint64(1); value <= 1000; value++ {
+		var w Writer
+		assertNilError(t, w.WriteFibonacci(value))
+		decoded, err := NewReader(w.Bytes()).ReadFibonacci()
+		assertNilError(t, err)
+		assertIntsEqual(t, int(value), int(decoded))
+	}
+
+	var w Writer
+	assertNilError(t, w.WriteFibonacci(33))
+	assertIntsEqual(t, 8, int(w.Len()))
+	if !bytes.Equal([]byte{0xab}, w.Bytes()) {
+		t.Errorf("Bytes were not equal. Expected ab, actual %x", w.Bytes())
+	}
+
+	err := w.WriteFibonacci(0)
+	assertStringsEqual(t, "fibonacci coding can't represent 0", err.Error())
+	assertIntsEqual(t, 8, int(w.Len()))
+}
+
+func TestWriteFibonacciRange(t *testing.T) {
+	var w Writer
+	assertNilError(t, w.WriteFibonacciRange([]int{2, 5, 6, 7}))
+	if !bytes.Equal([]byte{0x00, 0x23, 0x9b}, w.Bytes()) {
+		t.Errorf("Bytes were not equal. Expected 00239b, actual %x", w.Bytes())
+	}
+
+	ids, err := NewReader(w.Bytes()).ReadFibonacciRange(4)
+	assertNilError(t, err)
+	assertIntSlicesEqual(t, []int{2, 5, 6, 7}, ids)
+
+	err = w.WriteFibonacciRange([]int{2, 6, 5})
+	assertStringsEqual(t, "fibonacci ranges need positive, sorted and unique IDs, but 5 followed 6", err.Error())
+	err = w.WriteFibonacciRange([]int{0})
+	assertStringsEqual(t, "fibonacci ranges need positive, sorted and unique IDs, but 0 followed 0", err.Error())
+}
+
+func assertIntSlicesEqual(t *testing.T, expected []int, actual []int) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Fatalf("Slices were not equal. Expected %v, actual %v", expected, actual)
+	}
+	for i := range expected {
+		if expected[i] != actual[i] {
+			t.Fatalf("Slices were not equal. Expected %v, actual %v", expected, actual)
+		}
+	}
+}
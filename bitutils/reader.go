@@ -0,0 +1,93 @@
+package bitutils
+
+import "fmt"
+
+// Reader reads fields of arbitrary width sequentially from a bit-packed byte slice.
+// Formats like GPP pack many small fields back to back, which makes fixed offsets impractical.
+type Reader struct {
+	data []byte
+	pos  uint
+}
+
+// NewReader returns a Reader positioned at the first bit of data.
+func NewReader(data []byte) *Reader {
+	return &Reader{data: data}
+}
+
+// Position returns the index of the next bit to be read.
+func (r *Reader) Position() uint {
+	return r.pos
+}
+
+// Remaining returns the number of bits left to read.
+func (r *Reader) Remaining() uint {
+	total := uint(len(r.data)) * 8
+	if r.pos >= total {
+		return 0
+	}
+	return total - r.pos
+}
+
+// ReadBits reads the next bitCount bits (at most 64) as a big-endian unsigned integer.
+func (r *Reader) ReadBits(bitCount uint) (uint64, error) {
+	if bitCount > 64 {
+		return 0, fmt.Errorf("ReadBits can read at most 64 bits at a time, but %d were requested", bitCount)
+	}
+	if r.Remaining() < bitCount {
+		return 0, fmt.Errorf("ReadBits expected %d bits to start at bit %d, but the data was only %d bytes long", bitCount, r.pos, len(r.data))
+	}
+
+	var value uint64
+	for i := uint(0); i < bitCount; i++ {
+		value <<= 1
+		if r.data[r.pos/8]&(0x80>>(r.pos%8)) != 0 {
+			value |= 1
+		}
+		r.pos++
+	}
+	return value, nil
+}
+
+// ReadBool reads the next bit, returning true if it is a 1.
+func (r *Reader) ReadBool() (bool, error) {
+	value, err := r.ReadBits(1)
+	return value == 1, err
+}
+
+// FieldReader reads fixed layouts field by field. It remembers the first error, after which every read
+// returns zero, so callers only need to check Err once the layout is read.
+type FieldReader struct {
+	r   *Reader
+	err error
+}
+
+// NewFieldReader returns a FieldReader positioned at the first bit of data.
+func NewFieldReader(data []byte) *FieldReader {
+	return &FieldReader{r: NewReader(data)}
+}
+
+// Read reads the next bitCount bits (at most 64) as a big-endian unsigned integer, or returns 0 if this
+// or an earlier read failed.
+func (f *FieldReader) Read(bitCount uint) uint64 {
+	if f.err != nil {
+		return 0
+	}
+	var value uint64
+	value, f.err = f.r.ReadBits(bitCount)
+	return value
+}
+
+// ReadBool reads the next bit, returning true if it is a 1.
+func (f *FieldReader) ReadBool() bool {
+	return f.Read(1) == 1
+}
+
+// Position returns the index of the next bit to be read.
+func (f *FieldReader) Position() uint {
+	return f.r.Position()
+}
+
+// Err returns the error of the first read which failed, or nil if none did.
+func (f *FieldReader) Err() error {
+	return f.err
+}
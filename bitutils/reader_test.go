@@ -0,0 +1,62 @@
+package bitutils
+
+import (
+	"testing"
+)
+
+func TestReaderReadBits(t *testing.T) {
+	// 0000 0100 1010 0010 0000 0011 1011 0001 0000 0000 0010 1011
+	r := NewReader(testdata)
+
+	v, err := r.ReadBits(6)
+	assertNilError(t, err)
+	assertIntsEqual(t, 1, int(v))
+
+	v, err = r.ReadBits(2)
+	assertNilError(t, err)
+	assertIntsEqual(t, 0, int(v))
+
+	b, err := r.ReadBool()
+	assertNilError(t, err)
+	assertBoolsEqual(t, true, b)
+
+	v, err = r.ReadBits(36)
+	assertNilError(t, err)
+	assertIntsEqual(t, 0x440762005, int(v))
+	assertIntsEqual(t, 45, int(r.Position()))
+	assertIntsEqual(t, 3, int(r.Remaining()))
+
+	v, err = r.ReadBits(3)
+	assertNilError(t, err)
+	assertIntsEqual(t, 3, int(v))
+	assertIntsEqual(t, 0, int(r.Remaining()))
+}
+
+func TestReaderErrors(t *testing.T) {
+	r := NewReader(testdata)
+	_, err := r.ReadBits(65)
+	assertStringsEqual(t, "ReadBits can read at most 64 bits at a time, but 65 were requested", err.Error())
+
+	_, err = r.ReadBits(44)
+	assertNilError(t, err)
+	_, err = r.ReadBits(5)
+	assertStringsEqual(t, "ReadBits expected 5 bits to start at bit 44, but the data was only 6 bytes long", err.Error())
+
+	// A failed read must not move the reader.
+	assertIntsEqual(t, 44, int(r.Position()))
+}
+
+func TestFieldReader(t *testing.T) {
+	f := NewFieldReader(testdata)
+	assertIntsEqual(t, 1, int(f.Read(6)))
+	assertIntsEqual(t, 0, int(f.Read(2)))
+	assertBoolsEqual(t, true, f.ReadBool())
+	assertIntsEqual(t, 9, int(f.Position()))
+	assertNilError(t, f.Err())
+
+	// After the first failed read, every read returns zero and the reader stays put.
+	assertIntsEqual(t, 0, int(f.Read(40)))
+	assertIntsEqual(t, 0, int(f.Read(1)))
+	assertIntsEqual(t, 9, int(f.Position()))
+	assertStringsEqual(t, "ReadBits expected 40 bits to start at bit 9, but the data was only 6 bytes long", f.Err().Error())
+}
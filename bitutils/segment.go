@@ -0,0 +1,21 @@
+package bitutils
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// DecodeSegment decodes a base64 URL encoded (unpadded) segment, as used by GPP strings and their sections.
+//
+// These formats treat each character as 6 bits, so a segment's last field may end in a partial byte which
+// the standard decoder would drop. Zero-valued characters are appended to keep every encoded bit.
+func DecodeSegment(segment string) ([]byte, error) {
+	if extra := len(segment) % 4; extra != 0 {
+		segment += "AAA"[:4-extra]
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode segment: %v", err)
+	}
+	return decoded, nil
+}
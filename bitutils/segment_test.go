@@ -0,0 +1,16 @@
+package bitutils
+
+import (
+	"testing"
+)
+
+func TestDecodeSegment(t *testing.T) {
+	// "DBABM" holds 30 bits. The standard decoder would drop the last character's partial byte.
+	data, err := DecodeSegment("DBABM")
+	assertNilError(t, err)
+	assertIntsEqual(t, 6, len(data))
+	assertIntsEqual(t, 0x30, int(data[3]))
+
+	_, err = DecodeSegment("DBA!")
+	assertStringsEqual(t, "failed to decode segment: illegal base64 data at input byte 3", err.Error())
+}
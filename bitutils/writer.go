@@ -0,0 +1,37 @@
+package bitutils
+
+// Writer packs fields of arbitrary width into a byte slice. It is the counterpart of Reader.
+// The zero value is an empty Writer ready to use.
+type Writer struct {
+	data []byte
+	pos  uint
+}
+
+// Len returns the number of bits written so far.
+func (w *Writer) Len() uint {
+	return w.pos
+}
+
+// Bytes returns the written bits, padded with zeros to a whole number of bytes.
+func (w *Writer) Bytes() []byte {
+	return w.data
+}
+
+// WriteBits writes the low bitCount bits (at most 64) of value, most significant bit first.
+// Any higher bits of value are ignored.
+func (w *Writer) WriteBits(value uint64, bitCount uint) {
+	for i := bitCount; i > 0; i-- {
+		w.WriteBool(value&(1<<(i-1)) != 0)
+	}
+}
+
+// WriteBool writes a single bit: 1 for true and 0 for false.
+func (w *Writer) WriteBool(set bool) {
+	if w.pos%8 == 0 {
+		w.data = append(w.data, 0)
+	}
+	if set {
+		w.data[w.pos/8] |= 0x80 >> (w.pos % 8)
+	}
+	w.pos++
+}
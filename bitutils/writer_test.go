@@ -0,0 +1,42 @@
+package bitutils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	var w Writer
+	assertIntsEqual(t, 0, len(w.Bytes()))
+
+	// Rebuild testdata: 0000 0100 1010 0010 0000 0011 1011 0001 0000 0000 0010 1011
+	w.WriteBits(1, 6)
+	w.WriteBits(0, 2)
+	w.WriteBool(true)
+	w.WriteBits(0x440762005, 36)
+	w.WriteBits(0xff3, 3) // Only the low bits are written
+	assertIntsEqual(t, 48, int(w.Len()))
+	if !bytes.Equal(testdata, w.Bytes()) {
+		t.Errorf("Bytes were not equal. Expected %x, actual %x", testdata, w.Bytes())
+	}
+
+	w.WriteBool(true)
+	assertIntsEqual(t, 49, int(w.Len()))
+	if !bytes.Equal(append(testdata, 0x80), w.Bytes()) {
+		t.Errorf("Bytes were not equal. Expected %x80, actual %x", testdata, w.Bytes())
+	}
+}
+
+func TestWriterReaderRoundTrip(t *testing.T) {
+	var w Writer
+	for i := uint(1); i <= 64; i++ {
+		w.WriteBits(uint64(i), i)
+	}
+
+	r := NewReader(w.Bytes())
+	for i := uint(1); i <= 64; i++ {
+		value, err := r.ReadBits(i)
+		assertNilError(t, err)
+		assertIntsEqual(t, int(i), int(value))
+	}
+}
@@ -0,0 +1,194 @@
+// Command benchconsent measures how quickly the vendorconsent package parses a corpus of consent strings,
+// reporting the latency and allocations of each. Its results can be saved and compared with those of
+// another version of the library, so that performance changes are measurable.
+//
+// Usage:
+//
+//	benchconsent [-corpus file] [-benchtime 1s] [-save results.json] [-compare baseline.json]
+//
+// To compare two versions of the library, run it with -save against one of them, and then with -compare
+// against the other. The built-in corpus has BitField-heavy, RangeSection-heavy and multi-segment strings.
+package main
+
+import (
+	"bufio"
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/prebid/go-gdpr/vendorconsent"
+)
+
+//go:embed corpus.txt
+var defaultCorpus string
+
+// entry is a consent string of the corpus.
+type entry struct {
+	name    string
+	consent string
+}
+
+// Result is the measurement of parsing one consent string of the corpus.
+type Result struct {
+	Name        string `json:"name"`
+	NsPerOp     int64  `json:"nsPerOp"`
+	AllocsPerOp int64  `json:"allocsPerOp"`
+	BytesPerOp  int64  `json:"bytesPerOp"`
+}
+
+func main() {
+	testing.Init()
+	corpusFile := flag.String("corpus", "", "a file of consent strings to parse instead of the built-in corpus")
+	benchtime := flag.String("benchtime", "1s", "how long to parse each consent string for, or how many times, like 100x")
+	save := flag.String("save", "", "a file to save the results to as JSON")
+	compare := flag.String("compare", "", "a file of results saved by an earlier run to compare with")
+	flag.Parse()
+	if err := flag.Set("test.benchtime", *benchtime); err != nil {
+		log.Fatalf("Invalid -benchtime: %v", err)
+	}
+
+	corpus, err := loadCorpus(*corpusFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var baseline []Result
+	if *compare != "" {
+		if baseline, err = loadResults(*compare); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	results := make([]Result, 0, len(corpus))
+	for _, e := range corpus {
+		results = append(results, measure(e))
+	}
+	if err := printResults(os.Stdout, results, baseline); err != nil {
+		log.Fatal(err)
+	}
+
+	if *save != "" {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(*save, data, 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// loadCorpus reads the corpus from the file, or returns the built-in one if file is empty.
+func loadCorpus(file string) ([]entry, error) {
+	if file == "" {
+		return readCorpus(strings.NewReader(defaultCorpus))
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readCorpus(f)
+}
+
+// readCorpus reads lines of a name, a space and a consent string. Empty lines and lines starting with #
+// are skipped. Every consent string must parse, so that errors aren't measured by mistake.
+func readCorpus(r io.Reader) ([]entry, error) {
+	var corpus []entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, consent, ok := strings.Cut(text, " ")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected a name and a consent string", line)
+		}
+		consent = strings.TrimSpace(consent)
+		if _, err := vendorconsent.ParseString(consent); err != nil {
+			return nil, fmt.Errorf("line %d: %s doesn't parse: %v", line, name, err)
+		}
+		corpus = append(corpus, entry{name: name, consent: consent})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(corpus) == 0 {
+		return nil, fmt.Errorf("the corpus has no consent strings")
+	}
+	return corpus, nil
+}
+
+// loadResults reads results saved with -save.
+func loadResults(file string) ([]Result, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return results, nil
+}
+
+// measure benchmarks vendorconsent.ParseString, which every version of the library has, on the entry.
+func measure(e entry) Result {
+	result := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			vendorconsent.ParseString(e.consent)
+		}
+	})
+	return Result{
+		Name:        e.name,
+		NsPerOp:     result.NsPerOp(),
+		AllocsPerOp: result.AllocsPerOp(),
+		BytesPerOp:  result.AllocedBytesPerOp(),
+	}
+}
+
+// printResults writes the results as a table. If there's a baseline, the results are compared with the
+// baseline result of the same name.
+func printResults(w io.Writer, results []Result, baseline []Result) error {
+	previous := make(map[string]Result, len(baseline))
+	for _, result := range baseline {
+		previous[result.Name] = result
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	if len(baseline) == 0 {
+		fmt.Fprintln(tw, "name\tns/op\tallocs/op\tB/op\t")
+		for _, r := range results {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t\n", r.Name, r.NsPerOp, r.AllocsPerOp, r.BytesPerOp)
+		}
+		return tw.Flush()
+	}
+
+	fmt.Fprintln(tw, "name\told ns/op\tnew ns/op\tdelta\told allocs/op\tnew allocs/op\told B/op\tnew B/op\t")
+	for _, r := range results {
+		old, ok := previous[r.Name]
+		if !ok {
+			fmt.Fprintf(tw, "%s\t-\t%d\t-\t-\t%d\t-\t%d\t\n", r.Name, r.NsPerOp, r.AllocsPerOp, r.BytesPerOp)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%d\t%d\t%d\t%d\t\n", r.Name, old.NsPerOp, r.NsPerOp, delta(old.NsPerOp, r.NsPerOp), old.AllocsPerOp, r.AllocsPerOp, old.BytesPerOp, r.BytesPerOp)
+	}
+	return tw.Flush()
+}
+
+// delta formats the change from old to new as a percentage.
+func delta(old, new int64) string {
+	if old == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", float64(new-old)*100/float64(old))
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/prebid/go-gdpr/vendorconsent"
+	tcf2 "github.com/prebid/go-gdpr/vendorconsent/tcf2"
+)
+
+func TestDefaultCorpus(t *testing.T) {
+	corpus, err := loadCorpus("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make(map[string]string, len(corpus))
+	for _, e := range corpus {
+		names[e.name] = e.consent
+	}
+	for _, name := range []string{"bitfield-heavy", "range-heavy", "multi-segment"} {
+		if _, ok := names[name]; !ok {
+			t.Errorf("The corpus has no %s string", name)
+		}
+	}
+
+	parsed, err := vendorconsent.ParseString(names["multi-segment"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata := parsed.(tcf2.ConsentMetadata)
+	if !metadata.HasDisclosedVendors() || !metadata.HasPublisherTC() {
+		t.Errorf("The multi-segment string should have Disclosed Vendors and Publisher TC segments")
+	}
+}
+
+func TestReadCorpus(t *testing.T) {
+	corpus, err := readCorpus(strings.NewReader("# comment\n\nsmall COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corpus) != 1 || corpus[0].name != "small" {
+		t.Errorf("Unexpected corpus %v", corpus)
+	}
+
+	for _, corpus := range []string{"", "# comment only\n", "nameonly\n", "invalid COw\n"} {
+		if _, err := readCorpus(strings.NewReader(corpus)); err == nil {
+			t.Errorf("Expected an error for the corpus %q", corpus)
+		}
+	}
+}
+
+func TestPrintResults(t *testing.T) {
+	results := []Result{{Name: "a", NsPerOp: 150, AllocsPerOp: 1, BytesPerOp: 64}, {Name: "b", NsPerOp: 10, AllocsPerOp: 0}}
+	baseline := []Result{{Name: "a", NsPerOp: 200, AllocsPerOp: 3, BytesPerOp: 128}}
+
+	var out bytes.Buffer
+	if err := printResults(&out, results, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "allocs/op") || strings.Contains(out.String(), "delta") {
+		t.Errorf("Unexpected results without a baseline:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := printResults(&out, results, baseline); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and 2 results. Got:\n%s", out.String())
+	}
+	if fields := strings.Fields(lines[1]); strings.Join(fields, " ") != "a 200 150 -25.0% 3 1 128 64" {
+		t.Errorf("Unexpected comparison %q", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); strings.Join(fields, " ") != "b - 10 - - 0 - 0" {
+		t.Errorf("Unexpected comparison %q", lines[2])
+	}
+}
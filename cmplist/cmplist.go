@@ -0,0 +1,88 @@
+// Package cmplist parses and fetches the list of Consent Management Platforms registered with IAB Europe.
+// Every TCF consent string records the ID of the CMP which last updated it, and a string from a CMP which
+// isn't registered, or whose registration was revoked, may not have been collected according to the TCF.
+//
+// For the latest version, see: https://cmplist.consensu.org/v2/cmp-list.json
+package cmplist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// List is the list of registered CMPs.
+type List struct {
+	LastUpdated time.Time
+	// CMPs holds every CMP in the list, including deleted ones, by ID.
+	CMPs map[uint16]CMP
+}
+
+// CMP describes a registered CMP.
+type CMP struct {
+	ID           uint16
+	Name         string
+	IsCommercial bool
+	// Environments lists where the CMP runs, such as "Web" or "Native App (Mobile)".
+	Environments []string
+	// DeletedAt is when the CMP's registration was revoked, or the zero time if it's still registered.
+	DeletedAt time.Time
+}
+
+// Deleted returns true if the CMP's registration was revoked.
+func (c CMP) Deleted() bool {
+	return !c.DeletedAt.IsZero()
+}
+
+// CMP returns the CMP with the given ID. The bool is false if the list doesn't have it.
+func (l *List) CMP(id uint16) (CMP, bool) {
+	cmp, ok := l.CMPs[id]
+	return cmp, ok
+}
+
+// Parse parses a CMP list. It returns an error if the data isn't valid JSON, has no cmps, or a CMP's ID
+// doesn't match its key.
+func Parse(data []byte) (*List, error) {
+	var contract listContract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		return nil, fmt.Errorf("failed to parse the CMP list: %v", err)
+	}
+	if contract.CMPs == nil {
+		return nil, errors.New("invalid CMP list: cmps is missing")
+	}
+
+	list := &List{
+		LastUpdated: contract.LastUpdated,
+		CMPs:        make(map[uint16]CMP, len(contract.CMPs)),
+	}
+	for key, c := range contract.CMPs {
+		if key != fmt.Sprint(c.ID) || c.ID == 0 {
+			return nil, fmt.Errorf("invalid CMP list: cmps[%q] has id %d", key, c.ID)
+		}
+		cmp := CMP{
+			ID:           c.ID,
+			Name:         c.Name,
+			IsCommercial: c.IsCommercial,
+			Environments: c.Environments,
+		}
+		if c.DeletedAt != nil {
+			cmp.DeletedAt = *c.DeletedAt
+		}
+		list.CMPs[c.ID] = cmp
+	}
+	return list, nil
+}
+
+type listContract struct {
+	LastUpdated time.Time              `json:"lastUpdated"`
+	CMPs        map[string]cmpContract `json:"cmps"`
+}
+
+type cmpContract struct {
+	ID           uint16     `json:"id"`
+	Name         string     `json:"name"`
+	IsCommercial bool       `json:"isCommercial"`
+	Environments []string   `json:"environments"`
+	DeletedAt    *time.Time `json:"deletedAt"`
+}
@@ -0,0 +1,77 @@
+package cmplist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testList = `{
+	"lastUpdated": "2024-03-01T16:00:23Z",
+	"cmps": {
+		"2": {"id": 2, "name": "Registered CMP", "isCommercial": true, "environments": ["Web", "Native App (Mobile)"]},
+		"6": {"id": 6, "name": "Deleted CMP", "isCommercial": false, "environments": ["Web"], "deletedAt": "2020-08-15T00:00:00Z"}
+	}
+}`
+
+func TestParse(t *testing.T) {
+	list, err := Parse([]byte(testList))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 16, 0, 23, 0, time.UTC), list.LastUpdated)
+	assert.Len(t, list.CMPs, 2)
+
+	registered, ok := list.CMP(2)
+	assert.True(t, ok)
+	assert.Equal(t, CMP{
+		ID:           2,
+		Name:         "Registered CMP",
+		IsCommercial: true,
+		Environments: []string{"Web", "Native App (Mobile)"},
+	}, registered)
+	assert.False(t, registered.Deleted())
+
+	deleted, ok := list.CMP(6)
+	assert.True(t, ok)
+	assert.True(t, deleted.Deleted())
+	assert.Equal(t, time.Date(2020, 8, 15, 0, 0, 0, 0, time.UTC), deleted.DeletedAt)
+
+	_, ok = list.CMP(3)
+	assert.False(t, ok)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		expectedError string
+	}{
+		{
+			name:          "invalid_json",
+			data:          `{"cmps": [`,
+			expectedError: "failed to parse the CMP list: unexpected end of JSON input",
+		},
+		{
+			name:          "missing_cmps",
+			data:          `{"lastUpdated": "2024-03-01T16:00:23Z"}`,
+			expectedError: "invalid CMP list: cmps is missing",
+		},
+		{
+			name:          "mismatched_id",
+			data:          `{"cmps": {"2": {"id": 3}}}`,
+			expectedError: `invalid CMP list: cmps["2"] has id 3`,
+		},
+		{
+			name:          "zero_id",
+			data:          `{"cmps": {"0": {"id": 0}}}`,
+			expectedError: `invalid CMP list: cmps["0"] has id 0`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
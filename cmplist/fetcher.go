@@ -0,0 +1,52 @@
+package cmplist
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultURL is where IAB Europe publishes the CMP list.
+const DefaultURL = "https://cmplist.consensu.org/v2/cmp-list.json"
+
+// MaxListSize is the largest CMP list Fetch will read. The real list is a few hundred kilobytes.
+const MaxListSize = 8 << 20
+
+// Fetch downloads and parses the CMP list at url, usually DefaultURL. If client is nil,
+// http.DefaultClient is used.
+//
+// The list changes a few times a month, so fetch it at startup and then every day or so, rather than
+// per request.
+func Fetch(ctx context.Context, client *http.Client, url string) (*List, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request for CMP list %s: %v", url, err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch CMP list %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CMP list %s returned status %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxListSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CMP list %s: %v", url, err)
+	}
+	if len(data) > MaxListSize {
+		return nil, fmt.Errorf("CMP list %s is larger than %d bytes", url, MaxListSize)
+	}
+
+	list, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CMP list %s: %v", url, err)
+	}
+	return list, nil
+}
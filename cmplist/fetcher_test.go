@@ -0,0 +1,60 @@
+package cmplist
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cmp-list.json":
+			w.Write([]byte(testList))
+		case "/invalid.json":
+			w.Write([]byte(`{}`))
+		case "/huge.json":
+			w.Write([]byte(strings.Repeat(" ", MaxListSize+1)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	list, err := Fetch(context.Background(), nil, server.URL+"/cmp-list.json")
+	assert.NoError(t, err)
+	assert.Len(t, list.CMPs, 2)
+
+	tests := []struct {
+		name          string
+		path          string
+		expectedError string
+	}{
+		{
+			name:          "not_found",
+			path:          "/missing.json",
+			expectedError: "CMP list " + server.URL + "/missing.json returned status 404",
+		},
+		{
+			name:          "invalid",
+			path:          "/invalid.json",
+			expectedError: "failed to parse CMP list " + server.URL + "/invalid.json: invalid CMP list: cmps is missing",
+		},
+		{
+			name:          "too_large",
+			path:          "/huge.json",
+			expectedError: "CMP list " + server.URL + "/huge.json is larger than 8388608 bytes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Fetch(context.Background(), server.Client(), server.URL+tt.path)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package consent
+
+import (
+	"fmt"
+
+	"github.com/prebid/go-gdpr/gpp"
+	"github.com/prebid/go-gdpr/usprivacy"
+)
+
+// GDPRSignal is the gdpr_applies flag sent alongside the consent strings, such as OpenRTB's regs.gdpr.
+type GDPRSignal int
+
+const (
+	// GDPRUnknown means the flag wasn't sent.
+	GDPRUnknown GDPRSignal = iota
+	// GDPRApplies means the sender says the GDPR applies to the request.
+	GDPRApplies
+	// GDPRDoesNotApply means the sender says the GDPR doesn't apply to the request.
+	GDPRDoesNotApply
+)
+
+// FindingCode identifies a kind of inconsistency between signals. The codes are stable, so they can be
+// used as metric labels.
+type FindingCode string
+
+const (
+	// FindingTCFWithoutGDPR means the GPP string has a TCF EU section, but the GDPR was signaled as not applying.
+	FindingTCFWithoutGDPR FindingCode = "tcf_without_gdpr"
+	// FindingGDPRWithoutTCF means the GDPR was signaled as applying, but the GPP string has no TCF EU section.
+	FindingGDPRWithoutTCF FindingCode = "gdpr_without_tcf"
+	// FindingUSOptOutMismatch means the US National section and a US state section disagree on an opt-out.
+	FindingUSOptOutMismatch FindingCode = "us_opt_out_mismatch"
+	// FindingUSPrivacyMismatch means the uspv1 section disagrees with a US section on the sale opt-out.
+	FindingUSPrivacyMismatch FindingCode = "us_privacy_mismatch"
+)
+
+// Finding is a single inconsistency found by CheckConsistency.
+type Finding struct {
+	Code FindingCode
+	// Sections holds the IDs of the GPP sections involved, in ascending order.
+	Sections []int
+	Message  string
+}
+
+// CheckConsistency looks for GPP payloads whose signals contradict each other, or contradict the
+// gdpr_applies flag sent alongside them. Each section may be valid on its own: these findings point to a
+// misconfigured CMP rather than a malformed string, so they're meant for monitoring, not enforcement.
+//
+// Opt-outs are only compared when both sections define them, so a state section which doesn't cover
+// sharing never conflicts with the US National section's sharing opt-out.
+func CheckConsistency(container *gpp.Container, gdpr GDPRSignal) []Finding {
+	var findings []Finding
+
+	_, hasTCF := container.Section(gpp.SectionTCFEUV2)
+	switch {
+	case hasTCF && gdpr == GDPRDoesNotApply:
+		findings = append(findings, Finding{
+			Code:     FindingTCFWithoutGDPR,
+			Sections: []int{gpp.SectionTCFEUV2},
+			Message:  "the GPP string has a TCF EU section, but the GDPR was signaled as not applying",
+		})
+	case !hasTCF && gdpr == GDPRApplies:
+		findings = append(findings, Finding{
+			Code:    FindingGDPRWithoutTCF,
+			Message: "the GDPR was signaled as applying, but the GPP string has no TCF EU section",
+		})
+	}
+
+	var usNat gpp.USSection
+	var states []gpp.USSection
+	for _, section := range container.USSections() {
+		if section.ID() == gpp.SectionUSNat {
+			usNat = section
+		} else {
+			states = append(states, section)
+		}
+	}
+	if usNat != nil {
+		for _, state := range states {
+			findings = append(findings, compareOptOuts(usNat, state)...)
+		}
+	}
+
+	if section, ok := container.Section(gpp.SectionUSPV1); ok {
+		if usp, ok := section.(gpp.USPV1Section); ok {
+			for _, us := range container.USSections() {
+				if finding, ok := compareSaleOptOut(usp, us); ok {
+					findings = append(findings, finding)
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func compareOptOuts(usNat, state gpp.USSection) []Finding {
+	var findings []Finding
+	for _, optOut := range []struct {
+		name         string
+		usNat, state gpp.OptOut
+	}{
+		{"sale", usNat.SaleOptOut(), state.SaleOptOut()},
+		{"sharing", usNat.SharingOptOut(), state.SharingOptOut()},
+		{"targeted advertising", usNat.TargetedAdvertisingOptOut(), state.TargetedAdvertisingOptOut()},
+	} {
+		if optOut.usNat == gpp.OptOutNotApplicable || optOut.state == gpp.OptOutNotApplicable || optOut.usNat == optOut.state {
+			continue
+		}
+		findings = append(findings, Finding{
+			Code:     FindingUSOptOutMismatch,
+			Sections: []int{usNat.ID(), state.ID()},
+			Message: fmt.Sprintf("section %d says the user %s of %s, but section %d says they %s",
+				usNat.ID(), describeOptOut(optOut.usNat), optOut.name, state.ID(), describeOptOut(optOut.state)),
+		})
+	}
+	return findings
+}
+
+func compareSaleOptOut(usp gpp.USPV1Section, us gpp.USSection) (Finding, bool) {
+	var uspOptOut gpp.OptOut
+	switch usp.OptOutSale {
+	case usprivacy.Yes:
+		uspOptOut = gpp.OptedOut
+	case usprivacy.No:
+		uspOptOut = gpp.DidNotOptOut
+	default:
+		return Finding{}, false
+	}
+	if us.SaleOptOut() == gpp.OptOutNotApplicable || us.SaleOptOut() == uspOptOut {
+		return Finding{}, false
+	}
+	return Finding{
+		Code:     FindingUSPrivacyMismatch,
+		Sections: []int{gpp.SectionUSPV1, us.ID()},
+		Message: fmt.Sprintf("section %d says the user %s of sale, but section %d says they %s",
+			gpp.SectionUSPV1, describeOptOut(uspOptOut), us.ID(), describeOptOut(us.SaleOptOut())),
+	}, true
+}
+
+func describeOptOut(optOut gpp.OptOut) string {
+	if optOut == gpp.OptedOut {
+		return "opted out"
+	}
+	return "did not opt out"
+}
@@ -0,0 +1,112 @@
+package consent
+
+import (
+	"testing"
+
+	"github.com/prebid/go-gdpr/gpp"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// usNatOptedOut is a US National section where the user opted out of sale, sharing and targeted advertising.
+	usNatOptedOut = "BVVVAAAAAg"
+	// usNatNotOptedOut is a US National section where the user opted out of nothing.
+	usNatNotOptedOut = "BVVqAAAAAg"
+	// usVASaleAllowed is a Virginia section where the user opted out of targeted advertising, but not of sale.
+	usVASaleAllowed = "BVkAACA"
+	// usVAOptedOut is a Virginia section where the user opted out of both sale and targeted advertising.
+	usVAOptedOut = "BVUAACA"
+)
+
+func TestCheckConsistency(t *testing.T) {
+	tests := []struct {
+		name             string
+		gpp              string
+		gdpr             GDPRSignal
+		expectedFindings []Finding
+	}{
+		{
+			name: "tcf_and_gdpr",
+			gpp:  "DBABMA~" + gppTCString,
+			gdpr: GDPRApplies,
+		},
+		{
+			name: "tcf_and_unknown_gdpr",
+			gpp:  "DBABMA~" + gppTCString,
+			gdpr: GDPRUnknown,
+		},
+		{
+			name: "tcf_without_gdpr",
+			gpp:  "DBABMA~" + gppTCString,
+			gdpr: GDPRDoesNotApply,
+			expectedFindings: []Finding{{
+				Code:     FindingTCFWithoutGDPR,
+				Sections: []int{gpp.SectionTCFEUV2},
+				Message:  "the GPP string has a TCF EU section, but the GDPR was signaled as not applying",
+			}},
+		},
+		{
+			name: "gdpr_without_tcf",
+			gpp:  "DBABLA~" + usNatOptedOut,
+			gdpr: GDPRApplies,
+			expectedFindings: []Finding{{
+				Code:    FindingGDPRWithoutTCF,
+				Message: "the GDPR was signaled as applying, but the GPP string has no TCF EU section",
+			}},
+		},
+		{
+			name: "us_sections_agree",
+			gpp:  "DBACLM~" + usNatOptedOut + "~" + usVAOptedOut,
+		},
+		{
+			name: "us_state_more_permissive",
+			gpp:  "DBACLM~" + usNatOptedOut + "~" + usVASaleAllowed,
+			expectedFindings: []Finding{{
+				Code:     FindingUSOptOutMismatch,
+				Sections: []int{gpp.SectionUSNat, gpp.SectionUSVA},
+				Message:  "section 7 says the user opted out of sale, but section 9 says they did not opt out",
+			}},
+		},
+		{
+			name: "us_state_more_restrictive",
+			gpp:  "DBACLM~" + usNatNotOptedOut + "~" + usVAOptedOut,
+			expectedFindings: []Finding{
+				{
+					Code:     FindingUSOptOutMismatch,
+					Sections: []int{gpp.SectionUSNat, gpp.SectionUSVA},
+					Message:  "section 7 says the user did not opt out of sale, but section 9 says they opted out",
+				},
+				{
+					Code:     FindingUSOptOutMismatch,
+					Sections: []int{gpp.SectionUSNat, gpp.SectionUSVA},
+					Message:  "section 7 says the user did not opt out of targeted advertising, but section 9 says they opted out",
+				},
+			},
+		},
+		{
+			name: "us_privacy_agrees",
+			gpp:  "DBABzw~1YY-~" + usNatOptedOut,
+		},
+		{
+			name: "us_privacy_not_applicable",
+			gpp:  "DBABzw~1---~" + usNatOptedOut,
+		},
+		{
+			name: "us_privacy_mismatch",
+			gpp:  "DBABzw~1YN-~" + usNatOptedOut,
+			expectedFindings: []Finding{{
+				Code:     FindingUSPrivacyMismatch,
+				Sections: []int{gpp.SectionUSPV1, gpp.SectionUSNat},
+				Message:  "section 6 says the user did not opt out of sale, but section 7 says they opted out",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			container, err := gpp.Parse(tt.gpp)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedFindings, CheckConsistency(container, tt.gdpr))
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package consent
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/gpp"
+	"github.com/prebid/go-gdpr/usprivacy"
+	"github.com/prebid/go-gdpr/vendorconsent"
+)
+
+// ConsentContext bundles the privacy signals of a single request. Each signal is only parsed the first
+// time it's asked for, and the result is kept, so a request which never looks at its us_privacy string
+// never pays to parse it.
+//
+// A ConsentContext is safe for concurrent use.
+type ConsentContext struct {
+	gdpr      GDPRSignal
+	tcString  string
+	gppString string
+	gppSIDs   []int
+	usPrivacy string
+
+	tcfOnce sync.Once
+	tcf     api.VendorConsents
+	tcfErr  error
+
+	gppOnce sync.Once
+	gpp     *gpp.Container
+	gppErr  error
+
+	usPrivacyOnce    sync.Once
+	usPrivacyConsent usprivacy.Consent
+	usPrivacyErr     error
+}
+
+// NewConsentContext returns a ConsentContext for the signals of a request. Any of the strings may be empty,
+// and gppSIDs may be nil. Nothing is parsed until it's needed.
+func NewConsentContext(gdpr GDPRSignal, tcString, gppString string, gppSIDs []int, usPrivacy string) *ConsentContext {
+	return &ConsentContext{
+		gdpr:      gdpr,
+		tcString:  tcString,
+		gppString: gppString,
+		gppSIDs:   gppSIDs,
+		usPrivacy: usPrivacy,
+	}
+}
+
+// GDPR returns the gdpr_applies flag of the request.
+func (c *ConsentContext) GDPR() GDPRSignal {
+	return c.gdpr
+}
+
+// TCString returns the standalone TC string of the request.
+func (c *ConsentContext) TCString() string {
+	return c.tcString
+}
+
+// GPPString returns the GPP string of the request.
+func (c *ConsentContext) GPPString() string {
+	return c.gppString
+}
+
+// GPPSIDs returns the gpp_sid values of the request.
+func (c *ConsentContext) GPPSIDs() []int {
+	return c.gppSIDs
+}
+
+// USPrivacyString returns the standalone us_privacy string of the request.
+func (c *ConsentContext) USPrivacyString() string {
+	return c.usPrivacy
+}
+
+// TCF returns the parsed standalone TC string, or nil if the request had none.
+func (c *ConsentContext) TCF() (api.VendorConsents, error) {
+	c.tcfOnce.Do(func() {
+		if c.tcString == "" {
+			return
+		}
+		if c.tcf, c.tcfErr = vendorconsent.ParseString(c.tcString); c.tcfErr != nil {
+			c.tcf = nil
+			c.tcfErr = fmt.Errorf("invalid TC string: %v", c.tcfErr)
+		}
+	})
+	return c.tcf, c.tcfErr
+}
+
+// GPP returns the GPP string, or nil if the request had none. Only the header is checked here: like
+// gpp.ParseLazy, each section is decoded the first time it's accessed.
+func (c *ConsentContext) GPP() (*gpp.Container, error) {
+	c.gppOnce.Do(func() {
+		if c.gppString == "" {
+			return
+		}
+		if c.gpp, c.gppErr = gpp.ParseLazy(c.gppString); c.gppErr != nil {
+			c.gppErr = fmt.Errorf("invalid GPP string: %v", c.gppErr)
+		}
+	})
+	return c.gpp, c.gppErr
+}
+
+// ApplicableGPPSections returns the sections of the GPP string which gpp_sid lists. It returns nil if
+// the request had no GPP string.
+func (c *ConsentContext) ApplicableGPPSections() ([]gpp.Section, error) {
+	container, err := c.GPP()
+	if container == nil {
+		return nil, err
+	}
+	return gpp.ApplicableSections(container, c.gppSIDs), nil
+}
+
+// USPrivacy returns the parsed standalone us_privacy string. The bool is false if the request had none.
+func (c *ConsentContext) USPrivacy() (usprivacy.Consent, bool, error) {
+	c.usPrivacyOnce.Do(func() {
+		if c.usPrivacy == "" {
+			return
+		}
+		if c.usPrivacyConsent, c.usPrivacyErr = usprivacy.Parse(c.usPrivacy); c.usPrivacyErr != nil {
+			c.usPrivacyErr = fmt.Errorf("invalid us_privacy string: %v", c.usPrivacyErr)
+		}
+	})
+	return c.usPrivacyConsent, c.usPrivacy != "" && c.usPrivacyErr == nil, c.usPrivacyErr
+}
@@ -0,0 +1,97 @@
+package consent
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/prebid/go-gdpr/gpp"
+	"github.com/prebid/go-gdpr/usprivacy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsentContext(t *testing.T) {
+	ctx := NewConsentContext(GDPRApplies, otherTCString, gppWithTCFAndUSP, []int{2}, "1YN-")
+
+	assert.Equal(t, GDPRApplies, ctx.GDPR())
+	assert.Equal(t, otherTCString, ctx.TCString())
+	assert.Equal(t, gppWithTCFAndUSP, ctx.GPPString())
+	assert.Equal(t, []int{2}, ctx.GPPSIDs())
+	assert.Equal(t, "1YN-", ctx.USPrivacyString())
+
+	tcf, err := ctx.TCF()
+	assert.NoError(t, err)
+	assert.NotNil(t, tcf)
+
+	container, err := ctx.GPP()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{gpp.SectionTCFEUV2, gpp.SectionUSPV1}, container.SectionsPresent())
+
+	sections, err := ctx.ApplicableGPPSections()
+	assert.NoError(t, err)
+	if assert.Len(t, sections, 1) {
+		assert.Equal(t, gpp.SectionTCFEUV2, sections[0].ID())
+	}
+
+	consent, ok, err := ctx.USPrivacy()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, usprivacy.No, consent.OptOutSale)
+}
+
+func TestConsentContextEmpty(t *testing.T) {
+	ctx := NewConsentContext(GDPRUnknown, "", "", nil, "")
+
+	tcf, err := ctx.TCF()
+	assert.NoError(t, err)
+	assert.Nil(t, tcf)
+
+	container, err := ctx.GPP()
+	assert.NoError(t, err)
+	assert.Nil(t, container)
+
+	sections, err := ctx.ApplicableGPPSections()
+	assert.NoError(t, err)
+	assert.Nil(t, sections)
+
+	_, ok, err := ctx.USPrivacy()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestConsentContextInvalid(t *testing.T) {
+	ctx := NewConsentContext(GDPRApplies, "COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA!", "DBACNY~"+gppTCString, []int{2}, "1YN")
+
+	tcf, err := ctx.TCF()
+	assert.EqualError(t, err, "invalid TC string: failed to decode segment: illegal base64 data at input byte 47")
+	assert.Nil(t, tcf)
+
+	container, err := ctx.GPP()
+	assert.EqualError(t, err, "invalid GPP string: the GPP header lists more sections than the 1 the string contains")
+	assert.Nil(t, container)
+
+	_, err = ctx.ApplicableGPPSections()
+	assert.EqualError(t, err, "invalid GPP string: the GPP header lists more sections than the 1 the string contains")
+
+	_, ok, err := ctx.USPrivacy()
+	assert.EqualError(t, err, `invalid us_privacy string: us_privacy strings must be 4 characters long, but "1YN" has 3`)
+	assert.False(t, ok)
+}
+
+func TestConsentContextConcurrent(t *testing.T) {
+	ctx := NewConsentContext(GDPRApplies, otherTCString, gppWithTCFAndUSP, []int{2, 5}, "1YN-")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ctx.TCF()
+			assert.NoError(t, err)
+			_, err = ctx.ApplicableGPPSections()
+			assert.NoError(t, err)
+			_, _, err = ctx.USPrivacy()
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+}
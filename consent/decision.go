@@ -0,0 +1,213 @@
+package consent
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+	tcf2 "github.com/prebid/go-gdpr/consentconstants/tcf2"
+	"github.com/prebid/go-gdpr/gpp"
+	"github.com/prebid/go-gdpr/permissions"
+	"github.com/prebid/go-gdpr/usprivacy"
+)
+
+// Activity is something a service may want to do with a request's personal data on a vendor's behalf.
+type Activity int
+
+const (
+	// ActivityTransmitUserIDs is sending user IDs, such as buyer UIDs and device IDs, to the vendor.
+	ActivityTransmitUserIDs Activity = iota
+	// ActivityGeolocate is sending the user's precise geolocation to the vendor.
+	ActivityGeolocate
+	// ActivitySyncCookies is letting the vendor sync its cookie with the user's browser.
+	ActivitySyncCookies
+)
+
+// Activities lists every Activity.
+var Activities = []Activity{ActivityTransmitUserIDs, ActivityGeolocate, ActivitySyncCookies}
+
+func (a Activity) String() string {
+	switch a {
+	case ActivityTransmitUserIDs:
+		return "transmit_user_ids"
+	case ActivityGeolocate:
+		return "geolocate"
+	case ActivitySyncCookies:
+		return "sync_cookies"
+	default:
+		return fmt.Sprintf("Activity(%d)", int(a))
+	}
+}
+
+// DecisionInput holds the request details which Decide needs besides its consent strings.
+type DecisionInput struct {
+	// GDPRScope says whether the GDPR applies to the request. See permissions.Evaluator.Scope. The zero
+	// value is permissions.ScopeApplies.
+	GDPRScope permissions.Scope
+	// COPPA is the request's COPPA flag, such as OpenRTB's regs.coppa.
+	COPPA bool
+	// SecGPC is the value of the request's Sec-GPC header. It's only honored when the US laws apply; see
+	// USLawsApply.
+	SecGPC string
+	// GPPSIDs is the request's gpp_sid, which lists the sections of the GPP string that apply to it. See
+	// gpp.ParseSIDs. US sections which it doesn't list are ignored.
+	GPPSIDs []int
+	// USLawsApply says the US state privacy laws apply to the request, for instance because the caller
+	// located the user in the US. They also apply whenever GPPSIDs lists a US section: uspv1, US National
+	// or a US state.
+	USLawsApply bool
+}
+
+// PrivacyDecision holds which activities a request's privacy signals allow for one vendor.
+type PrivacyDecision struct {
+	TransmitUserIDs bool
+	Geolocate       bool
+	SyncCookies     bool
+
+	// Reasons says why each denied activity was denied. Allowed activities have no entry.
+	Reasons map[Activity]string
+}
+
+// Allowed returns true if the activity is allowed.
+func (d PrivacyDecision) Allowed(activity Activity) bool {
+	switch activity {
+	case ActivityTransmitUserIDs:
+		return d.TransmitUserIDs
+	case ActivityGeolocate:
+		return d.Geolocate
+	case ActivitySyncCookies:
+		return d.SyncCookies
+	default:
+		return false
+	}
+}
+
+// deny denies the activity, keeping the first reason it was denied for.
+func (d *PrivacyDecision) deny(activity Activity, format string, args ...interface{}) {
+	if !d.Allowed(activity) {
+		return
+	}
+	switch activity {
+	case ActivityTransmitUserIDs:
+		d.TransmitUserIDs = false
+	case ActivityGeolocate:
+		d.Geolocate = false
+	case ActivitySyncCookies:
+		d.SyncCookies = false
+	}
+	d.Reasons[activity] = fmt.Sprintf(format, args...)
+}
+
+// Decide combines the request's signals into a PrivacyDecision for the vendor. Each activity is allowed
+// unless one of the signals denies it:
+//
+//   - COPPA denies every activity.
+//   - An opt-out of sale, sharing or targeted advertising under the US laws denies every activity. It
+//     may come from the us_privacy string, a US section of the GPP string which gpp_sid lists, or a
+//     Global Privacy Control signal. See ResolveUSOptOuts.
+//   - GPC is a US state law signal, so a Sec-GPC header only counts if the US laws apply to the request:
+//     if input.USLawsApply is set, or gpp_sid lists a US section. Otherwise it's ignored, and a user
+//     covered by the GDPR who gave TCF consent keeps it.
+//   - If the GDPR applies, the vendor needs a legal basis from the evaluator for purpose 1 to sync
+//     cookies, for purpose 2 to receive user IDs, and for special feature 1 to receive precise
+//     geolocation. Requests without TCF consents are denied all three.
+//
+// gvl must be the vendor list the TCF consents were written for. It may be nil if the GDPR doesn't apply.
+func Decide(evaluator *permissions.Evaluator, gvl api.VendorList, signals Signals, input DecisionInput, vendorID uint16) PrivacyDecision {
+	decision := PrivacyDecision{
+		TransmitUserIDs: true,
+		Geolocate:       true,
+		SyncCookies:     true,
+		Reasons:         make(map[Activity]string),
+	}
+
+	if input.COPPA {
+		for _, activity := range Activities {
+			decision.deny(activity, "COPPA applies to the request")
+		}
+	}
+
+	if reason, optedOut := usOptOut(signals, input); optedOut {
+		for _, activity := range Activities {
+			decision.deny(activity, "%s", reason)
+		}
+	}
+
+	if input.GDPRScope == permissions.ScopeApplies {
+		decideTCF(&decision, evaluator, gvl, signals.TCF, vendorID)
+	}
+	return decision
+}
+
+// decideTCF denies the activities which the vendor has no legal basis for.
+func decideTCF(decision *PrivacyDecision, evaluator *permissions.Evaluator, gvl api.VendorList, consent api.VendorConsents, vendorID uint16) {
+	if consent == nil {
+		for _, activity := range Activities {
+			decision.deny(activity, "the GDPR applies, but the request has no TC string")
+		}
+		return
+	}
+	purposes := []struct {
+		activity Activity
+		purpose  consentconstants.Purpose
+	}{
+		{ActivitySyncCookies, tcf2.InfoStorageAccess},
+		{ActivityTransmitUserIDs, tcf2.BasicAdserving},
+	}
+	for _, p := range purposes {
+		if evaluator.Evaluate(consent, gvl, vendorID, p.purpose) == permissions.LegalBasisNone {
+			decision.deny(p.activity, "vendor %d has no legal basis for purpose %d", vendorID, p.purpose)
+		}
+	}
+	if evaluator.EvaluateSpecialFeature(consent, gvl, vendorID, tcf2.Geolocation) == permissions.LegalBasisNone {
+		decision.deny(ActivityGeolocate, "vendor %d may not use special feature 1, precise geolocation", vendorID)
+	}
+}
+
+// usOptOut returns the reason the user opted out under the US laws, if they did. Only the US sections
+// which apply to the request are considered.
+func usOptOut(signals Signals, input DecisionInput) (string, bool) {
+	var sections []gpp.USSection
+	if signals.GPP != nil {
+		for _, section := range signals.GPP.USSections() {
+			if slices.Contains(input.GPPSIDs, section.ID()) {
+				sections = append(sections, section)
+			}
+		}
+	}
+	secGPC := input.SecGPC
+	if !usLawsApply(input) {
+		secGPC = ""
+	}
+	optOuts := ResolveUSOptOuts(secGPC, sections)
+	switch {
+	case optOuts.GPC:
+		return "the user sent a Global Privacy Control signal", true
+	case optOuts.Sale:
+		return "the user opted out of the sale of their data", true
+	case optOuts.Sharing:
+		return "the user opted out of the sharing of their data", true
+	case optOuts.TargetedAdvertising:
+		return "the user opted out of targeted advertising", true
+	}
+	if signals.USPrivacy != "" {
+		if consent, err := usprivacy.Parse(signals.USPrivacy); err == nil && consent.OptedOutOfSale() {
+			return "the user opted out of the sale of their data", true
+		}
+	}
+	return "", false
+}
+
+// usLawsApply returns true if the caller says the US laws apply to the request, or gpp_sid lists a US section.
+func usLawsApply(input DecisionInput) bool {
+	if input.USLawsApply {
+		return true
+	}
+	for _, id := range input.GPPSIDs {
+		if id == gpp.SectionUSPV1 || (id >= gpp.SectionUSNat && id <= gpp.SectionUSCT) {
+			return true
+		}
+	}
+	return false
+}
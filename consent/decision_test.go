@@ -0,0 +1,226 @@
+package consent
+
+import (
+	"testing"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/prebid/go-gdpr/gpp"
+	"github.com/prebid/go-gdpr/permissions"
+	"github.com/prebid/go-gdpr/vendorlist2"
+	"github.com/stretchr/testify/assert"
+)
+
+// decisionList is a vendor list for otherTCString, which has consent for purposes 1 and 2 and vendors
+// 1, 2 and 4, but no special feature opt-ins.
+const decisionList = `{
+	"gvlSpecificationVersion": 2,
+	"vendorListVersion": 14,
+	"vendors": {
+		"1": {"id": 1, "purposes": [1, 2], "specialFeatures": [1]},
+		"2": {"id": 2, "purposes": [1]},
+		"3": {"id": 3, "purposes": [1, 2]}
+	}
+}`
+
+func TestDecide(t *testing.T) {
+	list, err := vendorlist2.ParseEagerly([]byte(decisionList))
+	assert.NoError(t, err)
+	evaluator := permissions.NewEvaluator(permissions.Config{
+		SpecialFeatures: map[consentconstants.SpecialFeature]permissions.SpecialFeatureConfig{
+			1: {VendorExceptions: []uint16{2}},
+		},
+	})
+
+	tests := []struct {
+		name      string
+		gpp       string
+		tcString  string
+		usPrivacy string
+		input     DecisionInput
+		vendorID  uint16
+		expected  PrivacyDecision
+	}{
+		{
+			name:     "tcf_allowed",
+			tcString: otherTCString,
+			vendorID: 1,
+			expected: PrivacyDecision{
+				TransmitUserIDs: true,
+				SyncCookies:     true,
+				Reasons:         map[Activity]string{ActivityGeolocate: "vendor 1 may not use special feature 1, precise geolocation"},
+			},
+		},
+		{
+			name:     "tcf_purpose_not_declared",
+			tcString: otherTCString,
+			vendorID: 2,
+			expected: PrivacyDecision{
+				Geolocate:   true,
+				SyncCookies: true,
+				Reasons:     map[Activity]string{ActivityTransmitUserIDs: "vendor 2 has no legal basis for purpose 2"},
+			},
+		},
+		{
+			name:     "tcf_vendor_denied",
+			tcString: otherTCString,
+			vendorID: 3,
+			expected: PrivacyDecision{
+				Reasons: map[Activity]string{
+					ActivityTransmitUserIDs: "vendor 3 has no legal basis for purpose 2",
+					ActivityGeolocate:       "vendor 3 may not use special feature 1, precise geolocation",
+					ActivitySyncCookies:     "vendor 3 has no legal basis for purpose 1",
+				},
+			},
+		},
+		{
+			name:     "gdpr_without_tc_string",
+			vendorID: 1,
+			expected: PrivacyDecision{
+				Reasons: map[Activity]string{
+					ActivityTransmitUserIDs: "the GDPR applies, but the request has no TC string",
+					ActivityGeolocate:       "the GDPR applies, but the request has no TC string",
+					ActivitySyncCookies:     "the GDPR applies, but the request has no TC string",
+				},
+			},
+		},
+		{
+			name:     "gdpr_not_applicable",
+			input:    DecisionInput{GDPRScope: permissions.ScopeNotApplicable},
+			vendorID: 3,
+			expected: PrivacyDecision{TransmitUserIDs: true, Geolocate: true, SyncCookies: true, Reasons: map[Activity]string{}},
+		},
+		{
+			name:     "coppa",
+			tcString: otherTCString,
+			input:    DecisionInput{COPPA: true},
+			vendorID: 1,
+			expected: PrivacyDecision{
+				Reasons: map[Activity]string{
+					ActivityTransmitUserIDs: "COPPA applies to the request",
+					ActivityGeolocate:       "COPPA applies to the request",
+					ActivitySyncCookies:     "COPPA applies to the request",
+				},
+			},
+		},
+		{
+			name:      "us_privacy_opted_out",
+			usPrivacy: "1YYN",
+			input:     DecisionInput{GDPRScope: permissions.ScopeNotApplicable},
+			expected: PrivacyDecision{
+				Reasons: map[Activity]string{
+					ActivityTransmitUserIDs: "the user opted out of the sale of their data",
+					ActivityGeolocate:       "the user opted out of the sale of their data",
+					ActivitySyncCookies:     "the user opted out of the sale of their data",
+				},
+			},
+		},
+		{
+			name:  "usnat_opted_out",
+			gpp:   "DBABLA~" + usNatOptedOut,
+			input: DecisionInput{GDPRScope: permissions.ScopeNotApplicable, GPPSIDs: []int{gpp.SectionUSNat}},
+			expected: PrivacyDecision{
+				Reasons: map[Activity]string{
+					ActivityTransmitUserIDs: "the user opted out of the sale of their data",
+					ActivityGeolocate:       "the user opted out of the sale of their data",
+					ActivitySyncCookies:     "the user opted out of the sale of their data",
+				},
+			},
+		},
+		{
+			name:  "gpc",
+			gpp:   "DBABLA~" + usNatNotOptedOut,
+			input: DecisionInput{GDPRScope: permissions.ScopeNotApplicable, SecGPC: "1", GPPSIDs: []int{gpp.SectionUSNat}},
+			expected: PrivacyDecision{
+				Reasons: map[Activity]string{
+					ActivityTransmitUserIDs: "the user sent a Global Privacy Control signal",
+					ActivityGeolocate:       "the user sent a Global Privacy Control signal",
+					ActivitySyncCookies:     "the user sent a Global Privacy Control signal",
+				},
+			},
+		},
+		{
+			name:  "gpc_us_laws_apply",
+			input: DecisionInput{GDPRScope: permissions.ScopeNotApplicable, SecGPC: "1", USLawsApply: true},
+			expected: PrivacyDecision{
+				Reasons: map[Activity]string{
+					ActivityTransmitUserIDs: "the user sent a Global Privacy Control signal",
+					ActivityGeolocate:       "the user sent a Global Privacy Control signal",
+					ActivitySyncCookies:     "the user sent a Global Privacy Control signal",
+				},
+			},
+		},
+		{
+			name:     "gpc_ignored_without_us_laws",
+			gpp:      "DBABLA~" + usNatNotOptedOut,
+			input:    DecisionInput{GDPRScope: permissions.ScopeNotApplicable, SecGPC: "1"},
+			expected: PrivacyDecision{TransmitUserIDs: true, Geolocate: true, SyncCookies: true, Reasons: map[Activity]string{}},
+		},
+		{
+			name:     "gpc_ignored_under_gdpr",
+			tcString: otherTCString,
+			input:    DecisionInput{SecGPC: "1"},
+			vendorID: 1,
+			expected: PrivacyDecision{
+				TransmitUserIDs: true,
+				SyncCookies:     true,
+				Reasons:         map[Activity]string{ActivityGeolocate: "vendor 1 may not use special feature 1, precise geolocation"},
+			},
+		},
+		{
+			name:  "us_state_opted_out",
+			gpp:   "DBACLM~" + usNatNotOptedOut + "~" + usVAOptedOut,
+			input: DecisionInput{GDPRScope: permissions.ScopeNotApplicable, GPPSIDs: []int{gpp.SectionUSNat, gpp.SectionUSVA}},
+			expected: PrivacyDecision{
+				Reasons: map[Activity]string{
+					ActivityTransmitUserIDs: "the user opted out of the sale of their data",
+					ActivityGeolocate:       "the user opted out of the sale of their data",
+					ActivitySyncCookies:     "the user opted out of the sale of their data",
+				},
+			},
+		},
+		{
+			name:     "us_state_not_applicable",
+			gpp:      "DBACLM~" + usNatNotOptedOut + "~" + usVAOptedOut,
+			input:    DecisionInput{GDPRScope: permissions.ScopeNotApplicable, GPPSIDs: []int{gpp.SectionUSNat}},
+			expected: PrivacyDecision{TransmitUserIDs: true, Geolocate: true, SyncCookies: true, Reasons: map[Activity]string{}},
+		},
+		{
+			name:      "us_not_opted_out",
+			gpp:       "DBABLA~" + usNatNotOptedOut,
+			usPrivacy: "1YNN",
+			input:     DecisionInput{GDPRScope: permissions.ScopeNotApplicable},
+			expected:  PrivacyDecision{TransmitUserIDs: true, Geolocate: true, SyncCookies: true, Reasons: map[Activity]string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signals, err := Reconcile(tt.gpp, tt.tcString, tt.usPrivacy)
+			assert.NoError(t, err)
+			var gvl api.VendorList
+			if signals.TCF != nil {
+				gvl = list
+			}
+			assert.Equal(t, tt.expected, Decide(evaluator, gvl, signals, tt.input, tt.vendorID))
+		})
+	}
+}
+
+func TestDecideKeepsFirstReason(t *testing.T) {
+	signals, err := Reconcile("", "", "1YYN")
+	assert.NoError(t, err)
+
+	decision := Decide(permissions.NewEvaluator(permissions.Config{}), nil, signals, DecisionInput{COPPA: true}, 1)
+	for _, activity := range Activities {
+		assert.False(t, decision.Allowed(activity))
+		assert.Equal(t, "COPPA applies to the request", decision.Reasons[activity])
+	}
+}
+
+func TestActivityString(t *testing.T) {
+	assert.Equal(t, "transmit_user_ids", ActivityTransmitUserIDs.String())
+	assert.Equal(t, "geolocate", ActivityGeolocate.String())
+	assert.Equal(t, "sync_cookies", ActivitySyncCookies.String())
+	assert.Equal(t, "Activity(7)", Activity(7).String())
+}
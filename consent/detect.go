@@ -0,0 +1,169 @@
+package consent
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/gpp"
+	"github.com/prebid/go-gdpr/usprivacy"
+	tcf1 "github.com/prebid/go-gdpr/vendorconsent/tcf1"
+	tcf2 "github.com/prebid/go-gdpr/vendorconsent/tcf2"
+)
+
+// Kind identifies the format of a consent string.
+type Kind int
+
+const (
+	// KindUnknown is the Kind of strings which Detect doesn't recognize.
+	KindUnknown Kind = iota
+	// KindTCF1 is a TCF v1.1 consent string.
+	KindTCF1
+	// KindTCF2 is a TCF v2 TC string.
+	KindTCF2
+	// KindGPP is a GPP string.
+	KindGPP
+	// KindUSPrivacy is a CCPA us_privacy string.
+	KindUSPrivacy
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindTCF1:
+		return "tcf1"
+	case KindTCF2:
+		return "tcf2"
+	case KindGPP:
+		return "gpp"
+	case KindUSPrivacy:
+		return "us_privacy"
+	default:
+		return "unknown"
+	}
+}
+
+// The first character of a base64 encoded TCF or GPP string holds its leading 6 bits: the Version of a
+// consent string, or the Type of a GPP header.
+const (
+	tcf1Prefix      = 'B'
+	tcf2Prefix      = 'C'
+	gppPrefix       = 'D'
+	usPrivacyPrefix = '1'
+	usPrivacyLength = 4
+)
+
+var errEmptyConsent = errors.New("consent string cannot be empty")
+
+// Consent is the minimal interface shared by every kind of parsed consent string.
+// Callers can switch on Kind(), or on the concrete type: *TCF, *GPP or *USPrivacy.
+type Consent interface {
+	// Kind returns the format of the consent string.
+	Kind() Kind
+
+	// Raw returns the consent string exactly as it was given to Detect.
+	Raw() string
+}
+
+// TCF is a parsed TCF v1.1 or v2 consent string.
+type TCF struct {
+	api.VendorConsents
+	kind Kind
+	raw  string
+}
+
+// Kind returns KindTCF1 or KindTCF2.
+func (c *TCF) Kind() Kind {
+	return c.kind
+}
+
+// Raw returns the consent string exactly as it was given to Detect.
+func (c *TCF) Raw() string {
+	return c.raw
+}
+
+// GPP is a parsed GPP string.
+type GPP struct {
+	*gpp.Container
+	raw string
+}
+
+// Kind returns KindGPP.
+func (c *GPP) Kind() Kind {
+	return KindGPP
+}
+
+// Raw returns the consent string exactly as it was given to Detect.
+func (c *GPP) Raw() string {
+	return c.raw
+}
+
+// USPrivacy is a parsed us_privacy string.
+type USPrivacy struct {
+	usprivacy.Consent
+	raw string
+}
+
+// Kind returns KindUSPrivacy.
+func (c *USPrivacy) Kind() Kind {
+	return KindUSPrivacy
+}
+
+// Raw returns the consent string exactly as it was given to Detect.
+func (c *USPrivacy) Raw() string {
+	return c.raw
+}
+
+// DetectKind returns the format of the string, judging only by its shape. It doesn't check that the
+// string parses.
+func DetectKind(s string) Kind {
+	switch {
+	case len(s) == usPrivacyLength && s[0] == usPrivacyPrefix:
+		return KindUSPrivacy
+	case s == "":
+		return KindUnknown
+	case s[0] == tcf1Prefix:
+		return KindTCF1
+	case s[0] == tcf2Prefix:
+		return KindTCF2
+	case s[0] == gppPrefix:
+		return KindGPP
+	default:
+		return KindUnknown
+	}
+}
+
+// Detect recognizes whether s is a TCF v1.1 string, a TCF v2 string, a GPP string or a us_privacy string,
+// and parses it accordingly. This suits callers which receive all of these in the same field.
+func Detect(s string) (Consent, error) {
+	if s == "" {
+		return nil, errEmptyConsent
+	}
+
+	kind := DetectKind(s)
+	switch kind {
+	case KindTCF1, KindTCF2:
+		parse := tcf1.ParseString
+		if kind == KindTCF2 {
+			parse = tcf2.ParseString
+		}
+		consents, err := parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v string: %v", kind, err)
+		}
+		return &TCF{VendorConsents: consents, kind: kind, raw: s}, nil
+	case KindGPP:
+		container, err := gpp.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v string: %v", kind, err)
+		}
+		return &GPP{Container: container, raw: s}, nil
+	case KindUSPrivacy:
+		consent, err := usprivacy.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v string: %v", kind, err)
+		}
+		return &USPrivacy{Consent: consent, raw: s}, nil
+	default:
+		return nil, fmt.Errorf("unrecognized consent string %q", s)
+	}
+}
@@ -0,0 +1,117 @@
+package consent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name         string
+		consent      string
+		expectedKind Kind
+	}{
+		{
+			name:         "tcf1",
+			consent:      "BONV8oqONXwgmADACHENAO7pqzAAppY",
+			expectedKind: KindTCF1,
+		},
+		{
+			name:         "tcf2",
+			consent:      gppTCString,
+			expectedKind: KindTCF2,
+		},
+		{
+			name:         "gpp",
+			consent:      gppWithTCFAndUSP,
+			expectedKind: KindGPP,
+		},
+		{
+			name:         "us_privacy",
+			consent:      "1YN-",
+			expectedKind: KindUSPrivacy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedKind, DetectKind(tt.consent))
+
+			parsed, err := Detect(tt.consent)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedKind, parsed.Kind())
+			assert.Equal(t, tt.consent, parsed.Raw())
+		})
+	}
+}
+
+func TestDetectConcreteTypes(t *testing.T) {
+	parsed, err := Detect(gppTCString)
+	assert.NoError(t, err)
+	tcf, ok := parsed.(*TCF)
+	assert.True(t, ok)
+	assert.Equal(t, uint8(2), tcf.Version())
+
+	parsed, err = Detect(gppWithTCFAndUSP)
+	assert.NoError(t, err)
+	container, ok := parsed.(*GPP)
+	assert.True(t, ok)
+	assert.Equal(t, []int{2, 6}, container.SectionsPresent())
+
+	parsed, err = Detect("1NYN")
+	assert.NoError(t, err)
+	usPrivacy, ok := parsed.(*USPrivacy)
+	assert.True(t, ok)
+	assert.True(t, usPrivacy.OptedOutOfSale())
+}
+
+func TestDetectInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		consent       string
+		expectedError string
+	}{
+		{
+			name:          "empty",
+			consent:       "",
+			expectedError: "consent string cannot be empty",
+		},
+		{
+			name:          "unrecognized",
+			consent:       "AONV8oqONXwgmADACHENAO7pqzAAppY",
+			expectedError: `unrecognized consent string "AONV8oqONXwgmADACHENAO7pqzAAppY"`,
+		},
+		{
+			name:          "bad_tcf2",
+			consent:       "CPXxRfAPXxRf",
+			expectedError: "invalid tcf2 string: vendor consent strings are at least 29 bytes long. This one was 9",
+		},
+		{
+			name:          "bad_gpp",
+			consent:       "DBACNY~" + gppTCString,
+			expectedError: "invalid gpp string: the GPP header lists more sections than the 1 the string contains",
+		},
+		{
+			name:          "bad_us_privacy",
+			consent:       "1YNX",
+			expectedError: `invalid us_privacy string: us_privacy string "1YNX" has 'X' at index 3, but only 'Y', 'N' and '-' are allowed`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := Detect(tt.consent)
+			assert.Nil(t, parsed)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestKindString(t *testing.T) {
+	assert.Equal(t, "tcf1", KindTCF1.String())
+	assert.Equal(t, "tcf2", KindTCF2.String())
+	assert.Equal(t, "gpp", KindGPP.String())
+	assert.Equal(t, "us_privacy", KindUSPrivacy.String())
+	assert.Equal(t, "unknown", KindUnknown.String())
+}
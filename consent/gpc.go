@@ -0,0 +1,48 @@
+package consent
+
+import (
+	"strings"
+
+	"github.com/prebid/go-gdpr/gpp"
+)
+
+// ParseGPCHeader interprets the value of a Sec-GPC request header. Per the Global Privacy Control
+// specification, only "1" signals the user's preference; any other value, or a missing header, means
+// no preference was expressed.
+func ParseGPCHeader(value string) bool {
+	return strings.TrimSpace(value) == "1"
+}
+
+// USOptOuts holds the resolved opt-outs of a request under the US privacy laws.
+type USOptOuts struct {
+	Sale                bool
+	Sharing             bool
+	TargetedAdvertising bool
+
+	// GPC is true if a Global Privacy Control signal was present, either in the Sec-GPC header or in the
+	// GPC subsection of a US section.
+	GPC bool
+}
+
+// ResolveUSOptOuts combines a Sec-GPC header value with the US sections of a GPP string.
+//
+// Following the IAB's guidance on GPC, a GPC signal is a valid opt-out of sale, sharing and targeted
+// advertising, and it takes precedence over a section which says the user did not opt out: the CMP may
+// simply not have seen the signal. Without GPC, an opt-out applies if any of the sections records it.
+func ResolveUSOptOuts(secGPC string, sections []gpp.USSection) USOptOuts {
+	optOuts := USOptOuts{GPC: ParseGPCHeader(secGPC)}
+	for _, section := range sections {
+		if section.GPC() {
+			optOuts.GPC = true
+		}
+		optOuts.Sale = optOuts.Sale || section.SaleOptOut() == gpp.OptedOut
+		optOuts.Sharing = optOuts.Sharing || section.SharingOptOut() == gpp.OptedOut
+		optOuts.TargetedAdvertising = optOuts.TargetedAdvertising || section.TargetedAdvertisingOptOut() == gpp.OptedOut
+	}
+	if optOuts.GPC {
+		optOuts.Sale = true
+		optOuts.Sharing = true
+		optOuts.TargetedAdvertising = true
+	}
+	return optOuts
+}
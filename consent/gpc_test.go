@@ -0,0 +1,89 @@
+package consent
+
+import (
+	"testing"
+
+	"github.com/prebid/go-gdpr/gpp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGPCHeader(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{value: "1", expected: true},
+		{value: " 1 ", expected: true},
+		{value: "", expected: false},
+		{value: "0", expected: false},
+		{value: "true", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseGPCHeader(tt.value))
+		})
+	}
+}
+
+func TestResolveUSOptOuts(t *testing.T) {
+	tests := []struct {
+		name     string
+		secGPC   string
+		gpp      string
+		expected USOptOuts
+	}{
+		{
+			name:     "nothing",
+			expected: USOptOuts{},
+		},
+		{
+			name:     "header_only",
+			secGPC:   "1",
+			expected: USOptOuts{Sale: true, Sharing: true, TargetedAdvertising: true, GPC: true},
+		},
+		{
+			name:     "not_opted_out",
+			gpp:      "DBABLA~" + usNatNotOptedOut,
+			expected: USOptOuts{},
+		},
+		{
+			name:     "opted_out",
+			gpp:      "DBABLA~" + usNatOptedOut,
+			expected: USOptOuts{Sale: true, Sharing: true, TargetedAdvertising: true},
+		},
+		{
+			name:     "header_overrides_section",
+			secGPC:   "1",
+			gpp:      "DBABLA~" + usNatNotOptedOut,
+			expected: USOptOuts{Sale: true, Sharing: true, TargetedAdvertising: true, GPC: true},
+		},
+		{
+			name:     "gpc_subsection",
+			gpp:      "DBABLA~" + usNatNotOptedOut + ".Y",
+			expected: USOptOuts{Sale: true, Sharing: true, TargetedAdvertising: true, GPC: true},
+		},
+		{
+			name:     "gpc_subsection_unset",
+			gpp:      "DBABLA~" + usNatNotOptedOut + ".Q",
+			expected: USOptOuts{},
+		},
+		{
+			name:     "state_section_opted_out",
+			gpp:      "DBACLM~" + usNatNotOptedOut + "~" + usVASaleAllowed,
+			expected: USOptOuts{TargetedAdvertising: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sections []gpp.USSection
+			if tt.gpp != "" {
+				container, err := gpp.Parse(tt.gpp)
+				assert.NoError(t, err)
+				sections = container.USSections()
+			}
+			assert.Equal(t, tt.expected, ResolveUSOptOuts(tt.secGPC, sections))
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Package consent combines the privacy signals which can arrive on a single request: a GPP string,
+// a standalone TC string and a standalone us_privacy string.
+package consent
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/gpp"
+	"github.com/prebid/go-gdpr/usprivacy"
+	"github.com/prebid/go-gdpr/vendorconsent"
+)
+
+// Signal identifies a privacy signal which can be carried both inside a GPP string and on its own.
+type Signal int
+
+const (
+	// SignalTCF is a TCF EU consent string: the GPP TCF EU v2 section, or a standalone TC string.
+	SignalTCF Signal = iota
+	// SignalUSPrivacy is a CCPA us_privacy string: the GPP uspv1 section, or a standalone us_privacy string.
+	SignalUSPrivacy
+)
+
+func (s Signal) String() string {
+	switch s {
+	case SignalTCF:
+		return "tcf"
+	case SignalUSPrivacy:
+		return "us_privacy"
+	default:
+		return fmt.Sprintf("Signal(%d)", int(s))
+	}
+}
+
+// Source describes where the authoritative value of a signal came from.
+type Source int
+
+const (
+	// SourceNone means the signal wasn't present anywhere.
+	SourceNone Source = iota
+	// SourceGPP means the signal came from a section of the GPP string.
+	SourceGPP
+	// SourceStandalone means the signal came from the standalone string.
+	SourceStandalone
+)
+
+// Conflict describes a signal whose GPP section disagrees with the standalone string.
+type Conflict struct {
+	Signal     Signal
+	GPP        string
+	Standalone string
+}
+
+// Signals is the authoritative set of privacy signals for a request.
+type Signals struct {
+	// GPP is the parsed GPP string, or nil if there wasn't one or its header was malformed.
+	// Sections which Reconcile didn't need are left encoded until they're first accessed.
+	GPP *gpp.Container
+
+	// TCF holds the authoritative TCF EU consents, or nil if neither input had any.
+	TCF       api.VendorConsents
+	TCFSource Source
+
+	// USPrivacy holds the authoritative us_privacy string, or "" if neither input had one.
+	USPrivacy       string
+	USPrivacySource Source
+
+	// Conflicts lists the signals whose GPP section and standalone string disagreed.
+	// In each case, the GPP section was used.
+	Conflicts []Conflict
+}
+
+// Reconcile combines a GPP string, a standalone TC string and a standalone us_privacy string into one
+// set of signals. Any of them may be empty.
+//
+// The IAB treats GPP as the source of truth, and the standalone strings as copies kept for backwards
+// compatibility. So if the GPP string has a section for a signal, that section is used and a differing
+// standalone string is reported as a Conflict. The standalone strings are only used, and so only parsed,
+// when the GPP string has no usable section for their signal.
+//
+// Each signal is read independently, so a malformed input only costs the signal it carries. If the GPP
+// header is malformed, both signals fall back to the standalone strings. If a single GPP section fails to
+// decode, that signal falls back to its standalone string. Reconcile always returns every signal it could
+// read; the error, if any, describes each input which was dropped.
+func Reconcile(gppString, tcString, usPrivacy string) (Signals, error) {
+	var signals Signals
+	var errs []error
+	if gppString != "" {
+		container, err := gpp.ParseLazy(gppString)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid GPP string: %v", err))
+		} else {
+			signals.GPP = container
+		}
+	}
+
+	section, err := signals.gppSection(gpp.SectionTCFEUV2)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid GPP string: %v", err))
+	}
+	if section != nil {
+		signals.TCF = section.(gpp.TCFEUV2Section).ConsentMetadata
+		signals.TCFSource = SourceGPP
+		signals.addConflict(SignalTCF, section.Encoded(), tcString)
+	} else if tcString != "" {
+		if consents, err := vendorconsent.ParseString(tcString); err != nil {
+			errs = append(errs, fmt.Errorf("invalid TC string: %v", err))
+		} else {
+			signals.TCF = consents
+			signals.TCFSource = SourceStandalone
+		}
+	}
+
+	section, err = signals.gppSection(gpp.SectionUSPV1)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid GPP string: %v", err))
+	}
+	if section != nil {
+		signals.USPrivacy = section.Encoded()
+		signals.USPrivacySource = SourceGPP
+		signals.addConflict(SignalUSPrivacy, section.Encoded(), usPrivacy)
+	} else if usPrivacy != "" {
+		if _, err := usprivacy.Parse(usPrivacy); err != nil {
+			errs = append(errs, fmt.Errorf("invalid us_privacy string: %v", err))
+		} else {
+			signals.USPrivacy = usPrivacy
+			signals.USPrivacySource = SourceStandalone
+		}
+	}
+
+	return signals, errors.Join(errs...)
+}
+
+// HasConflicts returns true if any GPP section disagreed with its standalone string.
+func (s Signals) HasConflicts() bool {
+	return len(s.Conflicts) > 0
+}
+
+// gppSection decodes the GPP section with the given ID. It returns a nil section if there's no GPP string,
+// if the GPP string doesn't contain the section, or if the section fails to decode. Only the last is an error.
+func (s *Signals) gppSection(id int) (gpp.Section, error) {
+	if s.GPP == nil || !slices.Contains(s.GPP.SectionsPresent(), id) {
+		return nil, nil
+	}
+	section, err := s.GPP.DecodeSection(id)
+	if err != nil {
+		return nil, err
+	}
+	return section, nil
+}
+
+// addConflict records a conflict if the standalone string was given and differs from the GPP section.
+func (s *Signals) addConflict(signal Signal, fromGPP, standalone string) {
+	if standalone != "" && standalone != fromGPP {
+		s.Conflicts = append(s.Conflicts, Conflict{
+			Signal:     signal,
+			GPP:        fromGPP,
+			Standalone: standalone,
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package consent
+
+import (
+	"testing"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/prebid/go-gdpr/permissions"
+	"github.com/prebid/go-gdpr/vendorlist2"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	gppTCString   = "CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"
+	otherTCString = "COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA"
+	// gppWithTCFAndUSP holds gppTCString and the us_privacy string "1YN-".
+	gppWithTCFAndUSP = "DBACNY~" + gppTCString + "~1YN-"
+	// gppWithUSNat holds a TCF EU section and a US National section, but no uspv1 section.
+	gppWithUSNat = "DBACMM~" + gppTCString + "~BVVqAAEABA"
+)
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name              string
+		gpp               string
+		tcString          string
+		usPrivacy         string
+		expectedTCF       string
+		expectedTCFSource Source
+		expectedUSPrivacy string
+		expectedUSPSource Source
+		expectedConflicts []Conflict
+	}{
+		{
+			name:              "nothing",
+			expectedTCFSource: SourceNone,
+			expectedUSPSource: SourceNone,
+		},
+		{
+			name:              "standalone_only",
+			tcString:          otherTCString,
+			usPrivacy:         "1NYN",
+			expectedTCF:       otherTCString,
+			expectedTCFSource: SourceStandalone,
+			expectedUSPrivacy: "1NYN",
+			expectedUSPSource: SourceStandalone,
+		},
+		{
+			name:              "gpp_only",
+			gpp:               gppWithTCFAndUSP,
+			expectedTCF:       gppTCString,
+			expectedTCFSource: SourceGPP,
+			expectedUSPrivacy: "1YN-",
+			expectedUSPSource: SourceGPP,
+		},
+		{
+			name:              "gpp_and_matching_standalone",
+			gpp:               gppWithTCFAndUSP,
+			tcString:          gppTCString,
+			usPrivacy:         "1YN-",
+			expectedTCF:       gppTCString,
+			expectedTCFSource: SourceGPP,
+			expectedUSPrivacy: "1YN-",
+			expectedUSPSource: SourceGPP,
+		},
+		{
+			name:              "gpp_wins_conflicts",
+			gpp:               gppWithTCFAndUSP,
+			tcString:          otherTCString,
+			usPrivacy:         "1NYN",
+			expectedTCF:       gppTCString,
+			expectedTCFSource: SourceGPP,
+			expectedUSPrivacy: "1YN-",
+			expectedUSPSource: SourceGPP,
+			expectedConflicts: []Conflict{
+				{Signal: SignalTCF, GPP: gppTCString, Standalone: otherTCString},
+				{Signal: SignalUSPrivacy, GPP: "1YN-", Standalone: "1NYN"},
+			},
+		},
+		{
+			name:              "standalone_fills_gpp_gaps",
+			gpp:               gppWithUSNat,
+			tcString:          otherTCString,
+			usPrivacy:         "1NYN",
+			expectedTCF:       gppTCString,
+			expectedTCFSource: SourceGPP,
+			expectedUSPrivacy: "1NYN",
+			expectedUSPSource: SourceStandalone,
+			expectedConflicts: []Conflict{
+				{Signal: SignalTCF, GPP: gppTCString, Standalone: otherTCString},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signals, err := Reconcile(tt.gpp, tt.tcString, tt.usPrivacy)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.gpp != "", signals.GPP != nil)
+			assert.Equal(t, tt.expectedTCFSource, signals.TCFSource)
+			if tt.expectedTCF == "" {
+				assert.Nil(t, signals.TCF)
+			} else {
+				assert.NotNil(t, signals.TCF)
+			}
+			assert.Equal(t, tt.expectedUSPrivacy, signals.USPrivacy)
+			assert.Equal(t, tt.expectedUSPSource, signals.USPrivacySource)
+			assert.Equal(t, tt.expectedConflicts, signals.Conflicts)
+			assert.Equal(t, len(tt.expectedConflicts) > 0, signals.HasConflicts())
+		})
+	}
+}
+
+func TestReconcileUsesGPPConsents(t *testing.T) {
+	signals, err := Reconcile(gppWithTCFAndUSP, otherTCString, "")
+	assert.NoError(t, err)
+
+	fromGPP, _ := signals.GPP.VendorConsents()
+	assert.Equal(t, fromGPP, signals.TCF)
+}
+
+// enforcementTCString has consent for purposes 1 and 2 and vendors 1 and 2, legitimate interest
+// transparency for purpose 7 and vendor 1, an opt-in to special feature 1, and a publisher restriction which
+// doesn't allow vendor 2 to use purpose 2.
+const enforcementTCString = "CO5rKAAO5rKAAAHABBENAPCIAMAAAAIAAAAAABsAAKACEAAgACA"
+
+// enforcementList is a vendor list for enforcementTCString.
+const enforcementList = `{
+	"gvlSpecificationVersion": 2,
+	"vendorListVersion": 15,
+	"vendors": {
+		"1": {"id": 1, "purposes": [1, 2], "legIntPurposes": [7], "specialFeatures": [1]},
+		"2": {"id": 2, "purposes": [1, 2]}
+	}
+}`
+
+// TestReconcileEnforcesGPPConsents checks that TCF consents from a GPP string are enforced just like the
+// same standalone TC string, including the parts which enforcement finds by type assertion.
+func TestReconcileEnforcesGPPConsents(t *testing.T) {
+	list, err := vendorlist2.ParseEagerly([]byte(enforcementList))
+	assert.NoError(t, err)
+
+	standalone, err := Reconcile("", enforcementTCString, "")
+	assert.NoError(t, err)
+	fromGPP, err := Reconcile("DBABMA~"+enforcementTCString, "", "")
+	assert.NoError(t, err)
+	container, ok := fromGPP.GPP.VendorConsents()
+	assert.True(t, ok)
+
+	for _, consents := range []api.VendorConsents{standalone.TCF, fromGPP.TCF, container} {
+		assert.Equal(t, permissions.LegalBasisLegitimateInterest, permissions.Evaluate(consents, list, 1, 7))
+		assert.Equal(t, permissions.LegalBasisConsent, permissions.Evaluate(consents, list, 2, 1))
+		assert.Equal(t, permissions.LegalBasisNone, permissions.Evaluate(consents, list, 2, 2))
+		assert.Equal(t, permissions.LegalBasisConsent, permissions.EvaluateSpecialFeature(consents, list, 1, consentconstants.SpecialFeature(1)))
+	}
+}
+
+func TestReconcileInvalid(t *testing.T) {
+	tests := []struct {
+		name              string
+		gpp               string
+		tcString          string
+		usPrivacy         string
+		expectedError     string
+		expectedTCFSource Source
+		expectedUSPrivacy string
+		expectedUSPSource Source
+	}{
+		{
+			name:              "bad_gpp",
+			gpp:               "DBACNY~" + gppTCString,
+			tcString:          otherTCString,
+			usPrivacy:         "1NYN",
+			expectedError:     "invalid GPP string: the GPP header lists more sections than the 1 the string contains",
+			expectedTCFSource: SourceStandalone,
+			expectedUSPrivacy: "1NYN",
+			expectedUSPSource: SourceStandalone,
+		},
+		{
+			name:              "bad_gpp_tcf_section",
+			gpp:               "DBACNY~CPXxRfAPXxRfAAfKABENB-!~1YN-",
+			tcString:          otherTCString,
+			expectedError:     "invalid GPP string: failed to decode GPP section 2: failed to decode segment: illegal base64 data at input byte 22",
+			expectedTCFSource: SourceStandalone,
+			expectedUSPrivacy: "1YN-",
+			expectedUSPSource: SourceGPP,
+		},
+		{
+			name:              "bad_gpp_uspv1_section",
+			gpp:               "DBACNY~" + gppTCString + "~1YN",
+			expectedError:     `invalid GPP string: failed to decode GPP section 6: us_privacy strings must be 4 characters long, but "1YN" has 3`,
+			expectedTCFSource: SourceGPP,
+			expectedUSPSource: SourceNone,
+		},
+		{
+			name:              "bad_tc_string",
+			tcString:          "COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA!",
+			usPrivacy:         "1NYN",
+			expectedError:     "invalid TC string: failed to decode segment: illegal base64 data at input byte 47",
+			expectedTCFSource: SourceNone,
+			expectedUSPrivacy: "1NYN",
+			expectedUSPSource: SourceStandalone,
+		},
+		{
+			name:              "bad_us_privacy",
+			tcString:          otherTCString,
+			usPrivacy:         "1YN",
+			expectedError:     `invalid us_privacy string: us_privacy strings must be 4 characters long, but "1YN" has 3`,
+			expectedTCFSource: SourceStandalone,
+			expectedUSPSource: SourceNone,
+		},
+		{
+			name:              "bad_tc_string_and_us_privacy",
+			tcString:          "not a TC string",
+			usPrivacy:         "1YN",
+			expectedError:     "invalid TC string: illegal base64 data at input byte 3\n" + `invalid us_privacy string: us_privacy strings must be 4 characters long, but "1YN" has 3`,
+			expectedTCFSource: SourceNone,
+			expectedUSPSource: SourceNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signals, err := Reconcile(tt.gpp, tt.tcString, tt.usPrivacy)
+			assert.EqualError(t, err, tt.expectedError)
+			assert.Equal(t, tt.expectedTCFSource, signals.TCFSource)
+			assert.Equal(t, tt.expectedTCFSource != SourceNone, signals.TCF != nil)
+			assert.Equal(t, tt.expectedUSPrivacy, signals.USPrivacy)
+			assert.Equal(t, tt.expectedUSPSource, signals.USPrivacySource)
+		})
+	}
+}
+
+func TestReconcileIgnoresUnusedStandaloneStrings(t *testing.T) {
+	// The GPP string is the source of truth, so broken standalone copies are only reported as conflicts.
+	signals, err := Reconcile(gppWithTCFAndUSP, "not a TC string", "1YN")
+	assert.NoError(t, err)
+	assert.Len(t, signals.Conflicts, 2)
+}
+
+func TestSignalString(t *testing.T) {
+	assert.Equal(t, "tcf", SignalTCF.String())
+	assert.Equal(t, "us_privacy", SignalUSPrivacy.String())
+	assert.Equal(t, "Signal(7)", Signal(7).String())
+}
@@ -0,0 +1,172 @@
+// Package devicestorage parses and fetches the device storage disclosures which vendors publish at the
+// deviceStorageDisclosureUrl given for them in the Global Vendor List. A disclosure lists each cookie and
+// other identifier a vendor stores on the user's device, how long it's kept and what it's used for.
+//
+// The URL is available from vendors which implement api.DeviceStorage:
+//
+//	if storage, ok := vendor.(api.DeviceStorage); ok && storage.DeviceStorageDisclosureURL() != "" {
+//		disclosure, err := fetcher.Fetch(ctx, storage.DeviceStorageDisclosureURL())
+//	}
+package devicestorage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/prebid/go-gdpr/consentconstants"
+)
+
+// Type is the kind of storage a disclosure describes.
+type Type string
+
+const (
+	// TypeCookie is an HTTP cookie.
+	TypeCookie Type = "cookie"
+	// TypeWeb is web storage, such as localStorage or IndexedDB.
+	TypeWeb Type = "web"
+	// TypeApp is storage used by a mobile app, such as its preferences.
+	TypeApp Type = "app"
+)
+
+// Document is a vendor's device storage disclosure.
+type Document struct {
+	Disclosures []Disclosure
+	// Domains lists the domains the vendor's storage is used on, and what for. It may be empty.
+	Domains []Domain
+}
+
+// Disclosure describes one identifier the vendor stores on the device.
+type Disclosure struct {
+	Identifier string
+	Type       Type
+	// MaxAgeSeconds is how long the identifier is kept. It's always set for cookies, and nil when the
+	// storage doesn't expire on its own.
+	MaxAgeSeconds *int64
+	// CookieRefresh is true if the cookie's lifetime is extended each time it's written.
+	CookieRefresh bool
+	// Domains are the domains the identifier is stored on. "*" means any domain the vendor is used on.
+	Domains         []string
+	Purposes        []consentconstants.Purpose
+	SpecialPurposes []consentconstants.Purpose
+	Description     string
+}
+
+// Domain describes how the vendor uses storage on one domain.
+type Domain struct {
+	Domain string
+	Use    string
+}
+
+// Parse parses and validates a device storage disclosure. It returns an error if the data isn't valid
+// JSON, or doesn't follow the schema: every disclosure needs an identifier, a known type and its
+// purposes, and cookies also need maxAgeSeconds and cookieRefresh.
+func Parse(data []byte) (*Document, error) {
+	var contract documentContract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		return nil, fmt.Errorf("failed to parse the device storage disclosure: %v", err)
+	}
+	if contract.Disclosures == nil {
+		return nil, errors.New("invalid device storage disclosure: disclosures is missing")
+	}
+
+	doc := &Document{
+		Disclosures: make([]Disclosure, 0, len(contract.Disclosures)),
+		Domains:     make([]Domain, 0, len(contract.Domains)),
+	}
+	for i, c := range contract.Disclosures {
+		disclosure, err := parseDisclosure(c)
+		if err != nil {
+			return nil, fmt.Errorf("invalid device storage disclosure: disclosures[%d]: %v", i, err)
+		}
+		doc.Disclosures = append(doc.Disclosures, disclosure)
+	}
+	for i, c := range contract.Domains {
+		if c.Domain == "" {
+			return nil, fmt.Errorf("invalid device storage disclosure: domains[%d]: domain is missing", i)
+		}
+		doc.Domains = append(doc.Domains, Domain{Domain: c.Domain, Use: c.Use})
+	}
+	return doc, nil
+}
+
+func parseDisclosure(contract disclosureContract) (Disclosure, error) {
+	disclosure := Disclosure{
+		Identifier:    contract.Identifier,
+		Type:          Type(contract.Type),
+		MaxAgeSeconds: contract.MaxAgeSeconds,
+		Domains:       contract.Domains,
+		Description:   contract.Description,
+	}
+	if contract.CookieRefresh != nil {
+		disclosure.CookieRefresh = *contract.CookieRefresh
+	}
+	// domain is the older, single-valued form of domains.
+	if len(disclosure.Domains) == 0 && contract.Domain != "" {
+		disclosure.Domains = []string{contract.Domain}
+	}
+
+	if disclosure.Identifier == "" {
+		return Disclosure{}, errors.New("identifier is missing")
+	}
+	switch disclosure.Type {
+	case TypeCookie:
+		if disclosure.MaxAgeSeconds == nil {
+			return Disclosure{}, errors.New("maxAgeSeconds is missing for a cookie")
+		}
+		if contract.CookieRefresh == nil {
+			return Disclosure{}, errors.New("cookieRefresh is missing for a cookie")
+		}
+	case TypeWeb, TypeApp:
+	default:
+		return Disclosure{}, fmt.Errorf("type %q isn't cookie, web or app", contract.Type)
+	}
+	if disclosure.MaxAgeSeconds != nil && *disclosure.MaxAgeSeconds < 0 {
+		return Disclosure{}, fmt.Errorf("maxAgeSeconds %d is negative", *disclosure.MaxAgeSeconds)
+	}
+	if contract.Purposes == nil {
+		return Disclosure{}, errors.New("purposes is missing")
+	}
+
+	var err error
+	if disclosure.Purposes, err = toPurposes("purposes", contract.Purposes); err != nil {
+		return Disclosure{}, err
+	}
+	if disclosure.SpecialPurposes, err = toPurposes("specialPurposes", contract.SpecialPurposes); err != nil {
+		return Disclosure{}, err
+	}
+	return disclosure, nil
+}
+
+func toPurposes(field string, ids []uint8) ([]consentconstants.Purpose, error) {
+	purposes := make([]consentconstants.Purpose, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 {
+			return nil, fmt.Errorf("%s contains 0, but IDs start at 1", field)
+		}
+		purposes = append(purposes, consentconstants.Purpose(id))
+	}
+	return purposes, nil
+}
+
+type documentContract struct {
+	Disclosures []disclosureContract `json:"disclosures"`
+	Domains     []domainContract     `json:"domains"`
+}
+
+type disclosureContract struct {
+	Identifier      string   `json:"identifier"`
+	Type            string   `json:"type"`
+	MaxAgeSeconds   *int64   `json:"maxAgeSeconds"`
+	CookieRefresh   *bool    `json:"cookieRefresh"`
+	Domain          string   `json:"domain"`
+	Domains         []string `json:"domains"`
+	Purposes        []uint8  `json:"purposes"`
+	SpecialPurposes []uint8  `json:"specialPurposes"`
+	Description     string   `json:"description"`
+}
+
+type domainContract struct {
+	Domain string `json:"domain"`
+	Use    string `json:"use"`
+}
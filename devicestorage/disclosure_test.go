@@ -0,0 +1,130 @@
+package devicestorage
+
+import (
+	"testing"
+
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/stretchr/testify/assert"
+)
+
+const testDisclosure = `
+{
+	"disclosures": [
+		{
+			"identifier": "uid",
+			"type": "cookie",
+			"maxAgeSeconds": 31536000,
+			"cookieRefresh": true,
+			"domains": ["*"],
+			"purposes": [1, 3, 4],
+			"specialPurposes": [1]
+		},
+		{
+			"identifier": "consent",
+			"type": "web",
+			"maxAgeSeconds": null,
+			"domain": "vendor.example.com",
+			"purposes": [1],
+			"description": "Remembers the user's choices."
+		}
+	],
+	"domains": [
+		{"domain": "vendor.example.com", "use": "Ad serving"}
+	]
+}
+`
+
+func TestParse(t *testing.T) {
+	doc, err := Parse([]byte(testDisclosure))
+	assert.NoError(t, err)
+
+	maxAge := int64(31536000)
+	assert.Equal(t, &Document{
+		Disclosures: []Disclosure{
+			{
+				Identifier:      "uid",
+				Type:            TypeCookie,
+				MaxAgeSeconds:   &maxAge,
+				CookieRefresh:   true,
+				Domains:         []string{"*"},
+				Purposes:        []consentconstants.Purpose{1, 3, 4},
+				SpecialPurposes: []consentconstants.Purpose{1},
+			},
+			{
+				Identifier:      "consent",
+				Type:            TypeWeb,
+				Domains:         []string{"vendor.example.com"},
+				Purposes:        []consentconstants.Purpose{1},
+				SpecialPurposes: []consentconstants.Purpose{},
+				Description:     "Remembers the user's choices.",
+			},
+		},
+		Domains: []Domain{{Domain: "vendor.example.com", Use: "Ad serving"}},
+	}, doc)
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		expectedError string
+	}{
+		{
+			name:          "malformed",
+			data:          `{"disclosures": [`,
+			expectedError: "failed to parse the device storage disclosure: unexpected end of JSON input",
+		},
+		{
+			name:          "no_disclosures",
+			data:          `{"domains": []}`,
+			expectedError: "invalid device storage disclosure: disclosures is missing",
+		},
+		{
+			name:          "no_identifier",
+			data:          `{"disclosures": [{"type": "web", "purposes": [1]}]}`,
+			expectedError: "invalid device storage disclosure: disclosures[0]: identifier is missing",
+		},
+		{
+			name:          "unknown_type",
+			data:          `{"disclosures": [{"identifier": "a", "type": "flash", "purposes": [1]}]}`,
+			expectedError: `invalid device storage disclosure: disclosures[0]: type "flash" isn't cookie, web or app`,
+		},
+		{
+			name:          "cookie_without_max_age",
+			data:          `{"disclosures": [{"identifier": "a", "type": "cookie", "cookieRefresh": false, "purposes": [1]}]}`,
+			expectedError: "invalid device storage disclosure: disclosures[0]: maxAgeSeconds is missing for a cookie",
+		},
+		{
+			name:          "cookie_without_refresh",
+			data:          `{"disclosures": [{"identifier": "a", "type": "cookie", "maxAgeSeconds": 60, "purposes": [1]}]}`,
+			expectedError: "invalid device storage disclosure: disclosures[0]: cookieRefresh is missing for a cookie",
+		},
+		{
+			name:          "negative_max_age",
+			data:          `{"disclosures": [{"identifier": "a", "type": "app", "maxAgeSeconds": -1, "purposes": [1]}]}`,
+			expectedError: "invalid device storage disclosure: disclosures[0]: maxAgeSeconds -1 is negative",
+		},
+		{
+			name:          "no_purposes",
+			data:          `{"disclosures": [{"identifier": "a", "type": "web"}]}`,
+			expectedError: "invalid device storage disclosure: disclosures[0]: purposes is missing",
+		},
+		{
+			name:          "purpose_zero",
+			data:          `{"disclosures": [{"identifier": "a", "type": "web", "purposes": [1], "specialPurposes": [0]}]}`,
+			expectedError: "invalid device storage disclosure: disclosures[0]: specialPurposes contains 0, but IDs start at 1",
+		},
+		{
+			name:          "domain_without_name",
+			data:          `{"disclosures": [], "domains": [{"use": "Ads"}]}`,
+			expectedError: "invalid device storage disclosure: domains[0]: domain is missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package devicestorage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// MaxDocumentSize is the largest disclosure a Fetcher will read. Real disclosures are a few kilobytes,
+// and the URLs are chosen by vendors, so anything bigger is rejected rather than read into memory.
+const MaxDocumentSize = 1 << 20
+
+// Fetcher downloads device storage disclosures and caches them by URL. It is safe for concurrent use.
+//
+// Vendors may update a disclosure at any time, so a cached one is revalidated on every Fetch with
+// If-None-Match and If-Modified-Since, and is only downloaded and parsed again if the server says it
+// changed.
+type Fetcher struct {
+	client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	doc          *Document
+	etag         string
+	lastModified string
+}
+
+// New returns a Fetcher which uses the given client. If client is nil, http.DefaultClient is used.
+func New(client *http.Client) *Fetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Fetcher{
+		client: client,
+		cache:  make(map[string]cacheEntry),
+	}
+}
+
+// Fetch downloads, parses and validates the disclosure at url. Callers shouldn't modify the returned
+// Document, since it's shared with later calls.
+func (f *Fetcher) Fetch(ctx context.Context, url string) (*Document, error) {
+	f.mu.Lock()
+	cached, ok := f.cache[url]
+	f.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request for device storage disclosure %s: %v", url, err)
+	}
+	if ok {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch device storage disclosure %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && ok {
+		return cached.doc, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("device storage disclosure %s returned status %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxDocumentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read device storage disclosure %s: %v", url, err)
+	}
+	if len(data) > MaxDocumentSize {
+		return nil, fmt.Errorf("device storage disclosure %s is larger than %d bytes", url, MaxDocumentSize)
+	}
+
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse device storage disclosure %s: %v", url, err)
+	}
+
+	f.mu.Lock()
+	f.cache[url] = cacheEntry{
+		doc:          doc,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}
+	f.mu.Unlock()
+	return doc, nil
+}
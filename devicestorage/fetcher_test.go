@@ -0,0 +1,89 @@
+package devicestorage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testServer serves testDisclosure at /devicestorage.json, and answers conditional requests for it
+// with 304. It counts the requests.
+func testServer(t *testing.T, requests *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		switch r.URL.Path {
+		case "/devicestorage.json":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte(testDisclosure))
+		case "/invalid.json":
+			w.Write([]byte(`{"disclosures": [{"identifier": "a"}]}`))
+		case "/huge.json":
+			w.Write([]byte(strings.Repeat(" ", MaxDocumentSize+1)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetch(t *testing.T) {
+	var requests int32
+	server := testServer(t, &requests)
+	fetcher := New(nil)
+
+	first, err := fetcher.Fetch(context.Background(), server.URL+"/devicestorage.json")
+	assert.NoError(t, err)
+	assert.Len(t, first.Disclosures, 2)
+
+	// The disclosure is revalidated, and the cached copy kept when the server answers 304.
+	second, err := fetcher.Fetch(context.Background(), server.URL+"/devicestorage.json")
+	assert.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestFetchErrors(t *testing.T) {
+	var requests int32
+	server := testServer(t, &requests)
+	fetcher := New(nil)
+
+	tests := []struct {
+		name          string
+		path          string
+		expectedError string
+	}{
+		{
+			name:          "not_found",
+			path:          "/missing.json",
+			expectedError: "device storage disclosure " + server.URL + "/missing.json returned status 404",
+		},
+		{
+			name:          "invalid",
+			path:          "/invalid.json",
+			expectedError: "failed to parse device storage disclosure " + server.URL + `/invalid.json: invalid device storage disclosure: disclosures[0]: type "" isn't cookie, web or app`,
+		},
+		{
+			name:          "too_large",
+			path:          "/huge.json",
+			expectedError: "device storage disclosure " + server.URL + "/huge.json is larger than 1048576 bytes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fetcher.Fetch(context.Background(), server.URL+tt.path)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
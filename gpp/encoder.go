@@ -0,0 +1,87 @@
+package gpp
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prebid/go-gdpr/bitutils"
+)
+
+// Encode assembles a GPP string from the given sections. The header is generated from the section IDs,
+// and the sections are written in ascending ID order.
+//
+// Any Section can be encoded: sections from a parsed Container, sections built with NewRawSection,
+// NewTCFEUV2Section or NewUSNatSection, or a mix of these.
+func Encode(sections ...Section) (string, error) {
+	sorted := make([]Section, len(sections))
+	copy(sorted, sections)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ID() < sorted[j].ID()
+	})
+
+	ids := make([]int, len(sorted))
+	for i, section := range sorted {
+		ids[i] = section.ID()
+		if ids[i] < 1 || ids[i] == SectionHeader {
+			return "", fmt.Errorf("%d is not a valid GPP section ID", ids[i])
+		}
+		if i > 0 && ids[i-1] == ids[i] {
+			return "", fmt.Errorf("GPP section %d was given more than once", ids[i])
+		}
+		if section.Encoded() == "" {
+			return "", fmt.Errorf("GPP section %d is empty", ids[i])
+		}
+		if strings.Contains(section.Encoded(), sectionSeparator) {
+			return "", fmt.Errorf("GPP section %d contains the section separator %q", ids[i], sectionSeparator)
+		}
+	}
+
+	var w bitutils.Writer
+	w.WriteBits(headerType, 6)
+	w.WriteBits(headerVersion, 6)
+	if err := w.WriteFibonacciRange(ids); err != nil {
+		return "", err
+	}
+
+	segments := make([]string, 0, len(sorted)+1)
+	segments = append(segments, encodeSegment(&w))
+	for _, section := range sorted {
+		segments = append(segments, section.Encoded())
+	}
+	return strings.Join(segments, sectionSeparator), nil
+}
+
+// NewRawSection returns a section holding an already encoded payload.
+func NewRawSection(id int, encoded string) RawSection {
+	return RawSection{id: id, encoded: encoded}
+}
+
+// NewTCFEUV2Section returns a TCF EU v2 section holding the given TC string.
+// It returns an error if the TC string can't be parsed.
+func NewTCFEUV2Section(tcString string) (TCFEUV2Section, error) {
+	return parseTCFEUV2Section(tcString)
+}
+
+// NewUSPV1Section returns a US Privacy section holding the given us_privacy string.
+// It returns an error if the string isn't a valid version 1 us_privacy string.
+func NewUSPV1Section(usPrivacy string) (USPV1Section, error) {
+	return parseUSPV1Section(usPrivacy)
+}
+
+// NewUSNatSection encodes the given values into a US National section.
+func NewUSNatSection(values USValues) (USNatSection, error) {
+	encoded, err := encodeUSSection(SectionUSNat, values)
+	if err != nil {
+		return USNatSection{}, err
+	}
+	return parseUSNatSection(encoded)
+}
+
+// encodeSegment base64 URL encodes the bits written to w, without padding. Like bitutils.DecodeSegment, it works in
+// 6-bit characters, so it emits no more characters than the bits need.
+func encodeSegment(w *bitutils.Writer) string {
+	encoded := base64.RawURLEncoding.EncodeToString(w.Bytes())
+	return encoded[:(w.Len()+5)/6]
+}
@@ -0,0 +1,110 @@
+package gpp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	tcf, err := NewTCFEUV2Section("CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA")
+	assert.NoError(t, err)
+
+	usNat, err := NewUSNatSection(USValues{
+		Version:                             1,
+		SharingNotice:                       NoticeProvided,
+		SaleOptOutNotice:                    NoticeProvided,
+		SharingOptOutNotice:                 NoticeProvided,
+		TargetedAdvertisingOptOutNotice:     NoticeProvided,
+		SensitiveDataProcessingOptOutNotice: NoticeProvided,
+		SensitiveDataLimitUseNotice:         NoticeProvided,
+		SaleOptOut:                          DidNotOptOut,
+		SharingOptOut:                       DidNotOptOut,
+		TargetedAdvertisingOptOut:           DidNotOptOut,
+		SensitiveDataProcessing:             []Consent{0, 0, 0, 0, 0, 0, 0, NoConsent},
+		PersonalDataConsents:                NoConsent,
+		GPCSegmentIncluded:                  true,
+	})
+	assert.NoError(t, err)
+	// The GPC subsection only needs 3 bits, so a single character.
+	assert.Equal(t, "BVVqAAEABA.Q", usNat.Encoded())
+
+	// Sections are sorted by ID, whatever order they're given in.
+	encoded, err := Encode(usNat, tcf)
+	assert.NoError(t, err)
+	assert.Equal(t, "DBACMM~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~BVVqAAEABA.Q", encoded)
+
+	container, err := Parse(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{SectionTCFEUV2, SectionUSNat}, container.SectionsPresent())
+	section, _ := container.Section(SectionUSNat)
+	assert.Equal(t, usNat, section)
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	gpp := "DBABh2~BWaAAFpk.Y~BVlAAlk~BVaABoA~BVqAAFW~BVUAAmGQ"
+	container, err := Parse(gpp)
+	assert.NoError(t, err)
+
+	encoded, err := Encode(container.Sections()...)
+	assert.NoError(t, err)
+	assert.Equal(t, gpp, encoded)
+}
+
+func TestEncodeNoSections(t *testing.T) {
+	encoded, err := Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, "DBAA", encoded)
+}
+
+func TestEncodeInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		sections      []Section
+		expectedError string
+	}{
+		{
+			name:          "header_id",
+			sections:      []Section{NewRawSection(SectionHeader, "DBAA")},
+			expectedError: "3 is not a valid GPP section ID",
+		},
+		{
+			name:          "duplicate",
+			sections:      []Section{NewRawSection(SectionUSCA, "BWaAAFpk"), NewRawSection(SectionUSCA, "BWaAAFpk")},
+			expectedError: "GPP section 8 was given more than once",
+		},
+		{
+			name:          "empty",
+			sections:      []Section{NewRawSection(SectionUSCA, "")},
+			expectedError: "GPP section 8 is empty",
+		},
+		{
+			name:          "separator",
+			sections:      []Section{NewRawSection(SectionUSCA, "BWaAAFpk~Y")},
+			expectedError: "GPP section 8 contains the section separator \"~\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Encode(tt.sections...)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestNewUSNatSectionInvalid(t *testing.T) {
+	_, err := NewUSNatSection(USValues{Version: 3})
+	assert.EqualError(t, err, "unsupported usnat section version 3")
+
+	_, err = NewUSNatSection(USValues{Version: 1, SensitiveDataProcessing: make([]Consent, 13)})
+	assert.EqualError(t, err, "the usnat section has 12 sensitive data categories, but 13 values were given")
+
+	_, err = NewUSNatSection(USValues{Version: 1, KnownChildSensitiveDataConsents: make([]Consent, 3)})
+	assert.EqualError(t, err, "the usnat section has 2 known child age ranges, but 3 values were given")
+}
+
+func TestNewTCFEUV2SectionInvalid(t *testing.T) {
+	_, err := NewTCFEUV2Section("CONciguONcjGKADACHENAOCIAC0ta__AACiQAA")
+	assert.EqualError(t, err, "vendor consent strings are at least 29 bytes long. This one was 28")
+}
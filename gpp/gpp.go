@@ -0,0 +1,260 @@
+// Package gpp parses IAB Global Privacy Platform strings.
+//
+// A GPP string is a header section followed by any number of privacy sections, separated by '~'.
+// The header lists the IDs of the sections which follow it, in order. For technical details, see
+// https://github.com/InteractiveAdvertisingBureau/Global-Privacy-Platform/blob/main/Core/Consent%20String%20Specification.md
+package gpp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/prebid/go-gdpr/api"
+)
+
+// Section IDs registered by the IAB for use in GPP strings.
+// https://github.com/InteractiveAdvertisingBureau/Global-Privacy-Platform/blob/main/Sections/Section%20Information.md
+const (
+	SectionTCFEUV2 = 2
+	SectionHeader  = 3
+	SectionTCFCAV1 = 5
+	SectionUSPV1   = 6
+	SectionUSNat   = 7
+	SectionUSCA    = 8
+	SectionUSVA    = 9
+	SectionUSCO    = 10
+	SectionUSUT    = 11
+	SectionUSCT    = 12
+)
+
+const sectionSeparator = "~"
+
+var errEmptyGPP = errors.New("GPP string cannot be empty")
+
+// Section is a single privacy section of a GPP string.
+type Section interface {
+	// ID returns the section ID, as registered by the IAB.
+	ID() int
+
+	// Encoded returns the section exactly as it appeared in the GPP string.
+	Encoded() string
+}
+
+// Container is a parsed GPP string.
+//
+// Sections are decoded on first access, and at most once, so a Container is safe for concurrent use.
+type Container struct {
+	header   header
+	sections []*lazySection
+}
+
+// lazySection holds a section's encoded form until it's first needed.
+type lazySection struct {
+	id      int
+	encoded string
+	once    sync.Once
+	section Section
+	err     error
+}
+
+// decode decodes the section on its first call, and returns the same result on every later one.
+func (s *lazySection) decode() (Section, error) {
+	s.once.Do(func() {
+		s.section, s.err = decodeSection(s.id, s.encoded)
+		if s.err != nil {
+			s.err = fmt.Errorf("failed to decode GPP section %d: %v", s.id, s.err)
+		}
+	})
+	return s.section, s.err
+}
+
+// orRaw returns the decoded section or, if it failed to decode, a RawSection holding its encoded form.
+func (s *lazySection) orRaw() Section {
+	if section, err := s.decode(); err == nil {
+		return section
+	}
+	return RawSection{id: s.id, encoded: s.encoded}
+}
+
+// Parse parses a GPP string. It returns an error if the header is malformed, if the number of sections
+// doesn't match the header, or if any section fails to decode.
+func Parse(gpp string) (*Container, error) {
+	container, err := ParseLazy(gpp)
+	if err != nil {
+		return nil, err
+	}
+	for _, section := range container.sections {
+		if _, err := section.decode(); err != nil {
+			return nil, err
+		}
+	}
+	return container, nil
+}
+
+// ParseLazy parses a GPP string's header, but leaves each section encoded until it's first accessed.
+// It returns an error if the header is malformed, or if the number of sections doesn't match the header.
+//
+// Callers which only consult one or two sections should prefer this to Parse. Sections which fail to
+// decode are returned as a RawSection by the accessors; DecodeSection returns the error itself.
+func ParseLazy(gpp string) (*Container, error) {
+	if gpp == "" {
+		return nil, errEmptyGPP
+	}
+
+	segments := strings.Split(gpp, sectionSeparator)
+	header, err := parseHeader(segments[0], len(segments)-1)
+	if err != nil {
+		return nil, err
+	}
+
+	encodedSections := segments[1:]
+	if len(header.sectionIDs) != len(encodedSections) {
+		return nil, fmt.Errorf("the GPP header lists %d sections, but the string contains %d", len(header.sectionIDs), len(encodedSections))
+	}
+
+	container := &Container{
+		header:   header,
+		sections: make([]*lazySection, len(encodedSections)),
+	}
+	for i, id := range header.sectionIDs {
+		container.sections[i] = &lazySection{id: id, encoded: encodedSections[i]}
+	}
+
+	return container, nil
+}
+
+// Version returns the version of the GPP header.
+func (c *Container) Version() uint8 {
+	return c.header.version
+}
+
+// UnknownVersion returns true if the header's version is newer than this package understands.
+// The header's section IDs were still read, but any fields the newer version added were ignored.
+func (c *Container) UnknownVersion() bool {
+	return c.header.version > headerVersion
+}
+
+// Sections returns the sections of the GPP string, in the order they appeared.
+// This decodes every section.
+func (c *Container) Sections() []Section {
+	sections := make([]Section, len(c.sections))
+	for i, section := range c.sections {
+		sections[i] = section.orRaw()
+	}
+	return sections
+}
+
+// SectionsPresent returns the IDs of the sections in the GPP string, in the order they appeared.
+// Callers can compare these with the gpp_sid values they received alongside the string.
+// This doesn't decode any section.
+func (c *Container) SectionsPresent() []int {
+	ids := make([]int, len(c.sections))
+	for i, section := range c.sections {
+		ids[i] = section.id
+	}
+	return ids
+}
+
+// Section returns the section with the given ID, and false if the GPP string doesn't contain it.
+func (c *Container) Section(id int) (Section, bool) {
+	if section := c.find(id); section != nil {
+		return section.orRaw(), true
+	}
+	return nil, false
+}
+
+// DecodeSection returns the section with the given ID. Unlike Section, it returns an error if the
+// GPP string doesn't contain the section, or if the section fails to decode.
+func (c *Container) DecodeSection(id int) (Section, error) {
+	section := c.find(id)
+	if section == nil {
+		return nil, fmt.Errorf("the GPP string has no section %d", id)
+	}
+	return section.decode()
+}
+
+func (c *Container) find(id int) *lazySection {
+	for _, section := range c.sections {
+		if section.id == id {
+			return section
+		}
+	}
+	return nil
+}
+
+// VendorConsents returns the consents of the TCF EU v2 section of the GPP string, if it has one.
+// They're the same tcf2.ConsentMetadata a standalone TC string parses to, so existing TCF enforcement code
+// runs unchanged on GPP input.
+func (c *Container) VendorConsents() (api.VendorConsents, bool) {
+	section, ok := c.Section(SectionTCFEUV2)
+	if !ok {
+		return nil, false
+	}
+	tcf, ok := section.(TCFEUV2Section)
+	if !ok {
+		return nil, false
+	}
+	return tcf.ConsentMetadata, true
+}
+
+// USSections returns the US National and US state sections of the GPP string, in the order they appeared.
+func (c *Container) USSections() []USSection {
+	var sections []USSection
+	for _, section := range c.sections {
+		if _, ok := usLayouts[section.id]; !ok {
+			continue
+		}
+		if usSection, ok := section.orRaw().(USSection); ok {
+			sections = append(sections, usSection)
+		}
+	}
+	return sections
+}
+
+// decodeSection decodes a single section. Sections without a decoder are returned as a RawSection.
+func decodeSection(id int, encoded string) (Section, error) {
+	if encoded == "" {
+		return nil, errors.New("empty section")
+	}
+
+	switch id {
+	case SectionTCFEUV2:
+		return parseTCFEUV2Section(encoded)
+	case SectionTCFCAV1:
+		return parseTCFCAV1Section(encoded)
+	case SectionUSPV1:
+		return parseUSPV1Section(encoded)
+	case SectionUSNat:
+		return parseUSNatSection(encoded)
+	case SectionUSCA:
+		return parseUSCASection(encoded)
+	case SectionUSVA:
+		return parseUSVASection(encoded)
+	case SectionUSCO:
+		return parseUSCOSection(encoded)
+	case SectionUSUT:
+		return parseUSUTSection(encoded)
+	case SectionUSCT:
+		return parseUSCTSection(encoded)
+	default:
+		return RawSection{id: id, encoded: encoded}, nil
+	}
+}
+
+// RawSection is a section which this package doesn't decode.
+type RawSection struct {
+	id      int
+	encoded string
+}
+
+// ID returns the section ID.
+func (s RawSection) ID() int {
+	return s.id
+}
+
+// Encoded returns the section exactly as it appeared in the GPP string.
+func (s RawSection) Encoded() string {
+	return s.encoded
+}
@@ -0,0 +1,224 @@
+package gpp
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name             string
+		gpp              string
+		expectedSections []int
+		expectedEncoded  []string
+	}{
+		{
+			name:             "single_section",
+			gpp:              "DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA",
+			expectedSections: []int{2},
+			expectedEncoded:  []string{"CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA"},
+		},
+		{
+			name:             "two_sections",
+			gpp:              "DBACNY~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~1YNN",
+			expectedSections: []int{2, 6},
+			expectedEncoded:  []string{"CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA", "1YNN"},
+		},
+		{
+			name:             "no_sections",
+			gpp:              "DBAA",
+			expectedSections: nil,
+			expectedEncoded:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			container, err := Parse(tt.gpp)
+			assert.NoError(t, err)
+			assert.Equal(t, uint8(1), container.Version())
+
+			var ids []int
+			var encoded []string
+			for _, section := range container.Sections() {
+				ids = append(ids, section.ID())
+				encoded = append(encoded, section.Encoded())
+			}
+			assert.Equal(t, tt.expectedSections, ids)
+			assert.Equal(t, tt.expectedEncoded, encoded)
+		})
+	}
+}
+
+func TestSectionDiscovery(t *testing.T) {
+	container, err := Parse("DBACMM~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~BVVqAAEABA")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 7}, container.SectionsPresent())
+
+	section, ok := container.Section(SectionUSNat)
+	assert.True(t, ok)
+	assert.IsType(t, USNatSection{}, section)
+	assert.Equal(t, "BVVqAAEABA", section.Encoded())
+
+	section, ok = container.Section(SectionTCFEUV2)
+	assert.True(t, ok)
+	assert.IsType(t, TCFEUV2Section{}, section)
+
+	section, ok = container.Section(SectionUSCA)
+	assert.False(t, ok)
+	assert.Nil(t, section)
+}
+
+// TestParseSpecExamples parses the example strings from the IAB's GPP specification.
+func TestParseSpecExamples(t *testing.T) {
+	container, err := Parse("DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA")
+	assert.NoError(t, err)
+	section, ok := container.Section(SectionTCFEUV2)
+	assert.True(t, ok)
+	assert.IsType(t, TCFEUV2Section{}, section)
+
+	container, err = Parse("DBACNY~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~1YNN")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{SectionTCFEUV2, SectionUSPV1}, container.SectionsPresent())
+	section, ok = container.Section(SectionUSPV1)
+	assert.True(t, ok)
+	assert.IsType(t, USPV1Section{}, section)
+
+	container, err = Parse("DBABLA~BVQqAAAAAAKA")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{SectionUSNat}, container.SectionsPresent())
+	section, ok = container.Section(SectionUSNat)
+	assert.True(t, ok)
+	assert.IsType(t, USNatSection{}, section)
+}
+
+func TestSectionDiscoveryNoSections(t *testing.T) {
+	container, err := Parse("DBAA")
+	assert.NoError(t, err)
+	assert.Empty(t, container.SectionsPresent())
+
+	_, ok := container.Section(SectionTCFEUV2)
+	assert.False(t, ok)
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		gpp           string
+		expectedError string
+	}{
+		{
+			name:          "empty",
+			gpp:           "",
+			expectedError: "GPP string cannot be empty",
+		},
+		{
+			name:          "too_few_sections",
+			gpp:           "DBACMM~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA",
+			expectedError: "the GPP header lists more sections than the 1 the string contains",
+		},
+		{
+			name:          "too_many_sections",
+			gpp:           "DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~BVVqAAEABA",
+			expectedError: "the GPP header lists 1 sections, but the string contains 2",
+		},
+		{
+			name:          "empty_section",
+			gpp:           "DBABMA~",
+			expectedError: "failed to decode GPP section 2: empty section",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			container, err := Parse(tt.gpp)
+			assert.Nil(t, container)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestParseLazy(t *testing.T) {
+	// The US National section has an unsupported version, so only decoding it fails.
+	gpp := "DBACMM~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~DVVqAAEABA"
+	_, err := Parse(gpp)
+	assert.EqualError(t, err, "failed to decode GPP section 7: unsupported usnat section version 3")
+
+	container, err := ParseLazy(gpp)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 7}, container.SectionsPresent())
+
+	consents, ok := container.VendorConsents()
+	assert.True(t, ok)
+	assert.Equal(t, uint8(2), consents.Version())
+
+	section, ok := container.Section(SectionUSNat)
+	assert.True(t, ok)
+	assert.Equal(t, RawSection{id: SectionUSNat, encoded: "DVVqAAEABA"}, section)
+	assert.Empty(t, container.USSections())
+
+	_, err = container.DecodeSection(SectionUSNat)
+	assert.EqualError(t, err, "failed to decode GPP section 7: unsupported usnat section version 3")
+	_, err = container.DecodeSection(SectionUSCA)
+	assert.EqualError(t, err, "the GPP string has no section 8")
+
+	assert.Equal(t, []Violation{
+		{SectionID: SectionUSNat, Message: "failed to decode GPP section 7: unsupported usnat section version 3"},
+	}, container.Validate())
+}
+
+func TestParseLazyInvalid(t *testing.T) {
+	_, err := ParseLazy("")
+	assert.EqualError(t, err, "GPP string cannot be empty")
+
+	_, err = ParseLazy("DBACMM~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA")
+	assert.EqualError(t, err, "the GPP header lists more sections than the 1 the string contains")
+}
+
+func TestParseLazyHugeSectionRange(t *testing.T) {
+	// These headers list ranges of billions of section IDs, which must fail without expanding them.
+	for _, header := range []string{"DRfAAENB-CgAAAAAAAAAAYgAAAAAAA", "DBABsoChSUkw"} {
+		_, err := ParseLazy(header + "~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA")
+		assert.Error(t, err, header)
+		_, err = Parse(header)
+		assert.Error(t, err, header)
+	}
+}
+
+func TestParseLazyConcurrentAccess(t *testing.T) {
+	container, err := ParseLazy("DBACMM~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~BVVqAAEABA")
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	sections := make([]Section, 8)
+	for i := range sections {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sections[i], _ = container.Section(SectionUSNat)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, section := range sections {
+		assert.IsType(t, USNatSection{}, section)
+		assert.Equal(t, sections[0], section)
+	}
+}
+
+func TestParseNewerVersion(t *testing.T) {
+	container, err := Parse("DCABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA")
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(2), container.Version())
+	assert.True(t, container.UnknownVersion())
+	assert.Equal(t, []int{2}, container.SectionsPresent())
+	assert.Equal(t, []Violation{
+		{SectionID: SectionHeader, Message: "the header has version 2, but only version 1 is understood"},
+	}, container.Validate())
+
+	container, err = Parse("DBABMA~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA")
+	assert.NoError(t, err)
+	assert.False(t, container.UnknownVersion())
+}
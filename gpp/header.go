@@ -0,0 +1,67 @@
+package gpp
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prebid/go-gdpr/bitutils"
+)
+
+const (
+	headerType    = 3
+	headerVersion = 1
+)
+
+type header struct {
+	version    uint8
+	sectionIDs []int
+	encoded    string
+	bits       uint
+}
+
+// parseHeader parses the GPP header section: a 6-bit Type (always 3), a 6-bit Version
+// and the IDs of the sections included in the string, encoded as a Fibonacci range.
+// Any fields which a later version adds after the IDs are ignored.
+//
+// Every section ID needs a section of its own, so headers which list more than maxSections IDs are rejected
+// before their IDs are expanded.
+func parseHeader(encoded string, maxSections int) (header, error) {
+	data, err := bitutils.DecodeSegment(encoded)
+	if err != nil {
+		return header{}, fmt.Errorf("invalid GPP header: %v", err)
+	}
+
+	r := bitutils.NewReader(data)
+	headerTypeBits, err := r.ReadBits(6)
+	if err != nil {
+		return header{}, fmt.Errorf("invalid GPP header: %v", err)
+	}
+	if headerTypeBits != headerType {
+		return header{}, fmt.Errorf("the GPP header encoded a Type of %d, but this value must be %d", headerTypeBits, headerType)
+	}
+
+	version, err := r.ReadBits(6)
+	if err != nil {
+		return header{}, fmt.Errorf("invalid GPP header: %v", err)
+	}
+	// Later versions may add fields after the section IDs, but the IDs themselves are expected to stay
+	// where they are. So headers newer than this package are read as far as it understands them.
+	if version < headerVersion {
+		return header{}, fmt.Errorf("the GPP header encoded a Version of %d, but versions start at %d", version, headerVersion)
+	}
+
+	sectionIDs, err := r.ReadFibonacciRange(maxSections)
+	if errors.Is(err, bitutils.ErrTooManyIDs) {
+		return header{}, fmt.Errorf("the GPP header lists more sections than the %d the string contains", maxSections)
+	}
+	if err != nil {
+		return header{}, fmt.Errorf("invalid GPP header section IDs: %v", err)
+	}
+
+	return header{
+		version:    uint8(version),
+		sectionIDs: sectionIDs,
+		encoded:    encoded,
+		bits:       r.Position(),
+	}, nil
+}
@@ -0,0 +1,104 @@
+package gpp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		sectionIDs []int
+	}{
+		{
+			name:       "single_section",
+			header:     "DBABMA",
+			sectionIDs: []int{2},
+		},
+		{
+			name:       "single_section_without_padding",
+			header:     "DBABM",
+			sectionIDs: []int{2},
+		},
+		{
+			name:       "two_sections",
+			header:     "DBACNY",
+			sectionIDs: []int{2, 6},
+		},
+		{
+			name:       "range_and_single_sections",
+			header:     "DBACPb",
+			sectionIDs: []int{2, 6, 7, 8},
+		},
+		{
+			name:       "one_long_range",
+			header:     "DBABuM",
+			sectionIDs: []int{2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := parseHeader(tt.header, len(tt.sectionIDs))
+			assert.NoError(t, err)
+			assert.Equal(t, uint8(1), h.version)
+			assert.Equal(t, tt.sectionIDs, h.sectionIDs)
+		})
+	}
+}
+
+func TestParseHeaderNewerVersion(t *testing.T) {
+	// Version 2, with section 2 and then bits which a later version might define.
+	h, err := parseHeader("DCABM_", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(2), h.version)
+	assert.Equal(t, []int{2}, h.sectionIDs)
+}
+
+func TestParseHeaderInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		header        string
+		expectedError string
+	}{
+		{
+			name:          "bad_base64",
+			header:        "DBA!",
+			expectedError: "invalid GPP header: failed to decode segment: illegal base64 data at input byte 3",
+		},
+		{
+			name:          "wrong_type",
+			header:        "CBABMA",
+			expectedError: "the GPP header encoded a Type of 2, but this value must be 3",
+		},
+		{
+			name:          "version_zero",
+			header:        "DAABMA",
+			expectedError: "the GPP header encoded a Version of 0, but versions start at 1",
+		},
+		{
+			name:          "truncated_section_ids",
+			header:        "DBAB",
+			expectedError: "invalid GPP header section IDs: ReadBits expected 1 bits to start at bit 24, but the data was only 3 bytes long",
+		},
+		{
+			name:          "more_sections_than_allowed",
+			header:        "DBABuM",
+			expectedError: "the GPP header lists more sections than the 9 the string contains",
+		},
+		{
+			name:          "unterminated_section_id",
+			header:        "DBABA",
+			expectedError: "invalid GPP header section IDs: unterminated fibonacci integer: ReadBits expected 1 bits to start at bit 48, but the data was only 6 bytes long",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseHeader(tt.header, 9)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
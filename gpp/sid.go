@@ -0,0 +1,96 @@
+package gpp
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseSIDs parses the gpp_sid parameter which accompanies a GPP string in OpenRTB and URL macros:
+// a comma-separated list of section IDs, such as "2,6". Whitespace around IDs is ignored, and an
+// empty parameter yields no IDs. The IDs are returned in the order they were listed.
+func ParseSIDs(sids string) ([]int, error) {
+	if strings.TrimSpace(sids) == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(sids, ",")
+	ids := make([]int, 0, len(parts))
+	seen := make(map[int]struct{}, len(parts))
+	for _, part := range parts {
+		id, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("gpp_sid contains %q, which is not a section ID", part)
+		}
+		if id < 1 {
+			return nil, fmt.Errorf("gpp_sid contains %d, but section IDs start at 1", id)
+		}
+		if _, ok := seen[id]; ok {
+			return nil, fmt.Errorf("gpp_sid contains section %d more than once", id)
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// SIDReport describes how the gpp_sid values of a request compare with the sections of its GPP string.
+type SIDReport struct {
+	// Missing holds the IDs listed in gpp_sid which the GPP string doesn't contain.
+	Missing []int
+	// Unlisted holds the IDs of sections in the GPP string which gpp_sid doesn't list.
+	Unlisted []int
+}
+
+// Consistent returns true if gpp_sid and the GPP string refer to exactly the same sections.
+func (r SIDReport) Consistent() bool {
+	return len(r.Missing) == 0 && len(r.Unlisted) == 0
+}
+
+// ReconcileSIDs compares gpp_sid values with the sections present in the GPP string.
+// Both lists in the report are sorted in ascending order.
+//
+// Per the GPP specification, gpp_sid names the sections which apply to the request, so callers should
+// only enforce sections listed in it. Missing sections usually point to a misconfigured CMP, while unlisted
+// ones are legitimate but must not be applied.
+func ReconcileSIDs(container *Container, sids []int) SIDReport {
+	present := make(map[int]struct{}, len(container.sections))
+	for _, id := range container.SectionsPresent() {
+		present[id] = struct{}{}
+	}
+	listed := make(map[int]struct{}, len(sids))
+	for _, id := range sids {
+		listed[id] = struct{}{}
+	}
+
+	var report SIDReport
+	for id := range listed {
+		if _, ok := present[id]; !ok {
+			report.Missing = append(report.Missing, id)
+		}
+	}
+	for id := range present {
+		if _, ok := listed[id]; !ok {
+			report.Unlisted = append(report.Unlisted, id)
+		}
+	}
+	sort.Ints(report.Missing)
+	sort.Ints(report.Unlisted)
+	return report
+}
+
+// ApplicableSections returns the sections of the GPP string which gpp_sid lists, in the order they
+// appeared in the string. These are the sections callers should enforce.
+func ApplicableSections(container *Container, sids []int) []Section {
+	var sections []Section
+	for _, section := range container.sections {
+		for _, id := range sids {
+			if section.id == id {
+				sections = append(sections, section.orRaw())
+				break
+			}
+		}
+	}
+	return sections
+}
@@ -0,0 +1,104 @@
+package gpp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		sids     string
+		expected []int
+	}{
+		{name: "empty", sids: "", expected: nil},
+		{name: "blank", sids: "  ", expected: nil},
+		{name: "single", sids: "2", expected: []int{2}},
+		{name: "multiple", sids: "2,6,7", expected: []int{2, 6, 7}},
+		{name: "unsorted", sids: "7,2", expected: []int{7, 2}},
+		{name: "whitespace", sids: " 2 , 6", expected: []int{2, 6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, err := ParseSIDs(tt.sids)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+}
+
+func TestParseSIDsInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		sids          string
+		expectedError string
+	}{
+		{name: "not_a_number", sids: "2,a", expectedError: `gpp_sid contains "a", which is not a section ID`},
+		{name: "trailing_comma", sids: "2,", expectedError: `gpp_sid contains "", which is not a section ID`},
+		{name: "zero", sids: "0", expectedError: "gpp_sid contains 0, but section IDs start at 1"},
+		{name: "negative", sids: "-1", expectedError: "gpp_sid contains -1, but section IDs start at 1"},
+		{name: "duplicate", sids: "2,6,2", expectedError: "gpp_sid contains section 2 more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSIDs(tt.sids)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestReconcileSIDs(t *testing.T) {
+	container, err := Parse("DBACNY~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~1YNN")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		sids       []int
+		expected   SIDReport
+		consistent bool
+	}{
+		{
+			name:       "consistent",
+			sids:       []int{6, 2},
+			expected:   SIDReport{},
+			consistent: true,
+		},
+		{
+			name:     "missing",
+			sids:     []int{2, 6, 8, 7},
+			expected: SIDReport{Missing: []int{7, 8}},
+		},
+		{
+			name:     "unlisted",
+			sids:     []int{6},
+			expected: SIDReport{Unlisted: []int{2}},
+		},
+		{
+			name:     "both",
+			sids:     []int{7},
+			expected: SIDReport{Missing: []int{7}, Unlisted: []int{2, 6}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := ReconcileSIDs(container, tt.sids)
+			assert.Equal(t, tt.expected, report)
+			assert.Equal(t, tt.consistent, report.Consistent())
+		})
+	}
+}
+
+func TestApplicableSections(t *testing.T) {
+	container, err := Parse("DBACNY~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~1YNN")
+	assert.NoError(t, err)
+
+	sections := ApplicableSections(container, []int{6, 7})
+	assert.Len(t, sections, 1)
+	assert.Equal(t, SectionUSPV1, sections[0].ID())
+
+	assert.Empty(t, ApplicableSections(container, nil))
+}
@@ -0,0 +1,37 @@
+package gpp
+
+import (
+	"fmt"
+
+	tcfca "github.com/prebid/go-gdpr/vendorconsent/tcfca"
+)
+
+// TCFCAV1Section is the TCF Canada section (ID 5) of a GPP string. It holds the core subsection and, if present,
+// the publisher purposes subsection.
+//
+// The section's payload is an ordinary TCF Canada consent string, so it embeds the parsed consents.
+type TCFCAV1Section struct {
+	tcfca.ConsentMetadata
+	encoded string
+}
+
+func parseTCFCAV1Section(encoded string) (TCFCAV1Section, error) {
+	consents, err := tcfca.ParseString(encoded)
+	if err != nil {
+		return TCFCAV1Section{}, fmt.Errorf("invalid tcfcav1 section: %v", err)
+	}
+	return TCFCAV1Section{
+		ConsentMetadata: consents,
+		encoded:         encoded,
+	}, nil
+}
+
+// ID returns SectionTCFCAV1.
+func (s TCFCAV1Section) ID() int {
+	return SectionTCFCAV1
+}
+
+// Encoded returns the section exactly as it appeared in the GPP string.
+func (s TCFCAV1Section) Encoded() string {
+	return s.encoded
+}
@@ -0,0 +1,98 @@
+package gpp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/stretchr/testify/assert"
+)
+
+const testTCFCAV1Section = "BPk6AIAPk6AJkEsACBENAyBQAcAAADAAAAAUoEAFIAUABQAIAAo.cAAACAAAAdQ"
+
+func TestParseTCFCAV1Section(t *testing.T) {
+	container, err := Parse("DBABD~" + testTCFCAV1Section)
+	assert.NoError(t, err)
+
+	section, ok := container.Sections()[0].(TCFCAV1Section)
+	assert.True(t, ok)
+	assert.Equal(t, SectionTCFCAV1, section.ID())
+	assert.Equal(t, testTCFCAV1Section, section.Encoded())
+
+	assert.Equal(t, uint8(1), section.Version())
+	assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), section.Created().UTC())
+	assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 10, 0, time.UTC), section.LastUpdated().UTC())
+	assert.Equal(t, uint16(300), section.CmpID())
+	assert.Equal(t, uint16(2), section.CmpVersion())
+	assert.Equal(t, uint8(1), section.ConsentScreen())
+	assert.Equal(t, "EN", section.ConsentLanguage())
+	assert.Equal(t, uint16(50), section.VendorListVersion())
+	assert.Equal(t, uint8(1), section.TCFPolicyVersion())
+	assert.False(t, section.UseNonStandardStacks())
+
+	assert.True(t, section.SpecialFeatureExpressConsent(1))
+	assert.False(t, section.SpecialFeatureExpressConsent(2))
+	assert.False(t, section.SpecialFeatureExpressConsent(13))
+
+	for purpose := consentconstants.Purpose(1); purpose <= 24; purpose++ {
+		assert.Equal(t, purpose <= 3, section.PurposeExpressConsent(purpose), "express consent for purpose %d", purpose)
+		assert.Equal(t, purpose == 4 || purpose == 5, section.PurposeImpliedConsent(purpose), "implied consent for purpose %d", purpose)
+	}
+
+	// VendorExpressConsent is a BitField, VendorImpliedConsent a RangeSection.
+	assert.Equal(t, uint16(10), section.MaxVendorID())
+	for vendor := uint16(1); vendor <= 20; vendor++ {
+		assert.Equal(t, vendor == 1 || vendor == 3 || vendor == 10, section.VendorExpressConsent(vendor), "express consent for vendor %d", vendor)
+		assert.Equal(t, (vendor >= 5 && vendor <= 8) || vendor == 20, section.VendorImpliedConsent(vendor), "implied consent for vendor %d", vendor)
+	}
+
+	assert.True(t, section.HasPublisherPurposes())
+	assert.True(t, section.PubPurposeExpressConsent(1))
+	assert.False(t, section.PubPurposeExpressConsent(2))
+	assert.True(t, section.PubPurposeImpliedConsent(2))
+	assert.Equal(t, uint8(3), section.NumCustomPurposes())
+	assert.True(t, section.CustomPurposeExpressConsent(1))
+	assert.False(t, section.CustomPurposeExpressConsent(2))
+	assert.True(t, section.CustomPurposeExpressConsent(3))
+	assert.False(t, section.CustomPurposeExpressConsent(4))
+	assert.True(t, section.CustomPurposeImpliedConsent(2))
+}
+
+func TestParseTCFCAV1SectionWithoutPublisherPurposes(t *testing.T) {
+	section, err := parseTCFCAV1Section("BPk6AIAPk6AJkEsACBENAyBQAcAAADAAAAAUoEAFIAUABQAIAAo")
+	assert.NoError(t, err)
+	assert.False(t, section.HasPublisherPurposes())
+	assert.False(t, section.PubPurposeExpressConsent(1))
+	assert.Equal(t, uint8(0), section.NumCustomPurposes())
+}
+
+func TestParseTCFCAV1SectionInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		encoded       string
+		expectedError string
+	}{
+		{
+			name:          "truncated_core",
+			encoded:       "BPk6AIAPk6AJkEsACBENAyBQ",
+			expectedError: "invalid tcfcav1 section: invalid tcfca core segment: ReadBits expected 12 bits to start at bit 139, but the data was only 18 bytes long",
+		},
+		{
+			name:          "truncated_vendors",
+			encoded:       "BPk6AIAPk6AJkEsACBENAyBQAcAAADAAAAAUoEAFIAU",
+			expectedError: "invalid tcfcav1 section: invalid tcfca VendorImpliedConsent: ParseUInt16 expected a 16-bit int to start at bit 256, but the consent string was only 33 bytes long",
+		},
+		{
+			name:          "truncated_publisher_purposes",
+			encoded:       "BPk6AIAPk6AJkEsACBENAyBQAcAAADAAAAAUoEAFIAUABQAIAAo.cAAACA",
+			expectedError: "invalid tcfcav1 section: invalid tcfca publisher purposes segment: ReadBits expected 24 bits to start at bit 27, but the data was only 6 bytes long",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTCFCAV1Section(tt.encoded)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
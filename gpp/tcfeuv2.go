@@ -0,0 +1,36 @@
+package gpp
+
+import (
+	tcf2 "github.com/prebid/go-gdpr/vendorconsent/tcf2"
+)
+
+// TCFEUV2Section is the TCF EU v2 section (ID 2) of a GPP string.
+//
+// The section's payload is an ordinary TC string, so it embeds the parsed consents. These are the same
+// tcf2.ConsentMetadata a standalone TC string parses to, including the legitimate interests, publisher
+// restrictions and publisher TC segment which enforcement code finds by type assertion.
+type TCFEUV2Section struct {
+	tcf2.ConsentMetadata
+	encoded string
+}
+
+func parseTCFEUV2Section(encoded string) (TCFEUV2Section, error) {
+	consents, err := tcf2.ParseStringConsentMetadata(encoded)
+	if err != nil {
+		return TCFEUV2Section{}, err
+	}
+	return TCFEUV2Section{
+		ConsentMetadata: consents,
+		encoded:         encoded,
+	}, nil
+}
+
+// ID returns SectionTCFEUV2.
+func (s TCFEUV2Section) ID() int {
+	return SectionTCFEUV2
+}
+
+// Encoded returns the section exactly as it appeared in the GPP string.
+func (s TCFEUV2Section) Encoded() string {
+	return s.encoded
+}
@@ -0,0 +1,44 @@
+package gpp
+
+import (
+	"testing"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTCFEUV2Section(t *testing.T) {
+	container, err := Parse("DBABMA~COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA")
+	assert.NoError(t, err)
+
+	section, ok := container.Sections()[0].(TCFEUV2Section)
+	assert.True(t, ok)
+	assert.Equal(t, SectionTCFEUV2, section.ID())
+	assert.Equal(t, "COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA", section.Encoded())
+
+	var consents api.VendorConsents
+	consents, ok = container.VendorConsents()
+	assert.True(t, ok)
+	assert.Equal(t, uint16(3), consents.CmpID())
+	assert.Equal(t, uint16(14), consents.VendorListVersion())
+	assert.Equal(t, uint16(10), consents.MaxVendorID())
+	assert.True(t, consents.PurposeAllowed(consentconstants.InfoStorageAccess))
+	assert.False(t, consents.PurposeAllowed(consentconstants.Purpose(4)))
+	assert.True(t, consents.VendorConsent(1))
+	assert.False(t, consents.VendorConsent(3))
+}
+
+func TestTCFEUV2SectionMissing(t *testing.T) {
+	container, err := Parse("DBABRY~BVVqAAEABA")
+	assert.NoError(t, err)
+
+	consents, ok := container.VendorConsents()
+	assert.False(t, ok)
+	assert.Nil(t, consents)
+}
+
+func TestTCFEUV2SectionInvalid(t *testing.T) {
+	_, err := Parse("DBABMA~CONciguONcjGKADACHENAOCIAC0ta__AACiQAA")
+	assert.EqualError(t, err, "failed to decode GPP section 2: vendor consent strings are at least 29 bytes long. This one was 28")
+}
@@ -0,0 +1,539 @@
+package gpp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prebid/go-gdpr/bitutils"
+)
+
+// USSection is implemented by the US National section and every US state section.
+//
+// The sections share value semantics but each state only defines a subset of the fields. Fields which a
+// section doesn't define are reported as not applicable, so callers can resolve opt-out signals without
+// caring which section they hold.
+type USSection interface {
+	Section
+
+	// Version returns the version of the section.
+	Version() uint8
+
+	SharingNotice() Notice
+	SaleOptOutNotice() Notice
+	SharingOptOutNotice() Notice
+	TargetedAdvertisingOptOutNotice() Notice
+	SensitiveDataProcessingOptOutNotice() Notice
+	SensitiveDataLimitUseNotice() Notice
+
+	SaleOptOut() OptOut
+	SharingOptOut() OptOut
+	TargetedAdvertisingOptOut() OptOut
+
+	SensitiveDataProcessing(category int) Consent
+	KnownChildSensitiveDataConsent(index int) Consent
+	PersonalDataConsents() Consent
+
+	MSPACoveredTransaction() MSPA
+	MSPAOptOutOptionMode() MSPA
+	MSPAServiceProviderMode() MSPA
+
+	GPCSegmentIncluded() bool
+	GPC() bool
+}
+
+// Notice is the value of a notice field in the US sections.
+type Notice uint8
+
+const (
+	// NoticeNotApplicable means the notice field doesn't apply to this string.
+	NoticeNotApplicable Notice = 0
+	// NoticeProvided means the notice was provided to the user.
+	NoticeProvided Notice = 1
+	// NoticeNotProvided means the notice was not provided to the user.
+	NoticeNotProvided Notice = 2
+)
+
+// OptOut is the value of an opt-out field in the US sections.
+type OptOut uint8
+
+const (
+	// OptOutNotApplicable means the opt-out field doesn't apply to this string.
+	OptOutNotApplicable OptOut = 0
+	// OptedOut means the user opted out.
+	OptedOut OptOut = 1
+	// DidNotOptOut means the user did not opt out.
+	DidNotOptOut OptOut = 2
+)
+
+// Consent is the value of a consent field in the US sections, such as sensitive data processing.
+type Consent uint8
+
+const (
+	// ConsentNotApplicable means the consent field doesn't apply to this string.
+	ConsentNotApplicable Consent = 0
+	// NoConsent means the user did not consent.
+	NoConsent Consent = 1
+	// Consented means the user consented.
+	Consented Consent = 2
+)
+
+// MSPA is the value of a Multi-State Privacy Agreement field in the US sections.
+type MSPA uint8
+
+const (
+	// MSPANotApplicable means the MSPA field doesn't apply to this string.
+	MSPANotApplicable MSPA = 0
+	// MSPAYes means the field's condition holds, e.g. the transaction is covered by the MSPA.
+	MSPAYes MSPA = 1
+	// MSPANo means the field's condition doesn't hold.
+	MSPANo MSPA = 2
+)
+
+const (
+	subsectionSeparator = "."
+	subsectionTypeGPC   = 1
+)
+
+// usField identifies a field of the US sections which is stored after the 6-bit Version.
+type usField int
+
+const (
+	usSharingNotice usField = iota
+	usSaleOptOutNotice
+	usSharingOptOutNotice
+	usTargetedAdvertisingOptOutNotice
+	usSensitiveDataProcessingOptOutNotice
+	usSensitiveDataLimitUseNotice
+	usSaleOptOut
+	usSharingOptOut
+	usTargetedAdvertisingOptOut
+	usSensitiveDataProcessing
+	usKnownChildSensitiveDataConsents
+	usPersonalDataConsents
+	usMSPACoveredTransaction
+	usMSPAOptOutOptionMode
+	usMSPAServiceProviderMode
+)
+
+// usLayout describes the fields of one version of a US section, in the order they're encoded.
+type usLayout struct {
+	fields             []usField
+	sensitiveDataCount int
+	knownChildCount    int
+	gpcSubsection      bool
+}
+
+var (
+	usNatFields = []usField{
+		usSharingNotice, usSaleOptOutNotice, usSharingOptOutNotice, usTargetedAdvertisingOptOutNotice,
+		usSensitiveDataProcessingOptOutNotice, usSensitiveDataLimitUseNotice,
+		usSaleOptOut, usSharingOptOut, usTargetedAdvertisingOptOut,
+		usSensitiveDataProcessing, usKnownChildSensitiveDataConsents, usPersonalDataConsents,
+		usMSPACoveredTransaction, usMSPAOptOutOptionMode, usMSPAServiceProviderMode,
+	}
+	usCAFields = []usField{
+		usSaleOptOutNotice, usSharingOptOutNotice, usSensitiveDataLimitUseNotice,
+		usSaleOptOut, usSharingOptOut,
+		usSensitiveDataProcessing, usKnownChildSensitiveDataConsents, usPersonalDataConsents,
+		usMSPACoveredTransaction, usMSPAOptOutOptionMode, usMSPAServiceProviderMode,
+	}
+	// Virginia, Colorado and Connecticut share a layout. They only differ in the number of categories.
+	usVAFields = []usField{
+		usSharingNotice, usSaleOptOutNotice, usTargetedAdvertisingOptOutNotice,
+		usSaleOptOut, usTargetedAdvertisingOptOut,
+		usSensitiveDataProcessing, usKnownChildSensitiveDataConsents,
+		usMSPACoveredTransaction, usMSPAOptOutOptionMode, usMSPAServiceProviderMode,
+	}
+	usUTFields = []usField{
+		usSharingNotice, usSaleOptOutNotice, usTargetedAdvertisingOptOutNotice, usSensitiveDataProcessingOptOutNotice,
+		usSaleOptOut, usTargetedAdvertisingOptOut,
+		usSensitiveDataProcessing, usKnownChildSensitiveDataConsents,
+		usMSPACoveredTransaction, usMSPAOptOutOptionMode, usMSPAServiceProviderMode,
+	}
+)
+
+// usLayouts holds the supported layouts, by section ID and then by section version.
+var usLayouts = map[int]map[uint8]usLayout{
+	SectionUSNat: {
+		1: {fields: usNatFields, sensitiveDataCount: 12, knownChildCount: 2, gpcSubsection: true},
+		2: {fields: usNatFields, sensitiveDataCount: 16, knownChildCount: 3, gpcSubsection: true},
+	},
+	SectionUSCA: {1: {fields: usCAFields, sensitiveDataCount: 9, knownChildCount: 2, gpcSubsection: true}},
+	SectionUSVA: {1: {fields: usVAFields, sensitiveDataCount: 8, knownChildCount: 1}},
+	SectionUSCO: {1: {fields: usVAFields, sensitiveDataCount: 7, knownChildCount: 1, gpcSubsection: true}},
+	SectionUSUT: {1: {fields: usUTFields, sensitiveDataCount: 8, knownChildCount: 1}},
+	SectionUSCT: {1: {fields: usVAFields, sensitiveDataCount: 8, knownChildCount: 3, gpcSubsection: true}},
+}
+
+var usSectionNames = map[int]string{
+	SectionUSNat: "usnat",
+	SectionUSCA:  "usca",
+	SectionUSVA:  "usva",
+	SectionUSCO:  "usco",
+	SectionUSUT:  "usut",
+	SectionUSCT:  "usct",
+}
+
+// usSection holds the fields of any US section. The exported section types embed it.
+type usSection struct {
+	id                                  int
+	encoded                             string
+	version                             uint8
+	sharingNotice                       Notice
+	saleOptOutNotice                    Notice
+	sharingOptOutNotice                 Notice
+	targetedAdvertisingOptOutNotice     Notice
+	sensitiveDataProcessingOptOutNotice Notice
+	sensitiveDataLimitUseNotice         Notice
+	saleOptOut                          OptOut
+	sharingOptOut                       OptOut
+	targetedAdvertisingOptOut           OptOut
+	sensitiveDataProcessing             []Consent
+	knownChildSensitiveDataConsents     []Consent
+	personalDataConsents                Consent
+	mspaCoveredTransaction              MSPA
+	mspaOptOutOptionMode                MSPA
+	mspaServiceProviderMode             MSPA
+	gpcSegmentIncluded                  bool
+	gpc                                 bool
+	coreBits                            uint
+}
+
+// parseUSSection decodes the US section with the given ID, using the layout for the version it encodes.
+func parseUSSection(id int, encoded string) (usSection, error) {
+	name := usSectionNames[id]
+	core, subsections := splitSubsections(encoded)
+	data, err := bitutils.DecodeSegment(core)
+	if err != nil {
+		return usSection{}, err
+	}
+
+	f := bitutils.NewFieldReader(data)
+	section := usSection{
+		id:      id,
+		encoded: encoded,
+		version: uint8(f.Read(6)),
+	}
+	if f.Err() != nil {
+		return usSection{}, fmt.Errorf("invalid %s section: %v", name, f.Err())
+	}
+	layout, ok := usLayouts[id][section.version]
+	if !ok {
+		return usSection{}, fmt.Errorf("unsupported %s section version %d", name, section.version)
+	}
+
+	for _, field := range layout.fields {
+		switch field {
+		case usSharingNotice:
+			section.sharingNotice = readNotice(f)
+		case usSaleOptOutNotice:
+			section.saleOptOutNotice = readNotice(f)
+		case usSharingOptOutNotice:
+			section.sharingOptOutNotice = readNotice(f)
+		case usTargetedAdvertisingOptOutNotice:
+			section.targetedAdvertisingOptOutNotice = readNotice(f)
+		case usSensitiveDataProcessingOptOutNotice:
+			section.sensitiveDataProcessingOptOutNotice = readNotice(f)
+		case usSensitiveDataLimitUseNotice:
+			section.sensitiveDataLimitUseNotice = readNotice(f)
+		case usSaleOptOut:
+			section.saleOptOut = readOptOut(f)
+		case usSharingOptOut:
+			section.sharingOptOut = readOptOut(f)
+		case usTargetedAdvertisingOptOut:
+			section.targetedAdvertisingOptOut = readOptOut(f)
+		case usSensitiveDataProcessing:
+			section.sensitiveDataProcessing = readConsents(f, layout.sensitiveDataCount)
+		case usKnownChildSensitiveDataConsents:
+			section.knownChildSensitiveDataConsents = readConsents(f, layout.knownChildCount)
+		case usPersonalDataConsents:
+			section.personalDataConsents = readConsent(f)
+		case usMSPACoveredTransaction:
+			section.mspaCoveredTransaction = readMSPA(f)
+		case usMSPAOptOutOptionMode:
+			section.mspaOptOutOptionMode = readMSPA(f)
+		case usMSPAServiceProviderMode:
+			section.mspaServiceProviderMode = readMSPA(f)
+		}
+	}
+	if f.Err() != nil {
+		return usSection{}, fmt.Errorf("invalid %s section: %v", name, f.Err())
+	}
+	section.coreBits = f.Position()
+
+	if len(subsections) > 0 {
+		if !layout.gpcSubsection {
+			return usSection{}, fmt.Errorf("the %s section doesn't support subsections", name)
+		}
+		section.gpcSegmentIncluded = true
+		if section.gpc, err = parseGPCSubsection(subsections[0]); err != nil {
+			return usSection{}, err
+		}
+	}
+
+	return section, nil
+}
+
+// USValues holds the values of a US section, for encoding. Fields which the section's layout doesn't
+// define must be left as zero values.
+type USValues struct {
+	Version                             uint8
+	SharingNotice                       Notice
+	SaleOptOutNotice                    Notice
+	SharingOptOutNotice                 Notice
+	TargetedAdvertisingOptOutNotice     Notice
+	SensitiveDataProcessingOptOutNotice Notice
+	SensitiveDataLimitUseNotice         Notice
+	SaleOptOut                          OptOut
+	SharingOptOut                       OptOut
+	TargetedAdvertisingOptOut           OptOut
+	// SensitiveDataProcessing may be shorter than the number of categories in the layout.
+	// Missing categories are encoded as ConsentNotApplicable.
+	SensitiveDataProcessing []Consent
+	// KnownChildSensitiveDataConsents may be shorter than the number of age ranges in the layout.
+	// Missing age ranges are encoded as ConsentNotApplicable.
+	KnownChildSensitiveDataConsents []Consent
+	PersonalDataConsents            Consent
+	MSPACoveredTransaction          MSPA
+	MSPAOptOutOptionMode            MSPA
+	MSPAServiceProviderMode         MSPA
+	GPCSegmentIncluded              bool
+	GPC                             bool
+}
+
+// encodeUSSection encodes values using the layout of the given US section.
+func encodeUSSection(id int, values USValues) (string, error) {
+	name := usSectionNames[id]
+	layout, ok := usLayouts[id][values.Version]
+	if !ok {
+		return "", fmt.Errorf("unsupported %s section version %d", name, values.Version)
+	}
+	if len(values.SensitiveDataProcessing) > layout.sensitiveDataCount {
+		return "", fmt.Errorf("the %s section has %d sensitive data categories, but %d values were given", name, layout.sensitiveDataCount, len(values.SensitiveDataProcessing))
+	}
+	if len(values.KnownChildSensitiveDataConsents) > layout.knownChildCount {
+		return "", fmt.Errorf("the %s section has %d known child age ranges, but %d values were given", name, layout.knownChildCount, len(values.KnownChildSensitiveDataConsents))
+	}
+	if values.GPCSegmentIncluded && !layout.gpcSubsection {
+		return "", fmt.Errorf("the %s section doesn't support subsections", name)
+	}
+
+	var w bitutils.Writer
+	w.WriteBits(uint64(values.Version), 6)
+	for _, field := range layout.fields {
+		switch field {
+		case usSharingNotice:
+			w.WriteBits(uint64(values.SharingNotice), 2)
+		case usSaleOptOutNotice:
+			w.WriteBits(uint64(values.SaleOptOutNotice), 2)
+		case usSharingOptOutNotice:
+			w.WriteBits(uint64(values.SharingOptOutNotice), 2)
+		case usTargetedAdvertisingOptOutNotice:
+			w.WriteBits(uint64(values.TargetedAdvertisingOptOutNotice), 2)
+		case usSensitiveDataProcessingOptOutNotice:
+			w.WriteBits(uint64(values.SensitiveDataProcessingOptOutNotice), 2)
+		case usSensitiveDataLimitUseNotice:
+			w.WriteBits(uint64(values.SensitiveDataLimitUseNotice), 2)
+		case usSaleOptOut:
+			w.WriteBits(uint64(values.SaleOptOut), 2)
+		case usSharingOptOut:
+			w.WriteBits(uint64(values.SharingOptOut), 2)
+		case usTargetedAdvertisingOptOut:
+			w.WriteBits(uint64(values.TargetedAdvertisingOptOut), 2)
+		case usSensitiveDataProcessing:
+			writeConsents(&w, values.SensitiveDataProcessing, layout.sensitiveDataCount)
+		case usKnownChildSensitiveDataConsents:
+			writeConsents(&w, values.KnownChildSensitiveDataConsents, layout.knownChildCount)
+		case usPersonalDataConsents:
+			w.WriteBits(uint64(values.PersonalDataConsents), 2)
+		case usMSPACoveredTransaction:
+			w.WriteBits(uint64(values.MSPACoveredTransaction), 2)
+		case usMSPAOptOutOptionMode:
+			w.WriteBits(uint64(values.MSPAOptOutOptionMode), 2)
+		case usMSPAServiceProviderMode:
+			w.WriteBits(uint64(values.MSPAServiceProviderMode), 2)
+		}
+	}
+	encoded := encodeSegment(&w)
+
+	if values.GPCSegmentIncluded {
+		var gpc bitutils.Writer
+		gpc.WriteBits(subsectionTypeGPC, 2)
+		gpc.WriteBool(values.GPC)
+		encoded += subsectionSeparator + encodeSegment(&gpc)
+	}
+	return encoded, nil
+}
+
+func writeConsents(w *bitutils.Writer, consents []Consent, count int) {
+	for i := 0; i < count; i++ {
+		w.WriteBits(uint64(consentAt(consents, i+1)), 2)
+	}
+}
+
+// ID returns the section ID.
+func (s usSection) ID() int {
+	return s.id
+}
+
+// Encoded returns the section exactly as it appeared in the GPP string.
+func (s usSection) Encoded() string {
+	return s.encoded
+}
+
+// Version returns the version of the section.
+func (s usSection) Version() uint8 {
+	return s.version
+}
+
+// SharingNotice returns whether notice was provided about sharing personal data with third parties.
+func (s usSection) SharingNotice() Notice {
+	return s.sharingNotice
+}
+
+// SaleOptOutNotice returns whether notice was provided about the opportunity to opt out of the sale of personal data.
+func (s usSection) SaleOptOutNotice() Notice {
+	return s.saleOptOutNotice
+}
+
+// SharingOptOutNotice returns whether notice was provided about the opportunity to opt out of the sharing of personal data.
+func (s usSection) SharingOptOutNotice() Notice {
+	return s.sharingOptOutNotice
+}
+
+// TargetedAdvertisingOptOutNotice returns whether notice was provided about the opportunity to opt out of targeted advertising.
+func (s usSection) TargetedAdvertisingOptOutNotice() Notice {
+	return s.targetedAdvertisingOptOutNotice
+}
+
+// SensitiveDataProcessingOptOutNotice returns whether notice was provided about the opportunity to opt out of
+// the processing of sensitive data.
+func (s usSection) SensitiveDataProcessingOptOutNotice() Notice {
+	return s.sensitiveDataProcessingOptOutNotice
+}
+
+// SensitiveDataLimitUseNotice returns whether notice was provided about the opportunity to limit the use or
+// disclosure of sensitive data.
+func (s usSection) SensitiveDataLimitUseNotice() Notice {
+	return s.sensitiveDataLimitUseNotice
+}
+
+// SaleOptOut returns whether the user opted out of the sale of their personal data.
+func (s usSection) SaleOptOut() OptOut {
+	return s.saleOptOut
+}
+
+// SharingOptOut returns whether the user opted out of the sharing of their personal data.
+func (s usSection) SharingOptOut() OptOut {
+	return s.sharingOptOut
+}
+
+// TargetedAdvertisingOptOut returns whether the user opted out of targeted advertising.
+func (s usSection) TargetedAdvertisingOptOut() OptOut {
+	return s.targetedAdvertisingOptOut
+}
+
+// SensitiveDataProcessing returns the user's consent to process the given category of sensitive data.
+// Categories are numbered from 1, in the order the section's specification lists them. Categories the
+// section doesn't define return ConsentNotApplicable.
+func (s usSection) SensitiveDataProcessing(category int) Consent {
+	return consentAt(s.sensitiveDataProcessing, category)
+}
+
+// KnownChildSensitiveDataConsent returns the consent for processing sensitive data from a known child.
+// Indexes are numbered from 1, in the order the section's specification lists the age ranges. Indexes the
+// section doesn't define return ConsentNotApplicable.
+func (s usSection) KnownChildSensitiveDataConsent(index int) Consent {
+	return consentAt(s.knownChildSensitiveDataConsents, index)
+}
+
+// PersonalDataConsents returns the user's consent to collect, use or share personal data for purposes
+// beyond what is reasonably necessary.
+func (s usSection) PersonalDataConsents() Consent {
+	return s.personalDataConsents
+}
+
+// MSPACoveredTransaction returns whether the publisher is a signatory to the MSPA and the transaction is
+// covered by it.
+func (s usSection) MSPACoveredTransaction() MSPA {
+	return s.mspaCoveredTransaction
+}
+
+// MSPAOptOutOptionMode returns whether the publisher is operating in the MSPA's Opt-Out Option Mode for
+// a covered transaction.
+func (s usSection) MSPAOptOutOptionMode() MSPA {
+	return s.mspaOptOutOptionMode
+}
+
+// MSPAServiceProviderMode returns whether the publisher is operating in the MSPA's Service Provider Mode for
+// a covered transaction.
+func (s usSection) MSPAServiceProviderMode() MSPA {
+	return s.mspaServiceProviderMode
+}
+
+// GPCSegmentIncluded returns true if the section carried the optional Global Privacy Control subsection.
+func (s usSection) GPCSegmentIncluded() bool {
+	return s.gpcSegmentIncluded
+}
+
+// GPC returns the Global Privacy Control signal. This is always false if GPCSegmentIncluded() is false.
+func (s usSection) GPC() bool {
+	return s.gpc
+}
+
+// splitSubsections splits a section into its core subsection and any optional subsections which follow it.
+func splitSubsections(encoded string) (string, []string) {
+	parts := strings.Split(encoded, subsectionSeparator)
+	return parts[0], parts[1:]
+}
+
+// parseGPCSubsection parses the optional Global Privacy Control subsection of the US sections:
+// a 2-bit SubsectionType (always 1) followed by a 1-bit Gpc flag.
+func parseGPCSubsection(encoded string) (bool, error) {
+	data, err := bitutils.DecodeSegment(encoded)
+	if err != nil {
+		return false, err
+	}
+	f := bitutils.NewFieldReader(data)
+	subsectionType := f.Read(2)
+	gpc := f.ReadBool()
+	if f.Err() != nil {
+		return false, fmt.Errorf("invalid GPC subsection: %v", f.Err())
+	}
+	if subsectionType != subsectionTypeGPC {
+		return false, fmt.Errorf("expected subsection type %d, got %d", subsectionTypeGPC, subsectionType)
+	}
+	return gpc, nil
+}
+
+func readNotice(f *bitutils.FieldReader) Notice {
+	return Notice(f.Read(2))
+}
+
+func readOptOut(f *bitutils.FieldReader) OptOut {
+	return OptOut(f.Read(2))
+}
+
+func readConsent(f *bitutils.FieldReader) Consent {
+	return Consent(f.Read(2))
+}
+
+func readMSPA(f *bitutils.FieldReader) MSPA {
+	return MSPA(f.Read(2))
+}
+
+func readConsents(f *bitutils.FieldReader, count int) []Consent {
+	consents := make([]Consent, count)
+	for i := range consents {
+		consents[i] = readConsent(f)
+	}
+	return consents
+}
+
+// consentAt returns the 1-based index'th value of consents, or ConsentNotApplicable if it's out of range.
+func consentAt(consents []Consent, index int) Consent {
+	if index < 1 || index > len(consents) {
+		return ConsentNotApplicable
+	}
+	return consents[index-1]
+}
@@ -0,0 +1,14 @@
+package gpp
+
+// USNatSection is the US National Privacy section (ID 7) of a GPP string. Version 1 sections define 12
+// sensitive data categories and 2 known child age ranges; version 2 sections define 16 and 3.
+// For technical details, see
+// https://github.com/InteractiveAdvertisingBureau/Global-Privacy-Platform/blob/main/Sections/US-National/IAB%20Privacy%E2%80%99s%20National%20Privacy%20Technical%20Specification.md
+type USNatSection struct {
+	usSection
+}
+
+func parseUSNatSection(encoded string) (USNatSection, error) {
+	section, err := parseUSSection(SectionUSNat, encoded)
+	return USNatSection{section}, err
+}
@@ -0,0 +1,109 @@
+package gpp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUSNatSectionV1(t *testing.T) {
+	section, err := parseUSNatSection("BWQZkAABaZ.Y")
+	assert.NoError(t, err)
+
+	assert.Equal(t, SectionUSNat, section.ID())
+	assert.Equal(t, "BWQZkAABaZ.Y", section.Encoded())
+	assert.Equal(t, uint8(1), section.Version())
+
+	assert.Equal(t, NoticeProvided, section.SharingNotice())
+	assert.Equal(t, NoticeProvided, section.SaleOptOutNotice())
+	assert.Equal(t, NoticeNotProvided, section.SharingOptOutNotice())
+	assert.Equal(t, NoticeProvided, section.TargetedAdvertisingOptOutNotice())
+	assert.Equal(t, NoticeNotApplicable, section.SensitiveDataProcessingOptOutNotice())
+	assert.Equal(t, NoticeNotApplicable, section.SensitiveDataLimitUseNotice())
+
+	assert.Equal(t, OptedOut, section.SaleOptOut())
+	assert.Equal(t, DidNotOptOut, section.SharingOptOut())
+	assert.Equal(t, OptedOut, section.TargetedAdvertisingOptOut())
+
+	assert.Equal(t, Consented, section.SensitiveDataProcessing(1))
+	assert.Equal(t, NoConsent, section.SensitiveDataProcessing(2))
+	assert.Equal(t, ConsentNotApplicable, section.SensitiveDataProcessing(3))
+	assert.Equal(t, NoConsent, section.SensitiveDataProcessing(12))
+	assert.Equal(t, ConsentNotApplicable, section.SensitiveDataProcessing(13))
+	assert.Equal(t, ConsentNotApplicable, section.SensitiveDataProcessing(0))
+
+	assert.Equal(t, NoConsent, section.KnownChildSensitiveDataConsent(1))
+	assert.Equal(t, Consented, section.KnownChildSensitiveDataConsent(2))
+	assert.Equal(t, ConsentNotApplicable, section.KnownChildSensitiveDataConsent(3))
+	assert.Equal(t, Consented, section.PersonalDataConsents())
+
+	assert.Equal(t, MSPAYes, section.MSPACoveredTransaction())
+	assert.Equal(t, MSPANo, section.MSPAOptOutOptionMode())
+	assert.Equal(t, MSPAYes, section.MSPAServiceProviderMode())
+
+	assert.True(t, section.GPCSegmentIncluded())
+	assert.True(t, section.GPC())
+}
+
+func TestParseUSNatSectionV2(t *testing.T) {
+	section, err := parseUSNatSection("CVVmAAAAAlmY")
+	assert.NoError(t, err)
+
+	assert.Equal(t, uint8(2), section.Version())
+	assert.Equal(t, DidNotOptOut, section.SaleOptOut())
+	assert.Equal(t, OptedOut, section.SharingOptOut())
+	assert.Equal(t, DidNotOptOut, section.TargetedAdvertisingOptOut())
+	assert.Equal(t, ConsentNotApplicable, section.SensitiveDataProcessing(15))
+	assert.Equal(t, Consented, section.SensitiveDataProcessing(16))
+	assert.Equal(t, Consented, section.KnownChildSensitiveDataConsent(3))
+	assert.Equal(t, NoConsent, section.PersonalDataConsents())
+
+	assert.Equal(t, MSPANo, section.MSPACoveredTransaction())
+	assert.Equal(t, MSPAYes, section.MSPAOptOutOptionMode())
+	assert.Equal(t, MSPANo, section.MSPAServiceProviderMode())
+
+	assert.False(t, section.GPCSegmentIncluded())
+	assert.False(t, section.GPC())
+}
+
+func TestParseUSNatSectionInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		encoded       string
+		expectedError string
+	}{
+		{
+			name:          "unsupported_version",
+			encoded:       "DVVqAAEABA",
+			expectedError: "unsupported usnat section version 3",
+		},
+		{
+			name:          "truncated",
+			encoded:       "BVVqAAEA",
+			expectedError: "invalid usnat section: ReadBits expected 2 bits to start at bit 48, but the data was only 6 bytes long",
+		},
+		{
+			name:          "wrong_gpc_subsection_type",
+			encoded:       "BVVqAAEABA.gA",
+			expectedError: "expected subsection type 1, got 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseUSNatSection(tt.encoded)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestUSNatSectionInContainer(t *testing.T) {
+	container, err := Parse("DBACMM~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~BVVqAAEABA.QA")
+	assert.NoError(t, err)
+
+	section, ok := container.Sections()[1].(USNatSection)
+	assert.True(t, ok)
+	assert.Equal(t, DidNotOptOut, section.SaleOptOut())
+	assert.True(t, section.GPCSegmentIncluded())
+	assert.False(t, section.GPC())
+}
@@ -0,0 +1,40 @@
+package gpp
+
+import (
+	"github.com/prebid/go-gdpr/usprivacy"
+)
+
+// USPV1Section is the US Privacy section (ID 6) of a GPP string.
+//
+// Unlike the other sections, its payload isn't base64 encoded: it's an ordinary CCPA us_privacy string,
+// such as "1YNN". It embeds the parsed string's fields.
+type USPV1Section struct {
+	usprivacy.Consent
+	encoded string
+}
+
+func parseUSPV1Section(encoded string) (USPV1Section, error) {
+	consent, err := usprivacy.Parse(encoded)
+	if err != nil {
+		return USPV1Section{}, err
+	}
+	return USPV1Section{
+		Consent: consent,
+		encoded: encoded,
+	}, nil
+}
+
+// ID returns SectionUSPV1.
+func (s USPV1Section) ID() int {
+	return SectionUSPV1
+}
+
+// Encoded returns the section exactly as it appeared in the GPP string.
+func (s USPV1Section) Encoded() string {
+	return s.encoded
+}
+
+// USPrivacy returns the section as a us_privacy string.
+func (s USPV1Section) USPrivacy() string {
+	return s.encoded
+}
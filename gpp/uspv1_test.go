@@ -0,0 +1,30 @@
+package gpp
+
+import (
+	"testing"
+
+	"github.com/prebid/go-gdpr/usprivacy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUSPV1Section(t *testing.T) {
+	container, err := Parse("DBACNY~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~1YN-")
+	assert.NoError(t, err)
+
+	section, ok := container.Section(SectionUSPV1)
+	assert.True(t, ok)
+	usp, ok := section.(USPV1Section)
+	assert.True(t, ok)
+	assert.Equal(t, SectionUSPV1, usp.ID())
+	assert.Equal(t, "1YN-", usp.Encoded())
+	assert.Equal(t, "1YN-", usp.USPrivacy())
+	assert.Equal(t, usprivacy.Yes, usp.Notice)
+	assert.Equal(t, usprivacy.No, usp.OptOutSale)
+	assert.Equal(t, usprivacy.NotApplicable, usp.LSPACovered)
+}
+
+func TestParseUSPV1SectionInvalid(t *testing.T) {
+	// The us_privacy parser has its own tests, so this only checks that its errors come through.
+	_, err := Parse("DBABT~1YXN")
+	assert.EqualError(t, err, `failed to decode GPP section 6: us_privacy string "1YXN" has 'X' at index 2, but only 'Y', 'N' and '-' are allowed`)
+}
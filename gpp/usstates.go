@@ -0,0 +1,55 @@
+package gpp
+
+// The US state sections. Each one carries the subset of the US National fields which that state's law
+// defines. For technical details, see
+// https://github.com/InteractiveAdvertisingBureau/Global-Privacy-Platform/tree/main/Sections/US-States
+
+// USCASection is the California section (ID 8) of a GPP string.
+type USCASection struct {
+	usSection
+}
+
+// USVASection is the Virginia section (ID 9) of a GPP string.
+type USVASection struct {
+	usSection
+}
+
+// USCOSection is the Colorado section (ID 10) of a GPP string.
+type USCOSection struct {
+	usSection
+}
+
+// USUTSection is the Utah section (ID 11) of a GPP string.
+type USUTSection struct {
+	usSection
+}
+
+// USCTSection is the Connecticut section (ID 12) of a GPP string.
+type USCTSection struct {
+	usSection
+}
+
+func parseUSCASection(encoded string) (USCASection, error) {
+	section, err := parseUSSection(SectionUSCA, encoded)
+	return USCASection{section}, err
+}
+
+func parseUSVASection(encoded string) (USVASection, error) {
+	section, err := parseUSSection(SectionUSVA, encoded)
+	return USVASection{section}, err
+}
+
+func parseUSCOSection(encoded string) (USCOSection, error) {
+	section, err := parseUSSection(SectionUSCO, encoded)
+	return USCOSection{section}, err
+}
+
+func parseUSUTSection(encoded string) (USUTSection, error) {
+	section, err := parseUSSection(SectionUSUT, encoded)
+	return USUTSection{section}, err
+}
+
+func parseUSCTSection(encoded string) (USCTSection, error) {
+	section, err := parseUSSection(SectionUSCT, encoded)
+	return USCTSection{section}, err
+}
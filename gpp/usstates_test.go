@@ -0,0 +1,114 @@
+package gpp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUSStateSections(t *testing.T) {
+	container, err := Parse("DBABh2~BWaAAFpk.Y~BVlAAlk~BVaABoA~BVqAAFW~BVUAAmGQ")
+	assert.NoError(t, err)
+
+	sections := container.USSections()
+	assert.Len(t, sections, 5)
+
+	ca, ok := sections[0].(USCASection)
+	assert.True(t, ok)
+	assert.Equal(t, SectionUSCA, ca.ID())
+	assert.Equal(t, NoticeNotApplicable, ca.SharingNotice())
+	assert.Equal(t, NoticeNotProvided, ca.SensitiveDataLimitUseNotice())
+	assert.Equal(t, OptedOut, ca.SaleOptOut())
+	assert.Equal(t, DidNotOptOut, ca.SharingOptOut())
+	assert.Equal(t, OptOutNotApplicable, ca.TargetedAdvertisingOptOut())
+	assert.Equal(t, Consented, ca.SensitiveDataProcessing(1))
+	assert.Equal(t, NoConsent, ca.SensitiveDataProcessing(9))
+	assert.Equal(t, ConsentNotApplicable, ca.SensitiveDataProcessing(10))
+	assert.Equal(t, Consented, ca.KnownChildSensitiveDataConsent(2))
+	assert.Equal(t, Consented, ca.PersonalDataConsents())
+	assert.Equal(t, MSPAYes, ca.MSPACoveredTransaction())
+	assert.Equal(t, MSPANo, ca.MSPAOptOutOptionMode())
+	assert.Equal(t, MSPAYes, ca.MSPAServiceProviderMode())
+	assert.True(t, ca.GPCSegmentIncluded())
+	assert.True(t, ca.GPC())
+
+	va, ok := sections[1].(USVASection)
+	assert.True(t, ok)
+	assert.Equal(t, SectionUSVA, va.ID())
+	assert.Equal(t, NoticeProvided, va.TargetedAdvertisingOptOutNotice())
+	assert.Equal(t, DidNotOptOut, va.SaleOptOut())
+	assert.Equal(t, OptOutNotApplicable, va.SharingOptOut())
+	assert.Equal(t, OptedOut, va.TargetedAdvertisingOptOut())
+	assert.Equal(t, Consented, va.SensitiveDataProcessing(8))
+	assert.Equal(t, NoConsent, va.KnownChildSensitiveDataConsent(1))
+	assert.Equal(t, ConsentNotApplicable, va.KnownChildSensitiveDataConsent(2))
+	assert.False(t, va.GPCSegmentIncluded())
+
+	co, ok := sections[2].(USCOSection)
+	assert.True(t, ok)
+	assert.Equal(t, SectionUSCO, co.ID())
+	assert.Equal(t, OptedOut, co.SaleOptOut())
+	assert.Equal(t, DidNotOptOut, co.TargetedAdvertisingOptOut())
+	assert.Equal(t, NoConsent, co.SensitiveDataProcessing(7))
+	assert.Equal(t, ConsentNotApplicable, co.SensitiveDataProcessing(8))
+	assert.Equal(t, Consented, co.KnownChildSensitiveDataConsent(1))
+	assert.Equal(t, MSPANo, co.MSPACoveredTransaction())
+	assert.Equal(t, MSPANotApplicable, co.MSPAOptOutOptionMode())
+	assert.Equal(t, MSPANotApplicable, co.MSPAServiceProviderMode())
+
+	ut, ok := sections[3].(USUTSection)
+	assert.True(t, ok)
+	assert.Equal(t, SectionUSUT, ut.ID())
+	assert.Equal(t, NoticeNotProvided, ut.SensitiveDataProcessingOptOutNotice())
+	assert.Equal(t, DidNotOptOut, ut.SaleOptOut())
+	assert.Equal(t, DidNotOptOut, ut.TargetedAdvertisingOptOut())
+	assert.Equal(t, NoConsent, ut.SensitiveDataProcessing(8))
+	assert.Equal(t, MSPAYes, ut.MSPACoveredTransaction())
+	assert.Equal(t, MSPAYes, ut.MSPAOptOutOptionMode())
+	assert.Equal(t, MSPANo, ut.MSPAServiceProviderMode())
+
+	ct, ok := sections[4].(USCTSection)
+	assert.True(t, ok)
+	assert.Equal(t, SectionUSCT, ct.ID())
+	assert.Equal(t, OptedOut, ct.SaleOptOut())
+	assert.Equal(t, Consented, ct.SensitiveDataProcessing(8))
+	assert.Equal(t, NoConsent, ct.KnownChildSensitiveDataConsent(1))
+	assert.Equal(t, Consented, ct.KnownChildSensitiveDataConsent(2))
+	assert.Equal(t, ConsentNotApplicable, ct.KnownChildSensitiveDataConsent(3))
+	assert.False(t, ct.GPCSegmentIncluded())
+}
+
+func TestParseUSStateSectionsInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		id            int
+		encoded       string
+		expectedError string
+	}{
+		{
+			name:          "unsupported_version",
+			id:            SectionUSCA,
+			encoded:       "CWaAAFpk",
+			expectedError: "unsupported usca section version 2",
+		},
+		{
+			name:          "gpc_not_supported",
+			id:            SectionUSVA,
+			encoded:       "BVlAAlk.Y",
+			expectedError: "the usva section doesn't support subsections",
+		},
+		{
+			name:          "truncated",
+			id:            SectionUSUT,
+			encoded:       "BVqA",
+			expectedError: "invalid usut section: ReadBits expected 2 bits to start at bit 24, but the data was only 3 bytes long",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeSection(tt.id, tt.encoded)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
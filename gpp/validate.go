@@ -0,0 +1,129 @@
+package gpp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prebid/go-gdpr/bitutils"
+	tcfca "github.com/prebid/go-gdpr/vendorconsent/tcfca"
+)
+
+// Violation is a way in which a GPP string breaks the specification without preventing it from being parsed.
+type Violation struct {
+	// SectionID is the ID of the offending section, or SectionHeader for problems with the header.
+	SectionID int
+	Message   string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("section %d: %s", v.SectionID, v.Message)
+}
+
+// validator is implemented by sections which can check rules that parsing tolerates.
+type validator interface {
+	violations() []string
+}
+
+// Validate checks the rules which Parse tolerates, and returns every violation it finds. It checks that no
+// subsection is empty, that each section only carries the subsections its specification defines, and that the
+// bits padding each segment out to a whole character are zero.
+// On containers from ParseLazy, it decodes every section and also reports those which fail to decode.
+//
+// A nil result means the string is well formed. The violations are meant for monitoring: none of them stop
+// the sections from being used.
+func (c *Container) Validate() []Violation {
+	var violations []Violation
+	add := func(id int, format string, args ...interface{}) {
+		violations = append(violations, Violation{SectionID: id, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if c.UnknownVersion() {
+		add(SectionHeader, "the header has version %d, but only version %d is understood", c.header.version, headerVersion)
+	} else if trailingBitsSet(c.header.encoded, c.header.bits) {
+		add(SectionHeader, "the bits after the section IDs are not all zero")
+	}
+
+	for _, lazy := range c.sections {
+		for i, subsection := range strings.Split(lazy.encoded, subsectionSeparator) {
+			if subsection == "" {
+				add(lazy.id, "subsection %d is empty", i)
+			}
+		}
+		section, err := lazy.decode()
+		if err != nil {
+			add(lazy.id, "%v", err)
+			continue
+		}
+		if v, ok := section.(validator); ok {
+			for _, message := range v.violations() {
+				add(section.ID(), "%s", message)
+			}
+		}
+	}
+	return violations
+}
+
+// trailingBitsSet returns true if any bit of the segment after the first used bits is set.
+func trailingBitsSet(segment string, used uint) bool {
+	data, err := bitutils.DecodeSegment(segment)
+	if err != nil {
+		return false
+	}
+	for i := used; i < uint(len(data))*8; i++ {
+		if data[i/8]&(0x80>>(i%8)) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s usSection) violations() []string {
+	var violations []string
+	core, subsections := splitSubsections(s.encoded)
+	if trailingBitsSet(core, s.coreBits) {
+		violations = append(violations, "the bits after the core subsection's fields are not all zero")
+	}
+	if len(subsections) > 1 {
+		violations = append(violations, fmt.Sprintf("the section carries %d subsections, but only the GPC subsection is defined", len(subsections)))
+	}
+	if len(subsections) > 0 && trailingBitsSet(subsections[0], 3) {
+		violations = append(violations, "the bits after the GPC subsection's fields are not all zero")
+	}
+	return violations
+}
+
+func (s TCFCAV1Section) violations() []string {
+	var violations []string
+	core, subsections := splitSubsections(s.encoded)
+	if trailingBitsSet(core, s.CoreSegmentBits()) {
+		violations = append(violations, "the bits after the core subsection's fields are not all zero")
+	}
+
+	publisherPurposes := 0
+	for i, subsection := range subsections {
+		data, err := bitutils.DecodeSegment(subsection)
+		if err != nil {
+			continue
+		}
+		f := bitutils.NewFieldReader(data)
+		subsectionType := f.Read(3)
+		if f.Err() != nil {
+			continue
+		}
+		if subsectionType != tcfca.SegmentTypePublisherPurposes {
+			violations = append(violations, fmt.Sprintf("subsection %d has the unknown type %d", i+1, subsectionType))
+			continue
+		}
+		publisherPurposes++
+		f.Read(48)
+		numCustomPurposes := uint(f.Read(6))
+		used := f.Position() + 2*numCustomPurposes
+		if trailingBitsSet(subsection, used) {
+			violations = append(violations, "the bits after the publisher purposes subsection's fields are not all zero")
+		}
+	}
+	if publisherPurposes > 1 {
+		violations = append(violations, fmt.Sprintf("the section carries %d publisher purposes subsections, but at most 1 is allowed", publisherPurposes))
+	}
+	return violations
+}
@@ -0,0 +1,79 @@
+package gpp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name               string
+		gpp                string
+		expectedViolations []Violation
+	}{
+		{
+			name: "well_formed_us_sections",
+			gpp:  "DBABh2~BWaAAFpk.Y~BVlAAlk~BVaABoA~BVqAAFW~BVUAAmGQ",
+		},
+		{
+			name: "well_formed_tcfca",
+			gpp:  "DBABD~BPk6AIAPk6AJkEsACBENAyBQAcAAADAAAAAUoEAFIAUABQAIAAo.cAAACAAAAdQ",
+		},
+		{
+			name: "extra_padding_characters",
+			gpp:  "DBACMM~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA~BVVqAAEABA.QA",
+		},
+		{
+			name: "header_trailing_bits",
+			gpp:  "DBABMB~CPXxRfAPXxRfAAfKABENB-CgAAAAAAAAAAYgAAAAAAAA",
+			expectedViolations: []Violation{
+				{SectionID: SectionHeader, Message: "the bits after the section IDs are not all zero"},
+			},
+		},
+		{
+			name: "empty_subsection",
+			gpp:  "DBABRY~abc..def",
+			expectedViolations: []Violation{
+				{SectionID: 30, Message: "subsection 1 is empty"},
+			},
+		},
+		{
+			name: "us_trailing_bits",
+			gpp:  "DBABLA~BVVqAAEABAB.R",
+			expectedViolations: []Violation{
+				{SectionID: SectionUSNat, Message: "the bits after the core subsection's fields are not all zero"},
+				{SectionID: SectionUSNat, Message: "the bits after the GPC subsection's fields are not all zero"},
+			},
+		},
+		{
+			name: "us_extra_subsections",
+			gpp:  "DBABLA~BVVqAAEABA.Q.Q",
+			expectedViolations: []Violation{
+				{SectionID: SectionUSNat, Message: "the section carries 2 subsections, but only the GPC subsection is defined"},
+			},
+		},
+		{
+			name: "tcfca_subsections",
+			gpp:  "DBABD~BPk6AIAPk6AJkEsACBENAyBQAcAAADAAAAAUoEAFIAUABQAIAAo.cAAACAAAAdQ.IA.cAAACAAAAdR",
+			expectedViolations: []Violation{
+				{SectionID: SectionTCFCAV1, Message: "subsection 2 has the unknown type 1"},
+				{SectionID: SectionTCFCAV1, Message: "the bits after the publisher purposes subsection's fields are not all zero"},
+				{SectionID: SectionTCFCAV1, Message: "the section carries 2 publisher purposes subsections, but at most 1 is allowed"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			container, err := Parse(tt.gpp)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedViolations, container.Validate())
+		})
+	}
+}
+
+func TestViolationString(t *testing.T) {
+	violation := Violation{SectionID: SectionHeader, Message: "the bits after the section IDs are not all zero"}
+	assert.Equal(t, "section 3: the bits after the section IDs are not all zero", violation.String())
+}
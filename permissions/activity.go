@@ -0,0 +1,70 @@
+package permissions
+
+import (
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+)
+
+// Activity names something product code does with personal data, such as measuring ad performance,
+// which needs the vendor to be allowed one or more purposes. Activities let that code ask whether it may
+// go ahead without referring to purpose IDs.
+type Activity string
+
+const (
+	// ActivityAdDelivery is selecting and delivering ads. By default, it needs purpose 2.
+	ActivityAdDelivery Activity = "ad_delivery"
+	// ActivityMeasurement is measuring how ads perform. By default, it needs purpose 7.
+	ActivityMeasurement Activity = "measurement"
+	// ActivityPersonalization is building a profile and using it to select personalised ads. By default,
+	// it needs purposes 3 and 4.
+	ActivityPersonalization Activity = "personalization"
+)
+
+// DefaultActivities returns the purposes each Activity needs when Config.Activities is nil.
+func DefaultActivities() map[Activity][]consentconstants.Purpose {
+	return map[Activity][]consentconstants.Purpose{
+		ActivityAdDelivery:      {2},
+		ActivityMeasurement:     {7},
+		ActivityPersonalization: {3, 4},
+	}
+}
+
+// activities copies the activities of the config, or returns the defaults.
+func activities(config Config) map[Activity][]consentconstants.Purpose {
+	if config.Activities == nil {
+		return DefaultActivities()
+	}
+	activities := make(map[Activity][]consentconstants.Purpose, len(config.Activities))
+	for activity, purposes := range config.Activities {
+		activities[activity] = append([]consentconstants.Purpose(nil), purposes...)
+	}
+	return activities
+}
+
+// ActivityChecker answers whether vendors may perform activities on one request. Get one from
+// Evaluator.Activities.
+type ActivityChecker struct {
+	evaluator *Evaluator
+	consent   api.VendorConsents
+	gvl       api.VendorList
+}
+
+// Activities returns an ActivityChecker for a request with the given consent string and vendor list.
+func (e *Evaluator) Activities(consent api.VendorConsents, gvl api.VendorList) ActivityChecker {
+	return ActivityChecker{evaluator: e, consent: consent, gvl: gvl}
+}
+
+// ActivityAllowed returns true if Evaluate allows the vendor every purpose which the activity needs, on
+// any legal basis. Activities which the Config doesn't map to any purposes are never allowed.
+func (c ActivityChecker) ActivityAllowed(activity Activity, vendorID uint16) bool {
+	purposes := c.evaluator.activities[activity]
+	if len(purposes) == 0 {
+		return false
+	}
+	for _, purpose := range purposes {
+		if c.evaluator.Evaluate(c.consent, c.gvl, vendorID, purpose) == LegalBasisNone {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,32 @@
+package permissions
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActivityAllowed(t *testing.T) {
+	list := parseTestList(t)
+	checker := NewEvaluator(Config{}).Activities(consent(nil), list)
+
+	assert.True(t, checker.ActivityAllowed(ActivityAdDelivery, 1))
+	assert.True(t, checker.ActivityAllowed(ActivityMeasurement, 1))
+	assert.False(t, checker.ActivityAllowed(ActivityPersonalization, 1))
+	assert.False(t, checker.ActivityAllowed(ActivityAdDelivery, 4))
+	assert.False(t, checker.ActivityAllowed("unknown", 1))
+}
+
+func TestActivityAllowedConfigured(t *testing.T) {
+	var config Config
+	assert.NoError(t, json.Unmarshal([]byte(`{"activities": {"storage": [1], "reporting": [2, 7]}}`), &config))
+	assert.Equal(t, map[Activity][]consentconstants.Purpose{"storage": {1}, "reporting": {2, 7}}, config.Activities)
+
+	checker := NewEvaluator(config).Activities(consent(nil), parseTestList(t))
+	assert.True(t, checker.ActivityAllowed("storage", 1))
+	assert.True(t, checker.ActivityAllowed("reporting", 1))
+	assert.False(t, checker.ActivityAllowed("reporting", 4))
+	assert.False(t, checker.ActivityAllowed(ActivityAdDelivery, 1))
+}
@@ -0,0 +1,89 @@
+package permissions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+)
+
+// ReasonCode identifies the step of an evaluation which decided its result. The codes are stable, so
+// they can be stored in logs and used as metric labels.
+type ReasonCode string
+
+const (
+	// ReasonDenyList means the vendor is on one of the Config's DenyLists.
+	ReasonDenyList ReasonCode = "deny_list"
+	// ReasonVendorException means the purpose's VendorExceptions list the vendor.
+	ReasonVendorException ReasonCode = "vendor_exception"
+	// ReasonVendorDenied means the purpose's DeniedVendors list the vendor.
+	ReasonVendorDenied ReasonCode = "vendor_denied"
+	// ReasonConsentExpired means the consent string expired. See Config.ExpireConsent.
+	ReasonConsentExpired ReasonCode = "consent_expired"
+	// ReasonPolicyVersionBelowMinimum means the consent string's TCF policy version is below
+	// Config.MinPolicyVersion.
+	ReasonPolicyVersionBelowMinimum ReasonCode = "policy_version_below_minimum"
+	// ReasonHostVendor means the vendor is the host vendor, decided by the Publisher TC segment.
+	ReasonHostVendor ReasonCode = "host_vendor"
+	// ReasonPurposeOneTreatment means the PurposeOneTreatment configuration decided purpose 1.
+	ReasonPurposeOneTreatment ReasonCode = "purpose_one_treatment"
+	// ReasonBasicEnforcement means the purpose was enforced in ModeBasic.
+	ReasonBasicEnforcement ReasonCode = "basic_enforcement"
+	// ReasonVendorsIgnored means the purpose was enforced in ModeFull with IgnoreVendors set.
+	ReasonVendorsIgnored ReasonCode = "vendors_ignored"
+	// ReasonFullEnforcement means the purpose was enforced in ModeFull.
+	ReasonFullEnforcement ReasonCode = "full_enforcement"
+	// ReasonGDPRNotApplicable means EvaluateInScope was told the GDPR doesn't apply to the request.
+	ReasonGDPRNotApplicable ReasonCode = "gdpr_not_applicable"
+)
+
+// AuditRecord describes one evaluation, for a compliance event log. It identifies the consent string by
+// a hash rather than holding it.
+type AuditRecord struct {
+	// ConsentHash is the hex encoded SHA-256 of the consent string's core segment, or "" if the consent
+	// string doesn't provide one.
+	ConsentHash string                   `json:"consentHash"`
+	VendorID    uint16                   `json:"vendor"`
+	Purpose     consentconstants.Purpose `json:"purpose"`
+	Outcome     LegalBasis               `json:"outcome"`
+	Reason      ReasonCode               `json:"reason"`
+	GVLVersion  uint16                   `json:"gvlVersion"`
+	Time        time.Time                `json:"timestamp"`
+}
+
+// consentHasher is implemented by TCF 2 consent strings.
+type consentHasher interface {
+	Hash() [sha256.Size]byte
+}
+
+// SetAuditHook makes the Evaluator call hook with an AuditRecord after each evaluation by Evaluate,
+// EvaluateString or EvaluateInScope, including results served from a Cache. Explain isn't audited. The
+// hook is called synchronously, so it should hand the record off rather than write it. Call it before
+// using the Evaluator.
+func (e *Evaluator) SetAuditHook(hook func(AuditRecord)) {
+	e.auditHook = hook
+}
+
+// audit passes the result of an evaluation to the audit hook, if there is one.
+func (e *Evaluator) audit(consent api.VendorConsents, gvl api.VendorList, vendorID uint16, purpose consentconstants.Purpose, basis LegalBasis, reason ReasonCode) {
+	if e.auditHook == nil {
+		return
+	}
+	record := AuditRecord{
+		VendorID: vendorID,
+		Purpose:  purpose,
+		Outcome:  basis,
+		Reason:   reason,
+		Time:     time.Now().UTC(),
+	}
+	if hasher, ok := consent.(consentHasher); ok {
+		hash := hasher.Hash()
+		record.ConsentHash = hex.EncodeToString(hash[:])
+	}
+	if gvl != nil {
+		record.GVLVersion = gvl.Version()
+	}
+	e.auditHook(record)
+}
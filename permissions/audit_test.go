@@ -0,0 +1,80 @@
+package permissions
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prebid/go-gdpr/vendorconsent"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditHook(t *testing.T) {
+	list := parseTestList(t)
+	evaluator := NewEvaluator(Config{DenyLists: map[string][]uint16{"regulatory": {4}}})
+	var records []AuditRecord
+	evaluator.SetAuditHook(func(record AuditRecord) { records = append(records, record) })
+
+	assert.Equal(t, LegalBasisConsent, evaluator.Evaluate(consent(nil), list, 1, 2))
+	assert.Equal(t, LegalBasisNone, evaluator.Evaluate(consent(nil), list, 4, 4))
+	assert.Equal(t, LegalBasisNotApplicable, evaluator.EvaluateInScope(ScopeNotApplicable, nil, nil, 1, 2))
+	evaluator.Explain(consent(nil), list, 1, 2)
+
+	if assert.Len(t, records, 3) {
+		for _, record := range records {
+			assert.WithinDuration(t, time.Now(), record.Time, time.Minute)
+		}
+		records[0].Time, records[1].Time, records[2].Time = time.Time{}, time.Time{}, time.Time{}
+		assert.Equal(t, []AuditRecord{
+			{VendorID: 1, Purpose: 2, Outcome: LegalBasisConsent, Reason: ReasonFullEnforcement, GVLVersion: 15},
+			{VendorID: 4, Purpose: 4, Outcome: LegalBasisNone, Reason: ReasonDenyList, GVLVersion: 15},
+			{VendorID: 1, Purpose: 2, Outcome: LegalBasisNotApplicable, Reason: ReasonGDPRNotApplicable},
+		}, records)
+	}
+}
+
+func TestAuditHookEvaluateString(t *testing.T) {
+	list := parseTestList(t)
+	parsed, err := vendorconsent.ParseString(cacheTestString)
+	assert.NoError(t, err)
+	hash := parsed.(consentHasher).Hash()
+
+	evaluator := NewEvaluator(Config{})
+	evaluator.SetCache(NewCache(10))
+	var records []AuditRecord
+	evaluator.SetAuditHook(func(record AuditRecord) { records = append(records, record) })
+
+	for i := 0; i < 2; i++ {
+		_, err := evaluator.EvaluateString(cacheTestString, list, 1, 2)
+		assert.NoError(t, err)
+	}
+	if assert.Len(t, records, 2) {
+		for _, record := range records {
+			assert.Equal(t, hex.EncodeToString(hash[:]), record.ConsentHash)
+			assert.Equal(t, ReasonFullEnforcement, record.Reason)
+		}
+	}
+}
+
+func TestAuditRecordJSON(t *testing.T) {
+	data, err := json.Marshal(AuditRecord{
+		ConsentHash: "ab",
+		VendorID:    1,
+		Purpose:     2,
+		Outcome:     LegalBasisLegitimateInterest,
+		Reason:      ReasonFullEnforcement,
+		GVLVersion:  15,
+		Time:        time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"consentHash": "ab",
+		"vendor": 1,
+		"purpose": 2,
+		"outcome": "legitimate interest",
+		"reason": "full_enforcement",
+		"gvlVersion": 15,
+		"timestamp": "2024-05-01T12:00:00Z"
+	}`, string(data))
+}
@@ -0,0 +1,43 @@
+package permissions
+
+import "math/bits"
+
+// Bitset is a set of vendor IDs. The zero value is an empty set.
+type Bitset struct {
+	words []uint64
+}
+
+// Has returns true if the vendor is in the set.
+func (b Bitset) Has(vendorID uint16) bool {
+	word := int(vendorID / 64)
+	return word < len(b.words) && b.words[word]&(1<<(vendorID%64)) != 0
+}
+
+// Len returns the number of vendors in the set.
+func (b Bitset) Len() int {
+	n := 0
+	for _, word := range b.words {
+		n += bits.OnesCount64(word)
+	}
+	return n
+}
+
+// VendorIDs returns the vendors in the set, in ascending order.
+func (b Bitset) VendorIDs() []uint16 {
+	ids := make([]uint16, 0, b.Len())
+	for i, word := range b.words {
+		for word != 0 {
+			ids = append(ids, uint16(i*64+bits.TrailingZeros64(word)))
+			word &= word - 1
+		}
+	}
+	return ids
+}
+
+func (b *Bitset) add(vendorID uint16) {
+	word := int(vendorID / 64)
+	if word >= len(b.words) {
+		b.words = append(b.words, make([]uint64, word+1-len(b.words))...)
+	}
+	b.words[word] |= 1 << (vendorID % 64)
+}
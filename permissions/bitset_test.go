@@ -0,0 +1,28 @@
+package permissions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitset(t *testing.T) {
+	var set Bitset
+	assert.False(t, set.Has(1))
+	assert.Equal(t, 0, set.Len())
+	assert.Equal(t, []uint16{}, set.VendorIDs())
+
+	for _, id := range []uint16{700, 1, 63, 64, 1, 65535} {
+		set.add(id)
+	}
+	assert.True(t, set.Has(1))
+	assert.True(t, set.Has(63))
+	assert.True(t, set.Has(64))
+	assert.True(t, set.Has(700))
+	assert.True(t, set.Has(65535))
+	assert.False(t, set.Has(0))
+	assert.False(t, set.Has(2))
+	assert.False(t, set.Has(701))
+	assert.Equal(t, 5, set.Len())
+	assert.Equal(t, []uint16{1, 63, 64, 700, 65535}, set.VendorIDs())
+}
@@ -0,0 +1,168 @@
+package permissions
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/prebid/go-gdpr/vendorconsent"
+)
+
+// Cache keeps the parsed form and evaluation results of recently seen consent strings, so that
+// Evaluator.EvaluateString doesn't parse and evaluate the same string again. A handful of strings make
+// up most traffic, so even a small cache saves most of the work.
+//
+// Entries are keyed by a hash of the consent string, the vendor list's version and a fingerprint of the
+// Evaluator's Config, so one Cache may be shared by several Evaluators. It is safe for concurrent use.
+type Cache struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[cacheKey]*list.Element
+}
+
+type cacheKey struct {
+	consent     [sha256.Size]byte
+	specVersion uint16
+	listVersion uint16
+	config      [sha256.Size]byte
+}
+
+type cacheEntry struct {
+	key     cacheKey
+	consent api.VendorConsents
+	results map[resultKey]outcome
+}
+
+type resultKey struct {
+	vendorID uint16
+	purpose  consentconstants.Purpose
+}
+
+type outcome struct {
+	basis  LegalBasis
+	reason ReasonCode
+}
+
+// NewCache returns a Cache which keeps up to size consent strings. When it's full, the least recently
+// used string is dropped. Sizes below 1 mean 1.
+func NewCache(size int) *Cache {
+	if size < 1 {
+		size = 1
+	}
+	return &Cache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[cacheKey]*list.Element),
+	}
+}
+
+// Len returns the number of consent strings in the cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// get returns the entry for key, marking it as recently used.
+func (c *Cache) get(key cacheKey) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheEntry), true
+}
+
+// add stores a new entry for key, or returns the one another goroutine stored first.
+func (c *Cache) add(key cacheKey, consent api.VendorConsents) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		return element.Value.(*cacheEntry)
+	}
+	entry := &cacheEntry{key: key, consent: consent, results: make(map[resultKey]outcome)}
+	c.entries[key] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+	return entry
+}
+
+func (c *Cache) result(entry *cacheEntry, key resultKey) (outcome, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result, ok := entry.results[key]
+	return result, ok
+}
+
+func (c *Cache) storeResult(entry *cacheEntry, key resultKey, result outcome) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.results[key] = result
+}
+
+// SetCache makes EvaluateString keep results in cache. Call it before using the Evaluator.
+func (e *Evaluator) SetCache(cache *Cache) {
+	e.cache = cache
+}
+
+// EvaluateString parses the consent string with vendorconsent.ParseString, and evaluates it like
+// Evaluate. With a Cache, each string is only parsed once, and each vendor and purpose only evaluated
+// once per vendor list version. Strings which have expired according to the Config are evaluated again
+// each time, so a result can't outlive the string's expiry.
+func (e *Evaluator) EvaluateString(consent string, gvl api.VendorList, vendorID uint16, purpose consentconstants.Purpose) (LegalBasis, error) {
+	if e.cache == nil {
+		parsed, err := vendorconsent.ParseString(consent)
+		if err != nil {
+			return LegalBasisNone, err
+		}
+		return e.Evaluate(parsed, gvl, vendorID, purpose), nil
+	}
+
+	key := e.cacheKey(consent, gvl)
+	entry, ok := e.cache.get(key)
+	if !ok {
+		parsed, err := vendorconsent.ParseString(consent)
+		if err != nil {
+			return LegalBasisNone, err
+		}
+		entry = e.cache.add(key, parsed)
+	}
+	if e.expired(entry.consent) {
+		return e.Evaluate(entry.consent, gvl, vendorID, purpose), nil
+	}
+
+	resultKey := resultKey{vendorID: vendorID, purpose: purpose}
+	result, ok := e.cache.result(entry, resultKey)
+	if !ok {
+		result.basis, result.reason = e.evaluate(entry.consent, gvl, vendorID, purpose)
+		e.cache.storeResult(entry, resultKey, result)
+	}
+	e.audit(entry.consent, gvl, vendorID, purpose, result.basis, result.reason)
+	return result.basis, nil
+}
+
+func (e *Evaluator) cacheKey(consent string, gvl api.VendorList) cacheKey {
+	return cacheKey{
+		consent:     sha256.Sum256([]byte(consent)),
+		specVersion: gvl.SpecVersion(),
+		listVersion: gvl.Version(),
+		config:      e.fingerprint,
+	}
+}
+
+// fingerprint identifies the policy a Config describes, so that a Cache shared by several Evaluators
+// doesn't mix their results. Maps are printed in key order, so equal Configs get equal fingerprints.
+func fingerprint(config Config) [sha256.Size]byte {
+	return sha256.Sum256([]byte(fmt.Sprintf("%+v", config)))
+}
@@ -0,0 +1,107 @@
+package permissions
+
+import (
+	"testing"
+
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/prebid/go-gdpr/vendorconsent"
+	"github.com/stretchr/testify/assert"
+)
+
+// cacheTestString allows purpose 6 only, and discloses legitimate interest for purposes 1 to 10.
+const cacheTestString = "COwAdDhOwAdDhN4ABAENAPCgAAQAAv___wAAAFP_AAp_4AI6ACACAA"
+
+func TestEvaluateString(t *testing.T) {
+	list := parseTestList(t)
+	for _, cached := range []bool{false, true} {
+		evaluator := NewEvaluator(Config{})
+		if cached {
+			evaluator.SetCache(NewCache(10))
+		}
+		parsed, err := vendorconsent.ParseString(cacheTestString)
+		assert.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			for purpose := consentconstants.Purpose(1); purpose <= 10; purpose++ {
+				for vendorID := uint16(1); vendorID <= 10; vendorID++ {
+					basis, err := evaluator.EvaluateString(cacheTestString, list, vendorID, purpose)
+					assert.NoError(t, err)
+					assert.Equal(t, Evaluate(parsed, list, vendorID, purpose), basis)
+				}
+			}
+		}
+
+		_, err = evaluator.EvaluateString("invalid", list, 1, 2)
+		assert.Error(t, err)
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	list := parseTestList(t)
+	cache := NewCache(2)
+	evaluator := NewEvaluator(Config{})
+	evaluator.SetCache(cache)
+
+	consents := []string{
+		cacheTestString,
+		"COx3XOeOx3XOeLkAAAENAfCIAAAAAHgAAIAAAAAAAAAA",
+		"COx3XOeOx3XOeLkAAAENAfCIAAAAAHgAAIYgAAAAAAAA",
+	}
+	for _, s := range consents {
+		_, err := evaluator.EvaluateString(s, list, 1, 7)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, cache.Len())
+
+	_, ok := cache.get(evaluator.cacheKey(consents[0], list))
+	assert.False(t, ok, "the least recently used string should have been dropped")
+	_, ok = cache.get(evaluator.cacheKey(consents[2], list))
+	assert.True(t, ok)
+
+	_, err := evaluator.EvaluateString("invalid", list, 1, 7)
+	assert.Error(t, err)
+	assert.Equal(t, 2, cache.Len())
+}
+
+func TestCacheSharedByEvaluators(t *testing.T) {
+	list := parseTestList(t)
+	cache := NewCache(10)
+	full := NewEvaluator(Config{})
+	full.SetCache(cache)
+	basic := NewEvaluator(Config{Purposes: map[consentconstants.Purpose]PurposeConfig{2: {Mode: ModeBasic}}})
+	basic.SetCache(cache)
+
+	basis, err := full.EvaluateString(cacheTestString, list, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, LegalBasisNone, basis)
+
+	basis, err = basic.EvaluateString(cacheTestString, list, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, LegalBasisLegitimateInterest, basis)
+	assert.Equal(t, 2, cache.Len())
+
+	same := NewEvaluator(Config{Purposes: map[consentconstants.Purpose]PurposeConfig{2: {Mode: ModeBasic}}})
+	assert.Equal(t, basic.fingerprint, same.fingerprint)
+}
+
+func TestCacheExpiredConsent(t *testing.T) {
+	list := parseTestList(t)
+	cache := NewCache(10)
+	// cacheTestString was created on 2020-03-09.
+	evaluator := NewEvaluator(Config{
+		Purposes:      map[consentconstants.Purpose]PurposeConfig{7: {VendorExceptions: []uint16{2}}},
+		ExpireConsent: true,
+	})
+	evaluator.SetCache(cache)
+
+	basis, err := evaluator.EvaluateString(cacheTestString, list, 1, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, LegalBasisNone, basis)
+	basis, err = evaluator.EvaluateString(cacheTestString, list, 2, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, LegalBasisVendorException, basis)
+
+	entry, ok := cache.get(evaluator.cacheKey(cacheTestString, list))
+	assert.True(t, ok)
+	assert.Empty(t, entry.results)
+}
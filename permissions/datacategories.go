@@ -0,0 +1,70 @@
+package permissions
+
+import (
+	"sort"
+
+	"github.com/prebid/go-gdpr/api"
+)
+
+// dataDeclarer is implemented by the vendors of version 3 vendor lists.
+type dataDeclarer interface {
+	DataDeclaration() []int
+}
+
+// DataCategoryReport collects the data categories which the vendors permitted on a request declared in
+// the dataDeclaration of a version 3 vendor list, for privacy reporting. The zero value is an empty
+// report ready to use.
+//
+// A request usually permits different vendors for each purpose, so add the result of EvaluateVendors
+// for each purpose of interest to build the report for the whole request:
+//
+//	var report permissions.DataCategoryReport
+//	for _, purpose := range purposes {
+//		report.Add(gvl, permissions.EvaluateVendors(consent, gvl, purpose))
+//	}
+type DataCategoryReport struct {
+	vendors map[int]*Bitset
+}
+
+// Add records the data categories declared by each permitted vendor. Vendors which aren't in the list,
+// or come from lists older than version 3, declare none.
+func (r *DataCategoryReport) Add(gvl api.VendorList, permitted Bitset) {
+	for _, vendorID := range permitted.VendorIDs() {
+		vendor := gvl.Vendor(vendorID)
+		if vendor == nil {
+			continue
+		}
+		declarer, ok := vendor.(dataDeclarer)
+		if !ok {
+			continue
+		}
+		for _, category := range declarer.DataDeclaration() {
+			if r.vendors == nil {
+				r.vendors = make(map[int]*Bitset)
+			}
+			if r.vendors[category] == nil {
+				r.vendors[category] = &Bitset{}
+			}
+			r.vendors[category].add(vendorID)
+		}
+	}
+}
+
+// Categories returns the data categories which at least one permitted vendor declared, in ascending
+// order. Look up their names with the vendor list's DataCategory.
+func (r *DataCategoryReport) Categories() []int {
+	categories := make([]int, 0, len(r.vendors))
+	for category := range r.vendors {
+		categories = append(categories, category)
+	}
+	sort.Ints(categories)
+	return categories
+}
+
+// Vendors returns the permitted vendors which declared the data category.
+func (r *DataCategoryReport) Vendors(category int) Bitset {
+	if vendors, ok := r.vendors[category]; ok {
+		return *vendors
+	}
+	return Bitset{}
+}
@@ -0,0 +1,51 @@
+package permissions
+
+import (
+	"testing"
+
+	"github.com/prebid/go-gdpr/vendorlist3"
+	"github.com/stretchr/testify/assert"
+)
+
+const testListV3 = `{
+	"gvlSpecificationVersion": 3,
+	"vendorListVersion": 40,
+	"tcfPolicyVersion": 4,
+	"vendors": {
+		"1": {"id": 1, "purposes": [1, 2], "dataDeclaration": [1, 2]},
+		"2": {"id": 2, "purposes": [2], "dataDeclaration": [2, 3]},
+		"3": {"id": 3, "purposes": [2], "dataDeclaration": [4]}
+	}
+}`
+
+func TestDataCategoryReport(t *testing.T) {
+	list, err := vendorlist3.ParseEagerly([]byte(testListV3))
+	assert.NoError(t, err)
+
+	var report DataCategoryReport
+	assert.Empty(t, report.Categories())
+
+	var purpose1, purpose2 Bitset
+	purpose1.add(1)
+	purpose2.add(1)
+	purpose2.add(2)
+	purpose2.add(9)
+	report.Add(list, purpose1)
+	report.Add(list, purpose2)
+
+	assert.Equal(t, []int{1, 2, 3}, report.Categories())
+	assert.Equal(t, []uint16{1}, report.Vendors(1).VendorIDs())
+	assert.Equal(t, []uint16{1, 2}, report.Vendors(2).VendorIDs())
+	assert.Equal(t, []uint16{2}, report.Vendors(3).VendorIDs())
+	assert.Equal(t, 0, report.Vendors(4).Len())
+}
+
+func TestDataCategoryReportV2(t *testing.T) {
+	var permitted Bitset
+	permitted.add(1)
+	permitted.add(2)
+
+	var report DataCategoryReport
+	report.Add(parseTestList(t), permitted)
+	assert.Empty(t, report.Categories())
+}
@@ -0,0 +1,407 @@
+package permissions
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/prebid/go-gdpr/vendorconsent"
+)
+
+// Mode selects how strictly an Evaluator enforces a purpose.
+type Mode uint8
+
+const (
+	// ModeFull enforces the whole TCF, as Evaluate does: the vendor's declarations in the vendor list, the
+	// publisher's restrictions, and the user's choices for both the purpose and the vendor.
+	ModeFull Mode = iota
+	// ModeBasic only checks the user's choice for the purpose, like prebid-server's basic enforcement. The
+	// vendor list, the vendor's consent and legitimate interest bits, and publisher restrictions are
+	// ignored. Legitimate interest is granted if the string discloses it for the purpose, and the purpose
+	// may be based on it.
+	ModeBasic
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeFull:
+		return "full"
+	case ModeBasic:
+		return "basic"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the Mode as "full" or "basic".
+func (m Mode) MarshalText() ([]byte, error) {
+	if m != ModeFull && m != ModeBasic {
+		return nil, fmt.Errorf("unknown enforcement mode %d", m)
+	}
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText decodes "full" or "basic", in any case, so that a Mode can be read from a config file.
+func (m *Mode) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "full":
+		*m = ModeFull
+	case "basic":
+		*m = ModeBasic
+	default:
+		return fmt.Errorf("unknown enforcement mode %q", text)
+	}
+	return nil
+}
+
+// Config configures an Evaluator. It can be decoded from JSON, so that services can keep their policy in
+// a config file:
+//
+//	{
+//	  "purposes": {
+//	    "2": {"mode": "basic"},
+//	    "7": {"ignoreVendors": true, "vendorExceptions": [32], "deniedVendors": [8]}
+//	  },
+//	  "purposeOneTreatment": {"enabled": true, "accessAllowed": true, "countries": ["DE"]},
+//	  "scope": {"includeUK": true, "whenUnknown": "applies"}
+//	}
+type Config struct {
+	// Purposes configures each purpose. Purposes missing from it use the zero PurposeConfig.
+	Purposes map[consentconstants.Purpose]PurposeConfig `json:"purposes"`
+	// SpecialFeatures configures each special feature. Special features missing from it use the zero
+	// SpecialFeatureConfig.
+	SpecialFeatures map[consentconstants.SpecialFeature]SpecialFeatureConfig `json:"specialFeatures"`
+	// PurposeOneTreatment configures purpose 1 for consent strings with PurposeOneTreatment set.
+	PurposeOneTreatment PurposeOneTreatment `json:"purposeOneTreatment"`
+	// Explain makes Evaluator.Explain record why it reached each result.
+	Explain bool `json:"explain"`
+	// Scope configures Evaluator.Scope.
+	Scope ScopeConfig `json:"scope"`
+	// ExpireConsent treats consent strings older than MaxConsentAgeDays as if the user had refused
+	// everything, so that only vendor exceptions are allowed. See vendorconsent.IsExpired.
+	ExpireConsent bool `json:"expireConsent"`
+	// MaxConsentAgeDays is the age at which consent strings expire, in days. Zero means
+	// vendorconsent.DefaultMaxAge.
+	MaxConsentAgeDays int `json:"maxConsentAgeDays"`
+	// MinPolicyVersion treats consent strings with a lower TCFPolicyVersion as if the user had refused
+	// everything, so that only vendor exceptions are allowed. Use 4 to require TCF 2.2. Zero accepts any
+	// string. See vendorconsent.ValidatePolicyVersion to report them instead.
+	MinPolicyVersion uint8 `json:"minPolicyVersion"`
+	// HostVendorID is the GVL ID of the host company, which processes data as the publisher's first
+	// party. If the consent string has a Publisher TC segment, the host's purposes are decided by the
+	// user's choices for the publisher in that segment rather than in the vendor section. Zero means
+	// there is no host vendor.
+	HostVendorID uint16 `json:"hostVendorID"`
+	// DenyLists holds named lists of vendors which are denied every purpose and special feature whatever
+	// the consent string says, such as vendors under regulatory action. They take precedence over all
+	// other settings, and Explain names the list which denied the vendor.
+	DenyLists map[string][]uint16 `json:"denyLists"`
+	// Activities maps each Activity to the purposes it needs. See Evaluator.Activities. If it's nil,
+	// DefaultActivities is used.
+	Activities map[Activity][]consentconstants.Purpose `json:"activities"`
+}
+
+// PurposeOneTreatment configures how an Evaluator handles consent strings with PurposeOneTreatment set.
+// Publishers set it when purpose 1, storing and accessing information on the device, wasn't disclosed
+// to the user because their country's law handles it separately, so the string's purpose 1 consent
+// doesn't apply.
+type PurposeOneTreatment struct {
+	// Enabled honors PurposeOneTreatment. If it's false, purpose 1 is evaluated as usual, which usually
+	// means it's denied, since the string can't hold consent for a purpose the user wasn't shown.
+	Enabled bool `json:"enabled"`
+	// AccessAllowed decides the outcome for purpose 1 when the treatment applies: the
+	// LegalBasisPurposeOneTreatment if it's true, and LegalBasisNone if it's false.
+	AccessAllowed bool `json:"accessAllowed"`
+	// Countries, if not empty, limits the treatment to consent strings whose PublisherCC is one of these
+	// ISO 3166-1 alpha-2 codes, such as "DE". They're matched case-insensitively. Strings from other
+	// countries are evaluated as usual.
+	Countries []string `json:"countries"`
+	// DefaultCountry is the ISO 3166-1 alpha-2 code used in place of the consent string's PublisherCC
+	// when that is missing or "AA", the code CMPs write when they don't know the country. If it's empty,
+	// such strings keep their PublisherCC.
+	DefaultCountry string `json:"defaultCountry"`
+}
+
+// PurposeConfig configures how an Evaluator enforces one purpose.
+type PurposeConfig struct {
+	Mode Mode `json:"mode"`
+	// IgnoreVendors skips the user's choices for the vendor in ModeFull, so that only the purpose needs
+	// the user's consent, or its legitimate interest disclosed. The vendor's declarations and publisher
+	// restrictions still apply. It's the opposite of prebid-server's enforce vendors flag, so that the
+	// zero value enforces vendors. ModeBasic always ignores them.
+	IgnoreVendors bool `json:"ignoreVendors"`
+	// VendorExceptions lists vendors which are allowed the purpose whatever the consent string says.
+	// They get LegalBasisVendorException.
+	VendorExceptions []uint16 `json:"vendorExceptions"`
+	// DeniedVendors lists vendors which are denied the purpose whatever the consent string says. It takes
+	// precedence over VendorExceptions and PurposeOneTreatment.
+	DeniedVendors []uint16 `json:"deniedVendors"`
+}
+
+// SpecialFeatureConfig configures how an Evaluator enforces one special feature.
+type SpecialFeatureConfig struct {
+	// VendorExceptions lists vendors which may use the special feature whatever the consent string says.
+	// They get LegalBasisVendorException.
+	VendorExceptions []uint16 `json:"vendorExceptions"`
+	// DeniedVendors lists vendors which may not use the special feature whatever the consent string
+	// says. It takes precedence over VendorExceptions.
+	DeniedVendors []uint16 `json:"deniedVendors"`
+}
+
+// Evaluator decides legal bases like Evaluate, but enforces each purpose according to its Config. It is
+// safe for concurrent use.
+type Evaluator struct {
+	purposes            map[consentconstants.Purpose]PurposeConfig
+	exceptions          map[consentconstants.Purpose]map[uint16]LegalBasis
+	featureExceptions   map[consentconstants.SpecialFeature]map[uint16]LegalBasis
+	purposeOneTreatment PurposeOneTreatment
+	purposeOneCountries map[string]struct{}
+	explain             bool
+	scopeCountries      map[string]struct{}
+	scopeWhenUnknown    Scope
+	expireConsent       bool
+	maxConsentAge       time.Duration
+	minPolicyVersion    uint8
+	hostVendorID        uint16
+	denyLists           map[uint16]string
+	activities          map[Activity][]consentconstants.Purpose
+
+	fingerprint [sha256.Size]byte
+	cache       *Cache
+	auditHook   func(AuditRecord)
+}
+
+// purposeOneTreated is implemented by TCF 2 consent strings.
+type purposeOneTreated interface {
+	PurposeOneTreatment() bool
+	PublisherCC() string
+}
+
+// publisherTC is implemented by TCF 2 consent strings.
+type publisherTC interface {
+	HasPublisherTC() bool
+	PublisherPurposeConsent(purpose consentconstants.Purpose) bool
+	PublisherPurposeLITransparency(purpose consentconstants.Purpose) bool
+}
+
+// NewEvaluator returns an Evaluator which enforces purposes according to config. The zero Config
+// enforces every purpose in ModeFull.
+func NewEvaluator(config Config) *Evaluator {
+	purposes := make(map[consentconstants.Purpose]PurposeConfig, len(config.Purposes))
+	exceptions := make(map[consentconstants.Purpose]map[uint16]LegalBasis)
+	for purpose, purposeConfig := range config.Purposes {
+		purposes[purpose] = purposeConfig
+		if len(purposeConfig.VendorExceptions) > 0 || len(purposeConfig.DeniedVendors) > 0 {
+			exceptions[purpose] = vendorExceptions(purposeConfig.VendorExceptions, purposeConfig.DeniedVendors)
+		}
+	}
+	featureExceptions := make(map[consentconstants.SpecialFeature]map[uint16]LegalBasis)
+	for feature, featureConfig := range config.SpecialFeatures {
+		if len(featureConfig.VendorExceptions) > 0 || len(featureConfig.DeniedVendors) > 0 {
+			featureExceptions[feature] = vendorExceptions(featureConfig.VendorExceptions, featureConfig.DeniedVendors)
+		}
+	}
+	names := make([]string, 0, len(config.DenyLists))
+	for name := range config.DenyLists {
+		names = append(names, name)
+	}
+	// Vendors on several lists are reported under the first name, so that traces don't vary by run.
+	sort.Strings(names)
+	denyLists := make(map[uint16]string)
+	for _, name := range names {
+		for _, id := range config.DenyLists[name] {
+			if _, ok := denyLists[id]; !ok {
+				denyLists[id] = name
+			}
+		}
+	}
+	countries := make(map[string]struct{}, len(config.PurposeOneTreatment.Countries))
+	for _, country := range config.PurposeOneTreatment.Countries {
+		countries[strings.ToUpper(country)] = struct{}{}
+	}
+	return &Evaluator{
+		purposes:            purposes,
+		exceptions:          exceptions,
+		featureExceptions:   featureExceptions,
+		purposeOneTreatment: config.PurposeOneTreatment,
+		purposeOneCountries: countries,
+		explain:             config.Explain,
+		scopeCountries:      scopeCountries(config.Scope),
+		scopeWhenUnknown:    config.Scope.WhenUnknown,
+		expireConsent:       config.ExpireConsent,
+		maxConsentAge:       time.Duration(config.MaxConsentAgeDays) * 24 * time.Hour,
+		minPolicyVersion:    config.MinPolicyVersion,
+		hostVendorID:        config.HostVendorID,
+		denyLists:           denyLists,
+		activities:          activities(config),
+		fingerprint:         fingerprint(config),
+	}
+}
+
+// vendorExceptions maps the exceptions and denied vendors to the basis they get.
+func vendorExceptions(allowed []uint16, denied []uint16) map[uint16]LegalBasis {
+	exceptions := make(map[uint16]LegalBasis, len(allowed)+len(denied))
+	for _, id := range allowed {
+		exceptions[id] = LegalBasisVendorException
+	}
+	for _, id := range denied {
+		exceptions[id] = LegalBasisNone
+	}
+	return exceptions
+}
+
+// Evaluate returns the legal basis on which the vendor may process data for the purpose, enforcing the
+// purpose in its configured Mode. Vendors on a deny list are always denied. Vendor exceptions and denied
+// vendors come next, then expired consent strings and those below the minimum policy version are denied
+// if the Config says so. The host vendor is then decided by the Publisher TC segment, if the string has
+// one. Purpose 1 then follows the PurposeOneTreatment configuration instead when it applies to the
+// consent string.
+func (e *Evaluator) Evaluate(consent api.VendorConsents, gvl api.VendorList, vendorID uint16, purpose consentconstants.Purpose) LegalBasis {
+	basis, reason := e.evaluate(consent, gvl, vendorID, purpose)
+	e.audit(consent, gvl, vendorID, purpose, basis, reason)
+	return basis
+}
+
+// evaluate works like Evaluate, and also returns the step which decided the result.
+func (e *Evaluator) evaluate(consent api.VendorConsents, gvl api.VendorList, vendorID uint16, purpose consentconstants.Purpose) (LegalBasis, ReasonCode) {
+	if _, ok := e.denyLists[vendorID]; ok {
+		return LegalBasisNone, ReasonDenyList
+	}
+	if basis, ok := e.exceptions[purpose][vendorID]; ok {
+		if basis == LegalBasisNone {
+			return basis, ReasonVendorDenied
+		}
+		return basis, ReasonVendorException
+	}
+	if e.expired(consent) {
+		return LegalBasisNone, ReasonConsentExpired
+	}
+	if consent.TCFPolicyVersion() < e.minPolicyVersion {
+		return LegalBasisNone, ReasonPolicyVersionBelowMinimum
+	}
+	if publisher, ok := e.hostPublisherTC(consent, vendorID); ok {
+		return evaluatePublisherTC(publisher, consent, purpose), ReasonHostVendor
+	}
+	if purpose == 1 && e.purposeOneTreated(consent) {
+		if e.purposeOneTreatment.AccessAllowed {
+			return LegalBasisPurposeOneTreatment, ReasonPurposeOneTreatment
+		}
+		return LegalBasisNone, ReasonPurposeOneTreatment
+	}
+	switch config := e.purposes[purpose]; {
+	case config.Mode == ModeBasic:
+		return evaluateBasic(consent, purpose), ReasonBasicEnforcement
+	case config.IgnoreVendors:
+		return evaluateIgnoringVendor(consent, gvl, vendorID, purpose), ReasonVendorsIgnored
+	default:
+		return Evaluate(consent, gvl, vendorID, purpose), ReasonFullEnforcement
+	}
+}
+
+// EvaluateSpecialFeature returns the legal basis on which the vendor may use the special feature, like
+// the package's EvaluateSpecialFeature, after applying the deny lists and the special feature's vendor
+// exceptions and denied vendors. Expired consent strings and those below the minimum policy version are denied if the Config
+// says so, as they are by Evaluate.
+func (e *Evaluator) EvaluateSpecialFeature(consent api.VendorConsents, gvl api.VendorList, vendorID uint16, feature consentconstants.SpecialFeature) LegalBasis {
+	if _, ok := e.denyLists[vendorID]; ok {
+		return LegalBasisNone
+	}
+	if basis, ok := e.featureExceptions[feature][vendorID]; ok {
+		return basis
+	}
+	if e.expired(consent) || consent.TCFPolicyVersion() < e.minPolicyVersion {
+		return LegalBasisNone
+	}
+	return EvaluateSpecialFeature(consent, gvl, vendorID, feature)
+}
+
+// expired returns true if the consent string should be treated as expired.
+func (e *Evaluator) expired(consent api.VendorConsents) bool {
+	return e.expireConsent && vendorconsent.IsExpired(consent, e.maxConsentAge)
+}
+
+// purposeOneTreated returns true if the PurposeOneTreatment configuration applies to the consent string.
+func (e *Evaluator) purposeOneTreated(consent api.VendorConsents) bool {
+	if !e.purposeOneTreatment.Enabled {
+		return false
+	}
+	treated, ok := consent.(purposeOneTreated)
+	if !ok || !treated.PurposeOneTreatment() {
+		return false
+	}
+	if len(e.purposeOneCountries) == 0 {
+		return true
+	}
+	_, ok = e.purposeOneCountries[e.publisherCC(treated)]
+	return ok
+}
+
+// publisherCC returns the consent string's PublisherCC, or the configured default if it's unknown.
+func (e *Evaluator) publisherCC(consent purposeOneTreated) string {
+	country := strings.ToUpper(consent.PublisherCC())
+	if (country == "" || country == "AA") && e.purposeOneTreatment.DefaultCountry != "" {
+		return strings.ToUpper(e.purposeOneTreatment.DefaultCountry)
+	}
+	return country
+}
+
+// hostPublisherTC returns the consent string's Publisher TC segment if the vendor is the host vendor
+// and the string has one.
+func (e *Evaluator) hostPublisherTC(consent api.VendorConsents, vendorID uint16) (publisherTC, bool) {
+	if e.hostVendorID == 0 || vendorID != e.hostVendorID {
+		return nil, false
+	}
+	publisher, ok := consent.(publisherTC)
+	if !ok || !publisher.HasPublisherTC() {
+		return nil, false
+	}
+	return publisher, true
+}
+
+// evaluatePublisherTC returns the legal basis the Publisher TC segment gives the publisher for the
+// purpose. Legitimate interest is subject to the same limits as for vendors.
+func evaluatePublisherTC(publisher publisherTC, consent api.VendorConsents, purpose consentconstants.Purpose) LegalBasis {
+	if publisher.PublisherPurposeConsent(purpose) {
+		return LegalBasisConsent
+	}
+	if publisher.PublisherPurposeLITransparency(purpose) && legitInterestAllowed(consent, purpose) {
+		return LegalBasisLegitimateInterest
+	}
+	return LegalBasisNone
+}
+
+// evaluateIgnoringVendor works like Evaluate, but doesn't check the user's choices for the vendor.
+func evaluateIgnoringVendor(consent api.VendorConsents, gvl api.VendorList, vendorID uint16, purpose consentconstants.Purpose) LegalBasis {
+	if _, ok := listedVendor(consent, gvl, vendorID); !ok {
+		return LegalBasisNone
+	}
+	switch ResolveBasis(consent, gvl, vendorID, purpose) {
+	case LegalBasisConsent:
+		if consent.PurposeAllowed(purpose) {
+			return LegalBasisConsent
+		}
+	case LegalBasisLegitimateInterest:
+		if legitInterestDisclosed(consent, purpose) {
+			return LegalBasisLegitimateInterest
+		}
+	}
+	return LegalBasisNone
+}
+
+// evaluateBasic returns the legal basis the consent string gives the purpose, whatever the vendor.
+func evaluateBasic(consent api.VendorConsents, purpose consentconstants.Purpose) LegalBasis {
+	if purpose == 0 {
+		return LegalBasisNone
+	}
+	if consent.PurposeAllowed(purpose) {
+		return LegalBasisConsent
+	}
+	if legitInterestDisclosed(consent, purpose) {
+		return LegalBasisLegitimateInterest
+	}
+	return LegalBasisNone
+}
@@ -0,0 +1,413 @@
+package permissions
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluatorModes(t *testing.T) {
+	list := parseTestList(t)
+	evaluator := NewEvaluator(Config{
+		Purposes: map[consentconstants.Purpose]PurposeConfig{
+			2: {Mode: ModeBasic},
+			4: {Mode: ModeBasic},
+			7: {Mode: ModeBasic},
+			3: {Mode: ModeFull},
+		},
+	})
+
+	tests := []struct {
+		name     string
+		consent  api.VendorConsents
+		vendorID uint16
+		purpose  consentconstants.Purpose
+		expected LegalBasis
+	}{
+		{name: "basic_consent", consent: consent(nil), vendorID: 1, purpose: 2, expected: LegalBasisConsent},
+		{name: "basic_ignores_vendor_list", consent: consent(nil), vendorID: 7, purpose: 2, expected: LegalBasisConsent},
+		{
+			name:     "basic_ignores_vendor_consent",
+			consent:  consent(func(c *fakeConsent) { c.vendors = nil }),
+			vendorID: 1, purpose: 2, expected: LegalBasisConsent,
+		},
+		{
+			name:     "basic_ignores_restrictions",
+			consent:  consent(restrict(2, restrictNotAllowed, 1)),
+			vendorID: 1, purpose: 2, expected: LegalBasisConsent,
+		},
+		{
+			name:     "basic_legitimate_interest",
+			consent:  consent(func(c *fakeConsent) { c.purposes = nil }),
+			vendorID: 1, purpose: 7, expected: LegalBasisLegitimateInterest,
+		},
+		{
+			name: "basic_legitimate_interest_not_allowed",
+			consent: consent(func(c *fakeConsent) {
+				c.purposes = nil
+				c.policyVersion = 4
+			}),
+			vendorID: 1, purpose: 4, expected: LegalBasisNone,
+		},
+		{
+			name: "basic_refused",
+			consent: consent(func(c *fakeConsent) {
+				c.purposes, c.liTransparency = nil, nil
+			}),
+			vendorID: 1, purpose: 2, expected: LegalBasisNone,
+		},
+		{name: "full", consent: consent(nil), vendorID: 7, purpose: 3, expected: LegalBasisNone},
+		{name: "full_by_default", consent: consent(nil), vendorID: 7, purpose: 1, expected: LegalBasisNone},
+		{name: "full_by_default_allowed", consent: consent(nil), vendorID: 1, purpose: 3, expected: LegalBasisConsent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, evaluator.Evaluate(tt.consent, list, tt.vendorID, tt.purpose))
+		})
+	}
+}
+
+func TestEvaluatorIgnoreVendors(t *testing.T) {
+	list := parseTestList(t)
+	evaluator := NewEvaluator(Config{
+		Purposes: map[consentconstants.Purpose]PurposeConfig{
+			2: {IgnoreVendors: true},
+			7: {IgnoreVendors: true},
+		},
+	})
+	refusedVendors := func(c *fakeConsent) { c.vendors, c.legitInterests = nil, nil }
+
+	tests := []struct {
+		name     string
+		consent  api.VendorConsents
+		vendorID uint16
+		purpose  consentconstants.Purpose
+		expected LegalBasis
+	}{
+		{name: "consent", consent: consent(refusedVendors), vendorID: 1, purpose: 2, expected: LegalBasisConsent},
+		{name: "legitimate_interest", consent: consent(refusedVendors), vendorID: 1, purpose: 7, expected: LegalBasisLegitimateInterest},
+		{
+			name: "purpose_refused",
+			consent: consent(func(c *fakeConsent) {
+				refusedVendors(c)
+				c.purposes = nil
+			}),
+			vendorID: 1, purpose: 2, expected: LegalBasisNone,
+		},
+		{name: "undeclared", consent: consent(refusedVendors), vendorID: 4, purpose: 2, expected: LegalBasisNone},
+		{name: "deleted", consent: consent(refusedVendors), vendorID: 3, purpose: 2, expected: LegalBasisNone},
+		{
+			name: "restricted",
+			consent: consent(func(c *fakeConsent) {
+				refusedVendors(c)
+				restrict(2, restrictNotAllowed, 1)(c)
+			}),
+			vendorID: 1, purpose: 2, expected: LegalBasisNone,
+		},
+		{name: "other_purpose_enforced", consent: consent(refusedVendors), vendorID: 1, purpose: 3, expected: LegalBasisNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, evaluator.Evaluate(tt.consent, list, tt.vendorID, tt.purpose))
+		})
+	}
+}
+
+func TestEvaluatorVendorExceptions(t *testing.T) {
+	list := parseTestList(t)
+	evaluator := NewEvaluator(Config{
+		Purposes: map[consentconstants.Purpose]PurposeConfig{
+			1: {VendorExceptions: []uint16{7}},
+			2: {Mode: ModeBasic, VendorExceptions: []uint16{1, 32}},
+		},
+		PurposeOneTreatment: PurposeOneTreatment{Enabled: true},
+	})
+	refused := consent(func(c *fakeConsent) {
+		c.purposes, c.liTransparency, c.vendors, c.legitInterests = nil, nil, nil, nil
+		c.purposeOneTreatment = true
+	})
+
+	assert.Equal(t, LegalBasisVendorException, evaluator.Evaluate(refused, list, 32, 2))
+	assert.Equal(t, LegalBasisVendorException, evaluator.Evaluate(refused, list, 1, 2))
+	assert.Equal(t, LegalBasisVendorException, evaluator.Evaluate(refused, list, 7, 1))
+	assert.Equal(t, LegalBasisNone, evaluator.Evaluate(refused, list, 2, 2))
+	assert.Equal(t, LegalBasisNone, evaluator.Evaluate(refused, list, 32, 1))
+}
+
+func TestEvaluatorDeniedVendors(t *testing.T) {
+	list := parseTestList(t)
+	evaluator := NewEvaluator(Config{
+		Purposes: map[consentconstants.Purpose]PurposeConfig{
+			1: {DeniedVendors: []uint16{1}},
+			2: {Mode: ModeBasic, DeniedVendors: []uint16{2}},
+			7: {VendorExceptions: []uint16{1, 32}, DeniedVendors: []uint16{32}},
+		},
+		PurposeOneTreatment: PurposeOneTreatment{Enabled: true, AccessAllowed: true},
+	})
+	treated := consent(func(c *fakeConsent) { c.purposeOneTreatment = true })
+
+	assert.Equal(t, LegalBasisNone, evaluator.Evaluate(consent(nil), list, 2, 2))
+	assert.Equal(t, LegalBasisConsent, evaluator.Evaluate(consent(nil), list, 1, 2))
+	assert.Equal(t, LegalBasisNone, evaluator.Evaluate(treated, list, 1, 1))
+	assert.Equal(t, LegalBasisPurposeOneTreatment, evaluator.Evaluate(treated, list, 2, 1))
+	assert.Equal(t, LegalBasisNone, evaluator.Evaluate(consent(nil), list, 32, 7))
+	assert.Equal(t, LegalBasisVendorException, evaluator.Evaluate(consent(nil), list, 1, 7))
+}
+
+func TestEvaluatorExpireConsent(t *testing.T) {
+	list := parseTestList(t)
+	// fakeConsent was created on 2023-01-01.
+	expiring := NewEvaluator(Config{
+		Purposes:      map[consentconstants.Purpose]PurposeConfig{2: {VendorExceptions: []uint16{2}}},
+		ExpireConsent: true,
+	})
+	assert.Equal(t, LegalBasisNone, expiring.Evaluate(consent(nil), list, 1, 2))
+	assert.Equal(t, LegalBasisVendorException, expiring.Evaluate(consent(nil), list, 2, 2))
+
+	lenient := NewEvaluator(Config{ExpireConsent: true, MaxConsentAgeDays: 36500})
+	assert.Equal(t, LegalBasisConsent, lenient.Evaluate(consent(nil), list, 1, 2))
+
+	assert.Equal(t, LegalBasisConsent, NewEvaluator(Config{}).Evaluate(consent(nil), list, 1, 2))
+}
+
+func TestEvaluatorMinPolicyVersion(t *testing.T) {
+	list := parseTestList(t)
+	evaluator := NewEvaluator(Config{
+		Purposes:         map[consentconstants.Purpose]PurposeConfig{2: {VendorExceptions: []uint16{2}}},
+		MinPolicyVersion: 4,
+	})
+	policy4 := consent(func(c *fakeConsent) { c.policyVersion = 4 })
+
+	assert.Equal(t, LegalBasisNone, evaluator.Evaluate(consent(nil), list, 1, 2))
+	assert.Equal(t, LegalBasisVendorException, evaluator.Evaluate(consent(nil), list, 2, 2))
+	assert.Equal(t, LegalBasisConsent, evaluator.Evaluate(policy4, list, 1, 2))
+}
+
+func TestEvaluatorHostVendor(t *testing.T) {
+	list := parseTestList(t)
+	evaluator := NewEvaluator(Config{HostVendorID: 4})
+	publisher := func(c *fakeConsent) {
+		c.publisherPurposes = map[consentconstants.Purpose]bool{1: true}
+		c.publisherLITransparency = map[consentconstants.Purpose]bool{2: true, 4: true}
+	}
+	withPublisherTC := consent(publisher)
+	policy4 := consent(func(c *fakeConsent) {
+		publisher(c)
+		c.policyVersion = 4
+	})
+
+	assert.Equal(t, LegalBasisConsent, evaluator.Evaluate(withPublisherTC, list, 4, 1))
+	assert.Equal(t, LegalBasisLegitimateInterest, evaluator.Evaluate(withPublisherTC, list, 4, 2))
+	assert.Equal(t, LegalBasisNone, evaluator.Evaluate(withPublisherTC, list, 4, 3))
+	assert.Equal(t, LegalBasisLegitimateInterest, evaluator.Evaluate(withPublisherTC, list, 4, 4))
+	assert.Equal(t, LegalBasisNone, evaluator.Evaluate(policy4, list, 4, 4))
+
+	// Other vendors, and the host without a Publisher TC segment, use the vendor section.
+	assert.Equal(t, LegalBasisConsent, evaluator.Evaluate(withPublisherTC, list, 1, 2))
+	assert.Equal(t, LegalBasisNone, evaluator.Evaluate(consent(nil), list, 4, 2))
+	assert.Equal(t, LegalBasisNone, NewEvaluator(Config{}).Evaluate(withPublisherTC, list, 4, 2))
+}
+
+func TestEvaluatorDenyLists(t *testing.T) {
+	list := parseTestList(t)
+	evaluator := NewEvaluator(Config{
+		Purposes: map[consentconstants.Purpose]PurposeConfig{2: {VendorExceptions: []uint16{2}}},
+		SpecialFeatures: map[consentconstants.SpecialFeature]SpecialFeatureConfig{
+			1: {VendorExceptions: []uint16{2}},
+		},
+		DenyLists: map[string][]uint16{"regulatory": {2}, "internal": {1}},
+	})
+
+	assert.Equal(t, LegalBasisNone, evaluator.Evaluate(consent(nil), list, 1, 2))
+	assert.Equal(t, LegalBasisNone, evaluator.Evaluate(consent(nil), list, 2, 2))
+	assert.Equal(t, LegalBasisNone, evaluator.EvaluateSpecialFeature(consent(nil), list, 2, 1))
+	assert.Equal(t, LegalBasisLegitimateInterest, evaluator.Evaluate(consent(nil), list, 4, 4))
+}
+
+func TestEvaluatorSpecialFeature(t *testing.T) {
+	list := parseTestList(t)
+	evaluator := NewEvaluator(Config{
+		SpecialFeatures: map[consentconstants.SpecialFeature]SpecialFeatureConfig{
+			1: {VendorExceptions: []uint16{1}, DeniedVendors: []uint16{3}},
+		},
+		MinPolicyVersion: 2,
+	})
+	optedOut := consent(func(c *fakeConsent) { c.specialFeatures = nil })
+
+	assert.Equal(t, LegalBasisConsent, evaluator.EvaluateSpecialFeature(consent(nil), list, 2, 1))
+	assert.Equal(t, LegalBasisNone, evaluator.EvaluateSpecialFeature(optedOut, list, 2, 1))
+	assert.Equal(t, LegalBasisVendorException, evaluator.EvaluateSpecialFeature(optedOut, list, 1, 1))
+	assert.Equal(t, LegalBasisNone, evaluator.EvaluateSpecialFeature(consent(nil), list, 3, 1))
+	assert.Equal(t, LegalBasisNone, evaluator.EvaluateSpecialFeature(consent(nil), list, 1, 2))
+
+	strict := NewEvaluator(Config{MinPolicyVersion: 4})
+	assert.Equal(t, LegalBasisNone, strict.EvaluateSpecialFeature(consent(nil), list, 2, 1))
+}
+
+func TestConfigJSON(t *testing.T) {
+	var config Config
+	err := json.Unmarshal([]byte(`{
+		"purposes": {
+			"2": {"mode": "basic"},
+			"3": {"mode": "FULL"},
+			"7": {"ignoreVendors": true, "vendorExceptions": [32, 33], "deniedVendors": [1]}
+		},
+		"specialFeatures": {"1": {"vendorExceptions": [5]}},
+		"purposeOneTreatment": {"enabled": true, "accessAllowed": true, "countries": ["DE"]},
+		"denyLists": {"regulatory": [8, 9]}
+	}`), &config)
+	assert.NoError(t, err)
+	assert.Equal(t, Config{
+		Purposes: map[consentconstants.Purpose]PurposeConfig{
+			2: {Mode: ModeBasic},
+			3: {Mode: ModeFull},
+			7: {IgnoreVendors: true, VendorExceptions: []uint16{32, 33}, DeniedVendors: []uint16{1}},
+		},
+		SpecialFeatures:     map[consentconstants.SpecialFeature]SpecialFeatureConfig{1: {VendorExceptions: []uint16{5}}},
+		PurposeOneTreatment: PurposeOneTreatment{Enabled: true, AccessAllowed: true, Countries: []string{"DE"}},
+		DenyLists:           map[string][]uint16{"regulatory": {8, 9}},
+	}, config)
+
+	data, err := json.Marshal(Config{Purposes: map[consentconstants.Purpose]PurposeConfig{2: {Mode: ModeBasic}}})
+	assert.NoError(t, err)
+	var decoded Config
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, ModeBasic, decoded.Purposes[2].Mode)
+}
+
+func TestModeText(t *testing.T) {
+	assert.Equal(t, "full", ModeFull.String())
+	assert.Equal(t, "basic", ModeBasic.String())
+	assert.Equal(t, "unknown", Mode(7).String())
+
+	_, err := Mode(7).MarshalText()
+	assert.Error(t, err)
+
+	var mode Mode
+	assert.NoError(t, mode.UnmarshalText([]byte("Basic")))
+	assert.Equal(t, ModeBasic, mode)
+	assert.EqualError(t, mode.UnmarshalText([]byte("strict")), `unknown enforcement mode "strict"`)
+}
+
+func TestNewEvaluatorCopiesConfig(t *testing.T) {
+	config := Config{Purposes: map[consentconstants.Purpose]PurposeConfig{2: {Mode: ModeBasic}}}
+	evaluator := NewEvaluator(config)
+	config.Purposes[2] = PurposeConfig{Mode: ModeFull}
+
+	assert.Equal(t, LegalBasisConsent, evaluator.Evaluate(consent(nil), parseTestList(t), 7, 2))
+}
+
+func TestEvaluatorPurposeOneTreatment(t *testing.T) {
+	list := parseTestList(t)
+	treated := func(country string) func(*fakeConsent) {
+		return func(c *fakeConsent) {
+			c.purposes, c.vendors = nil, nil
+			c.purposeOneTreatment = true
+			c.publisherCC = country
+		}
+	}
+
+	tests := []struct {
+		name     string
+		config   PurposeOneTreatment
+		consent  api.VendorConsents
+		purpose  consentconstants.Purpose
+		expected LegalBasis
+	}{
+		{
+			name:     "disabled",
+			consent:  consent(treated("DE")),
+			purpose:  1,
+			expected: LegalBasisNone,
+		},
+		{
+			name:     "allowed",
+			config:   PurposeOneTreatment{Enabled: true, AccessAllowed: true},
+			consent:  consent(treated("DE")),
+			purpose:  1,
+			expected: LegalBasisPurposeOneTreatment,
+		},
+		{
+			name:     "denied",
+			config:   PurposeOneTreatment{Enabled: true},
+			consent:  consent(func(c *fakeConsent) { c.purposeOneTreatment = true }),
+			purpose:  1,
+			expected: LegalBasisNone,
+		},
+		{
+			name:     "not_treated",
+			config:   PurposeOneTreatment{Enabled: true},
+			consent:  consent(nil),
+			purpose:  1,
+			expected: LegalBasisConsent,
+		},
+		{
+			name:     "other_purpose",
+			config:   PurposeOneTreatment{Enabled: true, AccessAllowed: true},
+			consent:  consent(treated("DE")),
+			purpose:  2,
+			expected: LegalBasisNone,
+		},
+		{
+			name:     "country_listed",
+			config:   PurposeOneTreatment{Enabled: true, AccessAllowed: true, Countries: []string{"fr", "de"}},
+			consent:  consent(treated("DE")),
+			purpose:  1,
+			expected: LegalBasisPurposeOneTreatment,
+		},
+		{
+			name:     "country_not_listed",
+			config:   PurposeOneTreatment{Enabled: true, AccessAllowed: true, Countries: []string{"FR"}},
+			consent:  consent(treated("DE")),
+			purpose:  1,
+			expected: LegalBasisNone,
+		},
+		{
+			name:     "default_country",
+			config:   PurposeOneTreatment{Enabled: true, AccessAllowed: true, Countries: []string{"DE"}, DefaultCountry: "de"},
+			consent:  consent(treated("AA")),
+			purpose:  1,
+			expected: LegalBasisPurposeOneTreatment,
+		},
+		{
+			name:     "default_country_missing",
+			config:   PurposeOneTreatment{Enabled: true, AccessAllowed: true, Countries: []string{"DE"}, DefaultCountry: "DE"},
+			consent:  consent(treated("")),
+			purpose:  1,
+			expected: LegalBasisPurposeOneTreatment,
+		},
+		{
+			name:     "default_country_not_listed",
+			config:   PurposeOneTreatment{Enabled: true, AccessAllowed: true, Countries: []string{"DE"}, DefaultCountry: "FR"},
+			consent:  consent(treated("AA")),
+			purpose:  1,
+			expected: LegalBasisNone,
+		},
+		{
+			name:     "default_country_unused",
+			config:   PurposeOneTreatment{Enabled: true, AccessAllowed: true, Countries: []string{"DE"}, DefaultCountry: "DE"},
+			consent:  consent(treated("FR")),
+			purpose:  1,
+			expected: LegalBasisNone,
+		},
+		{
+			name:     "unsupported_consent",
+			config:   PurposeOneTreatment{Enabled: true, AccessAllowed: true},
+			consent:  struct{ api.VendorConsents }{consent(treated("DE"))},
+			purpose:  1,
+			expected: LegalBasisNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluator := NewEvaluator(Config{PurposeOneTreatment: tt.config})
+			assert.Equal(t, tt.expected, evaluator.Evaluate(tt.consent, list, 1, tt.purpose))
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package permissions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/prebid/go-gdpr/vendorconsent"
+)
+
+// Explanation is the result of an evaluation, with the reasons for it.
+type Explanation struct {
+	Basis LegalBasis
+	// Trace lists the steps of the evaluation, in order. The last one gives the outcome and its reason,
+	// such as "vendor 32 denied purpose 4: publisher restriction type 0". It's nil unless the Evaluator's
+	// Config has Explain set.
+	Trace []string
+}
+
+func (x Explanation) String() string {
+	return strings.Join(x.Trace, "; ")
+}
+
+// Explain works like Evaluate, but also returns why, for logging or to answer publishers' questions.
+// Tracing is slower than Evaluate, so it only happens if the Config has Explain set. Otherwise Explain
+// returns the same basis as Evaluate with an empty trace, so calls can stay in place while it's off.
+func (e *Evaluator) Explain(consent api.VendorConsents, gvl api.VendorList, vendorID uint16, purpose consentconstants.Purpose) Explanation {
+	if !e.explain {
+		basis, _ := e.evaluate(consent, gvl, vendorID, purpose)
+		return Explanation{Basis: basis}
+	}
+	t := &tracer{vendorID: vendorID, purpose: purpose}
+	basis := e.explainEvaluate(consent, gvl, t)
+	return Explanation{Basis: basis, Trace: t.trace}
+}
+
+// tracer records the steps of one evaluation.
+type tracer struct {
+	vendorID uint16
+	purpose  consentconstants.Purpose
+	trace    []string
+}
+
+func (t *tracer) note(format string, args ...interface{}) {
+	t.trace = append(t.trace, fmt.Sprintf(format, args...))
+}
+
+func (t *tracer) allow(basis LegalBasis, format string, args ...interface{}) LegalBasis {
+	t.note("vendor %d allowed purpose %d on the basis of %s: %s", t.vendorID, t.purpose, basis, fmt.Sprintf(format, args...))
+	return basis
+}
+
+func (t *tracer) deny(format string, args ...interface{}) LegalBasis {
+	t.note("vendor %d denied purpose %d: %s", t.vendorID, t.purpose, fmt.Sprintf(format, args...))
+	return LegalBasisNone
+}
+
+// explainEvaluate follows the same steps as Evaluate, recording them.
+func (e *Evaluator) explainEvaluate(consent api.VendorConsents, gvl api.VendorList, t *tracer) LegalBasis {
+	if name, ok := e.denyLists[t.vendorID]; ok {
+		return t.deny("the vendor is on the %q deny list", name)
+	}
+	if basis, ok := e.exceptions[t.purpose][t.vendorID]; ok {
+		if basis == LegalBasisNone {
+			return t.deny("the config denies the vendor")
+		}
+		return t.allow(basis, "the config lists the vendor as an exception")
+	}
+	if e.expired(consent) {
+		return t.deny("the consent string expired, since it was last changed on %s", vendorconsent.LastChanged(consent).Format("2006-01-02"))
+	}
+	if consent.TCFPolicyVersion() < e.minPolicyVersion {
+		return t.deny("the consent string has TCF policy version %d, below the minimum of %d", consent.TCFPolicyVersion(), e.minPolicyVersion)
+	}
+	if publisher, ok := e.hostPublisherTC(consent, t.vendorID); ok {
+		t.note("the vendor is the host vendor, so the publisher TC segment applies")
+		return explainPublisherTC(publisher, consent, t)
+	}
+	if t.purpose == 1 && e.purposeOneTreated(consent) {
+		country := e.publisherCC(consent.(purposeOneTreated))
+		if e.purposeOneTreatment.AccessAllowed {
+			return t.allow(LegalBasisPurposeOneTreatment, "purpose one treatment applies to publisher country %s", country)
+		}
+		return t.deny("purpose one treatment applies to publisher country %s, and the config doesn't allow access", country)
+	}
+	config := e.purposes[t.purpose]
+	t.note("%s enforcement", config.Mode)
+	if config.Mode == ModeBasic {
+		return explainBasic(consent, t)
+	}
+	return explainFull(consent, gvl, !config.IgnoreVendors, t)
+}
+
+// explainPublisherTC mirrors evaluatePublisherTC.
+func explainPublisherTC(publisher publisherTC, consent api.VendorConsents, t *tracer) LegalBasis {
+	if publisher.PublisherPurposeConsent(t.purpose) {
+		return t.allow(LegalBasisConsent, "the user consented to the publisher's purpose")
+	}
+	if !publisher.PublisherPurposeLITransparency(t.purpose) {
+		return t.deny("the user didn't consent to the publisher's purpose, and no legitimate interest was disclosed for it")
+	}
+	if !legitInterestAllowed(consent, t.purpose) {
+		return t.deny("TCF policy version %d doesn't allow legitimate interest for the purpose", consent.TCFPolicyVersion())
+	}
+	return t.allow(LegalBasisLegitimateInterest, "the publisher's legitimate interest for the purpose was disclosed to the user")
+}
+
+// explainBasic follows the same steps as evaluateBasic, recording them.
+func explainBasic(consent api.VendorConsents, t *tracer) LegalBasis {
+	switch {
+	case t.purpose == 0:
+		return t.deny("there is no purpose 0")
+	case consent.PurposeAllowed(t.purpose):
+		return t.allow(LegalBasisConsent, "the user consented to the purpose")
+	case legitInterestDisclosed(consent, t.purpose):
+		return t.allow(LegalBasisLegitimateInterest, "the purpose's legitimate interest was disclosed to the user")
+	default:
+		return t.deny("the user didn't consent to the purpose, and no legitimate interest was disclosed for it")
+	}
+}
+
+// explainFull follows the same steps as Evaluate, or evaluateIgnoringVendor if enforceVendor is false,
+// recording them.
+func explainFull(consent api.VendorConsents, gvl api.VendorList, enforceVendor bool, t *tracer) LegalBasis {
+	if t.purpose == 0 {
+		return t.deny("there is no purpose 0")
+	}
+	vendor := gvl.Vendor(t.vendorID)
+	if vendor == nil {
+		return t.deny("the vendor isn't in vendor list version %d", gvl.Version())
+	}
+	if deletion, ok := vendor.(api.VendorDeletion); ok {
+		if deletedDate, deleted := deletion.DeletedDate(); deleted && !consent.Created().Before(deletedDate) {
+			return t.deny("the vendor was deleted from the vendor list on %s, before the consent string was created", deletedDate.Format("2006-01-02"))
+		}
+	}
+
+	basis := declaredBasis(vendor, t.purpose)
+	if basis != LegalBasisNone {
+		t.note("vendor %d declared %s for purpose %d", t.vendorID, basis, t.purpose)
+	}
+	if restrictions, ok := consent.(pubRestrictionChecker); ok {
+		purposeID := uint8(t.purpose)
+		switch {
+		case restrictions.CheckPubRestriction(purposeID, restrictNotAllowed, t.vendorID):
+			return t.deny("publisher restriction type %d", restrictNotAllowed)
+		case !flexiblePurpose(vendor, t.purpose):
+		case restrictions.CheckPubRestriction(purposeID, restrictRequireConsent, t.vendorID):
+			basis = LegalBasisConsent
+			t.note("publisher restriction type %d requires consent", restrictRequireConsent)
+		case restrictions.CheckPubRestriction(purposeID, restrictRequireLegitInterest, t.vendorID):
+			basis = LegalBasisLegitimateInterest
+			t.note("publisher restriction type %d requires legitimate interest", restrictRequireLegitInterest)
+		}
+	}
+
+	switch basis {
+	case LegalBasisConsent:
+		if !consent.PurposeAllowed(t.purpose) {
+			return t.deny("the user didn't consent to the purpose")
+		}
+		if enforceVendor && !consent.VendorConsent(t.vendorID) {
+			return t.deny("the user didn't consent to the vendor")
+		}
+		return t.allow(LegalBasisConsent, "the user consented to the purpose")
+	case LegalBasisLegitimateInterest:
+		if !legitInterestAllowed(consent, t.purpose) {
+			return t.deny("TCF policy version %d doesn't allow legitimate interest for the purpose", consent.TCFPolicyVersion())
+		}
+		if !legitInterestDisclosed(consent, t.purpose) {
+			return t.deny("the purpose's legitimate interest wasn't disclosed to the user")
+		}
+		if enforceVendor {
+			if li, ok := consent.(vendorLegitInterests); !ok || !li.VendorLegitInterest(t.vendorID) {
+				return t.deny("the user objected to the vendor's legitimate interest")
+			}
+		}
+		return t.allow(LegalBasisLegitimateInterest, "the purpose's legitimate interest was disclosed to the user")
+	default:
+		return t.deny("the vendor didn't declare the purpose in vendor list version %d", gvl.Version())
+	}
+}
@@ -0,0 +1,219 @@
+package permissions
+
+import (
+	"testing"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainMatchesEvaluate(t *testing.T) {
+	list := parseTestList(t)
+	configs := map[string]Config{
+		"full":  {},
+		"basic": {Purposes: map[consentconstants.Purpose]PurposeConfig{2: {Mode: ModeBasic}, 7: {Mode: ModeBasic}}},
+		"ignore_vendors": {
+			Purposes: map[consentconstants.Purpose]PurposeConfig{2: {IgnoreVendors: true}, 7: {IgnoreVendors: true}},
+		},
+		"exceptions": {
+			Purposes: map[consentconstants.Purpose]PurposeConfig{
+				1: {DeniedVendors: []uint16{2}},
+				2: {VendorExceptions: []uint16{3, 7}, DeniedVendors: []uint16{1}},
+			},
+			PurposeOneTreatment: PurposeOneTreatment{Enabled: true, AccessAllowed: true, Countries: []string{"DE"}},
+		},
+		"expired": {
+			Purposes:      map[consentconstants.Purpose]PurposeConfig{2: {VendorExceptions: []uint16{1}}},
+			ExpireConsent: true,
+		},
+		"not_expired":        {ExpireConsent: true, MaxConsentAgeDays: 36500},
+		"min_policy_version": {MinPolicyVersion: 4},
+		"host_vendor":        {HostVendorID: 4},
+		"deny_lists":         {HostVendorID: 4, DenyLists: map[string][]uint16{"regulatory": {1, 4}}},
+		"default_country": {
+			PurposeOneTreatment: PurposeOneTreatment{Enabled: true, Countries: []string{"DE"}, DefaultCountry: "DE"},
+		},
+	}
+	consents := map[string]api.VendorConsents{
+		"allowed":        consent(nil),
+		"policy_4":       consent(func(c *fakeConsent) { c.policyVersion = 4 }),
+		"purposes":       consent(func(c *fakeConsent) { c.purposes = nil }),
+		"transparency":   consent(func(c *fakeConsent) { c.liTransparency = nil }),
+		"vendors":        consent(func(c *fakeConsent) { c.vendors, c.legitInterests = nil, nil }),
+		"require_li":     consent(restrict(2, restrictRequireLegitInterest, 2)),
+		"require_cs":     consent(restrict(7, restrictRequireConsent, 2)),
+		"not_allowed":    consent(restrict(7, restrictNotAllowed, 1)),
+		"purpose_one_de": consent(func(c *fakeConsent) { c.purposeOneTreatment, c.publisherCC = true, "DE" }),
+		"purpose_one_fr": consent(func(c *fakeConsent) { c.purposeOneTreatment, c.publisherCC = true, "FR" }),
+		"purpose_one_aa": consent(func(c *fakeConsent) { c.purposeOneTreatment, c.publisherCC = true, "AA" }),
+		"publisher_tc": consent(func(c *fakeConsent) {
+			c.publisherPurposes = map[consentconstants.Purpose]bool{1: true}
+			c.publisherLITransparency = map[consentconstants.Purpose]bool{2: true, 4: true}
+		}),
+	}
+
+	for configName, config := range configs {
+		config.Explain = true
+		evaluator := NewEvaluator(config)
+		for consentName, c := range consents {
+			for purpose := consentconstants.Purpose(0); purpose <= 10; purpose++ {
+				for vendorID := uint16(0); vendorID <= 8; vendorID++ {
+					explanation := evaluator.Explain(c, list, vendorID, purpose)
+					assert.Equal(t, evaluator.Evaluate(c, list, vendorID, purpose), explanation.Basis,
+						"config %s, consent %s, vendor %d, purpose %d: %s", configName, consentName, vendorID, purpose, explanation)
+					assert.NotEmpty(t, explanation.Trace)
+				}
+			}
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	list := parseTestList(t)
+	evaluator := NewEvaluator(Config{
+		Purposes: map[consentconstants.Purpose]PurposeConfig{
+			2: {VendorExceptions: []uint16{32}, DeniedVendors: []uint16{1}},
+			3: {Mode: ModeBasic},
+		},
+		Explain: true,
+	})
+
+	tests := []struct {
+		name     string
+		consent  api.VendorConsents
+		vendorID uint16
+		purpose  consentconstants.Purpose
+		expected Explanation
+	}{
+		{
+			name:     "allowed",
+			consent:  consent(nil),
+			vendorID: 1, purpose: 7,
+			expected: Explanation{Basis: LegalBasisLegitimateInterest, Trace: []string{
+				"full enforcement",
+				"vendor 1 declared legitimate interest for purpose 7",
+				"vendor 1 allowed purpose 7 on the basis of legitimate interest: the purpose's legitimate interest was disclosed to the user",
+			}},
+		},
+		{
+			name:     "restricted",
+			consent:  consent(restrict(4, restrictNotAllowed, 4)),
+			vendorID: 4, purpose: 4,
+			expected: Explanation{Basis: LegalBasisNone, Trace: []string{
+				"full enforcement",
+				"vendor 4 declared legitimate interest for purpose 4",
+				"vendor 4 denied purpose 4: publisher restriction type 0",
+			}},
+		},
+		{
+			name:     "required_consent",
+			consent:  consent(func(c *fakeConsent) { restrict(7, restrictRequireConsent, 2)(c); c.vendors = nil }),
+			vendorID: 2, purpose: 7,
+			expected: Explanation{Basis: LegalBasisNone, Trace: []string{
+				"full enforcement",
+				"vendor 2 declared legitimate interest for purpose 7",
+				"publisher restriction type 1 requires consent",
+				"vendor 2 denied purpose 7: the user didn't consent to the vendor",
+			}},
+		},
+		{
+			name:     "missing",
+			consent:  consent(nil),
+			vendorID: 32, purpose: 7,
+			expected: Explanation{Basis: LegalBasisNone, Trace: []string{
+				"full enforcement",
+				"vendor 32 denied purpose 7: the vendor isn't in vendor list version 15",
+			}},
+		},
+		{
+			name:     "deleted",
+			consent:  consent(nil),
+			vendorID: 3, purpose: 2,
+			expected: Explanation{Basis: LegalBasisNone, Trace: []string{
+				"full enforcement",
+				"vendor 3 denied purpose 2: the vendor was deleted from the vendor list on 2022-01-01, before the consent string was created",
+			}},
+		},
+		{
+			name:     "exception",
+			consent:  consent(nil),
+			vendorID: 32, purpose: 2,
+			expected: Explanation{Basis: LegalBasisVendorException, Trace: []string{
+				"vendor 32 allowed purpose 2 on the basis of vendor exception: the config lists the vendor as an exception",
+			}},
+		},
+		{
+			name:     "denied",
+			consent:  consent(nil),
+			vendorID: 1, purpose: 2,
+			expected: Explanation{Basis: LegalBasisNone, Trace: []string{
+				"vendor 1 denied purpose 2: the config denies the vendor",
+			}},
+		},
+		{
+			name:     "basic",
+			consent:  consent(func(c *fakeConsent) { c.purposes = nil }),
+			vendorID: 32, purpose: 3,
+			expected: Explanation{Basis: LegalBasisLegitimateInterest, Trace: []string{
+				"basic enforcement",
+				"vendor 32 allowed purpose 3 on the basis of legitimate interest: the purpose's legitimate interest was disclosed to the user",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, evaluator.Explain(tt.consent, list, tt.vendorID, tt.purpose))
+		})
+	}
+}
+
+func TestExplainExpired(t *testing.T) {
+	evaluator := NewEvaluator(Config{ExpireConsent: true, Explain: true})
+
+	assert.Equal(t, Explanation{Basis: LegalBasisNone, Trace: []string{
+		"vendor 1 denied purpose 7: the consent string expired, since it was last changed on 2023-01-01",
+	}}, evaluator.Explain(consent(nil), parseTestList(t), 1, 7))
+}
+
+func TestExplainMinPolicyVersion(t *testing.T) {
+	evaluator := NewEvaluator(Config{MinPolicyVersion: 4, Explain: true})
+
+	assert.Equal(t, Explanation{Basis: LegalBasisNone, Trace: []string{
+		"vendor 1 denied purpose 7: the consent string has TCF policy version 2, below the minimum of 4",
+	}}, evaluator.Explain(consent(nil), parseTestList(t), 1, 7))
+}
+
+func TestExplainPurposeOneTreatmentDefaultCountry(t *testing.T) {
+	evaluator := NewEvaluator(Config{
+		PurposeOneTreatment: PurposeOneTreatment{Enabled: true, AccessAllowed: true, DefaultCountry: "DE"},
+		Explain:             true,
+	})
+	treated := consent(func(c *fakeConsent) { c.purposeOneTreatment, c.publisherCC = true, "AA" })
+
+	assert.Equal(t, Explanation{Basis: LegalBasisPurposeOneTreatment, Trace: []string{
+		"vendor 1 allowed purpose 1 on the basis of purpose one treatment: purpose one treatment applies to publisher country DE",
+	}}, evaluator.Explain(treated, parseTestList(t), 1, 1))
+}
+
+func TestExplainDenyLists(t *testing.T) {
+	evaluator := NewEvaluator(Config{
+		Purposes:  map[consentconstants.Purpose]PurposeConfig{7: {VendorExceptions: []uint16{1}}},
+		DenyLists: map[string][]uint16{"sanctioned": {1, 2}, "regulatory": {1}},
+		Explain:   true,
+	})
+
+	assert.Equal(t, Explanation{Basis: LegalBasisNone, Trace: []string{
+		`vendor 1 denied purpose 7: the vendor is on the "regulatory" deny list`,
+	}}, evaluator.Explain(consent(nil), parseTestList(t), 1, 7))
+	assert.Equal(t, Explanation{Basis: LegalBasisNone, Trace: []string{
+		`vendor 2 denied purpose 7: the vendor is on the "sanctioned" deny list`,
+	}}, evaluator.Explain(consent(nil), parseTestList(t), 2, 7))
+}
+
+func TestExplainDisabled(t *testing.T) {
+	explanation := NewEvaluator(Config{}).Explain(consent(nil), parseTestList(t), 1, 2)
+	assert.Equal(t, Explanation{Basis: LegalBasisConsent}, explanation)
+	assert.Equal(t, "", explanation.String())
+}
@@ -0,0 +1,308 @@
+// Package permissions decides on which legal basis, if any, a vendor may process personal data for a
+// purpose, by combining a TCF consent string with the Global Vendor List it was written for.
+//
+// It implements the checks each vendor would otherwise repeat: the vendor must be in the list and not
+// deleted, must have declared the purpose, and the user must have agreed to both the purpose and the
+// vendor on the legal basis which applies, after the publisher's restrictions are taken into account.
+package permissions
+
+import (
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/prebid/go-gdpr/vendorconsent"
+)
+
+// LegalBasis is the legal basis on which a vendor may process data for a purpose.
+type LegalBasis uint8
+
+const (
+	// LegalBasisNone means the vendor may not process data for the purpose.
+	LegalBasisNone LegalBasis = iota
+	// LegalBasisConsent means the vendor may process data for the purpose because the user consented.
+	LegalBasisConsent
+	// LegalBasisLegitimateInterest means the vendor may process data for the purpose on the basis of its
+	// legitimate interest, which the user didn't object to.
+	LegalBasisLegitimateInterest
+	// LegalBasisPurposeOneTreatment means the vendor may store and access information on the device
+	// (purpose 1) under the rules of the publisher's country, because the consent string has
+	// PurposeOneTreatment set and the Evaluator's Config allows it.
+	LegalBasisPurposeOneTreatment
+	// LegalBasisVendorException means the vendor may process data for the purpose because the Evaluator's
+	// Config lists it as an exception, whatever the consent string says.
+	LegalBasisVendorException
+	// LegalBasisNotApplicable means the vendor may process data for the purpose because GDPR doesn't
+	// apply to the request.
+	LegalBasisNotApplicable
+)
+
+func (b LegalBasis) String() string {
+	switch b {
+	case LegalBasisNone:
+		return "none"
+	case LegalBasisConsent:
+		return "consent"
+	case LegalBasisLegitimateInterest:
+		return "legitimate interest"
+	case LegalBasisPurposeOneTreatment:
+		return "purpose one treatment"
+	case LegalBasisVendorException:
+		return "vendor exception"
+	case LegalBasisNotApplicable:
+		return "not applicable"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the legal basis as its String, so that it reads well in JSON records such as an
+// AuditRecord.
+func (b LegalBasis) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// Publisher restriction types, as defined by the TCF.
+const (
+	restrictNotAllowed           uint8 = 0
+	restrictRequireConsent       uint8 = 1
+	restrictRequireLegitInterest uint8 = 2
+)
+
+// pubRestrictionChecker is implemented by consent strings with publisher restrictions.
+type pubRestrictionChecker interface {
+	CheckPubRestriction(purposeID uint8, restrictType uint8, vendor uint16) bool
+}
+
+// legitInterestTransparency is implemented by consent strings which record whether the user was told
+// about the legitimate interest for each purpose.
+type legitInterestTransparency interface {
+	PurposeLITransparency(id consentconstants.Purpose) bool
+}
+
+// Evaluate returns the legal basis on which the vendor may process data for the purpose.
+//
+// The vendor's basis is the one ResolveBasis returns: the one it declared for the purpose in the vendor
+// list, unless a publisher restriction changes it. The basis is then granted only if the consent string
+// agrees:
+//
+//   - Consent needs the user's consent to both the purpose and the vendor.
+//   - Legitimate interest needs the purpose's legitimate interest to have been disclosed to the user, and
+//     the user not to have objected to the vendor's legitimate interest. Purpose 1 can never be based on
+//     legitimate interest, nor can purposes 3 to 6 under TCF policy version 4 and later.
+//
+// Vendors missing from the list, or deleted from it before the consent string was created, get
+// LegalBasisNone, as do vendors evaluated against TCF v1 strings for a legitimate interest, since those
+// strings can't record one. Check that the list suits the string with vendorconsent.CheckCompatibility
+// first.
+func Evaluate(consent api.VendorConsents, gvl api.VendorList, vendorID uint16, purpose consentconstants.Purpose) LegalBasis {
+	switch ResolveBasis(consent, gvl, vendorID, purpose) {
+	case LegalBasisConsent:
+		if consentGranted(consent, gvl, vendorID, purpose) {
+			return LegalBasisConsent
+		}
+	case LegalBasisLegitimateInterest:
+		if legitInterestGranted(consent, gvl, vendorID, purpose) {
+			return LegalBasisLegitimateInterest
+		}
+	}
+	return LegalBasisNone
+}
+
+// VendorAllowedForPurposeLI returns true if the vendor may process data for the purpose on the basis of
+// its legitimate interest. That's the case if, once publisher restrictions are applied, the vendor's basis
+// for the purpose is legitimate interest, the consent string discloses the purpose's legitimate interest,
+// and the user didn't object to the vendor's. The rules are the same as Evaluate's.
+func VendorAllowedForPurposeLI(consent api.VendorConsents, gvl api.VendorList, vendorID uint16, purpose consentconstants.Purpose) bool {
+	return ResolveBasis(consent, gvl, vendorID, purpose) == LegalBasisLegitimateInterest &&
+		legitInterestGranted(consent, gvl, vendorID, purpose)
+}
+
+// Permission is whether a vendor may process data for a purpose, and on which legal basis. Unlike
+// LegalBasis, it only has the three outcomes of the TCF's own rules.
+type Permission uint8
+
+const (
+	// PermissionDenied means the vendor may not process data for the purpose.
+	PermissionDenied Permission = iota
+	// PermissionAllowedByConsent means the vendor may process data for the purpose because the user
+	// consented.
+	PermissionAllowedByConsent
+	// PermissionAllowedByLegitimateInterest means the vendor may process data for the purpose on the
+	// basis of its legitimate interest.
+	PermissionAllowedByLegitimateInterest
+)
+
+func (p Permission) String() string {
+	switch p {
+	case PermissionDenied:
+		return "denied"
+	case PermissionAllowedByConsent:
+		return "allowed by consent"
+	case PermissionAllowedByLegitimateInterest:
+		return "allowed by legitimate interest"
+	default:
+		return "unknown"
+	}
+}
+
+// Allowed returns true if the vendor may process data for the purpose on either basis.
+func (p Permission) Allowed() bool {
+	return p == PermissionAllowedByConsent || p == PermissionAllowedByLegitimateInterest
+}
+
+// EffectiveVendorPermission returns whether the vendor may process data for the purpose, and on which
+// basis, in one call. It follows the same rules as Evaluate. Callers which send user data to the vendor
+// can check Allowed, and those which must tell the vendor its basis can switch on the result.
+func EffectiveVendorPermission(consent api.VendorConsents, gvl api.VendorList, vendorID uint16, purpose consentconstants.Purpose) Permission {
+	switch Evaluate(consent, gvl, vendorID, purpose) {
+	case LegalBasisConsent:
+		return PermissionAllowedByConsent
+	case LegalBasisLegitimateInterest:
+		return PermissionAllowedByLegitimateInterest
+	default:
+		return PermissionDenied
+	}
+}
+
+// EvaluateSpecialPurpose returns the legal basis on which the vendor may process data for the special
+// purpose, such as ensuring security and preventing fraud (1) or delivering ads and content (2).
+//
+// Special purposes are always based on legitimate interest, and the user can't object to them, so the
+// consent string's choices don't matter. The vendor must still be in the list, not deleted before the
+// consent string was created, and have declared the special purpose. For consent strings with a
+// disclosedVendors segment, the vendor must also have been disclosed to the user. Otherwise, this returns
+// LegalBasisNone.
+func EvaluateSpecialPurpose(consent api.VendorConsents, gvl api.VendorList, vendorID uint16, specialPurpose consentconstants.Purpose) LegalBasis {
+	vendor, ok := listedVendor(consent, gvl, vendorID)
+	if !ok || !vendor.SpecialPurpose(specialPurpose) {
+		return LegalBasisNone
+	}
+	if consent.HasDisclosedVendors() && !consent.VendorDisclosed(vendorID) {
+		return LegalBasisNone
+	}
+	return LegalBasisLegitimateInterest
+}
+
+// specialFeatureOptIns is implemented by consent strings which record the user's special feature opt-ins.
+type specialFeatureOptIns interface {
+	SpecialFeatureOptIn(id uint16) bool
+}
+
+// EvaluateSpecialFeature returns the legal basis on which the vendor may use the special feature, such as
+// precise geolocation (1) or actively scanning device characteristics (2).
+//
+// Special features need the user to opt in, so the result is LegalBasisConsent if the user opted in to
+// the special feature, and the vendor is in the list, wasn't deleted before the consent string was
+// created, and declared the special feature. The opt-in applies to every vendor which declared the
+// feature, so the vendor's own consent bit isn't checked. For consent strings with a disclosedVendors
+// segment, the vendor must also have been disclosed to the user. Otherwise, and for TCF v1 strings,
+// which have no special features, this returns LegalBasisNone.
+func EvaluateSpecialFeature(consent api.VendorConsents, gvl api.VendorList, vendorID uint16, feature consentconstants.SpecialFeature) LegalBasis {
+	optIns, ok := consent.(specialFeatureOptIns)
+	if !ok || feature == 0 || !optIns.SpecialFeatureOptIn(uint16(feature)) {
+		return LegalBasisNone
+	}
+	vendor, ok := listedVendor(consent, gvl, vendorID)
+	if !ok || !vendor.SpecialFeature(feature) {
+		return LegalBasisNone
+	}
+	if consent.HasDisclosedVendors() && !consent.VendorDisclosed(vendorID) {
+		return LegalBasisNone
+	}
+	return LegalBasisConsent
+}
+
+// listedVendor returns the vendor from the list, unless it's missing or was deleted before the consent
+// string was created.
+func listedVendor(consent api.VendorConsents, gvl api.VendorList, vendorID uint16) (api.Vendor, bool) {
+	vendor := gvl.Vendor(vendorID)
+	if vendor == nil {
+		return nil, false
+	}
+	if deletion, ok := vendor.(api.VendorDeletion); ok {
+		if deletedDate, deleted := deletion.DeletedDate(); deleted && !consent.Created().Before(deletedDate) {
+			return nil, false
+		}
+	}
+	return vendor, true
+}
+
+// ResolveBasis returns the legal basis the vendor relies on for the purpose, once publisher restrictions
+// are applied, whether or not the consent string grants it. Evaluate then checks the basis against the
+// user's choices.
+//
+// The vendor's default basis is the one it declared for the purpose in the vendor list. A restriction of
+// type 0 disallows the purpose whatever the vendor declared, so the result is LegalBasisNone. A restriction
+// of type 1 (require consent) or 2 (require legitimate interest) only applies if the vendor declared the
+// purpose as flexible, in which case it replaces the default basis. TCF policy doesn't allow these
+// restrictions on purposes which aren't flexible, so they're ignored there. Vendors missing from the list
+// get LegalBasisNone.
+func ResolveBasis(consent api.VendorConsents, gvl api.VendorList, vendorID uint16, purpose consentconstants.Purpose) LegalBasis {
+	vendor := gvl.Vendor(vendorID)
+	if vendor == nil || purpose == 0 {
+		return LegalBasisNone
+	}
+
+	basis := declaredBasis(vendor, purpose)
+	if restrictions, ok := consent.(pubRestrictionChecker); ok {
+		switch {
+		case restrictions.CheckPubRestriction(uint8(purpose), restrictNotAllowed, vendorID):
+			return LegalBasisNone
+		case !flexiblePurpose(vendor, purpose):
+		case restrictions.CheckPubRestriction(uint8(purpose), restrictRequireConsent, vendorID):
+			basis = LegalBasisConsent
+		case restrictions.CheckPubRestriction(uint8(purpose), restrictRequireLegitInterest, vendorID):
+			basis = LegalBasisLegitimateInterest
+		}
+	}
+	return basis
+}
+
+// consentGranted returns true if the user consented to both the purpose and the vendor.
+func consentGranted(consent api.VendorConsents, gvl api.VendorList, vendorID uint16, purpose consentconstants.Purpose) bool {
+	return consent.PurposeAllowed(purpose) && vendorconsent.VendorConsent(consent, gvl, vendorID)
+}
+
+// legitInterestGranted returns true if the purpose may be based on legitimate interest, the consent
+// string discloses it, and the user didn't object to the vendor's legitimate interest.
+func legitInterestGranted(consent api.VendorConsents, gvl api.VendorList, vendorID uint16, purpose consentconstants.Purpose) bool {
+	return legitInterestDisclosed(consent, purpose) && vendorconsent.VendorLegitInterest(consent, gvl, vendorID)
+}
+
+// legitInterestDisclosed returns true if the purpose may be based on legitimate interest and the consent
+// string discloses it.
+func legitInterestDisclosed(consent api.VendorConsents, purpose consentconstants.Purpose) bool {
+	transparency, ok := consent.(legitInterestTransparency)
+	return ok && legitInterestAllowed(consent, purpose) && transparency.PurposeLITransparency(purpose)
+}
+
+// declaredBasis returns the basis the vendor declared for the purpose in the vendor list. A flexible
+// purpose is also listed under its default basis.
+func declaredBasis(vendor api.Vendor, purpose consentconstants.Purpose) LegalBasis {
+	switch {
+	case vendor.PurposeStrict(purpose):
+		return LegalBasisConsent
+	case vendor.LegitimateInterestStrict(purpose):
+		return LegalBasisLegitimateInterest
+	default:
+		return LegalBasisNone
+	}
+}
+
+// flexiblePurpose returns true if the vendor declared the purpose as flexible in the vendor list.
+func flexiblePurpose(vendor api.Vendor, purpose consentconstants.Purpose) bool {
+	flexible, ok := vendor.(api.FlexiblePurposes)
+	return ok && flexible.FlexiblePurpose(purpose)
+}
+
+// legitInterestAllowed returns false for purposes the TCF policy says can't be based on legitimate
+// interest.
+func legitInterestAllowed(consent api.VendorConsents, purpose consentconstants.Purpose) bool {
+	switch {
+	case purpose == 1:
+		return false
+	case purpose >= 3 && purpose <= 6:
+		return consent.TCFPolicyVersion() < 4
+	default:
+		return true
+	}
+}
@@ -0,0 +1,465 @@
+package permissions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/prebid/go-gdpr/vendorconsent"
+	"github.com/prebid/go-gdpr/vendorlist2"
+	"github.com/stretchr/testify/assert"
+)
+
+type restrictionKey struct {
+	purpose      uint8
+	restrictType uint8
+	vendor       uint16
+}
+
+// fakeConsent implements the parts of a TCF 2 consent string which Evaluate uses. Calling any other
+// method panics.
+type fakeConsent struct {
+	api.VendorConsents
+
+	policyVersion  uint8
+	purposes       map[consentconstants.Purpose]bool
+	liTransparency map[consentconstants.Purpose]bool
+	vendors        map[uint16]bool
+	legitInterests map[uint16]bool
+	restrictions   map[restrictionKey]bool
+	disclosed      map[uint16]bool
+
+	purposeOneTreatment bool
+	publisherCC         string
+	specialFeatures     map[uint16]bool
+
+	publisherPurposes       map[consentconstants.Purpose]bool
+	publisherLITransparency map[consentconstants.Purpose]bool
+}
+
+func (c fakeConsent) Created() time.Time                              { return time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC) }
+func (c fakeConsent) LastUpdated() time.Time                          { return c.Created() }
+func (c fakeConsent) TCFPolicyVersion() uint8                         { return c.policyVersion }
+func (c fakeConsent) PurposeAllowed(id consentconstants.Purpose) bool { return c.purposes[id] }
+func (c fakeConsent) PurposeLITransparency(id consentconstants.Purpose) bool {
+	return c.liTransparency[id]
+}
+func (c fakeConsent) VendorConsent(id uint16) bool       { return c.vendors[id] }
+func (c fakeConsent) VendorLegitInterest(id uint16) bool { return c.legitInterests[id] }
+func (c fakeConsent) VendorLegitInterestMaxID() uint16   { return 100 }
+func (c fakeConsent) MaxVendorID() uint16                { return 100 }
+func (c fakeConsent) HasDisclosedVendors() bool          { return c.disclosed != nil }
+func (c fakeConsent) VendorDisclosed(id uint16) bool     { return c.disclosed[id] }
+func (c fakeConsent) SpecialFeatureOptIn(id uint16) bool { return c.specialFeatures[id] }
+func (c fakeConsent) PurposeOneTreatment() bool          { return c.purposeOneTreatment }
+func (c fakeConsent) PublisherCC() string                { return c.publisherCC }
+func (c fakeConsent) HasPublisherTC() bool               { return c.publisherPurposes != nil }
+func (c fakeConsent) PublisherPurposeConsent(id consentconstants.Purpose) bool {
+	return c.publisherPurposes[id]
+}
+func (c fakeConsent) PublisherPurposeLITransparency(id consentconstants.Purpose) bool {
+	return c.publisherLITransparency[id]
+}
+func (c fakeConsent) CheckPubRestriction(purposeID uint8, restrictType uint8, vendor uint16) bool {
+	return c.restrictions[restrictionKey{purpose: purposeID, restrictType: restrictType, vendor: vendor}]
+}
+
+const testList = `{
+	"gvlSpecificationVersion": 2,
+	"vendorListVersion": 15,
+	"vendors": {
+		"1": {"id": 1, "purposes": [1, 2, 3], "legIntPurposes": [7]},
+		"2": {"id": 2, "purposes": [2], "legIntPurposes": [7], "flexiblePurposes": [2, 7], "specialFeatures": [1]},
+		"3": {"id": 3, "purposes": [2], "deletedDate": "2022-01-01T00:00:00Z"},
+		"4": {"id": 4, "legIntPurposes": [1, 4]},
+		"5": {"id": 5, "specialPurposes": [1, 2]},
+		"6": {"id": 6, "specialPurposes": [1], "specialFeatures": [1], "deletedDate": "2022-01-01T00:00:00Z"}
+	}
+}`
+
+var (
+	allPurposes = map[consentconstants.Purpose]bool{1: true, 2: true, 3: true, 4: true, 7: true}
+	allVendors  = map[uint16]bool{1: true, 2: true, 3: true, 4: true}
+)
+
+// consent returns a fakeConsent which allows every purpose and vendor in testList, changed by modify.
+func consent(modify func(*fakeConsent)) fakeConsent {
+	c := fakeConsent{
+		policyVersion:   2,
+		purposes:        allPurposes,
+		liTransparency:  allPurposes,
+		vendors:         allVendors,
+		legitInterests:  allVendors,
+		specialFeatures: map[uint16]bool{1: true},
+	}
+	if modify != nil {
+		modify(&c)
+	}
+	return c
+}
+
+// restrict adds a publisher restriction to a fakeConsent.
+func restrict(purpose uint8, restrictType uint8, vendor uint16) func(*fakeConsent) {
+	return func(c *fakeConsent) {
+		c.restrictions = map[restrictionKey]bool{{purpose: purpose, restrictType: restrictType, vendor: vendor}: true}
+	}
+}
+
+func parseTestList(t testing.TB) api.VendorList {
+	t.Helper()
+	list, err := vendorlist2.ParseEagerly([]byte(testList))
+	assert.NoError(t, err)
+	return list
+}
+
+func TestEvaluate(t *testing.T) {
+	list := parseTestList(t)
+
+	tests := []struct {
+		name     string
+		consent  api.VendorConsents
+		vendorID uint16
+		purpose  consentconstants.Purpose
+		expected LegalBasis
+	}{
+		{name: "consent", consent: consent(nil), vendorID: 1, purpose: 2, expected: LegalBasisConsent},
+		{name: "legitimate_interest", consent: consent(nil), vendorID: 1, purpose: 7, expected: LegalBasisLegitimateInterest},
+		{name: "undeclared_purpose", consent: consent(nil), vendorID: 1, purpose: 4, expected: LegalBasisNone},
+		{name: "vendor_missing_from_list", consent: consent(nil), vendorID: 7, purpose: 2, expected: LegalBasisNone},
+		{name: "vendor_deleted", consent: consent(nil), vendorID: 3, purpose: 2, expected: LegalBasisNone},
+		{name: "purpose_zero", consent: consent(nil), vendorID: 1, purpose: 0, expected: LegalBasisNone},
+		{
+			name:     "no_purpose_consent",
+			consent:  consent(func(c *fakeConsent) { c.purposes = map[consentconstants.Purpose]bool{3: true} }),
+			vendorID: 1, purpose: 2, expected: LegalBasisNone,
+		},
+		{
+			name:     "no_vendor_consent",
+			consent:  consent(func(c *fakeConsent) { c.vendors = map[uint16]bool{} }),
+			vendorID: 1, purpose: 2, expected: LegalBasisNone,
+		},
+		{
+			name:     "no_legitimate_interest_transparency",
+			consent:  consent(func(c *fakeConsent) { c.liTransparency = map[consentconstants.Purpose]bool{} }),
+			vendorID: 1, purpose: 7, expected: LegalBasisNone,
+		},
+		{
+			name:     "objected_to_legitimate_interest",
+			consent:  consent(func(c *fakeConsent) { c.legitInterests = map[uint16]bool{} }),
+			vendorID: 1, purpose: 7, expected: LegalBasisNone,
+		},
+		{name: "purpose_1_legitimate_interest", consent: consent(nil), vendorID: 4, purpose: 1, expected: LegalBasisNone},
+		{name: "purpose_4_legitimate_interest_policy_2", consent: consent(nil), vendorID: 4, purpose: 4, expected: LegalBasisLegitimateInterest},
+		{
+			name:     "purpose_4_legitimate_interest_policy_4",
+			consent:  consent(func(c *fakeConsent) { c.policyVersion = 4 }),
+			vendorID: 4, purpose: 4, expected: LegalBasisNone,
+		},
+		{
+			name:     "restriction_not_allowed",
+			consent:  consent(restrict(2, restrictNotAllowed, 1)),
+			vendorID: 1, purpose: 2, expected: LegalBasisNone,
+		},
+		{
+			name:     "restriction_for_other_vendor",
+			consent:  consent(restrict(2, restrictNotAllowed, 2)),
+			vendorID: 1, purpose: 2, expected: LegalBasisConsent,
+		},
+		{
+			name:     "flexible_restricted_to_legitimate_interest",
+			consent:  consent(restrict(2, restrictRequireLegitInterest, 2)),
+			vendorID: 2, purpose: 2, expected: LegalBasisLegitimateInterest,
+		},
+		{
+			name:     "flexible_restricted_to_consent",
+			consent:  consent(restrict(7, restrictRequireConsent, 2)),
+			vendorID: 2, purpose: 7, expected: LegalBasisConsent,
+		},
+		{
+			name: "flexible_restricted_to_consent_without_consent",
+			consent: consent(func(c *fakeConsent) {
+				restrict(7, restrictRequireConsent, 2)(c)
+				c.purposes = map[consentconstants.Purpose]bool{}
+			}),
+			vendorID: 2, purpose: 7, expected: LegalBasisNone,
+		},
+		{
+			name:     "inflexible_purpose_ignores_basis_restriction",
+			consent:  consent(restrict(7, restrictRequireConsent, 1)),
+			vendorID: 1, purpose: 7, expected: LegalBasisLegitimateInterest,
+		},
+		{
+			// Embedding only api.VendorConsents hides the legitimate interest methods, as for TCF v1 strings.
+			name:     "string_without_legitimate_interests",
+			consent:  struct{ api.VendorConsents }{consent(nil)},
+			vendorID: 1, purpose: 7, expected: LegalBasisNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Evaluate(tt.consent, list, tt.vendorID, tt.purpose))
+		})
+	}
+}
+
+func TestResolveBasis(t *testing.T) {
+	list := parseTestList(t)
+	// The basis doesn't depend on the user's choices.
+	refused := func(c *fakeConsent) {
+		c.purposes, c.liTransparency, c.vendors, c.legitInterests = nil, nil, nil, nil
+	}
+	refusedWith := func(purpose uint8, restrictType uint8, vendor uint16) func(*fakeConsent) {
+		return func(c *fakeConsent) {
+			refused(c)
+			restrict(purpose, restrictType, vendor)(c)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		consent  api.VendorConsents
+		vendorID uint16
+		purpose  consentconstants.Purpose
+		expected LegalBasis
+	}{
+		{name: "declared_consent", consent: consent(refused), vendorID: 1, purpose: 2, expected: LegalBasisConsent},
+		{name: "declared_legitimate_interest", consent: consent(refused), vendorID: 1, purpose: 7, expected: LegalBasisLegitimateInterest},
+		{name: "undeclared", consent: consent(refused), vendorID: 1, purpose: 4, expected: LegalBasisNone},
+		{name: "vendor_missing_from_list", consent: consent(refused), vendorID: 7, purpose: 2, expected: LegalBasisNone},
+		{name: "flexible_default", consent: consent(refused), vendorID: 2, purpose: 7, expected: LegalBasisLegitimateInterest},
+		{
+			name:     "flexible_consent_to_legitimate_interest",
+			consent:  consent(refusedWith(2, restrictRequireLegitInterest, 2)),
+			vendorID: 2, purpose: 2, expected: LegalBasisLegitimateInterest,
+		},
+		{
+			name:     "flexible_legitimate_interest_to_consent",
+			consent:  consent(refusedWith(7, restrictRequireConsent, 2)),
+			vendorID: 2, purpose: 7, expected: LegalBasisConsent,
+		},
+		{
+			name:     "flexible_restriction_matching_default",
+			consent:  consent(refusedWith(2, restrictRequireConsent, 2)),
+			vendorID: 2, purpose: 2, expected: LegalBasisConsent,
+		},
+		{
+			name:     "flexible_not_allowed",
+			consent:  consent(refusedWith(2, restrictNotAllowed, 2)),
+			vendorID: 2, purpose: 2, expected: LegalBasisNone,
+		},
+		{
+			name:     "inflexible_restriction_ignored",
+			consent:  consent(refusedWith(2, restrictRequireLegitInterest, 1)),
+			vendorID: 1, purpose: 2, expected: LegalBasisConsent,
+		},
+		{
+			name:     "inflexible_not_allowed",
+			consent:  consent(refusedWith(2, restrictNotAllowed, 1)),
+			vendorID: 1, purpose: 2, expected: LegalBasisNone,
+		},
+		{
+			name:     "string_without_restrictions",
+			consent:  struct{ api.VendorConsents }{consent(restrict(2, restrictRequireLegitInterest, 2))},
+			vendorID: 2, purpose: 2, expected: LegalBasisConsent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ResolveBasis(tt.consent, list, tt.vendorID, tt.purpose))
+		})
+	}
+}
+
+func TestVendorAllowedForPurposeLI(t *testing.T) {
+	list := parseTestList(t)
+
+	tests := []struct {
+		name     string
+		consent  api.VendorConsents
+		vendorID uint16
+		purpose  consentconstants.Purpose
+		expected bool
+	}{
+		{name: "declared_legitimate_interest", consent: consent(nil), vendorID: 1, purpose: 7, expected: true},
+		{name: "declared_consent", consent: consent(nil), vendorID: 1, purpose: 2, expected: false},
+		{
+			name:     "no_transparency",
+			consent:  consent(func(c *fakeConsent) { c.liTransparency = map[consentconstants.Purpose]bool{2: true} }),
+			vendorID: 1, purpose: 7, expected: false,
+		},
+		{
+			name:     "objected",
+			consent:  consent(func(c *fakeConsent) { c.legitInterests = map[uint16]bool{2: true} }),
+			vendorID: 1, purpose: 7, expected: false,
+		},
+		{
+			name:     "flexible_restricted_to_legitimate_interest",
+			consent:  consent(restrict(2, restrictRequireLegitInterest, 2)),
+			vendorID: 2, purpose: 2, expected: true,
+		},
+		{
+			name:     "flexible_restricted_to_consent",
+			consent:  consent(restrict(7, restrictRequireConsent, 2)),
+			vendorID: 2, purpose: 7, expected: false,
+		},
+		{
+			name:     "not_allowed",
+			consent:  consent(restrict(7, restrictNotAllowed, 1)),
+			vendorID: 1, purpose: 7, expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, VendorAllowedForPurposeLI(tt.consent, list, tt.vendorID, tt.purpose))
+		})
+	}
+}
+
+func TestEffectiveVendorPermission(t *testing.T) {
+	list := parseTestList(t)
+
+	tests := []struct {
+		name     string
+		consent  api.VendorConsents
+		vendorID uint16
+		purpose  consentconstants.Purpose
+		expected Permission
+	}{
+		{name: "consent", consent: consent(nil), vendorID: 1, purpose: 2, expected: PermissionAllowedByConsent},
+		{name: "legitimate_interest", consent: consent(nil), vendorID: 1, purpose: 7, expected: PermissionAllowedByLegitimateInterest},
+		{
+			name:     "denied",
+			consent:  consent(func(c *fakeConsent) { c.vendors = nil }),
+			vendorID: 1, purpose: 2, expected: PermissionDenied,
+		},
+		{name: "missing_vendor", consent: consent(nil), vendorID: 7, purpose: 2, expected: PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			permission := EffectiveVendorPermission(tt.consent, list, tt.vendorID, tt.purpose)
+			assert.Equal(t, tt.expected, permission)
+			assert.Equal(t, tt.expected != PermissionDenied, permission.Allowed())
+		})
+	}
+}
+
+func TestEvaluateSpecialPurpose(t *testing.T) {
+	list := parseTestList(t)
+
+	tests := []struct {
+		name           string
+		consent        api.VendorConsents
+		vendorID       uint16
+		specialPurpose consentconstants.Purpose
+		expected       LegalBasis
+	}{
+		{name: "declared", consent: consent(nil), vendorID: 5, specialPurpose: 2, expected: LegalBasisLegitimateInterest},
+		{name: "undeclared", consent: consent(nil), vendorID: 1, specialPurpose: 1, expected: LegalBasisNone},
+		{name: "vendor_missing_from_list", consent: consent(nil), vendorID: 7, specialPurpose: 1, expected: LegalBasisNone},
+		{name: "vendor_deleted", consent: consent(nil), vendorID: 6, specialPurpose: 1, expected: LegalBasisNone},
+		{
+			name: "without_any_consent",
+			consent: consent(func(c *fakeConsent) {
+				c.purposes, c.liTransparency, c.vendors, c.legitInterests = nil, nil, nil, nil
+			}),
+			vendorID: 5, specialPurpose: 1, expected: LegalBasisLegitimateInterest,
+		},
+		{
+			name:     "disclosed",
+			consent:  consent(func(c *fakeConsent) { c.disclosed = map[uint16]bool{5: true} }),
+			vendorID: 5, specialPurpose: 1, expected: LegalBasisLegitimateInterest,
+		},
+		{
+			name:     "not_disclosed",
+			consent:  consent(func(c *fakeConsent) { c.disclosed = map[uint16]bool{1: true} }),
+			vendorID: 5, specialPurpose: 1, expected: LegalBasisNone,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EvaluateSpecialPurpose(tt.consent, list, tt.vendorID, tt.specialPurpose))
+		})
+	}
+}
+
+func TestEvaluateSpecialFeature(t *testing.T) {
+	list := parseTestList(t)
+
+	tests := []struct {
+		name     string
+		consent  api.VendorConsents
+		vendorID uint16
+		feature  consentconstants.SpecialFeature
+		expected LegalBasis
+	}{
+		{name: "opted_in", consent: consent(nil), vendorID: 2, feature: 1, expected: LegalBasisConsent},
+		{name: "not_opted_in", consent: consent(func(c *fakeConsent) { c.specialFeatures = nil }), vendorID: 2, feature: 1, expected: LegalBasisNone},
+		{name: "undeclared", consent: consent(nil), vendorID: 1, feature: 1, expected: LegalBasisNone},
+		{name: "other_feature", consent: consent(nil), vendorID: 2, feature: 2, expected: LegalBasisNone},
+		{name: "feature_0", consent: consent(nil), vendorID: 2, feature: 0, expected: LegalBasisNone},
+		{name: "vendor_missing_from_list", consent: consent(nil), vendorID: 7, feature: 1, expected: LegalBasisNone},
+		{name: "vendor_deleted", consent: consent(nil), vendorID: 6, feature: 1, expected: LegalBasisNone},
+		{
+			name:     "without_vendor_consent",
+			consent:  consent(func(c *fakeConsent) { c.vendors = nil }),
+			vendorID: 2, feature: 1, expected: LegalBasisConsent,
+		},
+		{
+			name:     "not_disclosed",
+			consent:  consent(func(c *fakeConsent) { c.disclosed = map[uint16]bool{1: true} }),
+			vendorID: 2, feature: 1, expected: LegalBasisNone,
+		},
+		{name: "unsupported_consent", consent: struct{ api.VendorConsents }{consent(nil)}, vendorID: 2, feature: 1, expected: LegalBasisNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EvaluateSpecialFeature(tt.consent, list, tt.vendorID, tt.feature))
+		})
+	}
+}
+
+func TestEvaluateConsentString(t *testing.T) {
+	// This string allows purpose 6, discloses legitimate interest for purposes 1 to 10, and gives consent
+	// and legitimate interest to vendors 1 to 10.
+	parsed, err := vendorconsent.ParseString("COwAdDhOwAdDhN4ABAENAPCgAAQAAv___wAAAFP_AAp_4AI6ACACAA")
+	assert.NoError(t, err)
+	list, err := vendorlist2.ParseEagerly([]byte(`{
+		"gvlSpecificationVersion": 2,
+		"vendorListVersion": 15,
+		"vendors": {
+			"1": {"id": 1, "purposes": [2, 6], "legIntPurposes": [7]},
+			"11": {"id": 11, "purposes": [6]}
+		}
+	}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, LegalBasisConsent, Evaluate(parsed, list, 1, 6))
+	assert.Equal(t, LegalBasisNone, Evaluate(parsed, list, 1, 2))
+	assert.Equal(t, LegalBasisLegitimateInterest, Evaluate(parsed, list, 1, 7))
+	assert.Equal(t, LegalBasisNone, Evaluate(parsed, list, 11, 6))
+}
+
+func TestLegalBasisString(t *testing.T) {
+	assert.Equal(t, "none", LegalBasisNone.String())
+	assert.Equal(t, "consent", LegalBasisConsent.String())
+	assert.Equal(t, "legitimate interest", LegalBasisLegitimateInterest.String())
+	assert.Equal(t, "purpose one treatment", LegalBasisPurposeOneTreatment.String())
+	assert.Equal(t, "vendor exception", LegalBasisVendorException.String())
+	assert.Equal(t, "not applicable", LegalBasisNotApplicable.String())
+	assert.Equal(t, "unknown", LegalBasis(7).String())
+}
+
+func TestPermissionString(t *testing.T) {
+	assert.Equal(t, "denied", PermissionDenied.String())
+	assert.Equal(t, "allowed by consent", PermissionAllowedByConsent.String())
+	assert.Equal(t, "allowed by legitimate interest", PermissionAllowedByLegitimateInterest.String())
+	assert.Equal(t, "unknown", Permission(7).String())
+	assert.False(t, Permission(7).Allowed())
+}
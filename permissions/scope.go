@@ -0,0 +1,137 @@
+package permissions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+)
+
+// Scope says whether GDPR applies to a request.
+type Scope uint8
+
+const (
+	// ScopeApplies means GDPR applies, so the consent string must be evaluated.
+	ScopeApplies Scope = iota
+	// ScopeNotApplicable means GDPR doesn't apply, so personal data may be processed without a consent
+	// string.
+	ScopeNotApplicable
+)
+
+func (s Scope) String() string {
+	switch s {
+	case ScopeApplies:
+		return "applies"
+	case ScopeNotApplicable:
+		return "not applicable"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the Scope as "applies" or "not applicable".
+func (s Scope) MarshalText() ([]byte, error) {
+	if s != ScopeApplies && s != ScopeNotApplicable {
+		return nil, fmt.Errorf("unknown GDPR scope %d", s)
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes "applies" or "not applicable", in any case.
+func (s *Scope) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "applies":
+		*s = ScopeApplies
+	case "not applicable":
+		*s = ScopeNotApplicable
+	default:
+		return fmt.Errorf("unknown GDPR scope %q", text)
+	}
+	return nil
+}
+
+// EEACountries returns the countries of the European Economic Area, where GDPR applies: the member states
+// of the European Union, Iceland, Liechtenstein and Norway. Each is listed with its ISO 3166-1 alpha-2 and
+// alpha-3 codes, since geolocation data uses either.
+func EEACountries() []string {
+	return []string{
+		"AT", "AUT", "BE", "BEL", "BG", "BGR", "HR", "HRV", "CY", "CYP", "CZ", "CZE", "DK", "DNK",
+		"EE", "EST", "FI", "FIN", "FR", "FRA", "DE", "DEU", "GR", "GRC", "HU", "HUN", "IE", "IRL",
+		"IT", "ITA", "LV", "LVA", "LT", "LTU", "LU", "LUX", "MT", "MLT", "NL", "NLD", "PL", "POL",
+		"PT", "PRT", "RO", "ROU", "SK", "SVK", "SI", "SVN", "ES", "ESP", "SE", "SWE",
+		"IS", "ISL", "LI", "LIE", "NO", "NOR",
+	}
+}
+
+// ukCountries are the codes of the United Kingdom, whose UK GDPR mirrors GDPR.
+var ukCountries = []string{"GB", "GBR", "UK"}
+
+// ScopeConfig configures how an Evaluator decides whether GDPR applies to a request. The zero value
+// applies GDPR in the EEA, and when nothing is known about the request.
+type ScopeConfig struct {
+	// Countries lists the country codes where GDPR applies, matched case-insensitively. If it's empty,
+	// EEACountries is used.
+	Countries []string `json:"countries"`
+	// IncludeUK applies GDPR in the United Kingdom too.
+	IncludeUK bool `json:"includeUK"`
+	// WhenUnknown is the Scope of requests without a gdpr flag or a country.
+	WhenUnknown Scope `json:"whenUnknown"`
+}
+
+// ScopeRequest holds what a request says about its GDPR scope.
+type ScopeRequest struct {
+	// GDPR is the request's gdpr flag, as found in OpenRTB's regs.ext.gdpr: 1 if GDPR applies, 0 if it
+	// doesn't, and nil if it's missing. Other values are treated as missing.
+	GDPR *int
+	// Country is the user's country from geolocation, as an ISO 3166-1 alpha-2 or alpha-3 code, or ""
+	// if it's unknown.
+	Country string
+}
+
+// scopeCountries returns the set of countries where GDPR applies according to config.
+func scopeCountries(config ScopeConfig) map[string]struct{} {
+	codes := config.Countries
+	if len(codes) == 0 {
+		codes = EEACountries()
+	}
+	if config.IncludeUK {
+		codes = append(codes[:len(codes):len(codes)], ukCountries...)
+	}
+	countries := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		countries[strings.ToUpper(code)] = struct{}{}
+	}
+	return countries
+}
+
+// Scope decides whether GDPR applies to the request. The gdpr flag wins if the request has one, since the
+// publisher knows best. Otherwise the country decides, and requests with neither get the configured
+// WhenUnknown.
+func (e *Evaluator) Scope(request ScopeRequest) Scope {
+	if request.GDPR != nil {
+		switch *request.GDPR {
+		case 0:
+			return ScopeNotApplicable
+		case 1:
+			return ScopeApplies
+		}
+	}
+	if request.Country == "" {
+		return e.scopeWhenUnknown
+	}
+	if _, ok := e.scopeCountries[strings.ToUpper(request.Country)]; ok {
+		return ScopeApplies
+	}
+	return ScopeNotApplicable
+}
+
+// EvaluateInScope works like Evaluate for requests in GDPR scope. For requests out of scope, it returns
+// LegalBasisNotApplicable without looking at the consent string, which may be nil.
+func (e *Evaluator) EvaluateInScope(scope Scope, consent api.VendorConsents, gvl api.VendorList, vendorID uint16, purpose consentconstants.Purpose) LegalBasis {
+	if scope == ScopeNotApplicable {
+		e.audit(consent, gvl, vendorID, purpose, LegalBasisNotApplicable, ReasonGDPRNotApplicable)
+		return LegalBasisNotApplicable
+	}
+	return e.Evaluate(consent, gvl, vendorID, purpose)
+}
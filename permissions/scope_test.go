@@ -0,0 +1,81 @@
+package permissions
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScope(t *testing.T) {
+	zero, one, two := 0, 1, 2
+
+	tests := []struct {
+		name     string
+		config   ScopeConfig
+		request  ScopeRequest
+		expected Scope
+	}{
+		{name: "flag_applies", request: ScopeRequest{GDPR: &one, Country: "USA"}, expected: ScopeApplies},
+		{name: "flag_not_applicable", request: ScopeRequest{GDPR: &zero, Country: "DEU"}, expected: ScopeNotApplicable},
+		{name: "eea_alpha_2", request: ScopeRequest{Country: "de"}, expected: ScopeApplies},
+		{name: "eea_alpha_3", request: ScopeRequest{Country: "NOR"}, expected: ScopeApplies},
+		{name: "invalid_flag", request: ScopeRequest{GDPR: &two, Country: "FRA"}, expected: ScopeApplies},
+		{name: "outside_eea", request: ScopeRequest{Country: "USA"}, expected: ScopeNotApplicable},
+		{name: "uk_excluded", request: ScopeRequest{Country: "GBR"}, expected: ScopeNotApplicable},
+		{name: "uk_included", config: ScopeConfig{IncludeUK: true}, request: ScopeRequest{Country: "GBR"}, expected: ScopeApplies},
+		{name: "unknown", request: ScopeRequest{}, expected: ScopeApplies},
+		{name: "invalid_flag_unknown", request: ScopeRequest{GDPR: &two}, expected: ScopeApplies},
+		{
+			name:     "unknown_not_applicable",
+			config:   ScopeConfig{WhenUnknown: ScopeNotApplicable},
+			request:  ScopeRequest{},
+			expected: ScopeNotApplicable,
+		},
+		{name: "countries", config: ScopeConfig{Countries: []string{"ch"}}, request: ScopeRequest{Country: "CH"}, expected: ScopeApplies},
+		{name: "countries_replace_eea", config: ScopeConfig{Countries: []string{"CH"}}, request: ScopeRequest{Country: "DE"}, expected: ScopeNotApplicable},
+		{
+			name:     "countries_with_uk",
+			config:   ScopeConfig{Countries: []string{"CH"}, IncludeUK: true},
+			request:  ScopeRequest{Country: "GB"},
+			expected: ScopeApplies,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluator := NewEvaluator(Config{Scope: tt.config})
+			assert.Equal(t, tt.expected, evaluator.Scope(tt.request))
+		})
+	}
+}
+
+func TestScopeConfigDoesNotChangeCountries(t *testing.T) {
+	countries := make([]string, 1, 2)
+	countries[0] = "CH"
+	NewEvaluator(Config{Scope: ScopeConfig{Countries: countries, IncludeUK: true}})
+	assert.Equal(t, "", countries[:2][1])
+}
+
+func TestEvaluateInScope(t *testing.T) {
+	evaluator := NewEvaluator(Config{})
+	list := parseTestList(t)
+
+	assert.Equal(t, LegalBasisNotApplicable, evaluator.EvaluateInScope(ScopeNotApplicable, nil, list, 1, 2))
+	assert.Equal(t, LegalBasisConsent, evaluator.EvaluateInScope(ScopeApplies, consent(nil), list, 1, 2))
+}
+
+func TestScopeText(t *testing.T) {
+	var config ScopeConfig
+	assert.NoError(t, json.Unmarshal([]byte(`{"includeUK": true, "whenUnknown": "Not Applicable"}`), &config))
+	assert.Equal(t, ScopeConfig{IncludeUK: true, WhenUnknown: ScopeNotApplicable}, config)
+
+	data, err := json.Marshal(ScopeApplies)
+	assert.NoError(t, err)
+	assert.Equal(t, `"applies"`, string(data))
+
+	assert.Equal(t, "unknown", Scope(7).String())
+	_, err = Scope(7).MarshalText()
+	assert.Error(t, err)
+	assert.EqualError(t, config.WhenUnknown.UnmarshalText([]byte("maybe")), `unknown GDPR scope "maybe"`)
+}
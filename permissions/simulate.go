@@ -0,0 +1,52 @@
+package permissions
+
+import (
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+)
+
+// Difference is a vendor and purpose whose legal basis changes when the consent string is evaluated with
+// another vendor list.
+type Difference struct {
+	VendorID  uint16
+	Purpose   consentconstants.Purpose
+	Current   LegalBasis
+	Simulated LegalBasis
+}
+
+// Simulate evaluates the consent string with the current vendor list and with another one, such as an
+// upcoming version, whatever version the string refers to. It returns the vendors and purposes whose
+// legal basis differs, sorted by vendor and then purpose, to predict the impact of a vendor list
+// change. Simulations aren't audited.
+//
+// The vendors compared are those of both lists if they implement api.VendorIDLister, and otherwise
+// every vendor up to the highest one in the consent string.
+func (e *Evaluator) Simulate(consent api.VendorConsents, current api.VendorList, simulated api.VendorList, purposes []consentconstants.Purpose) []Difference {
+	var differences []Difference
+	for _, vendorID := range simulationVendors(consent, current, simulated) {
+		for _, purpose := range purposes {
+			currentBasis, _ := e.evaluate(consent, current, vendorID, purpose)
+			simulatedBasis, _ := e.evaluate(consent, simulated, vendorID, purpose)
+			if currentBasis != simulatedBasis {
+				differences = append(differences, Difference{
+					VendorID:  vendorID,
+					Purpose:   purpose,
+					Current:   currentBasis,
+					Simulated: simulatedBasis,
+				})
+			}
+		}
+	}
+	return differences
+}
+
+// simulationVendors returns the vendors Simulate compares, in ascending order.
+func simulationVendors(consent api.VendorConsents, lists ...api.VendorList) []uint16 {
+	var vendors Bitset
+	for _, list := range lists {
+		for _, vendorID := range candidateVendors(consent, list) {
+			vendors.add(vendorID)
+		}
+	}
+	return vendors.VendorIDs()
+}
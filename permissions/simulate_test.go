@@ -0,0 +1,39 @@
+package permissions
+
+import (
+	"testing"
+
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/prebid/go-gdpr/vendorlist2"
+	"github.com/stretchr/testify/assert"
+)
+
+// nextTestList is testList after vendor 1 moved purpose 2 to legitimate interest and vendor 3 was
+// restored.
+const nextTestList = `{
+	"gvlSpecificationVersion": 2,
+	"vendorListVersion": 16,
+	"vendors": {
+		"1": {"id": 1, "purposes": [1, 3], "legIntPurposes": [2, 7]},
+		"2": {"id": 2, "purposes": [2], "legIntPurposes": [7], "flexiblePurposes": [2, 7], "specialFeatures": [1]},
+		"3": {"id": 3, "purposes": [2]},
+		"4": {"id": 4, "legIntPurposes": [1, 4]},
+		"5": {"id": 5, "specialPurposes": [1, 2]},
+		"6": {"id": 6, "specialPurposes": [1], "specialFeatures": [1], "deletedDate": "2022-01-01T00:00:00Z"}
+	}
+}`
+
+func TestSimulate(t *testing.T) {
+	current := parseTestList(t)
+	next, err := vendorlist2.ParseEagerly([]byte(nextTestList))
+	assert.NoError(t, err)
+	evaluator := NewEvaluator(Config{})
+	purposes := []consentconstants.Purpose{1, 2, 7}
+
+	assert.Equal(t, []Difference{
+		{VendorID: 1, Purpose: 2, Current: LegalBasisConsent, Simulated: LegalBasisLegitimateInterest},
+		{VendorID: 3, Purpose: 2, Current: LegalBasisNone, Simulated: LegalBasisConsent},
+	}, evaluator.Simulate(consent(nil), current, next, purposes))
+
+	assert.Empty(t, evaluator.Simulate(consent(nil), current, current, purposes))
+}
@@ -0,0 +1,64 @@
+package permissions
+
+import (
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+)
+
+// vendorLegitInterests is implemented by consent strings with a legitimate interest section.
+type vendorLegitInterests interface {
+	VendorLegitInterest(id uint16) bool
+	VendorLegitInterestMaxID() uint16
+}
+
+// EvaluateVendors returns the vendors which Evaluate would allow the purpose, on any legal basis.
+//
+// It suits servers which filter many vendors per request: the purpose's consent and legitimate interest
+// transparency are read once, and each vendor then costs a lookup in the list and its bits in the
+// consent string. The vendors considered are the ones in the list if it implements api.VendorIDLister,
+// and otherwise every vendor up to the highest one in the consent string.
+func EvaluateVendors(consent api.VendorConsents, gvl api.VendorList, purpose consentconstants.Purpose) Bitset {
+	var allowed Bitset
+	if purpose == 0 {
+		return allowed
+	}
+	purposeConsent := consent.PurposeAllowed(purpose)
+	liDisclosed := legitInterestDisclosed(consent, purpose)
+	if !purposeConsent && !liDisclosed {
+		return allowed
+	}
+	legitInterests, _ := consent.(vendorLegitInterests)
+
+	for _, vendorID := range candidateVendors(consent, gvl) {
+		if _, ok := listedVendor(consent, gvl, vendorID); !ok {
+			continue
+		}
+		switch ResolveBasis(consent, gvl, vendorID, purpose) {
+		case LegalBasisConsent:
+			if purposeConsent && consent.VendorConsent(vendorID) {
+				allowed.add(vendorID)
+			}
+		case LegalBasisLegitimateInterest:
+			if liDisclosed && legitInterests != nil && legitInterests.VendorLegitInterest(vendorID) {
+				allowed.add(vendorID)
+			}
+		}
+	}
+	return allowed
+}
+
+// candidateVendors returns the vendors EvaluateVendors considers.
+func candidateVendors(consent api.VendorConsents, gvl api.VendorList) []uint16 {
+	if lister, ok := gvl.(api.VendorIDLister); ok {
+		return lister.VendorIDs()
+	}
+	maxID := consent.MaxVendorID()
+	if li, ok := consent.(vendorLegitInterests); ok && li.VendorLegitInterestMaxID() > maxID {
+		maxID = li.VendorLegitInterestMaxID()
+	}
+	ids := make([]uint16, 0, maxID)
+	for id := uint16(1); id != 0 && id <= maxID; id++ {
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,58 @@
+package permissions
+
+import (
+	"testing"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/prebid/go-gdpr/vendorconsent"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateVendors(t *testing.T) {
+	list := parseTestList(t)
+	consents := map[string]api.VendorConsents{
+		"allowed":        consent(nil),
+		"policy_4":       consent(func(c *fakeConsent) { c.policyVersion = 4 }),
+		"purposes":       consent(func(c *fakeConsent) { c.purposes = nil }),
+		"transparency":   consent(func(c *fakeConsent) { c.liTransparency = nil }),
+		"vendors":        consent(func(c *fakeConsent) { c.vendors = map[uint16]bool{2: true} }),
+		"require_li":     consent(restrict(2, restrictRequireLegitInterest, 2)),
+		"not_allowed":    consent(restrict(7, restrictNotAllowed, 1)),
+		"legit_interest": consent(func(c *fakeConsent) { c.legitInterests = map[uint16]bool{4: true} }),
+	}
+	lists := map[string]api.VendorList{
+		"lister":     list,
+		"not_lister": struct{ api.VendorList }{list},
+	}
+
+	for consentName, c := range consents {
+		for listName, l := range lists {
+			for purpose := consentconstants.Purpose(0); purpose <= 10; purpose++ {
+				allowed := EvaluateVendors(c, l, purpose)
+				for vendorID := uint16(0); vendorID <= 100; vendorID++ {
+					expected := Evaluate(c, l, vendorID, purpose) != LegalBasisNone
+					assert.Equal(t, expected, allowed.Has(vendorID), "consent %s, list %s, vendor %d, purpose %d", consentName, listName, vendorID, purpose)
+				}
+			}
+		}
+	}
+}
+
+func TestEvaluateVendorsConsentString(t *testing.T) {
+	parsed, err := vendorconsent.ParseString("COwAdDhOwAdDhN4ABAENAPCgAAQAAv___wAAAFP_AAp_4AI6ACACAA")
+	assert.NoError(t, err)
+	list := parseTestList(t)
+
+	assert.Equal(t, []uint16{1, 2}, EvaluateVendors(parsed, list, 7).VendorIDs())
+	assert.Equal(t, []uint16{}, EvaluateVendors(parsed, list, 2).VendorIDs())
+}
+
+func BenchmarkEvaluateVendors(b *testing.B) {
+	parsed, _ := vendorconsent.ParseString("COwAdDhOwAdDhN4ABAENAPCgAAQAAv___wAAAFP_AAp_4AI6ACACAA")
+	list := parseTestList(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		EvaluateVendors(parsed, list, 7)
+	}
+}
@@ -0,0 +1,133 @@
+// Package usprivacy parses and encodes the CCPA us_privacy string, as defined by the IAB. For technical details, see
+// https://github.com/InteractiveAdvertisingBureau/USPrivacy/blob/master/CCPA/US%20Privacy%20String.md
+package usprivacy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Version is the only us_privacy version which has been defined.
+const Version = 1
+
+const stringLength = 4
+
+// Flag is the value of one of the us_privacy string's fields.
+type Flag byte
+
+const (
+	// NotApplicable means the field doesn't apply, e.g. because the user isn't covered by the CCPA.
+	NotApplicable Flag = '-'
+	// Yes means the field's condition holds.
+	Yes Flag = 'Y'
+	// No means the field's condition doesn't hold.
+	No Flag = 'N'
+)
+
+func (f Flag) valid() bool {
+	return f == NotApplicable || f == Yes || f == No
+}
+
+// Consent is a parsed us_privacy string.
+type Consent struct {
+	// Version is the version of the us_privacy string.
+	Version uint8
+
+	// Notice is whether explicit notice of the opportunity to opt out of the sale of data was given.
+	Notice Flag
+
+	// OptOutSale is whether the user opted out of the sale of their data.
+	OptOutSale Flag
+
+	// LSPACovered is whether the publisher is a signatory to the IAB Limited Service Provider Agreement
+	// and the transaction is covered by it.
+	LSPACovered Flag
+}
+
+// NotApplicableConsent is the us_privacy string "1---", which signals that the CCPA doesn't apply.
+var NotApplicableConsent = Consent{
+	Version:     Version,
+	Notice:      NotApplicable,
+	OptOutSale:  NotApplicable,
+	LSPACovered: NotApplicable,
+}
+
+// Parse parses a us_privacy string, such as "1YNN".
+func Parse(usPrivacy string) (Consent, error) {
+	if len(usPrivacy) != stringLength {
+		return Consent{}, fmt.Errorf("us_privacy strings must be %d characters long, but %q has %d", stringLength, usPrivacy, len(usPrivacy))
+	}
+	if usPrivacy[0] != '0'+Version {
+		return Consent{}, fmt.Errorf("us_privacy string %q has version %c, but only version %d is supported", usPrivacy, usPrivacy[0], Version)
+	}
+	for i := 1; i < stringLength; i++ {
+		if !Flag(usPrivacy[i]).valid() {
+			return Consent{}, fmt.Errorf("us_privacy string %q has %q at index %d, but only 'Y', 'N' and '-' are allowed", usPrivacy, usPrivacy[i], i)
+		}
+	}
+
+	return Consent{
+		Version:     Version,
+		Notice:      Flag(usPrivacy[1]),
+		OptOutSale:  Flag(usPrivacy[2]),
+		LSPACovered: Flag(usPrivacy[3]),
+	}, nil
+}
+
+// OptedOutOfSale returns true if the user opted out of the sale of their data.
+func (c Consent) OptedOutOfSale() bool {
+	return c.OptOutSale == Yes
+}
+
+// Applies returns false if every field is NotApplicable, as in "1---", which means the CCPA doesn't apply.
+func (c Consent) Applies() bool {
+	return c.Notice != NotApplicable || c.OptOutSale != NotApplicable || c.LSPACovered != NotApplicable
+}
+
+// Encode returns the us_privacy string for c. It returns an error if c has an unsupported version,
+// or if a field isn't Yes, No or NotApplicable.
+func (c Consent) Encode() (string, error) {
+	if c.Version != Version {
+		return "", fmt.Errorf("us_privacy version %d isn't supported, only version %d is", c.Version, Version)
+	}
+	for _, field := range []struct {
+		name string
+		flag Flag
+	}{{"Notice", c.Notice}, {"OptOutSale", c.OptOutSale}, {"LSPACovered", c.LSPACovered}} {
+		if !field.flag.valid() {
+			return "", fmt.Errorf("us_privacy %s must be 'Y', 'N' or '-', but was %q", field.name, byte(field.flag))
+		}
+	}
+	return c.String(), nil
+}
+
+// String returns the us_privacy string for c, without checking that its fields are valid. Use Encode
+// when the result is going into an outgoing request.
+func (c Consent) String() string {
+	return string([]byte{'0' + c.Version, byte(c.Notice), byte(c.OptOutSale), byte(c.LSPACovered)})
+}
+
+// Normalize returns the canonical form of a us_privacy string. It tolerates the variants seen in practice:
+// surrounding whitespace and lowercase flags, so " 1ynn" becomes "1YNN", and shortened forms of the
+// not-applicable string, so "1-", "1--" and "---" become "1---". It returns an error if the string isn't a
+// us_privacy string even after these corrections.
+func Normalize(usPrivacy string) (string, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(usPrivacy))
+	if isShortNotApplicable(normalized) {
+		return NotApplicableConsent.String(), nil
+	}
+	consent, err := Parse(normalized)
+	if err != nil {
+		return "", err
+	}
+	return consent.Encode()
+}
+
+// isShortNotApplicable returns true for strings which drop the version or some of the dashes from "1---".
+func isShortNotApplicable(usPrivacy string) bool {
+	flags := strings.TrimPrefix(usPrivacy, "1")
+	if flags == "" || len(usPrivacy) >= stringLength {
+		return false
+	}
+	return strings.Trim(flags, string(NotApplicable)) == ""
+}
@@ -0,0 +1,181 @@
+package usprivacy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		usPrivacy        string
+		expected         Consent
+		expectedOptedOut bool
+	}{
+		{
+			usPrivacy:        "1YNN",
+			expected:         Consent{Version: 1, Notice: Yes, OptOutSale: No, LSPACovered: No},
+			expectedOptedOut: false,
+		},
+		{
+			usPrivacy:        "1YYY",
+			expected:         Consent{Version: 1, Notice: Yes, OptOutSale: Yes, LSPACovered: Yes},
+			expectedOptedOut: true,
+		},
+		{
+			usPrivacy:        "1---",
+			expected:         Consent{Version: 1, Notice: NotApplicable, OptOutSale: NotApplicable, LSPACovered: NotApplicable},
+			expectedOptedOut: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.usPrivacy, func(t *testing.T) {
+			consent, err := Parse(tt.usPrivacy)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, consent)
+			assert.Equal(t, tt.expectedOptedOut, consent.OptedOutOfSale())
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		usPrivacy     string
+		expectedError string
+	}{
+		{
+			name:          "empty",
+			usPrivacy:     "",
+			expectedError: `us_privacy strings must be 4 characters long, but "" has 0`,
+		},
+		{
+			name:          "too_long",
+			usPrivacy:     "1YNNN",
+			expectedError: `us_privacy strings must be 4 characters long, but "1YNNN" has 5`,
+		},
+		{
+			name:          "wrong_version",
+			usPrivacy:     "2YNN",
+			expectedError: `us_privacy string "2YNN" has version 2, but only version 1 is supported`,
+		},
+		{
+			name:          "bad_flag",
+			usPrivacy:     "1YXN",
+			expectedError: `us_privacy string "1YXN" has 'X' at index 2, but only 'Y', 'N' and '-' are allowed`,
+		},
+		{
+			name:          "lowercase_flag",
+			usPrivacy:     "1ynn",
+			expectedError: `us_privacy string "1ynn" has 'y' at index 1, but only 'Y', 'N' and '-' are allowed`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.usPrivacy)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	encoded, err := Consent{Version: 1, Notice: Yes, OptOutSale: Yes, LSPACovered: No}.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, "1YYN", encoded)
+
+	encoded, err = NotApplicableConsent.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, "1---", encoded)
+	assert.False(t, NotApplicableConsent.Applies())
+}
+
+func TestEncodeInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		consent       Consent
+		expectedError string
+	}{
+		{
+			name:          "zero_value",
+			consent:       Consent{},
+			expectedError: "us_privacy version 0 isn't supported, only version 1 is",
+		},
+		{
+			name:          "missing_flag",
+			consent:       Consent{Version: 1, Notice: Yes, OptOutSale: No},
+			expectedError: `us_privacy LSPACovered must be 'Y', 'N' or '-', but was '\x00'`,
+		},
+		{
+			name:          "lowercase_flag",
+			consent:       Consent{Version: 1, Notice: 'y', OptOutSale: No, LSPACovered: No},
+			expectedError: `us_privacy Notice must be 'Y', 'N' or '-', but was 'y'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.consent.Encode()
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestParseEncodeRoundTrip(t *testing.T) {
+	for _, usPrivacy := range []string{"1YNN", "1NYY", "1-N-", "1---"} {
+		consent, err := Parse(usPrivacy)
+		assert.NoError(t, err)
+		encoded, err := consent.Encode()
+		assert.NoError(t, err)
+		assert.Equal(t, usPrivacy, encoded)
+		assert.Equal(t, usPrivacy, consent.String())
+	}
+}
+
+func TestApplies(t *testing.T) {
+	assert.True(t, Consent{Version: 1, Notice: Yes, OptOutSale: NotApplicable, LSPACovered: NotApplicable}.Applies())
+	assert.False(t, Consent{Version: 1, Notice: NotApplicable, OptOutSale: NotApplicable, LSPACovered: NotApplicable}.Applies())
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		usPrivacy string
+		expected  string
+	}{
+		{usPrivacy: "1YNN", expected: "1YNN"},
+		{usPrivacy: "1ynn", expected: "1YNN"},
+		{usPrivacy: " 1Yn- ", expected: "1YN-"},
+		{usPrivacy: "1---", expected: "1---"},
+		{usPrivacy: "1--", expected: "1---"},
+		{usPrivacy: "1-", expected: "1---"},
+		{usPrivacy: "---", expected: "1---"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.usPrivacy, func(t *testing.T) {
+			normalized, err := Normalize(tt.usPrivacy)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, normalized)
+		})
+	}
+}
+
+func TestNormalizeInvalid(t *testing.T) {
+	tests := []struct {
+		usPrivacy     string
+		expectedError string
+	}{
+		{usPrivacy: "", expectedError: `us_privacy strings must be 4 characters long, but "" has 0`},
+		{usPrivacy: "1", expectedError: `us_privacy strings must be 4 characters long, but "1" has 1`},
+		{usPrivacy: "1YX-", expectedError: `us_privacy string "1YX-" has 'X' at index 2, but only 'Y', 'N' and '-' are allowed`},
+		{usPrivacy: "----", expectedError: `us_privacy string "----" has version -, but only version 1 is supported`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.usPrivacy, func(t *testing.T) {
+			_, err := Normalize(tt.usPrivacy)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
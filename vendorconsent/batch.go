@@ -0,0 +1,53 @@
+package vendorconsent
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prebid/go-gdpr/api"
+)
+
+// BatchResult is the outcome of parsing one of ParseBatch's inputs.
+type BatchResult struct {
+	Consent api.VendorConsents
+	Err     error
+}
+
+// ParseBatch parses every input with ParseString, using up to parallelism goroutines, and returns the
+// results in the same order as the inputs. It is meant for offline jobs, such as reprocessing logs, which
+// decode many strings at once. A parallelism below 1 means one goroutine per CPU.
+//
+// If ctx is cancelled, the inputs which haven't been parsed yet get ctx.Err() as their error.
+func ParseBatch(ctx context.Context, inputs []string, parallelism int) []BatchResult {
+	results := make([]BatchResult, len(inputs))
+	if parallelism < 1 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	parallelism = min(parallelism, len(inputs))
+
+	// Workers take the next input from a shared counter, so a slow string doesn't hold up a whole share
+	// of the batch.
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(parallelism)
+	for w := 0; w < parallelism; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(inputs) {
+					return
+				}
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+				results[i].Consent, results[i].Err = ParseString(inputs[i])
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
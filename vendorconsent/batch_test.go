@@ -0,0 +1,45 @@
+package vendorconsent
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseBatch(t *testing.T) {
+	inputs := []string{
+		"COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA",
+		"invalid",
+		"",
+		"BONciguONcjGKADACHENAOLS1rAHDAFAAEAASABQAMwAeACEAFw",
+	}
+	for _, parallelism := range []int{0, 1, 3, 10} {
+		results := ParseBatch(context.Background(), inputs, parallelism)
+		if len(results) != len(inputs) {
+			t.Fatalf("Expected %d results. Got %d", len(inputs), len(results))
+		}
+		for i, input := range inputs {
+			expected, expectedErr := ParseString(input)
+			if (expectedErr == nil) != (results[i].Err == nil) {
+				t.Errorf("Input %d: expected error %v. Got %v", i, expectedErr, results[i].Err)
+				continue
+			}
+			if expected != nil {
+				assertUInt16sEqual(t, expected.VendorListVersion(), results[i].Consent.VendorListVersion())
+			}
+		}
+	}
+
+	assertIntsEqual(t, 0, len(ParseBatch(context.Background(), nil, 4)))
+}
+
+func TestParseBatchCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := ParseBatch(ctx, []string{"COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA", "invalid"}, 2)
+	for _, result := range results {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("Expected context.Canceled. Got %v", result.Err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package vendorconsent
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+
+	"github.com/prebid/go-gdpr/api"
+)
+
+// DefaultParseCacheSize is how many consent strings ParseCached keeps.
+const DefaultParseCacheSize = 1000
+
+var defaultParseCache = NewParseCache(DefaultParseCacheSize)
+
+// ParseCached works like ParseString, but keeps the parsed forms of the most recently used consent strings
+// in a package level ParseCache of DefaultParseCacheSize strings. Real traffic repeats a few strings
+// heavily, so most calls don't parse at all.
+func ParseCached(consent string) (api.VendorConsents, error) {
+	return defaultParseCache.ParseString(consent)
+}
+
+// ParseCache keeps the parsed forms of recently used consent strings, keyed by the raw string. It is safe
+// for concurrent use. Parsed consent strings are never modified, so callers can share them.
+type ParseCache struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type parseCacheEntry struct {
+	consent string
+	parsed  api.VendorConsents
+}
+
+// NewParseCache returns a ParseCache which keeps up to size consent strings. When it's full, the least
+// recently used string is dropped. Sizes below 1 mean 1.
+func NewParseCache(size int) *ParseCache {
+	if size < 1 {
+		size = 1
+	}
+	return &ParseCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Len returns the number of consent strings in the cache.
+func (c *ParseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// ParseString returns the cached parse of the consent string, or parses it with ParseString and caches
+// the result. Strings which fail to parse aren't cached, so their errors are returned each time.
+func (c *ParseCache) ParseString(consent string) (api.VendorConsents, error) {
+	c.mu.Lock()
+	if element, ok := c.entries[consent]; ok {
+		c.order.MoveToFront(element)
+		c.mu.Unlock()
+		return element.Value.(*parseCacheEntry).parsed, nil
+	}
+	c.mu.Unlock()
+
+	parsed, err := ParseString(consent)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[consent]; ok {
+		c.order.MoveToFront(element)
+		return element.Value.(*parseCacheEntry).parsed, nil
+	}
+	// The string may be a slice of a much larger request, which the cache shouldn't keep alive.
+	entry := &parseCacheEntry{consent: strings.Clone(consent), parsed: parsed}
+	c.entries[entry.consent] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*parseCacheEntry).consent)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,66 @@
+package vendorconsent
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestParseCache(t *testing.T) {
+	cache := NewParseCache(2)
+	first := "COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA"
+	second := "COwAdDhOwAdDhN4ABAENAPCgAAQAAv___wAAAFP_AAp_4AI6ACACAA"
+	third := "COyiILmOyiILmADACHENAPCAAAAAAAAAAAAAE5QBgALgAqgD8AQACSwEygJyAAAAAA"
+
+	parsed, err := cache.ParseString(first)
+	assertNilError(t, err)
+	assertUInt16sEqual(t, 14, parsed.VendorListVersion())
+	_, err = cache.ParseString(second)
+	assertNilError(t, err)
+	_, err = cache.ParseString(first)
+	assertNilError(t, err)
+	assertIntsEqual(t, 2, cache.Len())
+
+	// The second string is the least recently used, so the third replaces it.
+	_, err = cache.ParseString(third)
+	assertNilError(t, err)
+	assertIntsEqual(t, 2, cache.Len())
+	if _, ok := cache.entries[second]; ok {
+		t.Errorf("The least recently used string should have been dropped")
+	}
+	if _, ok := cache.entries[first]; !ok {
+		t.Errorf("The most recently used string should have been kept")
+	}
+
+	for _, invalid := range []string{"invalid", ""} {
+		if _, err := cache.ParseString(invalid); err == nil {
+			t.Errorf("ParseString(%q) should have failed", invalid)
+		}
+	}
+	assertIntsEqual(t, 2, cache.Len())
+}
+
+func TestParseCacheConcurrent(t *testing.T) {
+	cache := NewParseCache(1)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				parsed, err := cache.ParseString("COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA")
+				if err != nil || parsed.VendorListVersion() != 14 {
+					t.Errorf("Unexpected parse: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assertIntsEqual(t, 1, cache.Len())
+}
+
+func TestParseCached(t *testing.T) {
+	parsed, err := ParseCached("COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA")
+	assertNilError(t, err)
+	assertUInt16sEqual(t, 14, parsed.VendorListVersion())
+}
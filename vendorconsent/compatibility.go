@@ -0,0 +1,104 @@
+package vendorconsent
+
+import (
+	"fmt"
+
+	"github.com/prebid/go-gdpr/api"
+)
+
+// Mismatch identifies why a consent string and a vendor list aren't compatible.
+type Mismatch int
+
+const (
+	// MismatchSpecVersion means the list uses a vendor list specification the consent string's TCF
+	// version can't be interpreted with.
+	MismatchSpecVersion Mismatch = iota + 1
+	// MismatchListVersion means the list is older than the version the consent string was written for.
+	MismatchListVersion
+	// MismatchPolicyVersion means the list was published for an older TCF policy than the consent string
+	// was written under.
+	MismatchPolicyVersion
+)
+
+// CompatibilityError is returned by CheckCompatibility. Use errors.As to tell it apart from other errors,
+// and Mismatch to decide what to do: a list version mismatch can usually be fixed by fetching a newer
+// list, while the others mean the wrong kind of list was loaded.
+type CompatibilityError struct {
+	Mismatch Mismatch
+
+	ConsentVersion           uint8
+	ConsentTCFPolicyVersion  uint8
+	ConsentVendorListVersion uint16
+
+	ListSpecVersion      uint16
+	ListVersion          uint16
+	ListTCFPolicyVersion uint8
+}
+
+func (e *CompatibilityError) Error() string {
+	switch e.Mismatch {
+	case MismatchSpecVersion:
+		return fmt.Sprintf("a TCF v%d consent string with policy version %d can't be interpreted with a version %d vendor list",
+			e.ConsentVersion, e.ConsentTCFPolicyVersion, e.ListSpecVersion)
+	case MismatchListVersion:
+		return fmt.Sprintf("the consent string needs vendor list version %d, but the list is version %d",
+			e.ConsentVendorListVersion, e.ListVersion)
+	case MismatchPolicyVersion:
+		return fmt.Sprintf("the consent string needs TCF policy version %d, but the vendor list is for policy version %d",
+			e.ConsentTCFPolicyVersion, e.ListTCFPolicyVersion)
+	default:
+		return "the consent string and vendor list aren't compatible"
+	}
+}
+
+// tcfPolicyVersioner is implemented by vendor lists which record their TCF policy version, like the ones
+// returned by the vendorlist3 package.
+type tcfPolicyVersioner interface {
+	TCFPolicyVersion() uint8
+}
+
+// CheckCompatibility returns a *CompatibilityError if the vendor list can't be used to interpret the
+// consent string, and nil if it can.
+//
+// TCF v1 strings need a version 1 list, which has no gvlSpecificationVersion. TCF v2 strings need a
+// version 2 list up to policy version 3, and a version 3 list from policy version 4. The list must be
+// the version the string was written for, or newer, since vendors are never removed from later
+// versions. Lists which record their own policy version, like those from vendorlist3, must also be for
+// the string's policy version or a later one.
+func CheckCompatibility(consent api.VendorConsents, list api.VendorList) error {
+	err := &CompatibilityError{
+		ConsentVersion:           consent.Version(),
+		ConsentTCFPolicyVersion:  consent.TCFPolicyVersion(),
+		ConsentVendorListVersion: consent.VendorListVersion(),
+		ListSpecVersion:          list.SpecVersion(),
+		ListVersion:              list.Version(),
+	}
+	if versioner, ok := list.(tcfPolicyVersioner); ok {
+		err.ListTCFPolicyVersion = versioner.TCFPolicyVersion()
+	}
+
+	switch {
+	case err.ListSpecVersion != requiredSpecVersion(consent):
+		err.Mismatch = MismatchSpecVersion
+	case err.ListVersion < err.ConsentVendorListVersion:
+		err.Mismatch = MismatchListVersion
+	case err.ListTCFPolicyVersion != 0 && err.ListTCFPolicyVersion < err.ConsentTCFPolicyVersion:
+		err.Mismatch = MismatchPolicyVersion
+	default:
+		return nil
+	}
+	return err
+}
+
+// requiredSpecVersion returns the vendor list specification version needed to interpret the consent
+// string. Version 1 lists don't record it, so they report 0.
+func requiredSpecVersion(consent api.VendorConsents) uint16 {
+	switch {
+	case consent.Version() < 2:
+		return 0
+	case consent.TCFPolicyVersion() < 4:
+		return 2
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,123 @@
+package vendorconsent
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/vendorlist"
+	"github.com/prebid/go-gdpr/vendorlist2"
+	"github.com/prebid/go-gdpr/vendorlist3"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	// validateTCString has policy version 2. Swapping its 23rd character for an E makes it policy version 4.
+	policy2 := validateTCString
+	policy4 := validateTCString[:22] + "E" + validateTCString[23:]
+	// This TCF v1 string has vendor list version 14.
+	tcf1String := "BONV8oqONXwgmADACHENAO7pqzAAppY"
+
+	tests := []struct {
+		description      string
+		consent          string
+		list             api.VendorList
+		expectedMismatch Mismatch
+		expectedError    string
+	}{
+		{
+			description: "TCF v1 with a version 1 list",
+			consent:     tcf1String,
+			list:        parseV1List(t, `{"vendorListVersion": 14, "vendors": [{"id": 1, "purposeIds": [1]}]}`),
+		},
+		{
+			description:      "TCF v1 with a version 2 list",
+			consent:          tcf1String,
+			list:             parseV2List(t, `{"gvlSpecificationVersion": 2, "vendorListVersion": 14, "vendors": {}}`),
+			expectedMismatch: MismatchSpecVersion,
+			expectedError:    "a TCF v1 consent string with policy version 0 can't be interpreted with a version 2 vendor list",
+		},
+		{
+			description: "Policy 2 with the same list version",
+			consent:     policy2,
+			list:        parseV2List(t, `{"gvlSpecificationVersion": 2, "vendorListVersion": 15, "vendors": {}}`),
+		},
+		{
+			description: "Policy 2 with a newer list",
+			consent:     policy2,
+			list:        parseV2List(t, `{"gvlSpecificationVersion": 2, "vendorListVersion": 16, "vendors": {}}`),
+		},
+		{
+			description:      "Policy 2 with an older list",
+			consent:          policy2,
+			list:             parseV2List(t, `{"gvlSpecificationVersion": 2, "vendorListVersion": 14, "vendors": {}}`),
+			expectedMismatch: MismatchListVersion,
+			expectedError:    "the consent string needs vendor list version 15, but the list is version 14",
+		},
+		{
+			description:      "Policy 2 with a version 3 list",
+			consent:          policy2,
+			list:             parseV3List(t, `{"gvlSpecificationVersion": 3, "vendorListVersion": 15, "tcfPolicyVersion": 4, "vendors": {}}`),
+			expectedMismatch: MismatchSpecVersion,
+			expectedError:    "a TCF v2 consent string with policy version 2 can't be interpreted with a version 3 vendor list",
+		},
+		{
+			description: "Policy 4 with a version 3 list",
+			consent:     policy4,
+			list:        parseV3List(t, `{"gvlSpecificationVersion": 3, "vendorListVersion": 15, "tcfPolicyVersion": 4, "vendors": {}}`),
+		},
+		{
+			description:      "Policy 4 with a version 2 list",
+			consent:          policy4,
+			list:             parseV2List(t, `{"gvlSpecificationVersion": 2, "vendorListVersion": 15, "vendors": {}}`),
+			expectedMismatch: MismatchSpecVersion,
+			expectedError:    "a TCF v2 consent string with policy version 4 can't be interpreted with a version 2 vendor list",
+		},
+		{
+			description:      "Policy 4 with a list for an older policy",
+			consent:          policy4,
+			list:             parseV3List(t, `{"gvlSpecificationVersion": 3, "vendorListVersion": 15, "tcfPolicyVersion": 3, "vendors": {}}`),
+			expectedMismatch: MismatchPolicyVersion,
+			expectedError:    "the consent string needs TCF policy version 4, but the vendor list is for policy version 3",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			consent, err := ParseString(test.consent)
+			assertNilError(t, err)
+
+			err = CheckCompatibility(consent, test.list)
+			if test.expectedMismatch == 0 {
+				assertNilError(t, err)
+				return
+			}
+			var compatibilityErr *CompatibilityError
+			if !errors.As(err, &compatibilityErr) {
+				t.Fatalf("Expected a *CompatibilityError, got %v", err)
+			}
+			assertIntsEqual(t, int(test.expectedMismatch), int(compatibilityErr.Mismatch))
+			assertStringsEqual(t, test.expectedError, err.Error())
+		})
+	}
+}
+
+func parseV1List(t *testing.T, data string) api.VendorList {
+	t.Helper()
+	list, err := vendorlist.ParseEagerly([]byte(data))
+	assertNilError(t, err)
+	return list
+}
+
+func parseV2List(t *testing.T, data string) api.VendorList {
+	t.Helper()
+	list, err := vendorlist2.ParseEagerly([]byte(data))
+	assertNilError(t, err)
+	return list
+}
+
+func parseV3List(t *testing.T, data string) api.VendorList {
+	t.Helper()
+	list, err := vendorlist3.ParseEagerly([]byte(data))
+	assertNilError(t, err)
+	return list
+}
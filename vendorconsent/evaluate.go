@@ -0,0 +1,116 @@
+package vendorconsent
+
+import (
+	"fmt"
+
+	"github.com/prebid/go-gdpr/api"
+)
+
+// BeyondListPolicy decides how vendors with IDs above the highest vendor in the vendor list are evaluated.
+// These show up when a CMP uses a newer list than the caller, so the vendor may well exist.
+type BeyondListPolicy uint8
+
+const (
+	// BeyondListDeny treats vendors beyond the list as having no consent. This is what VendorConsent and
+	// VendorLegitInterest do.
+	BeyondListDeny BeyondListPolicy = iota
+	// BeyondListAllowIfConsented trusts the consent string for vendors beyond the list.
+	BeyondListAllowIfConsented
+	// BeyondListError returns an error for vendors beyond the list, so the caller can fetch a newer one.
+	BeyondListError
+)
+
+// VendorConsent returns true if the consent string gives consent to the vendor, and the vendor list
+// says that consent is still valid.
+//
+// Per IAB policy, a vendor deleted from the list before the consent string was created can't receive
+// consent from it, so this returns false even if the string's bit is set. Vendors missing from the list
+// are treated as having no consent.
+func VendorConsent(consent api.VendorConsents, list api.VendorList, vendorID uint16) bool {
+	return vendorValid(consent, list, vendorID) && consent.VendorConsent(vendorID)
+}
+
+// VendorLegitInterest returns true if the consent string establishes legitimate interest for the vendor,
+// and the vendor list says it's still valid. It follows the same rules as VendorConsent, and is always
+// false for consent strings without a legitimate interest section.
+func VendorLegitInterest(consent api.VendorConsents, list api.VendorList, vendorID uint16) bool {
+	li, ok := consent.(legitimateInterests)
+	return ok && vendorValid(consent, list, vendorID) && li.VendorLegitInterest(vendorID)
+}
+
+// VendorConsentWithPolicy works like VendorConsent, but evaluates vendors above the highest vendor in
+// the list according to the policy.
+//
+// The policy only applies to lists which implement api.MaxVendorIDReporter or api.VendorIDLister, as every
+// list in this module does. Other lists behave as if the policy was BeyondListDeny.
+func VendorConsentWithPolicy(consent api.VendorConsents, list api.VendorList, vendorID uint16, policy BeyondListPolicy) (bool, error) {
+	if beyondList(list, vendorID) {
+		return applyBeyondListPolicy(list, vendorID, policy, consent.VendorConsent(vendorID))
+	}
+	return VendorConsent(consent, list, vendorID), nil
+}
+
+// VendorLegitInterestWithPolicy works like VendorLegitInterest, but evaluates vendors above the highest
+// vendor in the list according to the policy, the same way as VendorConsentWithPolicy.
+func VendorLegitInterestWithPolicy(consent api.VendorConsents, list api.VendorList, vendorID uint16, policy BeyondListPolicy) (bool, error) {
+	if beyondList(list, vendorID) {
+		li, ok := consent.(legitimateInterests)
+		return applyBeyondListPolicy(list, vendorID, policy, ok && li.VendorLegitInterest(vendorID))
+	}
+	return VendorLegitInterest(consent, list, vendorID), nil
+}
+
+func applyBeyondListPolicy(list api.VendorList, vendorID uint16, policy BeyondListPolicy, consented bool) (bool, error) {
+	switch policy {
+	case BeyondListDeny:
+		return false, nil
+	case BeyondListAllowIfConsented:
+		return consented, nil
+	case BeyondListError:
+		return false, fmt.Errorf("vendor %d is beyond the highest vendor in vendor list version %d", vendorID, list.Version())
+	default:
+		return false, fmt.Errorf("unknown BeyondListPolicy %d", policy)
+	}
+}
+
+// beyondList returns true if the vendor's ID is above every vendor in the list. It's always false for
+// lists which can't enumerate their vendors.
+func beyondList(list api.VendorList, vendorID uint16) bool {
+	listMax, ok := maxVendorID(list)
+	return ok && vendorID > listMax
+}
+
+// maxVendorID returns the highest vendor ID in the list, if the list can enumerate its vendors. Lists
+// which report it themselves, as the eagerly parsed ones do, are asked for it rather than scanned on every
+// call.
+func maxVendorID(list api.VendorList) (uint16, bool) {
+	if reporter, ok := list.(api.MaxVendorIDReporter); ok {
+		return reporter.MaxVendorID(), true
+	}
+	lister, ok := list.(api.VendorIDLister)
+	if !ok {
+		return 0, false
+	}
+	var listMax uint16
+	for _, id := range lister.VendorIDs() {
+		if id > listMax {
+			listMax = id
+		}
+	}
+	return listMax, true
+}
+
+// vendorValid returns true if the vendor is in the list, and wasn't deleted before the consent string
+// was created.
+func vendorValid(consent api.VendorConsents, list api.VendorList, vendorID uint16) bool {
+	vendor := list.Vendor(vendorID)
+	if vendor == nil {
+		return false
+	}
+	deletion, ok := vendor.(api.VendorDeletion)
+	if !ok {
+		return true
+	}
+	deletedDate, deleted := deletion.DeletedDate()
+	return !deleted || consent.Created().Before(deletedDate)
+}
@@ -0,0 +1,131 @@
+package vendorconsent
+
+import (
+	"testing"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/vendorlist3"
+)
+
+func TestVendorConsentDeletedVendors(t *testing.T) {
+	// validateTCString was created on 2020-03-09.
+	consent, err := ParseString(validateTCString)
+	assertNilError(t, err)
+
+	list, err := vendorlist3.ParseEagerly([]byte(`{
+		"gvlSpecificationVersion": 3,
+		"vendorListVersion": 15,
+		"vendors": {
+			"1": {"id": 1},
+			"2": {"id": 2, "deletedDate": "2020-01-01T00:00:00Z"},
+			"3": {"id": 3, "deletedDate": "2021-01-01T00:00:00Z"},
+			"11": {"id": 11}
+		}
+	}`))
+	assertNilError(t, err)
+
+	tests := []struct {
+		description string
+		vendorID    uint16
+		expected    bool
+	}{
+		{description: "Vendor in the list", vendorID: 1, expected: true},
+		{description: "Vendor deleted before the string was created", vendorID: 2, expected: false},
+		{description: "Vendor deleted after the string was created", vendorID: 3, expected: true},
+		{description: "Vendor missing from the list", vendorID: 4, expected: false},
+		{description: "Vendor without consent", vendorID: 11, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assertBoolsEqual(t, test.expected, VendorConsent(consent, list, test.vendorID))
+			assertBoolsEqual(t, test.expected, VendorLegitInterest(consent, list, test.vendorID))
+		})
+	}
+}
+
+func TestVendorConsentWithPolicy(t *testing.T) {
+	// validateTCString has consent and legitimate interest for vendors 1 to 10.
+	consent, err := ParseString(validateTCString)
+	assertNilError(t, err)
+
+	list, err := vendorlist3.ParseEagerly([]byte(`{
+		"gvlSpecificationVersion": 3,
+		"vendorListVersion": 15,
+		"vendors": {
+			"1": {"id": 1},
+			"3": {"id": 3},
+			"5": {"id": 5}
+		}
+	}`))
+	assertNilError(t, err)
+
+	tests := []struct {
+		description string
+		vendorID    uint16
+		policy      BeyondListPolicy
+		expected    bool
+		expectError bool
+	}{
+		{description: "Vendor in the list", vendorID: 3, policy: BeyondListError, expected: true},
+		{description: "Vendor missing below the list's max", vendorID: 4, policy: BeyondListAllowIfConsented, expected: false},
+		{description: "Deny beyond the list", vendorID: 8, policy: BeyondListDeny, expected: false},
+		{description: "Allow consented vendor beyond the list", vendorID: 8, policy: BeyondListAllowIfConsented, expected: true},
+		{description: "Allow unconsented vendor beyond the list", vendorID: 11, policy: BeyondListAllowIfConsented, expected: false},
+		{description: "Error beyond the list", vendorID: 8, policy: BeyondListError, expectError: true},
+		{description: "Unknown policy", vendorID: 8, policy: BeyondListPolicy(9), expectError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			result, err := VendorConsentWithPolicy(consent, list, test.vendorID, test.policy)
+			assertPolicyResult(t, test.expected, test.expectError, result, err)
+			result, err = VendorLegitInterestWithPolicy(consent, list, test.vendorID, test.policy)
+			assertPolicyResult(t, test.expected, test.expectError, result, err)
+		})
+	}
+}
+
+// reportingList hides the VendorIDs of the list it wraps, and reports a max vendor ID of its own.
+type reportingList struct {
+	api.VendorList
+	maxVendorID uint16
+}
+
+func (l reportingList) MaxVendorID() uint16 {
+	return l.maxVendorID
+}
+
+func TestVendorConsentWithPolicyReportedMax(t *testing.T) {
+	consent, err := ParseString(validateTCString)
+	assertNilError(t, err)
+
+	list, err := vendorlist3.ParseEagerly([]byte(`{
+		"gvlSpecificationVersion": 3,
+		"vendorListVersion": 15,
+		"vendors": {
+			"1": {"id": 1}
+		}
+	}`))
+	assertNilError(t, err)
+
+	// Vendor 8 is only beyond the list if the reported max is used.
+	result, err := VendorConsentWithPolicy(consent, reportingList{VendorList: list, maxVendorID: 5}, 8, BeyondListAllowIfConsented)
+	assertNilError(t, err)
+	assertBoolsEqual(t, true, result)
+	result, err = VendorConsentWithPolicy(consent, reportingList{VendorList: list, maxVendorID: 10}, 8, BeyondListAllowIfConsented)
+	assertNilError(t, err)
+	assertBoolsEqual(t, false, result)
+}
+
+func assertPolicyResult(t *testing.T, expected bool, expectError bool, actual bool, err error) {
+	t.Helper()
+	if expectError {
+		if err == nil {
+			t.Errorf("Expected an error, but got none")
+		}
+		return
+	}
+	assertNilError(t, err)
+	assertBoolsEqual(t, expected, actual)
+}
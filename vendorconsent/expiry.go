@@ -0,0 +1,33 @@
+package vendorconsent
+
+import (
+	"time"
+
+	"github.com/prebid/go-gdpr/api"
+)
+
+// DefaultMaxAge is how long a consent string stays valid by default. TCF policy asks CMPs to renew
+// consent after 13 months, counted here as 395 days, the average length of 13 months.
+const DefaultMaxAge = 395 * 24 * time.Hour
+
+// IsExpired returns true if the consent string is older than maxAge, counting from the later of its
+// Created and LastUpdated times. A maxAge of zero or less means DefaultMaxAge.
+//
+// An expired string may no longer reflect the user's choices, since the CMP should have asked them
+// again. Callers can treat it as if there was no consent at all.
+func IsExpired(consent api.VendorConsents, maxAge time.Duration) bool {
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	return time.Since(LastChanged(consent)) > maxAge
+}
+
+// LastChanged returns the later of the consent string's Created and LastUpdated times, which is when
+// the user last made their choices.
+func LastChanged(consent api.VendorConsents) time.Time {
+	created, updated := consent.Created(), consent.LastUpdated()
+	if updated.After(created) {
+		return updated
+	}
+	return created
+}
@@ -0,0 +1,54 @@
+package vendorconsent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/go-gdpr/api"
+)
+
+// datedConsent is a consent string with the given timestamps. Calling any other method panics.
+type datedConsent struct {
+	api.VendorConsents
+	created     time.Time
+	lastUpdated time.Time
+}
+
+func (c datedConsent) Created() time.Time     { return c.created }
+func (c datedConsent) LastUpdated() time.Time { return c.lastUpdated }
+
+func TestIsExpired(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-400 * 24 * time.Hour)
+	recent := now.Add(-30 * 24 * time.Hour)
+
+	tests := []struct {
+		description string
+		consent     api.VendorConsents
+		maxAge      time.Duration
+		expected    bool
+	}{
+		{description: "Recent string", consent: datedConsent{created: recent, lastUpdated: recent}, expected: false},
+		{description: "Old string", consent: datedConsent{created: old, lastUpdated: old}, expected: true},
+		{description: "Old string updated recently", consent: datedConsent{created: old, lastUpdated: recent}, expected: false},
+		{description: "Zero last updated", consent: datedConsent{created: recent}, expected: false},
+		{description: "Custom max age", consent: datedConsent{created: recent, lastUpdated: recent}, maxAge: 7 * 24 * time.Hour, expected: true},
+		{description: "Future string", consent: datedConsent{created: now.Add(time.Hour)}, maxAge: time.Minute, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			assertBoolsEqual(t, test.expected, IsExpired(test.consent, test.maxAge))
+		})
+	}
+}
+
+func TestIsExpiredConsentString(t *testing.T) {
+	// validateTCString was created on 2020-03-09.
+	consent, err := ParseString(validateTCString)
+	assertNilError(t, err)
+
+	assertBoolsEqual(t, true, IsExpired(consent, 0))
+	assertBoolsEqual(t, false, IsExpired(consent, time.Since(time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC))))
+	assertBoolsEqual(t, true, LastChanged(consent).Equal(time.Date(2020, 3, 9, 19, 11, 31, 300000000, time.UTC)))
+}
@@ -0,0 +1,54 @@
+package vendorconsent
+
+// VendorConsentsBatch sets out[i] to whether vendor ids[i] has the user's consent, like VendorConsent, for
+// callers which check many vendors per request. out must be at least as long as ids.
+func (c ConsentMetadata) VendorConsentsBatch(ids []uint16, out []bool) {
+	sectionBatch(c.vendorConsents, ids, out)
+}
+
+// VendorLegitInterestsBatch sets out[i] to whether legitimate interest is established for vendor ids[i],
+// like VendorLegitInterest. out must be at least as long as ids.
+func (c ConsentMetadata) VendorLegitInterestsBatch(ids []uint16, out []bool) {
+	sectionBatch(c.vendorLegitimateInterests, ids, out)
+}
+
+// VendorsDisclosedBatch sets out[i] to whether vendor ids[i] was disclosed to the user, like
+// VendorDisclosed. out must be at least as long as ids.
+func (c ConsentMetadata) VendorsDisclosedBatch(ids []uint16, out []bool) {
+	sectionBatch(c.disclosedVendors, ids, out)
+}
+
+// sectionBatch answers VendorConsent for every vendor in ids. A nil section has no vendors.
+func sectionBatch(section VendorSection, ids []uint16, out []bool) {
+	out = out[:len(ids)]
+	switch section := section.(type) {
+	case nil:
+		clear(out)
+	case *rangeSection:
+		section.vendorConsentsBatch(ids, out)
+	default:
+		for i, id := range ids {
+			out[i] = section.VendorConsent(id)
+		}
+	}
+}
+
+// vendorConsentsBatch answers VendorConsent for every vendor in ids. Indexed sections are searched for each
+// vendor. Sections small enough not to be indexed are scanned once, checking every vendor against each entry.
+func (p *rangeSection) vendorConsentsBatch(ids []uint16, out []bool) {
+	if len(p.index) > 0 {
+		for i, id := range ids {
+			out[i] = p.VendorConsent(id)
+		}
+		return
+	}
+
+	clear(out)
+	for _, entry := range p.consents {
+		for i, id := range ids {
+			if entry.Contains(id) && id <= p.maxVendorID {
+				out[i] = true
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package vendorconsent
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestVendorBatches(t *testing.T) {
+	// The vendor consents and legitimate interests are RangeSections, and the Disclosed Vendors segment is
+	// a BitField.
+	consentString := "COyfVVoOyfVVoADACHENAwCAAAAAAAAAAAAAE5QBgALgAqgD8AQACSwEygJyAnSAMABgAFkAgQCDASeAmYBOgAA." +
+		base64.RawURLEncoding.EncodeToString([]byte{0x20, 0x01, 0x4a, 0x80})
+
+	ids := []uint16{0, 1, 3, 23, 24, 42, 127, 130, 626, 628, 700}
+	out := make([]bool, len(ids))
+	checkBatch := func(name string, batch func([]uint16, []bool), single func(uint16) bool) {
+		for i := range out {
+			out[i] = true
+		}
+		batch(ids, out)
+		for i, id := range ids {
+			if out[i] != single(id) {
+				t.Errorf("%s for vendor %d was %t", name, id, out[i])
+			}
+		}
+	}
+
+	// Check both unindexed and indexed RangeSections.
+	for _, indexed := range []bool{false, true} {
+		parsed, err := ParseString(consentString)
+		assertNilError(t, err)
+		consent := parsed.(ConsentMetadata)
+		if indexed {
+			indexRangeSections(t, consent)
+		}
+
+		checkBatch("VendorConsentsBatch", consent.VendorConsentsBatch, consent.VendorConsent)
+		checkBatch("VendorLegitInterestsBatch", consent.VendorLegitInterestsBatch, consent.VendorLegitInterest)
+		checkBatch("VendorsDisclosedBatch", consent.VendorsDisclosedBatch, consent.VendorDisclosed)
+	}
+
+	parsed, err := ParseString("COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA")
+	assertNilError(t, err)
+	consent := parsed.(ConsentMetadata)
+	checkBatch("VendorConsentsBatch", consent.VendorConsentsBatch, consent.VendorConsent)
+	checkBatch("VendorsDisclosedBatch", consent.VendorsDisclosedBatch, consent.VendorDisclosed)
+}
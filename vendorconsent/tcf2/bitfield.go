@@ -1,23 +1,38 @@
 package vendorconsent
 
-import (
-	"fmt"
-)
-
 func parseBitField(metadata ConsentMetadata, vendorBitsRequired uint16, startbit uint) (*consentBitField, uint, error) {
-	data := metadata.data
+	field := &consentBitField{}
+	end, err := field.parse(metadata.data, vendorBitsRequired, startbit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return field, end, nil
+}
 
-	// add 7 to force rounding to next integer value
-	bytesRequired := (uint(vendorBitsRequired) + startbit + 7) / 8
-	if uint(len(data)) < bytesRequired {
-		return nil, 0, fmt.Errorf("a BitField for %d vendors requires a consent string of %d bytes. This consent string had %d", vendorBitsRequired, bytesRequired, len(data))
+// parse fills f with the BitField which starts at startbit, and returns the index of the first bit after it.
+func (f *consentBitField) parse(data []byte, vendorBitsRequired uint16, startbit uint) (uint, error) {
+	end, err := validateBitField(data, vendorBitsRequired, startbit)
+	if err != nil {
+		return 0, err
 	}
 
-	return &consentBitField{
+	*f = consentBitField{
 		data:        data,
 		startbit:    startbit,
 		maxVendorID: vendorBitsRequired,
-	}, startbit + uint(vendorBitsRequired), nil
+	}
+	return end, nil
+}
+
+// validateBitField checks that data holds the BitField which starts at startbit, and returns the index of
+// the first bit after it.
+func validateBitField(data []byte, vendorBitsRequired uint16, startbit uint) (uint, error) {
+	// add 7 to force rounding to next integer value
+	bytesRequired := (uint(vendorBitsRequired) + startbit + 7) / 8
+	if uint(len(data)) < bytesRequired {
+		return 0, parseError(ErrTruncated, nil, "a BitField for %d vendors requires a consent string of %d bytes. This consent string had %d", uint(vendorBitsRequired), bytesRequired, uint(len(data)))
+	}
+	return startbit + uint(vendorBitsRequired), nil
 }
 
 // A BitField has len(MaxVendorID()) entries, with one bit for every vendor in the range.
@@ -35,7 +35,7 @@ func TestBitField(t *testing.T) {
 func TestParseBitFieldRounding(t *testing.T) {
 	// crafted metadata to have 232 bits of data
 	data := make([]byte, 29)
-	metadata := ConsentMetadata{data: data}
+	metadata := ConsentMetadata{&parsedConsent{consentFields: consentFields{Header: Header{data: data}}}}
 	// having 3 vendors with 230 bits of header should require 30 bytes of data (233 bits rounded to upper byte)
 	_, _, err := parseBitField(metadata, 3, 230)
 	assertError(t, err)
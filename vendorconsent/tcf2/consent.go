@@ -2,11 +2,9 @@ package vendorconsent
 
 import (
 	"encoding/base64"
-	"fmt"
 	"strings"
 
 	"github.com/prebid/go-gdpr/api"
-	"github.com/prebid/go-gdpr/bitutils"
 	"github.com/prebid/go-gdpr/consentconstants"
 )
 
@@ -17,7 +15,7 @@ const (
 
 // Segment types defined in TCF 2.x specification.
 // https://github.com/InteractiveAdvertisingBureau/GDPR-Transparency-and-Consent-Framework/blob/master/TCFv2/IAB%20Tech%20Lab%20-%20Consent%20string%20and%20vendor%20list%20formats%20v2.md#publisher-purposes-transparency-and-consent
-// Only `SegmentTypeDisclosedVendors` is used in this file, but all types are included for specification completeness.
+// Segments of other types, such as the OOB vendor segments, are skipped.
 const (
 	SegmentTypeCoreString       = 0
 	SegmentTypeDisclosedVendors = 1
@@ -25,123 +23,366 @@ const (
 )
 
 // ParseString parses the TCF 2.0 vendor string base64 encoded
+//
+// Like Parse, a successful ParseString allocates once for consent strings of up to 512 bytes, which is
+// enough for most of them.
 func ParseString(consent string) (api.VendorConsents, error) {
-	if consent == "" {
-		return nil, consentconstants.ErrEmptyDecodedConsent
-	}
-
-	consentMeta, err := parseCoreAndDisclosedVendors(consent)
+	metadata, err := ParseStringConsentMetadata(consent)
 	if err != nil {
 		return nil, err
 	}
+	return metadata, nil
+}
+
+// ParseStringConsentMetadata works like ParseString, but returns the ConsentMetadata itself rather than an
+// api.VendorConsents. Callers in hot paths can then call its methods directly, which the compiler can
+// inline, instead of through the interface.
+func ParseStringConsentMetadata(consent string) (ConsentMetadata, error) {
+	var metadata ConsentMetadata
+	if err := ParseStringInto(&metadata, consent); err != nil {
+		return ConsentMetadata{}, err
+	}
+	return metadata, nil
+}
 
-	return consentMeta, nil
+// ParseStringInto works like ParseString, but parses into dst and reuses the memory which dst holds from
+// earlier calls, including that of the Disclosed Vendors and Publisher TC segments. See ParseInto.
+//
+// Latency-critical deployments can build with the gdpr_unsafe tag, which makes ParseString and
+// ParseStringInto read the consent string's memory directly rather than copying it before decoding. The
+// parsed consent doesn't refer to the string either way.
+func ParseStringInto(dst *ConsentMetadata, consent string) error {
+	return Options{}.ParseStringInto(dst, consent)
 }
 
 // Parse parses the TCF 2.0 "Core string" segment. This string should *not* be encoded (by base64 or any other encoding).
 // If the data is malformed and cannot be interpreted as a vendor consent string, this will return an error.
+//
+// A successful Parse allocates once, for the ConsentMetadata, unless a RangeSection has more than 16 entries
+// or the publisher restrictions have more than 8 purposes and types or 16 vendor ranges, which need room of
+// their own.
 func Parse(data []byte) (api.VendorConsents, error) {
-	metadata, err := parseMetadata(data)
+	metadata, err := ParseConsentMetadata(data)
 	if err != nil {
 		return nil, err
 	}
+	return metadata, nil
+}
 
-	var vendorConsents vendorConsentsResolver
-	var vendorLegitInts vendorConsentsResolver
+// ParseConsentMetadata works like Parse, but returns the ConsentMetadata itself, like
+// ParseStringConsentMetadata.
+func ParseConsentMetadata(data []byte) (ConsentMetadata, error) {
+	var metadata ConsentMetadata
+	if err := ParseInto(&metadata, data); err != nil {
+		return ConsentMetadata{}, err
+	}
+	return metadata, nil
+}
 
-	var legitIntStart uint
-	var pubRestrictsStart uint
-	// Bit 229 determines whether or not the consent string encodes Vendor data in a RangeSection or BitField.
-	// We know from parseMetadata that we have at least 29*8=232 bits available
-	if isSet(data, 229) {
-		vendorConsents, legitIntStart, err = parseRangeSection(metadata, metadata.MaxVendorID(), 230)
-	} else {
-		vendorConsents, legitIntStart, err = parseBitField(metadata, metadata.MaxVendorID(), 230)
+// ParseHeader reads the fixed-width fields of the TCF 2.0 "Core string", from Version to MaxVendorID,
+// without decoding the vendor sections or anything after them. Callers which only need those fields, to
+// route requests or for telemetry, can use it rather than Parse. It only checks the fields it reads, so a
+// consent string which Parse rejects may still have a Header. The Header refers to data.
+func ParseHeader(data []byte) (Header, error) {
+	if err := parseMetadata(data); err != nil {
+		return Header{}, err
+	}
+	return Header{data: data[:headerBytes:headerBytes]}, nil
+}
+
+// ParseHeaderString works like ParseHeader, but with a base64 encoded consent string, of which it only
+// decodes the start.
+func ParseHeaderString(consent string) (Header, error) {
+	if consent == "" {
+		return Header{}, consentconstants.ErrEmptyDecodedConsent
 	}
+	coreSegment, _, _ := strings.Cut(consent, string(consentStringTCF2Separator))
+	coreSegment = coreSegment[:min(len(coreSegment), headerChars)]
+	data, _, err := decodeSegment(coreSegment, make([]byte, len(coreSegment)))
 	if err != nil {
-		return nil, err
+		return Header{}, err
+	}
+	return ParseHeader(data)
+}
+
+// ParseInto works like Parse, but parses into dst and reuses the memory which dst holds from earlier calls,
+// so servers can pool ConsentMetadata values and parse consent strings without allocating. Like Parse, it
+// only reads the Core string, so dst has no Disclosed Vendors or Publisher TC segment afterwards.
+//
+// dst refers to data rather than copying it, so data mustn't change while dst is in use. Copies of dst share
+// its memory, so they change the next time dst is reused. If the data is malformed, ParseInto returns an
+// error and leaves dst empty.
+func ParseInto(dst *ConsentMetadata, data []byte) error {
+	return parseInto(dst, data, Options{})
+}
+
+// parseInto parses the Core string into dst with the options.
+func parseInto(dst *ConsentMetadata, data []byte, opts Options) error {
+	dst.Reset()
+	if dst.parsedConsent == nil {
+		dst.parsedConsent = &parsedConsent{}
 	}
+	buffers := &dst.buffers
 
-	metadata.vendorConsents = vendorConsents
-	metadata.vendorLegitimateInterestStart = legitIntStart + 17
-	legIntMaxVend, err := bitutils.ParseUInt16(data, legitIntStart)
+	layout, err := validateCore(data, !opts.SkipPublisherRestrictions)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if err := opts.checkMaxVendorID(layout.vendorConsents.maxVendorID); err != nil {
+		return err
+	}
+	if err := opts.checkMaxVendorID(layout.legitimateInterests.maxVendorID); err != nil {
+		return err
 	}
 
-	if legitIntStart+16 >= uint(len(data))*8 {
-		return nil, fmt.Errorf("invalid consent data: no legitimate interest start position")
+	dst.consentFields = consentFields{
+		Header:                        Header{data: data},
+		vendorLegitimateInterestStart: layout.legitimateInterests.start,
+		pubRestrictionsStart:          layout.pubRestrictionsStart,
+		vendorConsents:                fillSection(data, layout.vendorConsents, &buffers.vendorConsentBits, &buffers.vendorConsentRanges),
+		vendorLegitimateInterests:     fillSection(data, layout.legitimateInterests, &buffers.legitInterestBits, &buffers.legitInterestRanges),
 	}
-	if isSet(data, legitIntStart+16) {
-		vendorLegitInts, pubRestrictsStart, err = parseRangeSection(metadata, legIntMaxVend, metadata.vendorLegitimateInterestStart)
-	} else {
-		vendorLegitInts, pubRestrictsStart, err = parseBitField(metadata, legIntMaxVend, metadata.vendorLegitimateInterestStart)
+	if opts.MergeVendorLegalBases {
+		buffers.legalBases.words = buffers.legalBases.words[:0]
+		if buffers.legalBases.words == nil {
+			buffers.legalBases.words = buffers.inlineLegalBases[:0]
+		}
+		buffers.legalBases.addSection(dst.vendorConsents)
+		buffers.legalBases.addSection(dst.vendorLegitimateInterests)
+		dst.legalBases = &buffers.legalBases
 	}
-	if err != nil {
-		return nil, err
+	if opts.SkipPublisherRestrictions {
+		buffers.lazyRestrictions.reset(data, layout.pubRestrictionsStart, &buffers.restrictions)
+		dst.publisherRestrictions = &buffers.lazyRestrictions
+		return nil
 	}
+	buffers.restrictions.fill(data, layout.pubRestrictionsStart)
+	dst.publisherRestrictions = &buffers.restrictions
+	return nil
+}
+
+// Errors without details are predeclared, so returning them doesn't allocate.
+var (
+	errNoLegitimateInterestStart = parseError(ErrTruncated, nil, "invalid consent data: no legitimate interest start position")
+	errEmptySegment              = parseError(ErrInvalidSegment, nil, "empty segment string")
+	errSegmentTooShort           = parseError(ErrInvalidSegment, nil, "segment too short")
+	errVendorListVersionZero     = parseError(ErrInvalidVendorListVersion, nil, "the consent string encoded a VendorListVersion of 0, but this value must be greater than or equal to 1")
+)
 
-	metadata.vendorLegitimateInterests = vendorLegitInts
-	metadata.pubRestrictionsStart = pubRestrictsStart
+// coreLayout records where the sections of a Core string start, as validateCore found them.
+type coreLayout struct {
+	vendorConsents       sectionLayout
+	legitimateInterests  sectionLayout
+	pubRestrictionsStart uint
+}
+
+// sectionLayout describes a vendor section of a Core string.
+type sectionLayout struct {
+	start       uint // the first bit of the BitField or RangeSection
+	maxVendorID uint16
+	isRange     bool
+	numEntries  uint16 // the number of entries, if it's a RangeSection
+}
+
+// validateCore checks the whole Core string in one pass, before ParseInto builds anything from it. The
+// sections can then be read without checking bounds again, and the accessors rely on the checks too. The
+// publisher restrictions are only checked if restrictions is true.
+func validateCore(data []byte, restrictions bool) (coreLayout, error) {
+	var layout coreLayout
+	if err := parseMetadata(data); err != nil {
+		return layout, err
+	}
 
-	pubRestrictions, _, err := parsePubRestriction(metadata, pubRestrictsStart)
+	// Bit 229 determines whether or not the consent string encodes Vendor data in a RangeSection or BitField.
+	// We know from parseMetadata that we have at least 29*8=232 bits available
+	layout.vendorConsents = sectionLayout{start: 230, maxVendorID: bitsAt(data, 213, 16), isRange: isSet(data, 229)}
+	legitIntStart, err := validateSection(data, &layout.vendorConsents)
 	if err != nil {
-		return nil, err
+		return layout, err
+	}
+
+	legIntMaxVend, err := parseBits(data, legitIntStart, 16)
+	if err != nil {
+		return layout, err
+	}
+	if legitIntStart+16 >= uint(len(data))*8 {
+		return layout, errNoLegitimateInterestStart
+	}
+	layout.legitimateInterests = sectionLayout{start: legitIntStart + 17, maxVendorID: legIntMaxVend, isRange: isSet(data, legitIntStart+16)}
+	if layout.pubRestrictionsStart, err = validateSection(data, &layout.legitimateInterests); err != nil {
+		return layout, err
+	}
+
+	if restrictions {
+		_, err = validatePubRestrictions(data, layout.pubRestrictionsStart)
 	}
+	return layout, err
+}
+
+// validateSection checks a vendor section, records its number of entries if it's a RangeSection, and
+// returns the index of the first bit after it.
+func validateSection(data []byte, section *sectionLayout) (uint, error) {
+	if section.isRange {
+		numEntries, end, err := validateRangeSection(data, section.maxVendorID, section.start)
+		section.numEntries = numEntries
+		return end, err
+	}
+	return validateBitField(data, section.maxVendorID, section.start)
+}
+
+// fillSection builds a vendor section which validateCore accepted, in bits or ranges.
+func fillSection(data []byte, section sectionLayout, bits *consentBitField, ranges *rangeSection) VendorSection {
+	if section.isRange {
+		ranges.fill(data, section.maxVendorID, section.start, section.numEntries)
+		return ranges
+	}
+	*bits = consentBitField{data: data, startbit: section.start, maxVendorID: section.maxVendorID}
+	return bits
+}
+
+// parseBuffers holds the sections of a ConsentMetadata, so that ParseInto can reuse them. Small sections
+// keep their entries in arrays within the buffers, so that parsing them doesn't allocate.
+//
+// So do consent strings of up to inlineSegmentBytes, which hold the BitFields of up to about a thousand
+// vendors, and merged legal bases of vendor IDs below inlineLegalBaseVendors. Larger ones are kept in
+// slices of their own.
+type parseBuffers struct {
+	vendorConsentBits   consentBitField
+	vendorConsentRanges rangeSection
+	legitInterestBits   consentBitField
+	legitInterestRanges rangeSection
+	restrictions        pubRestrictions
+	lazyRestrictions    lazyPubRestrictions
+	legalBases          VendorSet
+	disclosedBits       consentBitField
+	disclosedRanges     rangeSection
+	publisherTC         publisherTC
+	segments            []byte
+	inlineSegments      [inlineSegmentBytes]byte
+	inlineLegalBases    [inlineLegalBaseVendors / 64]uint64
+}
+
+const (
+	inlineSegmentBytes     = 512
+	inlineLegalBaseVendors = 1024
+)
 
-	metadata.publisherRestrictions = pubRestrictions
+// ParseVendorSection parses a vendor section which starts at startbit: a 16-bit MaxVendorId, a 1-bit IsRangeEncoding
+// and then either a BitField or a RangeSection. TC strings use this layout for their vendor sections, and other
+// IAB formats borrowed it. It returns the section and the index of the first bit after it.
+func ParseVendorSection(data []byte, startbit uint) (VendorSection, uint, error) {
+	maxVendorID, err := parseBits(data, startbit, 16)
+	if err != nil {
+		return nil, 0, err
+	}
+	if startbit+16 >= uint(len(data))*8 {
+		return nil, 0, parseError(ErrTruncated, nil, "invalid vendor section: no IsRangeEncoding bit at position %d", startbit+16)
+	}
 
-	return metadata, err
+	var section VendorSection
+	var end uint
+	metadata := ConsentMetadata{&parsedConsent{consentFields: consentFields{Header: Header{data: data}}}}
+	if isSet(data, startbit+16) {
+		section, end, err = parseRangeSection(metadata, maxVendorID, startbit+17)
+	} else {
+		section, end, err = parseBitField(metadata, maxVendorID, startbit+17)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return section, end, nil
 }
 
-func parseCoreAndDisclosedVendors(consent string) (ConsentMetadata, error) {
+// parseCoreAndDisclosedVendors parses the consent string into dst with the options, whose limits allowed
+// it. If it fails, dst is left empty.
+func parseCoreAndDisclosedVendors(dst *ConsentMetadata, consent string, opts Options) error {
+	dst.Reset()
+	if dst.parsedConsent == nil {
+		dst.parsedConsent = &parsedConsent{}
+	}
+	buffers := &dst.buffers
+
 	// Split TCF 2.0 segments by '.'
 	// Format: [Core String].[Disclosed Vendors].[Publisher TC]
-	segments := strings.Split(consent, string(consentStringTCF2Separator))
+	coreSegment, segments, _ := strings.Cut(consent, string(consentStringTCF2Separator))
+
+	// Every segment is decoded into buffers.segments, unless the caller's scratch buffer has room for those
+	// after the Core string. Decoding shrinks them, so the length of the consent string is enough for all
+	// of them.
+	size := len(consent)
+	useScratch := segments != "" && len(opts.SegmentScratch) >= len(segments)
+	if useScratch {
+		size = len(coreSegment)
+	}
+	if cap(buffers.segments) < size {
+		if size <= len(buffers.inlineSegments) {
+			buffers.segments = buffers.inlineSegments[:]
+		} else {
+			buffers.segments = make([]byte, size)
+		}
+	}
+	decodeBuffer := buffers.segments[:size]
 
 	// Parse the core string (always first segment)
-	coreSegmentDecoded, err := decodeSegment(segments[0])
+	coreSegmentDecoded, decodeBuffer, err := decodeSegment(coreSegment, decodeBuffer)
 	if err != nil {
-		return ConsentMetadata{}, err
+		return err
+	}
+	if useScratch {
+		decodeBuffer = opts.SegmentScratch
 	}
 
 	// Parse the core string
-	result, err := Parse(coreSegmentDecoded)
-	if err != nil {
-		return ConsentMetadata{}, err
+	if err := parseInto(dst, coreSegmentDecoded, opts); err != nil {
+		return err
 	}
 
-	metadata := result.(ConsentMetadata)
-
-	// Parse disclosed vendors segment if present (TCF 2.3+)
-	// Iterate through segments to find disclosed vendors by type (segments after Core String segment can be in any order)
-	for _, segment := range segments[1:] {
+	// Parse the disclosed vendors (TCF 2.3+) and publisher TC segments if present
+	// Iterate through segments to find them by type (segments after Core String segment can be in any order)
+	for segments != "" {
+		var segment string
+		segment, segments, _ = strings.Cut(segments, string(consentStringTCF2Separator))
 		if segment == "" {
 			continue
 		}
 
-		decoded, err := decodeSegment(segment)
+		var decoded []byte
+		decoded, decodeBuffer, err = decodeSegment(segment, decodeBuffer)
 		if err != nil {
-			return ConsentMetadata{}, err
+			dst.Reset()
+			return err
 		}
 
 		segmentType, err := getSegmentType(decoded)
 		if err != nil {
-			return ConsentMetadata{}, err
+			dst.Reset()
+			return err
 		}
 
-		if segmentType == SegmentTypeDisclosedVendors { // Disclosed Vendors segment
-			disclosedVendors, err := parseDisclosedVendorsSegment(decoded)
+		switch segmentType {
+		case SegmentTypeDisclosedVendors:
+			disclosedVendors, err := parseDisclosedVendorsSegment(decoded, buffers)
 			if err != nil {
-				return ConsentMetadata{}, fmt.Errorf("failed to parse disclosed vendors segment: %v", err)
+				dst.Reset()
+				return parseError(ErrInvalidSegment, err, "failed to parse disclosed vendors segment")
+			}
+			if err := opts.checkMaxVendorID(disclosedVendors.MaxVendorID()); err != nil {
+				dst.Reset()
+				return err
+			}
+			dst.disclosedVendors = disclosedVendors
+			dst.hasDisclosedVendors = true
+		case SegmentTypePublisherTC:
+			// Vendors don't rely on the publisher TC segment, so a malformed one is ignored rather than
+			// rejecting a string whose core is valid. HasPublisherTC reports false for it.
+			if publisherTC, err := parsePublisherTCSegment(decoded); err == nil {
+				buffers.publisherTC = publisherTC
+				dst.publisherTC = &buffers.publisherTC
 			}
-			metadata.disclosedVendors = disclosedVendors
-			metadata.hasDisclosedVendors = true
-			break
 		}
 	}
 
-	return metadata, nil
+	return nil
 }
 
 // IsConsentV2 return true if the consent strings looks like a tcf v2 consent string
@@ -149,26 +390,27 @@ func IsConsentV2(consent string) bool {
 	return len(consent) > 0 && consent[0] == consentStringTCF2Prefix
 }
 
-// decodeSegment decodes a base64 encoded segment string.
-func decodeSegment(segmentString string) ([]byte, error) {
+// decodeSegment decodes a base64 encoded segment string into the start of buf, which must be at least as
+// long as the segment string. It returns the decoded segment and the rest of buf.
+func decodeSegment(segmentString string, buf []byte) ([]byte, []byte, error) {
 	if segmentString == "" {
-		return nil, fmt.Errorf("empty segment string")
+		return nil, buf, errEmptySegment
 	}
 
-	buff := []byte(segmentString)
-	decoded := buff
-	n, err := base64.RawURLEncoding.Decode(decoded, buff)
+	// Unless segmentBytes avoids copying the string, this decodes in place, which base64 allows because
+	// the decoded bytes never overtake the encoded ones.
+	n, err := base64.RawURLEncoding.Decode(buf, segmentBytes(segmentString, buf))
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode segment: %v", err)
+		return nil, buf, parseError(ErrInvalidSegment, err, "failed to decode segment")
 	}
 
-	return decoded[:n:n], nil
+	return buf[:n:n], buf[n:], nil
 }
 
 // getSegmentType extracts the 3-bit segment type from the segment data
 func getSegmentType(data []byte) (uint8, error) {
 	if len(data) < 1 {
-		return 0, fmt.Errorf("segment too short")
+		return 0, errSegmentTooShort
 	}
 
 	segmentType := data[0] >> 5
@@ -1,7 +1,12 @@
 package vendorconsent
 
 import (
+	"encoding/base64"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/prebid/go-gdpr/bitutils"
 )
 
 func TestParseLegitIntSetWithBitField(t *testing.T) {
@@ -15,3 +20,250 @@ func TestParseLegitIntSetWithRangeSection(t *testing.T) {
 	_, err := Parse(decode(t, "COvcSpYOvcSpYC9AAAENAPCAAAAAAAAAAAAAAFQBgAAgABAACAAEAAQAAgAA"))
 	assertError(t, err)
 }
+
+func TestParseVendorSection(t *testing.T) {
+	data := decode(t, "COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA")
+	// The vendor consent section of a TC string starts at bit 213.
+	section, end, err := ParseVendorSection(data, 213)
+	assertNilError(t, err)
+	assertUInt16sEqual(t, 10, section.MaxVendorID())
+	assertIntsEqual(t, 240, int(end))
+
+	vendorsWithConsent := buildMap(1, 2, 4, 7, 9, 10)
+	for i := uint16(1); i <= section.MaxVendorID(); i++ {
+		_, ok := vendorsWithConsent[uint(i)]
+		assertBoolsEqual(t, ok, section.VendorConsent(i))
+	}
+
+	_, _, err = ParseVendorSection(data[:27], 213)
+	assertError(t, err)
+}
+
+func TestParseConsentMetadata(t *testing.T) {
+	consent := "COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA.IAFKgA"
+	metadata, err := ParseStringConsentMetadata(consent)
+	assertNilError(t, err)
+	parsed, err := ParseString(consent)
+	assertNilError(t, err)
+	assertUInt16sEqual(t, parsed.VendorListVersion(), metadata.VendorListVersion())
+	for i := uint16(1); i <= parsed.MaxVendorID(); i++ {
+		assertBoolsEqual(t, parsed.VendorConsent(i), metadata.VendorConsent(i))
+	}
+	assertBoolsEqual(t, true, metadata.VendorDisclosed(3))
+
+	metadata, err = ParseConsentMetadata(decode(t, "COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA"))
+	assertNilError(t, err)
+	assertUInt16sEqual(t, 14, metadata.VendorListVersion())
+
+	metadata, err = ParseStringConsentMetadata("invalid")
+	assertError(t, err)
+	if metadata.parsedConsent != nil {
+		t.Errorf("ParseStringConsentMetadata should return the zero ConsentMetadata on error")
+	}
+	metadata, err = ParseConsentMetadata(nil)
+	assertError(t, err)
+	if metadata.parsedConsent != nil {
+		t.Errorf("ParseConsentMetadata should return the zero ConsentMetadata on error")
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	consents := []string{
+		"COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA.IAFKgA",
+		"COxPe2TOxPe2TALABAENAPCgAAAAAAAAAAAAAFAAAAoAAA4IACACAIABgACAFA4ADACAAIygAGADwAQBIAIAIB0AEAEBSACACAA",
+		"COyiCPlOyiCPlKxMIAENAfCAAAAAAAAAAAAAAAAAAAAA",
+	}
+	for _, consent := range consents {
+		parsed, err := ParseStringConsentMetadata(consent)
+		assertNilError(t, err)
+		header, err := ParseHeaderString(consent)
+		assertNilError(t, err)
+
+		assertUInt8sEqual(t, parsed.Version(), header.Version())
+		if !parsed.Created().Equal(header.Created()) || !parsed.LastUpdated().Equal(header.LastUpdated()) {
+			t.Errorf("Header dates %v and %v did not match %v and %v", header.Created(), header.LastUpdated(), parsed.Created(), parsed.LastUpdated())
+		}
+		assertUInt16sEqual(t, parsed.CmpID(), header.CmpID())
+		assertUInt16sEqual(t, parsed.CmpVersion(), header.CmpVersion())
+		assertStringsEqual(t, parsed.ConsentLanguage(), header.ConsentLanguage())
+		assertUInt16sEqual(t, parsed.VendorListVersion(), header.VendorListVersion())
+		assertUInt8sEqual(t, parsed.TCFPolicyVersion(), header.TCFPolicyVersion())
+		assertUInt16sEqual(t, parsed.MaxVendorID(), header.MaxVendorID())
+		assertStringsEqual(t, parsed.PublisherCC(), header.PublisherCC())
+		if parsed.PurposeConsentMask() != header.PurposeConsentMask() {
+			t.Errorf("Header purposes %x did not match %x", header.PurposeConsentMask(), parsed.PurposeConsentMask())
+		}
+
+		header, err = ParseHeader(decode(t, strings.Split(consent, ".")[0]))
+		assertNilError(t, err)
+		assertUInt16sEqual(t, parsed.CmpID(), header.CmpID())
+	}
+
+	// The vendor sections aren't read, so a string which is cut within them still has a header.
+	_, err := ParseString("COvcSpYOvcSpYC9AAAENAPCAAAAAAAAAAAAAAFAAAAA")
+	assertError(t, err)
+	header, err := ParseHeaderString("COvcSpYOvcSpYC9AAAENAPCAAAAAAAAAAAAAAFAAAAA")
+	assertNilError(t, err)
+	assertUInt16sEqual(t, 10, header.MaxVendorID())
+
+	for _, consent := range []string{"", "COwGVJOOwGVJOADACHENAOCAAO6as", "BONciguONcjGKADACHENAOLS1rAHDAFAAEAASABQAMwAeACEAFw", "CO!GVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA"} {
+		_, err := ParseHeaderString(consent)
+		assertError(t, err)
+	}
+}
+
+func TestParseHeaderStringAllocations(t *testing.T) {
+	consent := "COxPe2TOxPe2TALABAENAPCgAAAAAAAAAAAAAFAAAAoAAA4IACACAIABgACAFA4ADACAAIygAGADwAQBIAIAIB0AEAEBSACACAA"
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := ParseHeaderString(consent); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs > 1 {
+		t.Errorf("ParseHeaderString made %v allocations", allocs)
+	}
+}
+
+func TestParseInto(t *testing.T) {
+	// These use a BitField, RangeSections and publisher restrictions, so ParseInto switches between sections of
+	// each kind while it reuses dst.
+	consents := []string{
+		"COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA",
+		"COxPe2TOxPe2TALABAENAPCgAAAAAAAAAAAAAFAAAAoAAA4IACACAIABgACAFA4ADACAAIygAGADwAQBIAIAIB0AEAEBSACACAA",
+		"COwAdDhOwAdDhN4ABAENAPCgAAQAAv___wAAAFP_AAp_4AI6ACACAA",
+		"COzSDo9OzSDo9B9AAAENAiCAALAAAAAAAAAACOQAQCOAAAAA",
+		"COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA",
+	}
+	var dst ConsentMetadata
+	for _, consent := range consents {
+		data := decode(t, consent)
+		parsed, err := Parse(data)
+		assertNilError(t, err)
+		expected := parsed.(ConsentMetadata)
+		assertNilError(t, ParseInto(&dst, data))
+
+		assertUInt16sEqual(t, expected.MaxVendorID(), dst.MaxVendorID())
+		for i := uint16(1); i <= expected.MaxVendorID(); i++ {
+			assertBoolsEqual(t, expected.VendorConsent(i), dst.VendorConsent(i))
+		}
+		assertUInt16sEqual(t, expected.VendorLegitInterestMaxID(), dst.VendorLegitInterestMaxID())
+		for i := uint16(1); i <= dst.VendorLegitInterestMaxID(); i++ {
+			assertBoolsEqual(t, expected.VendorLegitInterest(i), dst.VendorLegitInterest(i))
+		}
+		expectedRestrictions := expected.PublisherRestrictions()
+		if !reflect.DeepEqual(expectedRestrictions, dst.PublisherRestrictions()) {
+			t.Errorf("Publisher restrictions %v did not match %v", dst.PublisherRestrictions(), expectedRestrictions)
+		}
+	}
+}
+
+func TestParseIntoError(t *testing.T) {
+	var dst ConsentMetadata
+	assertNilError(t, ParseInto(&dst, decode(t, "COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA")))
+	assertError(t, ParseInto(&dst, decode(t, "COvcSpYOvcSpYC9AAAENAPCAAAAAAAAAAAAAAFAAAAA")))
+	if dst.data != nil || dst.vendorConsents != nil {
+		t.Errorf("ParseInto should leave dst empty on error")
+	}
+}
+
+func TestParseIntoAllocations(t *testing.T) {
+	data := decode(t, "COxPe2TOxPe2TALABAENAPCgAAAAAAAAAAAAAFAAAAoAAA4IACACAIABgACAFA4ADACAAIygAGADwAQBIAIAIB0AEAEBSACACAA")
+	var dst ConsentMetadata
+	assertNilError(t, ParseInto(&dst, data))
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := ParseInto(&dst, data); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("ParseInto made %v allocations when reusing dst", allocs)
+	}
+}
+
+func TestParseAllocations(t *testing.T) {
+	// These use a BitField, RangeSections and publisher restrictions.
+	consents := []string{
+		"COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA",
+		"COxPe2TOxPe2TALABAENAPCgAAAAAAAAAAAAAFAAAAoAAA4IACACAIABgACAFA4ADACAAIygAGADwAQBIAIAIB0AEAEBSACACAA",
+		"COyiILmOyiILmADACHENAPCAAAAAAAAAAAAAE5QBgALgAqgD8AQACSwEygJyAAAAAA",
+	}
+	for _, consent := range consents {
+		data := decode(t, consent)
+		allocs := testing.AllocsPerRun(100, func() {
+			if _, err := Parse(data); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if allocs > 1 {
+			t.Errorf("Parse made %v allocations for %s", allocs, consent)
+		}
+	}
+}
+
+func TestParseStringAllocations(t *testing.T) {
+	// The core string has publisher restrictions, and a Disclosed Vendors segment follows it.
+	consent := "COxPe2TOxPe2TALABAENAPCgAAAAAAAAAAAAAFAAAAoAAA4IACACAIABgACAFA4ADACAAIygAGADwAQBIAIAIB0AEAEBSACACAA.IAFKgA"
+	for _, options := range []Options{{}, {MergeVendorLegalBases: true}} {
+		allocs := testing.AllocsPerRun(100, func() {
+			if _, err := options.ParseString(consent); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if allocs > 1 {
+			t.Errorf("ParseString made %v allocations with %+v", allocs, options)
+		}
+	}
+}
+
+func TestParseStringLong(t *testing.T) {
+	// A string longer than the inline buffer is decoded into a slice of its own.
+	var w bitutils.Writer
+	w.WriteBits(SegmentTypeDisclosedVendors, 3)
+	w.WriteBits(4000, 16)
+	w.WriteBits(0, 1)
+	for id := 1; id <= 4000; id++ {
+		w.WriteBits(uint64(id%2), 1)
+	}
+	disclosed := base64.RawURLEncoding.EncodeToString(w.Bytes())
+
+	parsed, err := ParseString("COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA." + disclosed)
+	assertNilError(t, err)
+	metadata := parsed.(ConsentMetadata)
+	assertUInt16sEqual(t, 4000, metadata.VendorDisclosedMaxVendorId())
+	assertBoolsEqual(t, true, metadata.VendorDisclosed(3999))
+	assertBoolsEqual(t, false, metadata.VendorDisclosed(4000))
+	assertIntsEqual(t, 2000, metadata.CountDisclosedVendors())
+}
+
+func TestParseStringIntoAllocations(t *testing.T) {
+	// The core string has publisher restrictions, and a Disclosed Vendors segment follows it.
+	consent := "COxPe2TOxPe2TALABAENAPCgAAAAAAAAAAAAAFAAAAoAAA4IACACAIABgACAFA4ADACAAIygAGADwAQBIAIAIB0AEAEBSACACAA.IAFKgA"
+	var dst ConsentMetadata
+	assertNilError(t, ParseStringInto(&dst, consent))
+	assertBoolsEqual(t, true, dst.VendorDisclosed(3))
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := ParseStringInto(&dst, consent); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("ParseStringInto made %v allocations when reusing dst", allocs)
+	}
+}
+
+func TestParseStringEmptySegments(t *testing.T) {
+	core := "COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA"
+	disclosed := "IAFKgA" // vendors 1, 3 and 5
+
+	for _, consent := range []string{core + ".", core + "..", core + ".." + disclosed, core + "." + disclosed + "."} {
+		parsed, err := ParseString(consent)
+		assertNilError(t, err)
+		assertUInt16sEqual(t, 14, parsed.VendorListVersion())
+		assertBoolsEqual(t, consent != core+"." && consent != core+"..", parsed.(ConsentMetadata).HasDisclosedVendors())
+	}
+
+	for _, consent := range []string{".", "." + core, "." + disclosed} {
+		_, err := ParseString(consent)
+		assertError(t, err)
+	}
+}
@@ -0,0 +1,73 @@
+package vendorconsent
+
+import "github.com/prebid/go-gdpr/bitutils"
+
+// CountVendorConsents returns the number of vendors with the user's consent. It counts the set bits of a
+// BitField a word at a time, and adds up the lengths of a RangeSection's entries, rather than checking each
+// vendor.
+func (c ConsentMetadata) CountVendorConsents() int {
+	return countSection(c.vendorConsents)
+}
+
+// CountVendorLegitimateInterests returns the number of vendors with legitimate interest established, like
+// CountVendorConsents.
+func (c ConsentMetadata) CountVendorLegitimateInterests() int {
+	return countSection(c.vendorLegitimateInterests)
+}
+
+// CountDisclosedVendors returns the number of vendors disclosed to the user, like CountVendorConsents. It's 0
+// if the consent string has no Disclosed Vendors segment.
+func (c ConsentMetadata) CountDisclosedVendors() int {
+	return countSection(c.disclosedVendors)
+}
+
+// countSection returns the number of vendors in the section. A nil section has no vendors.
+func countSection(section VendorSection) int {
+	switch section := section.(type) {
+	case nil:
+		return 0
+	case *consentBitField:
+		if section == nil {
+			return 0
+		}
+		// Parsing checked that the data holds the whole BitField, so this can't fail.
+		count, _ := bitutils.CountSetBits(section.data, section.startbit, uint(section.maxVendorID))
+		return count
+	case *rangeSection:
+		if section == nil {
+			return 0
+		}
+		return section.count()
+	default:
+		count := 0
+		for id := uint16(1); id <= section.MaxVendorID() && id != 0; id++ {
+			if section.VendorConsent(id) {
+				count++
+			}
+		}
+		return count
+	}
+}
+
+// count returns the number of vendors in the section.
+func (p *rangeSection) count() int {
+	count := 0
+	for _, entry := range p.disjointRanges() {
+		count += int(entry.endID) - int(entry.startID) + 1
+	}
+	return count
+}
+
+// disjointRanges returns the section's vendors as sorted ranges which don't overlap: the index if there is
+// one, the entries if they're already sorted and disjoint, and otherwise a merged copy of them.
+func (p *rangeSection) disjointRanges() []rangeConsent {
+	if len(p.index) > 0 {
+		return p.index
+	}
+	for i := 1; i < len(p.consents); i++ {
+		if p.consents[i].startID <= p.consents[i-1].endID {
+			return mergeRanges(append([]rangeConsent(nil), p.consents...))
+		}
+	}
+	return p.consents
+}
@@ -0,0 +1,50 @@
+package vendorconsent
+
+import "testing"
+
+func TestCountVendors(t *testing.T) {
+	consents := []string{
+		"COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA",
+		"COyfVVoOyfVVoADACHENAwCAAAAAAAAAAAAAE5QBgALgAqgD8AQACSwEygJyAnSAMABgAFkAgQCDASeAmYBOgAA.IAFKgA",
+		"COwAdDhOwAdDhN4ABAENAPCgAAQAAv___wAAAFP_AAp_4AI6ACACAA",
+	}
+	for _, consent := range consents {
+		parsed, err := ParseString(consent)
+		assertNilError(t, err)
+		metadata := parsed.(ConsentMetadata)
+
+		var consented, legitimateInterests, disclosed int
+		for id := uint16(1); id <= 1000; id++ {
+			if metadata.VendorConsent(id) {
+				consented++
+			}
+			if metadata.VendorLegitInterest(id) {
+				legitimateInterests++
+			}
+			if metadata.VendorDisclosed(id) {
+				disclosed++
+			}
+		}
+		assertIntsEqual(t, consented, metadata.CountVendorConsents())
+		assertIntsEqual(t, legitimateInterests, metadata.CountVendorLegitimateInterests())
+		assertIntsEqual(t, disclosed, metadata.CountDisclosedVendors())
+	}
+}
+
+func TestRangeSectionCount(t *testing.T) {
+	section := &rangeSection{
+		maxVendorID: 100,
+		consents: []rangeConsent{
+			{startID: 50, endID: 60},
+			{startID: 3, endID: 3},
+			{startID: 55, endID: 70},
+			{startID: 4, endID: 10},
+		},
+	}
+	assertIntsEqual(t, 29, section.count())
+	// Counting mustn't reorder the entries.
+	assertUInt16sEqual(t, 50, section.consents[0].startID)
+
+	section.consents = []rangeConsent{{startID: 3, endID: 3}, {startID: 4, endID: 10}, {startID: 50, endID: 70}}
+	assertIntsEqual(t, 29, section.count())
+}
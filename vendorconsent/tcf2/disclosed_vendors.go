@@ -1,56 +1,47 @@
 package vendorconsent
 
-import (
-	"fmt"
-
-	"github.com/prebid/go-gdpr/bitutils"
-)
+var errEmptyDisclosedVendors = parseError(ErrInvalidSegment, nil, "data is empty")
 
 // parseDisclosedVendorsSegment parses the Disclosed Vendors segment (SegmentType=1).
-// This segment is mandatory in TCF 2.3.
-func parseDisclosedVendorsSegment(data []byte) (vendorConsentsResolver, error) {
+// This segment is mandatory in TCF 2.3. The section it returns is one of the buffers.
+func parseDisclosedVendorsSegment(data []byte, buffers *parseBuffers) (VendorSection, error) {
 	if len(data) == 0 {
-		return nil, fmt.Errorf("data is empty")
+		return nil, errEmptyDisclosedVendors
 	}
 
 	// Need at least 3 bits for segment type + 16 bits for MaxVendorId + 1 bit for IsRangeEncoding
 	if len(data) < 3 {
-		return nil, fmt.Errorf("segment too short: %d bytes, need at least 3", len(data))
+		return nil, parseError(ErrInvalidSegment, nil, "segment too short: %d bytes, need at least 3", uint(len(data)))
 	}
 
-	segmentType, err := bitutils.ParseByte8(data, 0)
+	segmentType, err := parseBits(data, 0, 8)
 	if err != nil {
-		return nil, fmt.Errorf("parse segment type: %v", err)
+		return nil, parseError(ErrInvalidSegment, err, "parse segment type")
 	}
 	segmentType = segmentType >> 5 // Get first 3 bits
 
 	if segmentType != SegmentTypeDisclosedVendors {
-		return nil, fmt.Errorf("expected segment type 1, got %d", segmentType)
+		return nil, parseError(ErrInvalidSegment, nil, "expected segment type 1, got %d", uint(segmentType))
 	}
 
-	maxVendorID, err := bitutils.ParseUInt16(data, 3)
+	maxVendorID, err := parseBits(data, 3, 16)
 	if err != nil {
-		return nil, fmt.Errorf("parse MaxVendorId: %v", err)
+		return nil, parseError(ErrInvalidSegment, err, "parse MaxVendorId")
 	}
 
 	// IsRangeEncoding is at bit 19 (0-based indexing)
 	// see https://github.com/InteractiveAdvertisingBureau/GDPR-Transparency-and-Consent-Framework/blob/master/TCFv2/IAB%20Tech%20Lab%20-%20Consent%20string%20and%20vendor%20list%20formats%20v2.md#disclosed-vendors)
 	isRangeEncoding := isSet(data, 19)
 
-	// Create a temporary metadata just for parsing purposes
-	tempMetadata := ConsentMetadata{data: data}
-
 	if isRangeEncoding {
-		rangeSection, _, err := parseRangeSection(tempMetadata, maxVendorID, 20)
-		if err != nil {
-			return nil, fmt.Errorf("parse range section: %v", err)
+		if _, err := buffers.disclosedRanges.parse(data, maxVendorID, 20); err != nil {
+			return nil, parseError(ErrInvalidSegment, err, "parse range section")
 		}
-		return rangeSection, nil
+		return &buffers.disclosedRanges, nil
 	}
 
-	bitField, _, err := parseBitField(tempMetadata, maxVendorID, 20)
-	if err != nil {
-		return nil, fmt.Errorf("parse bit field: %v", err)
+	if _, err := buffers.disclosedBits.parse(data, maxVendorID, 20); err != nil {
+		return nil, parseError(ErrInvalidSegment, err, "parse bit field")
 	}
-	return bitField, nil
+	return &buffers.disclosedBits, nil
 }
@@ -0,0 +1,63 @@
+package vendorconsent
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The kinds of problem a ParseError reports. Check for them with errors.Is.
+var (
+	// ErrTruncated means the data ends before a field which the consent string declares.
+	ErrTruncated = errors.New("the consent string is truncated")
+	// ErrUnsupportedVersion means the consent string encoded a Version below 2.
+	ErrUnsupportedVersion = errors.New("the consent string's version is not supported")
+	// ErrInvalidVendorListVersion means the consent string encoded a VendorListVersion of 0.
+	ErrInvalidVendorListVersion = errors.New("the consent string's vendor list version is invalid")
+	// ErrInvalidRangeEntry means a RangeEntry holds vendor IDs which its section doesn't allow.
+	ErrInvalidRangeEntry = errors.New("the consent string has an invalid range entry")
+	// ErrInvalidSegment means a segment of the consent string couldn't be decoded, or isn't of the type
+	// expected.
+	ErrInvalidSegment = errors.New("the consent string has an invalid segment")
+)
+
+// ParseError describes why a consent string is malformed. Malformed strings are common in real traffic,
+// so the message is only formatted if Error is called, and errors without details are predeclared.
+type ParseError struct {
+	kind   error
+	cause  error
+	format string
+	args   [3]uint
+	nargs  uint8
+}
+
+// parseError returns a ParseError of the given kind. Its message is the format applied to args (at most 3),
+// followed by the cause's message if there is one.
+func parseError(kind error, cause error, format string, args ...uint) *ParseError {
+	err := &ParseError{kind: kind, cause: cause, format: format}
+	err.nargs = uint8(copy(err.args[:], args))
+	return err
+}
+
+func (e *ParseError) Error() string {
+	var args [3]any
+	for i := range e.args[:e.nargs] {
+		args[i] = e.args[i]
+	}
+	message := fmt.Sprintf(e.format, args[:e.nargs]...)
+	switch {
+	case e.cause == nil:
+		return message
+	case message == "":
+		return e.cause.Error()
+	default:
+		return message + ": " + e.cause.Error()
+	}
+}
+
+// Unwrap returns the kind of the error, and its cause if it has one.
+func (e *ParseError) Unwrap() []error {
+	if e.cause == nil {
+		return []error{e.kind}
+	}
+	return []error{e.kind, e.cause}
+}
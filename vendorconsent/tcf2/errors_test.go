@@ -0,0 +1,54 @@
+package vendorconsent
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseErrorKinds(t *testing.T) {
+	testCases := []struct {
+		consent string
+		kind    error
+		message string
+	}{
+		{"CONciguONcjGKADACHENAOCIAC0ta__AACiQAA", ErrTruncated, "vendor consent strings are at least 29 bytes long. This one was 28"},
+		{"BONciguONcjGKADACHENAOCIAC0ta__AACiQABgAAYA", ErrUnsupportedVersion, "the consent string encoded a Version of 1, but this value must be greater than or equal to 2"},
+		{"CONciguONcjGKADACHENAACIAC0ta__AACiQABgAAYA", ErrInvalidVendorListVersion, "the consent string encoded a VendorListVersion of 0, but this value must be greater than or equal to 1"},
+		{"CONciguONcjGKADACHENAOCIAC0ta__AACiQAeAA", ErrTruncated, "a BitField for 60 vendors requires a consent string of 37 bytes. This consent string had 30"},
+		{"CONciguONcjGKADACHENAOCIAC0ta__AACiQABwAQQ", ErrTruncated, "ParseUInt16 expected a 16-bit int to start at bit 243, but the consent string was only 31 bytes long"},
+		{"CONciguONcjGKADACHENAOCIAC0ta__AACiQABwAgACAAA", ErrInvalidRangeEntry, "bit 242 range entry excludes vendor 4, but only vendors [1, 3] are valid"},
+		{"COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA.IA", ErrInvalidSegment, "failed to parse disclosed vendors segment: segment too short: 1 bytes, need at least 3"},
+		{"COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA.!!", ErrInvalidSegment, "failed to decode segment: illegal base64 data at input byte 0"},
+	}
+	for _, test := range testCases {
+		_, err := ParseString(test.consent)
+		if !errors.Is(err, test.kind) {
+			t.Errorf("Parsing %s should have failed with %v. Got %v", test.consent, test.kind, err)
+			continue
+		}
+		assertStringsEqual(t, test.message, err.Error())
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Parsing %s should have failed with a ParseError. Got %T", test.consent, err)
+		}
+	}
+}
+
+func TestParseErrorAllocations(t *testing.T) {
+	data := decode(t, "COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA")
+	var dst ConsentMetadata
+	// The error is created once, and only formatted if its message is read.
+	allocs := testing.AllocsPerRun(100, func() {
+		ParseInto(&dst, data[:29])
+	})
+	if allocs != 1 {
+		t.Errorf("Rejecting a truncated BitField made %v allocations", allocs)
+	}
+	// Errors without details are predeclared.
+	allocs = testing.AllocsPerRun(100, func() {
+		ParseStringInto(&dst, ".IAFKgA")
+	})
+	if allocs != 0 {
+		t.Errorf("Rejecting an empty core string made %v allocations", allocs)
+	}
+}
@@ -0,0 +1,55 @@
+package vendorconsent
+
+import (
+	"iter"
+
+	"github.com/prebid/go-gdpr/bitutils"
+)
+
+// ConsentedVendors returns an iterator over the vendors with the user's consent, in ascending order. It
+// walks the set bits of a BitField or the entries of a RangeSection, so it's much quicker than calling
+// VendorConsent for every vendor up to MaxVendorID.
+func (c ConsentMetadata) ConsentedVendors() iter.Seq[uint16] {
+	return sectionVendors(c.vendorConsents)
+}
+
+// sectionVendors returns an iterator over the vendors in the section, in ascending order. A nil section has
+// no vendors.
+func sectionVendors(section VendorSection) iter.Seq[uint16] {
+	return func(yield func(uint16) bool) {
+		switch section := section.(type) {
+		case nil:
+		case *consentBitField:
+			if section == nil {
+				return
+			}
+			// Parsing checked that the data holds the whole BitField, so this can't fail.
+			setBits, _ := bitutils.SetBits(section.data, section.startbit, uint(section.maxVendorID))
+			for index := range setBits {
+				if !yield(uint16(index + 1)) {
+					return
+				}
+			}
+		case *rangeSection:
+			if section == nil {
+				return
+			}
+			for _, entry := range section.disjointRanges() {
+				for id := entry.startID; ; id++ {
+					if !yield(id) {
+						return
+					}
+					if id == entry.endID {
+						break
+					}
+				}
+			}
+		default:
+			for id := uint16(1); id <= section.MaxVendorID() && id != 0; id++ {
+				if section.VendorConsent(id) && !yield(id) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package vendorconsent
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestConsentedVendors(t *testing.T) {
+	// These encode their vendor consents as a BitField and as RangeSections.
+	consents := []string{
+		"COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA",
+		"COyfVVoOyfVVoADACHENAwCAAAAAAAAAAAAAE5QBgALgAqgD8AQACSwEygJyAnSAMABgAFkAgQCDASeAmYBOgAA",
+		"COwAdDhOwAdDhN4ABAENAPCgAAQAAv___wAAAFP_AAp_4AI6ACACAA",
+	}
+	for _, consent := range consents {
+		parsed, err := ParseString(consent)
+		assertNilError(t, err)
+		metadata := parsed.(ConsentMetadata)
+
+		var expected []uint16
+		for id := uint16(1); id <= metadata.MaxVendorID(); id++ {
+			if metadata.VendorConsent(id) {
+				expected = append(expected, id)
+			}
+		}
+		assertUInt16SlicesEqual(t, expected, slices.Collect(metadata.ConsentedVendors()))
+
+		// The loop panics if the iterator keeps yielding after it stops.
+		for range metadata.ConsentedVendors() {
+			break
+		}
+	}
+}
+
+func TestRangeSectionVendors(t *testing.T) {
+	section := &rangeSection{
+		maxVendorID: 65535,
+		consents: []rangeConsent{
+			{startID: 65533, endID: 65535},
+			{startID: 3, endID: 3},
+			{startID: 2, endID: 4},
+		},
+	}
+	assertUInt16SlicesEqual(t, []uint16{2, 3, 4, 65533, 65534, 65535}, slices.Collect(sectionVendors(section)))
+	assertUInt16SlicesEqual(t, nil, slices.Collect(sectionVendors(nil)))
+}
@@ -0,0 +1,46 @@
+package vendorconsent
+
+import (
+	"errors"
+
+	"github.com/prebid/go-gdpr/api"
+)
+
+// ErrLimitExceeded is the kind of ParseError returned for consent strings which exceed Limits.
+var ErrLimitExceeded = errors.New("the consent string exceeds a parse limit")
+
+// Limits bound the consent strings which its ParseString and ParseStringInto accept, so that untrusted
+// input can't make the parser allocate large buffers. Strings beyond them are rejected with a ParseError of
+// kind ErrLimitExceeded before they are decoded. Zero fields mean no limit, so the zero Limits behave like
+// the package's ParseString and ParseStringInto.
+type Limits struct {
+	// MaxLength is the longest consent string accepted, in bytes.
+	MaxLength int `json:"maxLength"`
+	// MaxSegments is the most segments accepted, counting the Core string and any empty segments.
+	MaxSegments int `json:"maxSegments"`
+	// MaxVendorID is the highest MaxVendorId accepted for the vendor consents, the legitimate interests
+	// and the disclosed vendors.
+	MaxVendorID uint16 `json:"maxVendorID"`
+}
+
+// ParseString works like the package's ParseString, but rejects consent strings beyond the limits.
+func (l Limits) ParseString(consent string) (api.VendorConsents, error) {
+	var metadata ConsentMetadata
+	if err := l.ParseStringInto(&metadata, consent); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// ParseStringInto works like the package's ParseStringInto, but rejects consent strings beyond the limits.
+func (l Limits) ParseStringInto(dst *ConsentMetadata, consent string) error {
+	return Options{Limits: l}.ParseStringInto(dst, consent)
+}
+
+// checkMaxVendorID returns an error if a vendor section's MaxVendorId is beyond the limit.
+func (l Limits) checkMaxVendorID(maxVendorID uint16) error {
+	if l.MaxVendorID > 0 && maxVendorID > l.MaxVendorID {
+		return parseError(ErrLimitExceeded, nil, "the consent string has a MaxVendorId of %d, but at most %d is allowed", uint(maxVendorID), uint(l.MaxVendorID))
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package vendorconsent
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	// The core string has a MaxVendorId of 10 for its vendor consents, and so does the Disclosed Vendors
+	// segment.
+	core := "COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA"
+	consent := core + ".IAFKgA"
+
+	testCases := []struct {
+		name    string
+		limits  Limits
+		consent string
+		message string
+	}{
+		{"no limits", Limits{}, consent, ""},
+		{"within limits", Limits{MaxLength: len(consent), MaxSegments: 2, MaxVendorID: 10}, consent, ""},
+		{"too long", Limits{MaxLength: len(consent) - 1}, consent, "the consent string is 54 bytes long, but at most 53 are allowed"},
+		{"too many segments", Limits{MaxSegments: 2}, consent + ".", "the consent string has 3 segments, but at most 2 are allowed"},
+		{"vendor consents", Limits{MaxVendorID: 9}, core, "the consent string has a MaxVendorId of 10, but at most 9 is allowed"},
+		{"disclosed vendors", Limits{MaxVendorID: 10}, core + ".IAFqgA", "the consent string has a MaxVendorId of 11, but at most 10 is allowed"},
+	}
+	for _, test := range testCases {
+		parsed, err := test.limits.ParseString(test.consent)
+		if test.message == "" {
+			assertNilError(t, err)
+			assertUInt16sEqual(t, 14, parsed.VendorListVersion())
+			continue
+		}
+		if !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("%s: expected ErrLimitExceeded. Got %v", test.name, err)
+			continue
+		}
+		assertStringsEqual(t, test.message, err.Error())
+	}
+
+	var dst ConsentMetadata
+	assertError(t, Limits{MaxVendorID: 9}.ParseStringInto(&dst, consent))
+	if dst.data != nil {
+		t.Errorf("ParseStringInto should leave dst empty when a limit is exceeded")
+	}
+	assertError(t, Limits{}.ParseStringInto(&dst, ""))
+}
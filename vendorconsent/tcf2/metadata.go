@@ -1,67 +1,89 @@
 package vendorconsent
 
 import (
+	"crypto/sha256"
 	"encoding/binary"
-	"errors"
-	"fmt"
+	"math/bits"
 	"time"
 
 	"github.com/prebid/go-gdpr/consentconstants"
 )
 
-var (
-	errInvalidVendorListVersion = errors.New("the consent string encoded a VendorListVersion of 0, but this value must be greater than or equal to 1")
-)
-
-// parseMetadata parses the metadata from the consent string.
+// parseMetadata checks the metadata of the consent string.
 // This returns an error if the input is too short to answer questions about that data.
-func parseMetadata(data []byte) (ConsentMetadata, error) {
-	if len(data) < 29 {
-		return ConsentMetadata{}, fmt.Errorf("vendor consent strings are at least 29 bytes long. This one was %d", len(data))
+func parseMetadata(data []byte) error {
+	if len(data) < headerBytes {
+		return parseError(ErrTruncated, nil, "vendor consent strings are at least 29 bytes long. This one was %d", uint(len(data)))
 	}
-	metadata := ConsentMetadata{
-		data: data,
+	header := Header{data: data}
+	if version := header.Version(); version < 2 {
+		return parseError(ErrUnsupportedVersion, nil, "the consent string encoded a Version of %d, but this value must be greater than or equal to 2", uint(version))
 	}
-	if metadata.Version() < 2 {
-		version := metadata.Version()
-		metadata.data = nil
-		return metadata, fmt.Errorf("the consent string encoded a Version of %d, but this value must be greater than or equal to 2", version)
+	if header.VendorListVersion() == 0 {
+		return errVendorListVersionZero
 	}
-	if metadata.VendorListVersion() == 0 {
-		metadata.data = nil
-		return metadata, errInvalidVendorListVersion
-
-	}
-	return metadata, nil
+	return nil
 }
 
 // ConsentMetadata implements the parts of the VendorConsents interface which are common
 // to BitFields and RangeSections. This relies on Parse to have done some validation already,
 // to make sure that functions on it don't overflow the bounds of the byte array.
+//
+// ConsentMetadata only points to the parsed consent, which holds the sections and the memory they use, so
+// Parse allocates once and converting it to api.VendorConsents doesn't allocate. Copies share the parsed
+// consent.
 type ConsentMetadata struct {
-	data                          []byte
+	*parsedConsent
+}
+
+// parsedConsent is what a ConsentMetadata points to. Its sections point into buffers.
+type parsedConsent struct {
+	consentFields
+	buffers parseBuffers // memory which ParseInto reuses
+}
+
+// consentFields are the parts of a parsedConsent which Reset clears.
+type consentFields struct {
+	Header
 	vendorLegitimateInterestStart uint
 	pubRestrictionsStart          uint
-	vendorConsents                vendorConsentsResolver
-	vendorLegitimateInterests     vendorConsentsResolver
+	vendorConsents                VendorSection
+	vendorLegitimateInterests     VendorSection
 	publisherRestrictions         pubRestrictResolver
-	disclosedVendors              vendorConsentsResolver // TCF 2.3: Disclosed Vendors segment
-	hasDisclosedVendors           bool                   // TCF 2.3: whether the Disclosed Vendors segment was present
+	disclosedVendors              VendorSection // TCF 2.3: Disclosed Vendors segment
+	hasDisclosedVendors           bool          // TCF 2.3: whether the Disclosed Vendors segment was present
+	publisherTC                   *publisherTC  // Publisher TC segment, or nil if it's missing
+	legalBases                    *VendorSet    // vendors with consent or legitimate interest, if merged
 }
 
-type vendorConsentsResolver interface {
+// Header holds the fixed-width fields at the start of the Core string, from Version to MaxVendorID. A
+// ConsentMetadata has all of its methods. See ParseHeader.
+type Header struct {
+	data []byte
+}
+
+// headerBytes is the length of the fixed-width fields, and headerChars the number of base64 characters
+// which encode them.
+const (
+	headerBytes = 29
+	headerChars = (headerBytes*8 + 5) / 6
+)
+
+// VendorSection is a decoded list of vendors: either a BitField or a RangeSection.
+type VendorSection interface {
 	MaxVendorID() uint16
 	VendorConsent(id uint16) bool
 }
 
 type pubRestrictResolver interface {
 	CheckPubRestriction(purposeID uint8, restrictType uint8, vendor uint16) bool
+	PublisherRestrictions() []PublisherRestriction
 }
 
 // Version returns the version stored in the first 6 bits
-func (c ConsentMetadata) Version() uint8 {
+func (h Header) Version() uint8 {
 	// Stored in bits 0-5
-	return uint8(c.data[0] >> 2)
+	return uint8(h.data[0] >> 2)
 }
 
 const (
@@ -70,65 +92,65 @@ const (
 )
 
 // Created returns the created date stored in bits 7 to 42
-func (c ConsentMetadata) Created() time.Time {
+func (h Header) Created() time.Time {
 	// Stored in bits 6-41.. which is [000000xx xxxxxxxx xxxxxxxx xxxxxxxx xxxxxxxx xx000000] starting at the 1st byte
 	deciseconds := int64(binary.BigEndian.Uint64([]byte{
 		0x0,
 		0x0,
 		0x0,
-		(c.data[0]&0x3)<<2 | c.data[1]>>6,
-		c.data[1]<<2 | c.data[2]>>6,
-		c.data[2]<<2 | c.data[3]>>6,
-		c.data[3]<<2 | c.data[4]>>6,
-		c.data[4]<<2 | c.data[5]>>6,
+		(h.data[0]&0x3)<<2 | h.data[1]>>6,
+		h.data[1]<<2 | h.data[2]>>6,
+		h.data[2]<<2 | h.data[3]>>6,
+		h.data[3]<<2 | h.data[4]>>6,
+		h.data[4]<<2 | h.data[5]>>6,
 	}))
 	return time.Unix(deciseconds/decisPerOne, (deciseconds%decisPerOne)*nanosPerDeci)
 }
 
 // LastUpdated returns the last updated date stored in bits 43 to 78
-func (c ConsentMetadata) LastUpdated() time.Time {
+func (h Header) LastUpdated() time.Time {
 	// Stored in bits 42-77... which is [00xxxxxx xxxxxxxx xxxxxxxx xxxxxxxx xxxxxx00 ] starting at the 6th byte
 	deciseconds := int64(binary.BigEndian.Uint64([]byte{
 		0x0,
 		0x0,
 		0x0,
-		(c.data[5] >> 2) & 0x0f,
-		c.data[5]<<6 | c.data[6]>>2,
-		c.data[6]<<6 | c.data[7]>>2,
-		c.data[7]<<6 | c.data[8]>>2,
-		c.data[8]<<6 | c.data[9]>>2,
+		(h.data[5] >> 2) & 0x0f,
+		h.data[5]<<6 | h.data[6]>>2,
+		h.data[6]<<6 | h.data[7]>>2,
+		h.data[7]<<6 | h.data[8]>>2,
+		h.data[8]<<6 | h.data[9]>>2,
 	}))
 	return time.Unix(deciseconds/decisPerOne, (deciseconds%decisPerOne)*nanosPerDeci)
 }
 
 // CmpID returns the Consent Management Platform identifier stored in bits 79 to 90
-func (c ConsentMetadata) CmpID() uint16 {
+func (h Header) CmpID() uint16 {
 	// Stored in bits 78-89... which is [000000xx xxxxxxxx xx000000] starting at the 10th byte
-	leftByte := ((c.data[9] & 0x03) << 2) | c.data[10]>>6
-	rightByte := (c.data[10] << 2) | c.data[11]>>6
+	leftByte := ((h.data[9] & 0x03) << 2) | h.data[10]>>6
+	rightByte := (h.data[10] << 2) | h.data[11]>>6
 	return binary.BigEndian.Uint16([]byte{leftByte, rightByte})
 }
 
 // CmpVersion returns the Consent Management Platform version stored in bits 91 to 102
-func (c ConsentMetadata) CmpVersion() uint16 {
+func (h Header) CmpVersion() uint16 {
 	// Stored in bits 90-101.. which is [00xxxxxx xxxxxx00] starting at the 12th byte
-	leftByte := (c.data[11] >> 2) & 0x0f
-	rightByte := (c.data[11] << 6) | c.data[12]>>2
+	leftByte := (h.data[11] >> 2) & 0x0f
+	rightByte := (h.data[11] << 6) | h.data[12]>>2
 	return binary.BigEndian.Uint16([]byte{leftByte, rightByte})
 }
 
 // ConsentScreen returns the consent screen info stored in bits 103 to 108
-func (c ConsentMetadata) ConsentScreen() uint8 {
+func (h Header) ConsentScreen() uint8 {
 	// Stored in bits 102-107.. which is [000000xx xxxx0000] starting at the 13th byte
-	return uint8(((c.data[12] & 0x03) << 4) | c.data[13]>>4)
+	return uint8(((h.data[12] & 0x03) << 4) | h.data[13]>>4)
 }
 
 // ConsentLanguage returns the two letter code for consent language stored in bits 109 to 120
-func (c ConsentMetadata) ConsentLanguage() string {
+func (h Header) ConsentLanguage() string {
 	// Stored in bits 108-119... which is [0000xxxx xxxxxxxx] starting at the 14th byte.
 	// Each letter is stored as 6 bits, with A=0 and Z=25
-	leftChar := ((c.data[13] & 0x0f) << 2) | c.data[14]>>6
-	rightChar := c.data[14] & 0x3f
+	leftChar := ((h.data[13] & 0x0f) << 2) | h.data[14]>>6
+	rightChar := h.data[14] & 0x3f
 	return string([]byte{leftChar + 65, rightChar + 65}) // Unicode A-Z is 65-90
 }
 
@@ -138,58 +160,90 @@ func (c ConsentMetadata) VendorLegitInterestMaxID() uint16 {
 }
 
 // VendorListVersion returns the vendor list version stored in bits 121 to 132
-func (c ConsentMetadata) VendorListVersion() uint16 {
+func (h Header) VendorListVersion() uint16 {
 	// The vendor list version is stored in bits 121 - 132
-	rightByte := ((c.data[16] & 0xf0) >> 4) | ((c.data[15] & 0x0f) << 4)
-	leftByte := c.data[15] >> 4
+	rightByte := ((h.data[16] & 0xf0) >> 4) | ((h.data[15] & 0x0f) << 4)
+	leftByte := h.data[15] >> 4
 	return binary.BigEndian.Uint16([]byte{leftByte, rightByte})
 }
 
 // TCFPolicyVersion returns the TCF policy version stored in bits 133 to 138
-func (c ConsentMetadata) TCFPolicyVersion() uint8 {
+func (h Header) TCFPolicyVersion() uint8 {
 	// Stored in bits 133-138.. which is [0000xxxx xx00000000] starting at the 17th byte
-	return uint8(((c.data[16] & 0x0f) << 2) | (c.data[17]&0xc0)>>6)
+	return uint8(((h.data[16] & 0x0f) << 2) | (h.data[17]&0xc0)>>6)
 }
 
 // MaxVendorID returns the maximum value for vendor identifier in bits 214 to 229
-func (c ConsentMetadata) MaxVendorID() uint16 {
+func (h Header) MaxVendorID() uint16 {
 	// The max vendor ID is stored in bits 214 - 229
-	leftByte := ((c.data[26] & 0x07) << 5) | ((c.data[27] & 0xf8) >> 3)
-	rightByte := ((c.data[27] & 0x07) << 5) | ((c.data[28] & 0xf8) >> 3)
+	leftByte := ((h.data[26] & 0x07) << 5) | ((h.data[27] & 0xf8) >> 3)
+	rightByte := ((h.data[27] & 0x07) << 5) | ((h.data[28] & 0xf8) >> 3)
 	return binary.BigEndian.Uint16([]byte{leftByte, rightByte})
 }
 
 // PurposeAllowed returns if the given purpose (1 to 24 max) is enabled, info stored in bits 153 to 176
-func (c ConsentMetadata) PurposeAllowed(id consentconstants.Purpose) bool {
+func (h Header) PurposeAllowed(id consentconstants.Purpose) bool {
 	// Purposes are stored in bits 152 - 175. The interface contract only defines behavior for ints in the range [1, 24]...
 	// so in the valid range, this won't even overflow a uint8.
 	if id > 24 {
 		return false
 	}
-	return isSet(c.data, uint(id)+151)
+	return isSet(h.data, uint(id)+151)
 }
 
 // PurposeLITransparency returns if the given purpose transparency (1 to 24 max) is enabled, info stored in bits 177 to 200
-func (c ConsentMetadata) PurposeLITransparency(id consentconstants.Purpose) bool {
+func (h Header) PurposeLITransparency(id consentconstants.Purpose) bool {
 	// Purposes are stored in bits 176 - 199. The interface contract only defines behavior for ints in the range [1, 24]...
 	// so in the valid range, this won't even overflow a uint8.
 	if id > 24 {
 		return false
 	}
-	return isSet(c.data, uint(id)+175)
+	return isSet(h.data, uint(id)+175)
+}
+
+// PurposeConsentMask returns the purposes the user consented to as a bitmask, in which bit i-1 is set if
+// purpose i is allowed. Callers checking many purposes can test its bits rather than call PurposeAllowed
+// for each.
+func (h Header) PurposeConsentMask() uint32 {
+	// Purposes are stored in bits 152 - 175, which are bytes 19 to 21.
+	return purposeMask(h.data[19:22])
+}
+
+// PurposeLIMask returns the purposes with legitimate interest transparency as a bitmask, in which bit i-1 is
+// set if PurposeLITransparency(i) is true.
+func (h Header) PurposeLIMask() uint32 {
+	// Purposes are stored in bits 176 - 199, which are bytes 22 to 24.
+	return purposeMask(h.data[22:25])
+}
+
+// purposeMask turns the 24 purpose bits in data, which start with purpose 1, into a mask starting with
+// purpose 1 in the lowest bit.
+func purposeMask(data []byte) uint32 {
+	value := uint32(data[0])<<16 | uint32(data[1])<<8 | uint32(data[2])
+	return bits.Reverse32(value) >> 8
 }
 
 // PurposeOneTreatment returns if Purpose 1 is enable, info stored in bit 201
-func (c ConsentMetadata) PurposeOneTreatment() bool {
-	return isSet(c.data, 200)
+func (h Header) PurposeOneTreatment() bool {
+	return isSet(h.data, 200)
+}
+
+// PublisherCC returns the two letter ISO 3166-1 alpha-2 code of the publisher's country, stored in bits
+// 202 to 213
+func (h Header) PublisherCC() string {
+	// Stored in bits 201-212... which is [0xxxxxxx xxxxx000] starting at the 26th byte.
+	// Each letter is stored as 6 bits, with A=0 and Z=25
+	leftChar := (h.data[25] & 0x7e) >> 1
+	rightChar := ((h.data[25] & 0x01) << 5) | h.data[26]>>3
+	return string([]byte{leftChar + 65, rightChar + 65}) // Unicode A-Z is 65-90
 }
 
 // SpecialFeatureOptIn returns if the given special feature is enable, stored in bits 140 to 152
-func (c ConsentMetadata) SpecialFeatureOptIn(id uint16) bool {
+func (h Header) SpecialFeatureOptIn(id uint16) bool {
 	if id > 12 {
 		return false
 	}
-	return isSet(c.data, 140+uint(id)-1)
+	return isSet(h.data, 140+uint(id)-1)
 }
 
 // VendorConsent returns true if there is consent for the given vendor id
@@ -207,6 +261,12 @@ func (c ConsentMetadata) CheckPubRestriction(purposeID uint8, restrictType uint8
 	return c.publisherRestrictions.CheckPubRestriction(purposeID, restrictType, vendor)
 }
 
+// PublisherRestrictions returns every publisher restriction in the consent string, sorted by purpose ID
+// and then by restriction type.
+func (c ConsentMetadata) PublisherRestrictions() []PublisherRestriction {
+	return c.publisherRestrictions.PublisherRestrictions()
+}
+
 // VendorDisclosed returns true if the vendor was disclosed to the user (TCF 2.3).
 // For backward compatibility with TCF 2.0/2.2 strings without disclosed vendors segment,
 // returns false when no disclosed vendors data is available.
@@ -230,9 +290,45 @@ func (c ConsentMetadata) HasDisclosedVendors() bool {
 	return c.hasDisclosedVendors
 }
 
+// Hash returns the SHA-256 of the core string's bytes. It identifies the user's choices in logs without
+// storing the string itself. Strings which differ only in their other segments get the same hash.
+func (c ConsentMetadata) Hash() [sha256.Size]byte {
+	return sha256.Sum256(c.data)
+}
+
 // Returns true if the bitIndex'th bit in data is a 1, and false if it's a 0.
 func isSet(data []byte, bitIndex uint) bool {
 	byteIndex := bitIndex / 8
 	bitOffset := bitIndex % 8
 	return byteToBool(data[byteIndex] & (0x80 >> bitOffset))
 }
+
+// parseBits returns the bitCount bits (8, 12 or 16) starting at bitIndex, like the bitutils parsers and
+// with the same messages, but reports truncated data with a ParseError.
+func parseBits(data []byte, bitIndex uint, bitCount uint) (uint16, error) {
+	if uint(len(data))*8 < bitIndex+bitCount {
+		var format string
+		switch bitCount {
+		case 8:
+			format = "ParseByte8 expected 8 bits to start at bit %d, but the consent string was only %d bytes long"
+		case 12:
+			format = "ParseUInt12 expected a 12-bit int to start at bit %d, but the consent string was only %d bytes long"
+		default:
+			format = "ParseUInt16 expected a 16-bit int to start at bit %d, but the consent string was only %d bytes long"
+		}
+		return 0, parseError(ErrTruncated, nil, format, bitIndex, uint(len(data)))
+	}
+	return bitsAt(data, bitIndex, bitCount), nil
+}
+
+// bitsAt returns the bitCount bits (at most 16) starting at bitIndex as a big-endian integer. Unlike the
+// bitutils parsers, it doesn't check that data holds them, so it's only used once validation has.
+func bitsAt(data []byte, bitIndex uint, bitCount uint) uint16 {
+	var value uint32
+	lastByte := (bitIndex + bitCount - 1) / 8
+	for i := bitIndex / 8; i <= lastByte; i++ {
+		value = value<<8 | uint32(data[i])
+	}
+	value >>= (lastByte+1)*8 - (bitIndex + bitCount)
+	return uint16(value & (1<<bitCount - 1))
+}
@@ -3,6 +3,9 @@ package vendorconsent
 import (
 	"testing"
 	"time"
+
+	"github.com/prebid/go-gdpr/bitutils"
+	"github.com/prebid/go-gdpr/consentconstants"
 )
 
 func TestCreatedDate(t *testing.T) {
@@ -128,6 +131,52 @@ func TestTCF2Fields(t *testing.T) {
 	assertBoolsEqual(t, false, consent.SpecialFeatureOptIn(2))
 }
 
+func TestPurposeMasks(t *testing.T) {
+	// Of purposes 1 to 10, the user allowed 1, 2, 3, 5, 6, 7 and 9.
+	baseConsent, err := Parse(decode(t, "COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA"))
+	assertNilError(t, err)
+	consent := baseConsent.(ConsentMetadata)
+	assertIntsEqual(t, 0x177, int(consent.PurposeConsentMask()&0x3ff))
+
+	baseConsent, err = Parse(decode(t, "COwAdDhOwAdDhN4ABAENAPCgAAQAAv___wAAAFP_AAp_4AI6ACACAA"))
+	assertNilError(t, err)
+	consent = baseConsent.(ConsentMetadata)
+	for _, masks := range []struct {
+		mask    uint32
+		allowed func(consentconstants.Purpose) bool
+	}{{consent.PurposeConsentMask(), consent.PurposeAllowed}, {consent.PurposeLIMask(), consent.PurposeLITransparency}} {
+		for purpose := consentconstants.Purpose(1); purpose <= 24; purpose++ {
+			assertBoolsEqual(t, masks.allowed(purpose), masks.mask&(1<<(purpose-1)) != 0)
+		}
+		assertIntsEqual(t, 0, int(masks.mask>>24))
+	}
+}
+
+func TestPublisherCC(t *testing.T) {
+	baseConsent, err := Parse(decode(t, "COx3XOeOx3XOeLkAAAENAfCIAAAAAHgAAIAAAAAAAAAA"))
+	assertNilError(t, err)
+	assertStringsEqual(t, "AA", baseConsent.(ConsentMetadata).PublisherCC())
+
+	baseConsent, err = Parse(decode(t, "COx3XOeOx3XOeLkAAAENAfCIAAAAAHgAAIYgAAAAAAAA"))
+	assertNilError(t, err)
+	consent := baseConsent.(ConsentMetadata)
+	assertStringsEqual(t, "DE", consent.PublisherCC())
+	assertBoolsEqual(t, true, consent.PurposeOneTreatment())
+}
+
+func TestHash(t *testing.T) {
+	withSegment, err := ParseString("COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA.IAAA")
+	assertNilError(t, err)
+	core, err := ParseString("COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA")
+	assertNilError(t, err)
+	other, err := ParseString("COx3XOeOx3XOeLkAAAENAfCIAAAAAHgAAIYgAAAAAAAA")
+	assertNilError(t, err)
+
+	hash := core.(ConsentMetadata).Hash()
+	assertBoolsEqual(t, true, hash == withSegment.(ConsentMetadata).Hash())
+	assertBoolsEqual(t, false, hash == other.(ConsentMetadata).Hash())
+}
+
 func TestLITransparency(t *testing.T) {
 	baseConsent, err := Parse(decode(t, "COx3XOeOx3XOeLkAAAENAfCIAAAAAHgAAIAAAAAAAAAA"))
 	assertNilError(t, err)
@@ -181,3 +230,19 @@ func TestHasDisclosedVendorsWithoutSegment(t *testing.T) {
 	// HasDisclosedVendors should return false when segment is not present
 	assertBoolsEqual(t, false, consent.HasDisclosedVendors())
 }
+
+func TestBitsAt(t *testing.T) {
+	data := []byte{0x04, 0xa2, 0x03, 0xb1, 0x00, 0x2b}
+	for bit := uint(0); bit+16 <= 48; bit++ {
+		byte8, err := bitutils.ParseByte8(data, bit)
+		assertNilError(t, err)
+		assertUInt16sEqual(t, uint16(byte8), bitsAt(data, bit, 8))
+		uint12, err := bitutils.ParseUInt12(data, bit)
+		assertNilError(t, err)
+		assertUInt16sEqual(t, uint12, bitsAt(data, bit, 12))
+		value, err := bitutils.ParseUInt16(data, bit)
+		assertNilError(t, err)
+		assertUInt16sEqual(t, value, bitsAt(data, bit, 16))
+	}
+	assertUInt16sEqual(t, 0x2b, bitsAt(data, 40, 8))
+}
@@ -0,0 +1,106 @@
+package vendorconsent
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+)
+
+// Options change how consent strings are parsed by its methods. The zero Options parse like the package's
+// functions.
+type Options struct {
+	// Limits bound the consent strings which are accepted.
+	Limits
+	// SkipPublisherRestrictions leaves the publisher restrictions of the Core string to be decoded the first
+	// time CheckPubRestriction or PublisherRestrictions is called, which speeds up parsing for callers who
+	// never consult them. They aren't checked while parsing either, so a string whose restrictions are
+	// malformed is accepted, and behaves as if it had none.
+	SkipPublisherRestrictions bool `json:"skipPublisherRestrictions"`
+	// MergeVendorLegalBases builds a VendorSet of the vendors with either the user's consent or legitimate
+	// interest while parsing, so that VendorConsentOrLegitInterest looks a vendor up once rather than in
+	// both sections.
+	MergeVendorLegalBases bool `json:"mergeVendorLegalBases"`
+	// SegmentScratch is a buffer, owned by the caller, which the segments after the Core string are decoded
+	// into if it's at least as long as they are, so that services which parse a consent string per request
+	// can reuse one buffer rather than let each parse find room for them. The Disclosed Vendors then refer
+	// to it, so it mustn't be reused while the parsed consent is in use.
+	SegmentScratch []byte `json:"-"`
+}
+
+// Parse works like the package's Parse, with the options.
+func (o Options) Parse(data []byte) (api.VendorConsents, error) {
+	var metadata ConsentMetadata
+	if err := o.ParseInto(&metadata, data); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// ParseInto works like the package's ParseInto, with the options.
+func (o Options) ParseInto(dst *ConsentMetadata, data []byte) error {
+	return parseInto(dst, data, o)
+}
+
+// ParseString works like the package's ParseString, with the options.
+func (o Options) ParseString(consent string) (api.VendorConsents, error) {
+	var metadata ConsentMetadata
+	if err := o.ParseStringInto(&metadata, consent); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// ParseStringInto works like the package's ParseStringInto, with the options.
+func (o Options) ParseStringInto(dst *ConsentMetadata, consent string) error {
+	if consent == "" {
+		dst.Reset()
+		return consentconstants.ErrEmptyDecodedConsent
+	}
+	if o.MaxLength > 0 && len(consent) > o.MaxLength {
+		dst.Reset()
+		return parseError(ErrLimitExceeded, nil, "the consent string is %d bytes long, but at most %d are allowed", uint(len(consent)), uint(o.MaxLength))
+	}
+	if o.MaxSegments > 0 {
+		if segments := strings.Count(consent, string(consentStringTCF2Separator)) + 1; segments > o.MaxSegments {
+			dst.Reset()
+			return parseError(ErrLimitExceeded, nil, "the consent string has %d segments, but at most %d are allowed", uint(segments), uint(o.MaxSegments))
+		}
+	}
+	return parseCoreAndDisclosedVendors(dst, consent, o)
+}
+
+// lazyPubRestrictions decodes the publisher restrictions into restrictions the first time they're used,
+// for Options.SkipPublisherRestrictions. Copies of a ConsentMetadata share it, so it decodes them once
+// even if they're used concurrently.
+type lazyPubRestrictions struct {
+	once         sync.Once
+	data         []byte
+	startbit     uint
+	restrictions *pubRestrictions
+}
+
+// reset makes l decode the publisher restrictions which start at startbit into restrictions.
+func (l *lazyPubRestrictions) reset(data []byte, startbit uint, restrictions *pubRestrictions) {
+	l.once = sync.Once{}
+	l.data = data
+	l.startbit = startbit
+	l.restrictions = restrictions
+}
+
+func (l *lazyPubRestrictions) decode() {
+	if _, err := l.restrictions.parse(l.data, l.startbit); err != nil {
+		l.restrictions.restrictions = l.restrictions.restrictions[:0]
+	}
+}
+
+func (l *lazyPubRestrictions) CheckPubRestriction(purposeID uint8, restrictType uint8, vendor uint16) bool {
+	l.once.Do(l.decode)
+	return l.restrictions.CheckPubRestriction(purposeID, restrictType, vendor)
+}
+
+func (l *lazyPubRestrictions) PublisherRestrictions() []PublisherRestriction {
+	l.once.Do(l.decode)
+	return l.restrictions.PublisherRestrictions()
+}
@@ -0,0 +1,157 @@
+package vendorconsent
+
+import (
+	"encoding/base64"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/prebid/go-gdpr/bitutils"
+)
+
+func TestSkipPublisherRestrictions(t *testing.T) {
+	consent := "COxPe2TOxPe2TALABAENAPCgAAAAAAAAAAAAAFAAAAoAAA4IACACAIABgACAFA4ADACAAIygAGADwAQBIAIAIB0AEAEBSACACAA"
+	expected, err := ParseString(consent)
+	assertNilError(t, err)
+	options := Options{SkipPublisherRestrictions: true}
+	parsed, err := options.ParseString(consent)
+	assertNilError(t, err)
+
+	// Copies share the lazily decoded restrictions, so using them concurrently must be safe.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			parsed.(ConsentMetadata).PublisherRestrictions()
+		}()
+	}
+	wg.Wait()
+
+	restrictions := parsed.(ConsentMetadata).PublisherRestrictions()
+	if !reflect.DeepEqual(expected.(ConsentMetadata).PublisherRestrictions(), restrictions) {
+		t.Errorf("Lazily decoded publisher restrictions %v did not match %v", restrictions, expected.(ConsentMetadata).PublisherRestrictions())
+	}
+	for _, restriction := range restrictions {
+		for _, vendors := range restriction.Vendors {
+			assertBoolsEqual(t, true, parsed.(ConsentMetadata).CheckPubRestriction(restriction.PurposeID, restriction.RestrictType, vendors.End))
+		}
+	}
+	assertBoolsEqual(t, false, parsed.(ConsentMetadata).CheckPubRestriction(1, 0, 500))
+
+	// Reusing dst must decode the restrictions of the new string.
+	var dst ConsentMetadata
+	assertNilError(t, options.ParseStringInto(&dst, consent))
+	assertIntsEqual(t, len(restrictions), len(dst.PublisherRestrictions()))
+	assertNilError(t, options.ParseStringInto(&dst, "COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA"))
+	assertIntsEqual(t, 0, len(dst.PublisherRestrictions()))
+}
+
+func TestSkipPublisherRestrictionsMalformed(t *testing.T) {
+	data := decode(t, "COxPe2TOxPe2TALABAENAPCgAAAAAAAAAAAAAFAAAAoAAA4IACACAIABgACAFA4ADACAAIygAGADwAQBIAIAIB0AEAEBSACACAA")
+	layout, err := validateCore(data, true)
+	assertNilError(t, err)
+	// Cut the string within the number of publisher restrictions.
+	data = data[:(layout.pubRestrictionsStart+8)/8]
+
+	_, err = Parse(data)
+	assertError(t, err)
+	parsed, err := Options{SkipPublisherRestrictions: true}.Parse(data)
+	assertNilError(t, err)
+	assertBoolsEqual(t, false, parsed.(ConsentMetadata).CheckPubRestriction(1, 0, 1))
+	assertIntsEqual(t, 0, len(parsed.(ConsentMetadata).PublisherRestrictions()))
+}
+
+func BenchmarkSkipPublisherRestrictions(b *testing.B) {
+	data, _, err := decodeSegment("COxPe2TOxPe2TALABAENAPCgAAAAAAAAAAAAAFAAAAoAAA4IACACAIABgACAFA4ADACAAIygAGADwAQBIAIAIB0AEAEBSACACAA", make([]byte, 128))
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, options := range []Options{{}, {SkipPublisherRestrictions: true}} {
+		name := "decode"
+		if options.SkipPublisherRestrictions {
+			name = "skip"
+		}
+		b.Run(name, func(b *testing.B) {
+			var dst ConsentMetadata
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := options.ParseInto(&dst, data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeVendorLegalBases(t *testing.T) {
+	// These encode their vendor sections as BitFields and as RangeSections.
+	consents := []string{
+		"COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA",
+		"COyfVVoOyfVVoADACHENAwCAAAAAAAAAAAAAE5QBgALgAqgD8AQACSwEygJyAnSAMABgAFkAgQCDASeAmYBOgAA",
+		"COwAdDhOwAdDhN4ABAENAPCgAAQAAv___wAAAFP_AAp_4AI6ACACAA",
+	}
+	options := Options{MergeVendorLegalBases: true}
+	var dst ConsentMetadata
+	for _, consent := range consents {
+		assertNilError(t, options.ParseStringInto(&dst, consent))
+		if dst.legalBases == nil {
+			t.Fatalf("The legal bases of %s weren't merged", consent)
+		}
+		parsed, err := ParseString(consent)
+		assertNilError(t, err)
+		metadata := parsed.(ConsentMetadata)
+		for id := uint16(0); id <= 1000; id++ {
+			expected := metadata.VendorConsent(id) || metadata.VendorLegitInterest(id)
+			assertBoolsEqual(t, expected, dst.VendorConsentOrLegitInterest(id))
+			assertBoolsEqual(t, expected, metadata.VendorConsentOrLegitInterest(id))
+		}
+	}
+
+	assertNilError(t, ParseStringInto(&dst, consents[0]))
+	if dst.legalBases != nil {
+		t.Errorf("The legal bases should only be merged when the option is set")
+	}
+}
+
+func TestSegmentScratch(t *testing.T) {
+	// The Disclosed Vendors segment is too long for the consent to hold inline.
+	var w bitutils.Writer
+	w.WriteBits(SegmentTypeDisclosedVendors, 3)
+	w.WriteBits(4000, 16)
+	w.WriteBits(0, 1)
+	for id := 1; id <= 4000; id++ {
+		w.WriteBits(uint64(id%2), 1)
+	}
+	consent := "COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA." + base64.RawURLEncoding.EncodeToString(w.Bytes())
+
+	scratch := make([]byte, 1024)
+	options := Options{SegmentScratch: scratch}
+	parsed, err := options.ParseString(consent)
+	assertNilError(t, err)
+	metadata := parsed.(ConsentMetadata)
+	assertBoolsEqual(t, true, metadata.VendorDisclosed(3999))
+	assertIntsEqual(t, 2000, metadata.CountDisclosedVendors())
+	if &metadata.buffers.disclosedBits.data[0] != &scratch[0] {
+		t.Errorf("The Disclosed Vendors segment should be decoded into the scratch buffer")
+	}
+
+	for _, test := range []struct {
+		options Options
+		allocs  float64
+	}{{Options{}, 2}, {options, 1}} {
+		allocs := testing.AllocsPerRun(100, func() {
+			if _, err := test.options.ParseString(consent); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if allocs != test.allocs {
+			t.Errorf("ParseString made %v allocations with a scratch buffer of %d bytes. Expected %v", allocs, len(test.options.SegmentScratch), test.allocs)
+		}
+	}
+
+	// A scratch buffer which is too short is ignored.
+	parsed, err = Options{SegmentScratch: make([]byte, 10)}.ParseString(consent)
+	assertNilError(t, err)
+	assertIntsEqual(t, 2000, parsed.(ConsentMetadata).CountDisclosedVendors())
+}
@@ -0,0 +1,34 @@
+package vendorconsent
+
+import "sync"
+
+var consentPool = sync.Pool{
+	New: func() any {
+		return &ConsentMetadata{&parsedConsent{}}
+	},
+}
+
+// Acquire returns an empty ConsentMetadata from an internal pool, to fill with ParseStringInto or ParseInto.
+// Reusing consent objects this way keeps servers which parse a consent string per request from allocating
+// new ones each time. Hand it back with Release once the request is done with it.
+func Acquire() *ConsentMetadata {
+	return consentPool.Get().(*ConsentMetadata)
+}
+
+// Release resets the consent and returns it to the pool which Acquire uses. Neither the consent nor copies
+// of it may be used afterwards.
+func Release(consent *ConsentMetadata) {
+	if consent == nil {
+		return
+	}
+	consent.Reset()
+	consentPool.Put(consent)
+}
+
+// Reset empties the consent, but keeps the memory it holds so that the next ParseStringInto or ParseInto
+// can reuse it. Methods which read the consent string mustn't be called on an empty consent.
+func (c *ConsentMetadata) Reset() {
+	if c.parsedConsent != nil {
+		c.consentFields = consentFields{}
+	}
+}
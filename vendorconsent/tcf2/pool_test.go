@@ -0,0 +1,48 @@
+package vendorconsent
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/prebid/go-gdpr/bitutils"
+)
+
+func TestAcquireRelease(t *testing.T) {
+	var w bitutils.Writer
+	w.WriteBits(SegmentTypePublisherTC, 3)
+	w.WriteBits(0x800000, 24) // purpose 1
+	w.WriteBits(0, 24)
+	w.WriteBits(0, 6)
+	publisherSegment := base64.RawURLEncoding.EncodeToString(w.Bytes())
+	disclosedSegment := base64.RawURLEncoding.EncodeToString([]byte{0x20, 0x01, 0x4a, 0x80}) // vendors 1, 3 and 5
+
+	consent := Acquire()
+	assertNilError(t, ParseStringInto(consent, publisherTCCoreString+"."+disclosedSegment+"."+publisherSegment))
+	assertUInt16sEqual(t, 14, consent.VendorListVersion())
+	assertBoolsEqual(t, true, consent.VendorConsent(1))
+	assertBoolsEqual(t, true, consent.HasDisclosedVendors())
+	assertBoolsEqual(t, true, consent.VendorDisclosed(3))
+	assertBoolsEqual(t, false, consent.VendorDisclosed(2))
+	assertBoolsEqual(t, true, consent.HasPublisherTC())
+	assertBoolsEqual(t, true, consent.PublisherPurposeConsent(1))
+
+	// Reusing the consent mustn't keep the segments of the previous string.
+	assertNilError(t, ParseStringInto(consent, "COyiILmOyiILmADACHENAPCAAAAAAAAAAAAAE5QBgALgAqgD8AQACSwEygJyAAAAAA"))
+	assertUInt16sEqual(t, 15, consent.VendorListVersion())
+	assertBoolsEqual(t, false, consent.HasDisclosedVendors())
+	assertBoolsEqual(t, false, consent.VendorDisclosed(3))
+	assertBoolsEqual(t, false, consent.HasPublisherTC())
+
+	assertError(t, ParseStringInto(consent, ""))
+	if consent.data != nil {
+		t.Errorf("ParseStringInto should leave the consent empty on error")
+	}
+
+	Release(consent)
+	consent = Acquire()
+	if consent.data != nil || consent.vendorConsents != nil || consent.disclosedVendors != nil {
+		t.Errorf("Acquire should return an empty consent")
+	}
+	Release(consent)
+	Release(nil)
+}
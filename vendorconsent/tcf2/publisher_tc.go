@@ -0,0 +1,96 @@
+package vendorconsent
+
+import (
+	"fmt"
+
+	"github.com/prebid/go-gdpr/bitutils"
+	"github.com/prebid/go-gdpr/consentconstants"
+)
+
+// publisherTC holds the Publisher Purposes Transparency and Consent segment (SegmentType=3), which records
+// the user's choices for the publisher's own use of data, as opposed to the vendors'.
+type publisherTC struct {
+	purposesConsent              uint64
+	purposesLITransparency       uint64
+	numCustomPurposes            uint8
+	customPurposesConsent        uint64
+	customPurposesLITransparency uint64
+}
+
+// parsePublisherTCSegment parses the Publisher TC segment: a 3-bit SegmentType, 24 bits of
+// PubPurposesConsent, 24 bits of PubPurposesLITransparency, a 6-bit NumCustomPurposes, and then
+// NumCustomPurposes bits each of CustomPurposesConsent and CustomPurposesLITransparency.
+// see https://github.com/InteractiveAdvertisingBureau/GDPR-Transparency-and-Consent-Framework/blob/master/TCFv2/IAB%20Tech%20Lab%20-%20Consent%20string%20and%20vendor%20list%20formats%20v2.md#publisher-purposes-transparency-and-consent
+func parsePublisherTCSegment(data []byte) (publisherTC, error) {
+	reader := bitutils.NewReader(data)
+	segmentType, err := reader.ReadBits(3)
+	if err != nil {
+		return publisherTC{}, fmt.Errorf("parse segment type: %v", err)
+	}
+	if segmentType != SegmentTypePublisherTC {
+		return publisherTC{}, fmt.Errorf("expected segment type %d, got %d", SegmentTypePublisherTC, segmentType)
+	}
+
+	var segment publisherTC
+	if segment.purposesConsent, err = reader.ReadBits(24); err != nil {
+		return publisherTC{}, fmt.Errorf("parse PubPurposesConsent: %v", err)
+	}
+	if segment.purposesLITransparency, err = reader.ReadBits(24); err != nil {
+		return publisherTC{}, fmt.Errorf("parse PubPurposesLITransparency: %v", err)
+	}
+	numCustomPurposes, err := reader.ReadBits(6)
+	if err != nil {
+		return publisherTC{}, fmt.Errorf("parse NumCustomPurposes: %v", err)
+	}
+	segment.numCustomPurposes = uint8(numCustomPurposes)
+	if segment.customPurposesConsent, err = reader.ReadBits(uint(numCustomPurposes)); err != nil {
+		return publisherTC{}, fmt.Errorf("parse CustomPurposesConsent: %v", err)
+	}
+	if segment.customPurposesLITransparency, err = reader.ReadBits(uint(numCustomPurposes)); err != nil {
+		return publisherTC{}, fmt.Errorf("parse CustomPurposesLITransparency: %v", err)
+	}
+	return segment, nil
+}
+
+// HasPublisherTC returns true if the consent string includes a Publisher TC segment.
+func (c ConsentMetadata) HasPublisherTC() bool {
+	return c.publisherTC != nil
+}
+
+// PublisherPurposeConsent returns true if the user consented to the publisher's own use of data for the
+// purpose, according to the Publisher TC segment. It returns false if there is no such segment.
+func (c ConsentMetadata) PublisherPurposeConsent(id consentconstants.Purpose) bool {
+	return c.publisherTC != nil && isFlagSet(c.publisherTC.purposesConsent, 24, uint8(id))
+}
+
+// PublisherPurposeLITransparency returns true if the publisher's legitimate interest for the purpose
+// was disclosed to the user and not objected to, according to the Publisher TC segment. It returns
+// false if there is no such segment.
+func (c ConsentMetadata) PublisherPurposeLITransparency(id consentconstants.Purpose) bool {
+	return c.publisherTC != nil && isFlagSet(c.publisherTC.purposesLITransparency, 24, uint8(id))
+}
+
+// NumCustomPurposes returns the number of the publisher's custom purposes in the Publisher TC segment.
+func (c ConsentMetadata) NumCustomPurposes() uint8 {
+	if c.publisherTC == nil {
+		return 0
+	}
+	return c.publisherTC.numCustomPurposes
+}
+
+// CustomPurposeConsent returns true if the user consented to the publisher's custom purpose, counting
+// from 1.
+func (c ConsentMetadata) CustomPurposeConsent(id uint8) bool {
+	return c.publisherTC != nil && isFlagSet(c.publisherTC.customPurposesConsent, c.publisherTC.numCustomPurposes, id)
+}
+
+// CustomPurposeLITransparency returns true if the publisher's legitimate interest for its custom
+// purpose, counting from 1, was disclosed to the user and not objected to.
+func (c ConsentMetadata) CustomPurposeLITransparency(id uint8) bool {
+	return c.publisherTC != nil && isFlagSet(c.publisherTC.customPurposesLITransparency, c.publisherTC.numCustomPurposes, id)
+}
+
+// isFlagSet returns true if flag id, counting from 1 at the most significant of width bits, is set.
+func isFlagSet(flags uint64, width uint8, id uint8) bool {
+	return id >= 1 && id <= width && flags&(1<<(width-id)) != 0
+}
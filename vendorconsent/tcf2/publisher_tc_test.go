@@ -0,0 +1,60 @@
+package vendorconsent
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/prebid/go-gdpr/bitutils"
+)
+
+const publisherTCCoreString = "COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA"
+
+func TestParsePublisherTC(t *testing.T) {
+	var w bitutils.Writer
+	w.WriteBits(SegmentTypePublisherTC, 3)
+	w.WriteBits(0xA00000, 24) // purposes 1 and 3
+	w.WriteBits(0x400000, 24) // purpose 2
+	w.WriteBits(3, 6)
+	w.WriteBits(0x5, 3) // custom purposes 1 and 3
+	w.WriteBits(0x2, 3) // custom purpose 2
+
+	baseConsent, err := ParseString(publisherTCCoreString + "." + base64.RawURLEncoding.EncodeToString(w.Bytes()))
+	assertNilError(t, err)
+	consent := baseConsent.(ConsentMetadata)
+
+	assertBoolsEqual(t, true, consent.HasPublisherTC())
+	assertBoolsEqual(t, true, consent.PublisherPurposeConsent(1))
+	assertBoolsEqual(t, false, consent.PublisherPurposeConsent(2))
+	assertBoolsEqual(t, true, consent.PublisherPurposeConsent(3))
+	assertBoolsEqual(t, false, consent.PublisherPurposeConsent(25))
+	assertBoolsEqual(t, false, consent.PublisherPurposeLITransparency(1))
+	assertBoolsEqual(t, true, consent.PublisherPurposeLITransparency(2))
+
+	assertUInt8sEqual(t, 3, consent.NumCustomPurposes())
+	assertBoolsEqual(t, true, consent.CustomPurposeConsent(1))
+	assertBoolsEqual(t, false, consent.CustomPurposeConsent(2))
+	assertBoolsEqual(t, true, consent.CustomPurposeConsent(3))
+	assertBoolsEqual(t, false, consent.CustomPurposeConsent(4))
+	assertBoolsEqual(t, false, consent.CustomPurposeLITransparency(1))
+	assertBoolsEqual(t, true, consent.CustomPurposeLITransparency(2))
+}
+
+func TestNoPublisherTC(t *testing.T) {
+	baseConsent, err := ParseString(publisherTCCoreString)
+	assertNilError(t, err)
+	consent := baseConsent.(ConsentMetadata)
+
+	assertBoolsEqual(t, false, consent.HasPublisherTC())
+	assertBoolsEqual(t, false, consent.PublisherPurposeConsent(1))
+	assertUInt8sEqual(t, 0, consent.NumCustomPurposes())
+	assertBoolsEqual(t, false, consent.CustomPurposeConsent(1))
+}
+
+func TestMalformedPublisherTCIgnored(t *testing.T) {
+	// Ends partway through PubPurposesLITransparency.
+	truncated := base64.RawURLEncoding.EncodeToString([]byte{0x60, 0x00, 0x00, 0x00})
+
+	baseConsent, err := ParseString(publisherTCCoreString + "." + truncated)
+	assertNilError(t, err)
+	assertBoolsEqual(t, false, baseConsent.(ConsentMetadata).HasPublisherTC())
+}
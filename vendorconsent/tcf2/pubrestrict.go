@@ -1,54 +1,126 @@
 package vendorconsent
 
-import (
-	"fmt"
-
-	"github.com/prebid/go-gdpr/bitutils"
-)
+import "sort"
 
 // IAB spec does not specify a max vendorID for the publisher restrictions. This should be one bit short of the max possible.
 const assumedMaxVendorID uint16 = 32767
 
 func parsePubRestriction(metadata ConsentMetadata, startbit uint) (*pubRestrictions, uint, error) {
-	data := metadata.data
-	numRestrictions, err := bitutils.ParseUInt12(data, startbit)
+	restrictions := &pubRestrictions{}
+	end, err := restrictions.parse(metadata.data, startbit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return restrictions, end, nil
+}
+
+// parse fills p with the publisher restrictions which start at startbit, and returns the index of the
+// first bit after them. It reuses the memory of p's map and vendor ranges.
+func (p *pubRestrictions) parse(data []byte, startbit uint) (uint, error) {
+	end, err := validatePubRestrictions(data, startbit)
 	if err != nil {
-		return nil, 0, fmt.Errorf("Error on parsing the number of publisher restrictions: %s", err.Error())
+		return 0, err
+	}
+	p.fill(data, startbit)
+	return end, nil
+}
+
+// validatePubRestrictions checks the publisher restrictions which start at startbit, and returns the
+// index of the first bit after them.
+func validatePubRestrictions(data []byte, startbit uint) (uint, error) {
+	numRestrictions, err := parseBits(data, startbit, 12)
+	if err != nil {
+		return 0, parseError(ErrTruncated, err, "Error on parsing the number of publisher restrictions")
 	}
 
-	// Parse out the "exceptions" here.
 	currentOffset := startbit + 12
-	restrictions := make(map[byte]pubRestriction, numRestrictions)
 	for j := uint16(0); j < numRestrictions; j++ {
-		restrictData, err := bitutils.ParseByte8(data, currentOffset)
-		if err != nil {
-			return nil, 0, fmt.Errorf("Error on parsing the publisher restriction purpose/type: %s", err.Error())
+		if _, err := parseBits(data, currentOffset, 8); err != nil {
+			return 0, parseError(ErrTruncated, err, "Error on parsing the publisher restriction purpose/type")
 		}
 		currentOffset = currentOffset + 8
-		numEntries, err := bitutils.ParseUInt12(data, currentOffset)
+		numEntries, err := parseBits(data, currentOffset, 12)
 		if err != nil {
-			return nil, 0, fmt.Errorf("Error on parsing the number of publisher restriction vendor ranges: %s", err.Error())
+			return 0, parseError(ErrTruncated, err, "Error on parsing the number of publisher restriction vendor ranges")
 		}
 		currentOffset = currentOffset + 12
-		vendors := make([]rangeConsent, numEntries)
-		for i := range vendors {
-			bitsConsumed, err := parseRangeConsent(&vendors[i], data, currentOffset, assumedMaxVendorID)
+		for i := uint16(0); i < numEntries; i++ {
+			bitsConsumed, err := validateRangeConsent(data, currentOffset, assumedMaxVendorID)
 			if err != nil {
-				return nil, 0, err
+				return 0, err
 			}
 			currentOffset = currentOffset + bitsConsumed
 		}
-		restrictions[restrictData] = pubRestriction{
+	}
+	return currentOffset, nil
+}
+
+// fill reads the publisher restrictions which validatePubRestrictions accepted into p, without checking
+// them again.
+func (p *pubRestrictions) fill(data []byte, startbit uint) {
+	numRestrictions := bitsAt(data, startbit, 12)
+	currentOffset := startbit + 12
+	p.restrictions = p.restrictions[:0]
+	if p.restrictions == nil {
+		p.restrictions = p.inlineRestrictions[:0]
+	}
+	// The vendors of every restriction are carved out of ranges. If it has to grow, restrictions which
+	// were already parsed keep the old backing array.
+	p.ranges = p.ranges[:0]
+	if p.ranges == nil {
+		p.ranges = p.inlineRanges[:0]
+	}
+	for j := uint16(0); j < numRestrictions; j++ {
+		restrictData := byte(bitsAt(data, currentOffset, 8))
+		currentOffset = currentOffset + 8
+		numEntries := bitsAt(data, currentOffset, 12)
+		currentOffset = currentOffset + 12
+		start := len(p.ranges)
+		for i := uint16(0); i < numEntries; i++ {
+			entry, bitsConsumed := readRangeConsent(data, currentOffset)
+			p.ranges = append(p.ranges, entry)
+			currentOffset = currentOffset + bitsConsumed
+		}
+		p.set(pubRestriction{
 			purposeID:    (restrictData & 0xfc) >> 2,
 			restrictType: (restrictData & 0x03),
-			vendors:      vendors,
+			vendors:      p.ranges[start:len(p.ranges):len(p.ranges)],
+		})
+	}
+}
+
+// set adds the restriction, replacing any earlier one for the same purpose and restriction type.
+func (p *pubRestrictions) set(restriction pubRestriction) {
+	for i := range p.restrictions {
+		if p.restrictions[i].purposeID == restriction.purposeID && p.restrictions[i].restrictType == restriction.restrictType {
+			p.restrictions[i] = restriction
+			return
 		}
 	}
-	return &pubRestrictions{restrictions: restrictions}, currentOffset, nil
+	p.restrictions = append(p.restrictions, restriction)
 }
 
+// PublisherRestriction is a restriction the publisher placed on a purpose for some vendors.
+type PublisherRestriction struct {
+	PurposeID uint8
+	// RestrictType is 0 (purpose flatly not allowed), 1 (require consent) or 2 (require legitimate interest).
+	RestrictType uint8
+	Vendors      []VendorRange
+}
+
+// VendorRange is a range of vendor IDs. Both bounds are inclusive.
+type VendorRange struct {
+	Start uint16
+	End   uint16
+}
+
+// pubRestrictions holds the restrictions in a slice rather than a map, since there are few of them. It
+// keeps the first restrictions and vendor ranges in arrays, so parsing them doesn't allocate.
 type pubRestrictions struct {
-	restrictions map[byte]pubRestriction
+	restrictions       []pubRestriction
+	ranges             []rangeConsent
+	inlineRestrictions [8]pubRestriction
+	inlineRanges       [inlineRangeEntries]rangeConsent
 }
 
 type pubRestriction struct {
@@ -59,15 +131,39 @@ type pubRestriction struct {
 
 func (p *pubRestrictions) CheckPubRestriction(purposeID uint8, restrictType uint8, vendor uint16) bool {
 	key := byte(purposeID<<2 | (restrictType & 0x03))
-	restriction, ok := p.restrictions[key]
-	if !ok {
-		return false
-	}
-	for i := 0; i < len(restriction.vendors); i++ {
-		if restriction.vendors[i].Contains(vendor) {
-			return true
+	for _, restriction := range p.restrictions {
+		if restriction.purposeID<<2|restriction.restrictType != key {
+			continue
+		}
+		for i := 0; i < len(restriction.vendors); i++ {
+			if restriction.vendors[i].Contains(vendor) {
+				return true
+			}
 		}
+		return false
 	}
 	return false
 
 }
+
+func (p *pubRestrictions) PublisherRestrictions() []PublisherRestriction {
+	list := make([]PublisherRestriction, 0, len(p.restrictions))
+	for _, restriction := range p.restrictions {
+		vendors := make([]VendorRange, len(restriction.vendors))
+		for i, r := range restriction.vendors {
+			vendors[i] = VendorRange{Start: r.startID, End: r.endID}
+		}
+		list = append(list, PublisherRestriction{
+			PurposeID:    restriction.purposeID,
+			RestrictType: restriction.restrictType,
+			Vendors:      vendors,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].PurposeID != list[j].PurposeID {
+			return list[i].PurposeID < list[j].PurposeID
+		}
+		return list[i].RestrictType < list[j].RestrictType
+	})
+	return list
+}
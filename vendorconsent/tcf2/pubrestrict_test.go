@@ -1,6 +1,7 @@
 package vendorconsent
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -54,3 +55,27 @@ func TestPubRestrictions3(t *testing.T) {
 	_, err := Parse(decode(t, "COzSDo9OzSDo9B9AAAENAiCAALAAAAAAAAAACOQAQCOAAAAA"))
 	assertNilError(t, err)
 }
+
+func TestPublisherRestrictions(t *testing.T) {
+	baseConsent, err := Parse(decode(t, "COxPe2TOxPe2TALABAENAPCgAAAAAAAAAAAAAFAAAAoAAA4IACACAIABgACAFA4ADACAAIygAGADwAQBIAIAIB0AEAEBSACACAA"))
+	assertNilError(t, err)
+
+	expected := []PublisherRestriction{
+		{PurposeID: 1, RestrictType: 0, Vendors: []VendorRange{{Start: 32, End: 32}}},
+		{PurposeID: 2, RestrictType: 0, Vendors: []VendorRange{{Start: 1, End: 40}}},
+		{PurposeID: 2, RestrictType: 1, Vendors: []VendorRange{{Start: 32, End: 32}}},
+		{PurposeID: 7, RestrictType: 0, Vendors: []VendorRange{{Start: 32, End: 35}}},
+		{PurposeID: 7, RestrictType: 1, Vendors: []VendorRange{{Start: 32, End: 32}}},
+		{PurposeID: 10, RestrictType: 0, Vendors: []VendorRange{{Start: 30, End: 32}}},
+		{PurposeID: 10, RestrictType: 1, Vendors: []VendorRange{{Start: 32, End: 32}}},
+	}
+	if actual := baseConsent.(ConsentMetadata).PublisherRestrictions(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Wrong publisher restrictions. Expected %+v, actual %+v", expected, actual)
+	}
+}
+
+func TestPublisherRestrictionsNone(t *testing.T) {
+	baseConsent, err := Parse(decode(t, "COzSDo9OzSDo9B9AAAENAiCAALAAAAAAAAAACOQAQCOAAAAA"))
+	assertNilError(t, err)
+	assertIntsEqual(t, 0, len(baseConsent.(ConsentMetadata).PublisherRestrictions()))
+}
@@ -1,93 +1,178 @@
 package vendorconsent
 
 import (
-	"fmt"
-
-	"github.com/prebid/go-gdpr/bitutils"
+	"cmp"
+	"slices"
 )
 
+// rangeIndexThreshold is the number of entries above which a RangeSection is indexed when it's parsed, so
+// that looking up a vendor takes O(log n) time rather than checking every entry. Small sections are
+// quicker to scan than to index.
+const rangeIndexThreshold = 32
+
 func parseRangeSection(metadata ConsentMetadata, maxVendorID uint16, startbit uint) (*rangeSection, uint, error) {
-	data := metadata.data
+	section := &rangeSection{}
+	end, err := section.parse(metadata.data, maxVendorID, startbit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return section, end, nil
+}
 
+// parse fills p with the RangeSection which starts at startbit, and returns the index of the first bit
+// after it. It reuses the memory of p's entries if there is enough.
+func (p *rangeSection) parse(data []byte, maxVendorID uint16, startbit uint) (uint, error) {
+	numEntries, end, err := validateRangeSection(data, maxVendorID, startbit)
+	if err != nil {
+		return 0, err
+	}
+	p.fill(data, maxVendorID, startbit, numEntries)
+	return end, nil
+}
+
+// validateRangeSection checks the RangeSection which starts at startbit, and returns its number of entries
+// and the index of the first bit after it.
+func validateRangeSection(data []byte, maxVendorID uint16, startbit uint) (uint16, uint, error) {
 	// Check we have enough bytes to read the NumEntries field (12 bits starting at startbit)
 	minBytesRequired := (startbit + 12 + 7) / 8
 	if uint(len(data)) < minBytesRequired {
-		return nil, 0, fmt.Errorf("vendor consent strings using RangeSections require at least %d bytes to read NumEntries. Got %d", minBytesRequired, len(data))
+		return 0, 0, parseError(ErrTruncated, nil, "vendor consent strings using RangeSections require at least %d bytes to read NumEntries. Got %d", minBytesRequired, uint(len(data)))
 	}
 
 	// This makes an int from bits [startBit, startBit + 12)
-	numEntries, err := bitutils.ParseUInt12(data, startbit)
+	numEntries, err := parseBits(data, startbit, 12)
 	if err != nil {
-		return nil, 0, err
+		return 0, 0, err
 	}
 
-	// Parse out the "exceptions" here.
 	currentOffset := startbit + 12
-	consents := make([]rangeConsent, numEntries)
-	for i := range consents {
-		bitsConsumed, err := parseRangeConsent(&consents[i], data, currentOffset, maxVendorID)
+	for i := uint16(0); i < numEntries; i++ {
+		bitsConsumed, err := validateRangeConsent(data, currentOffset, maxVendorID)
 		if err != nil {
-			return nil, 0, err
+			return 0, 0, err
 		}
 		currentOffset = currentOffset + bitsConsumed
 	}
+	return numEntries, currentOffset, nil
+}
+
+// fill reads the entries of a RangeSection which validateRangeSection accepted into p, without checking
+// them again.
+func (p *rangeSection) fill(data []byte, maxVendorID uint16, startbit uint, numEntries uint16) {
+	consents := p.consents[:0]
+	if cap(consents) < int(numEntries) {
+		if int(numEntries) <= len(p.inline) {
+			consents = p.inline[:0]
+		} else {
+			consents = make([]rangeConsent, 0, numEntries)
+		}
+	}
+	consents = consents[:numEntries]
+	currentOffset := startbit + 12
+	for i := range consents {
+		var bitsConsumed uint
+		consents[i], bitsConsumed = readRangeConsent(data, currentOffset)
+		currentOffset = currentOffset + bitsConsumed
+	}
+
+	p.consents = consents
+	p.maxVendorID = maxVendorID
+	p.buildIndex(rangeIndexThreshold)
+}
+
+// buildIndex sorts and merges the entries into disjoint ranges if there are more than threshold of them,
+// and otherwise clears the index.
+func (p *rangeSection) buildIndex(threshold int) {
+	p.index = p.index[:0]
+	if len(p.consents) <= threshold {
+		return
+	}
 
-	return &rangeSection{
-		consents:    consents,
-		maxVendorID: maxVendorID,
-	}, currentOffset, nil
+	p.index = mergeRanges(append(p.index, p.consents...))
+}
+
+// mergeRanges sorts the ranges and merges those which overlap or touch, in place, and returns the merged
+// ranges.
+func mergeRanges(ranges []rangeConsent) []rangeConsent {
+	if len(ranges) == 0 {
+		return ranges
+	}
+	slices.SortFunc(ranges, func(a, b rangeConsent) int {
+		return cmp.Compare(a.startID, b.startID)
+	})
+	merged := ranges[:1]
+	for _, entry := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if uint(entry.startID) <= uint(last.endID)+1 {
+			last.endID = max(last.endID, entry.endID)
+		} else {
+			merged = append(merged, entry)
+		}
+	}
+	return merged
 }
 
 // RangeSection Exception implementations
 
-// parseRangeConsents parses a RangeSection starting from the initial bit.
-// It returns the number of bits consumed by the parsing.
-func parseRangeConsent(dst *rangeConsent, data []byte, initialBit uint, maxVendorID uint16) (uint, error) {
+// validateRangeConsent checks the RangeEntry starting from the initial bit.
+// It returns the number of bits the entry takes up.
+func validateRangeConsent(data []byte, initialBit uint, maxVendorID uint16) (uint, error) {
 	// Fixes #10
 	if uint(len(data)) <= initialBit/8 {
-		return 0, fmt.Errorf("bit %d was supposed to start a new RangeEntry, but the consent string was only %d bytes long", initialBit, len(data))
+		return 0, parseError(ErrTruncated, nil, "bit %d was supposed to start a new RangeEntry, but the consent string was only %d bytes long", initialBit, uint(len(data)))
 	}
 	// If the first bit is set, it's a Range of IDs
 	if isSet(data, initialBit) {
-		start, err := bitutils.ParseUInt16(data, initialBit+1)
+		start, err := parseBits(data, initialBit+1, 16)
 		if err != nil {
 			return 0, err
 		}
-		end, err := bitutils.ParseUInt16(data, initialBit+17)
+		end, err := parseBits(data, initialBit+17, 16)
 		if err != nil {
 			return 0, err
 		}
 		if start == 0 {
-			return 0, fmt.Errorf("bit %d range entry exclusion starts at 0, but the min vendor ID is 1", initialBit)
+			return 0, parseError(ErrInvalidRangeEntry, nil, "bit %d range entry exclusion starts at 0, but the min vendor ID is 1", initialBit)
 		}
 		if end > maxVendorID {
-			return 0, fmt.Errorf("bit %d range entry exclusion ends at %d, but the max vendor ID is %d", initialBit, end, maxVendorID)
+			return 0, parseError(ErrInvalidRangeEntry, nil, "bit %d range entry exclusion ends at %d, but the max vendor ID is %d", initialBit, uint(end), uint(maxVendorID))
 		}
 		if end <= start {
-			return 0, fmt.Errorf("bit %d range entry excludes vendors [%d, %d]. The start should be less than the end", initialBit, start, end)
+			return 0, parseError(ErrInvalidRangeEntry, nil, "bit %d range entry excludes vendors [%d, %d]. The start should be less than the end", initialBit, uint(start), uint(end))
 		}
-		dst.startID = start
-		dst.endID = end
 		return 33, nil
 	}
 
-	vendorID, err := bitutils.ParseUInt16(data, initialBit+1)
+	vendorID, err := parseBits(data, initialBit+1, 16)
 	if err != nil {
 		return 0, err
 	}
 	if vendorID == 0 || vendorID > maxVendorID {
-		return 0, fmt.Errorf("bit %d range entry excludes vendor %d, but only vendors [1, %d] are valid", initialBit, vendorID, maxVendorID)
+		return 0, parseError(ErrInvalidRangeEntry, nil, "bit %d range entry excludes vendor %d, but only vendors [1, %d] are valid", initialBit, uint(vendorID), uint(maxVendorID))
 	}
 
-	dst.startID = vendorID
-	dst.endID = vendorID
 	return 17, nil
 }
 
+// readRangeConsent reads the RangeEntry starting from the initial bit, which validateRangeConsent
+// accepted. It returns the entry and the number of bits it takes up.
+func readRangeConsent(data []byte, initialBit uint) (rangeConsent, uint) {
+	start := bitsAt(data, initialBit+1, 16)
+	if isSet(data, initialBit) {
+		return rangeConsent{startID: start, endID: bitsAt(data, initialBit+17, 16)}, 33
+	}
+	return rangeConsent{startID: start, endID: start}, 17
+}
+
+// inlineRangeEntries is the number of entries a rangeSection holds without allocating.
+const inlineRangeEntries = 16
+
 // A RangeConsents encodes consents that have been registered.
 type rangeSection struct {
 	consents    []rangeConsent
 	maxVendorID uint16
+	index       []rangeConsent // sorted, disjoint ranges, or empty if the section isn't indexed
+	inline      [inlineRangeEntries]rangeConsent
 }
 
 func (p *rangeSection) MaxVendorID() uint16 {
@@ -104,6 +189,20 @@ func (p *rangeSection) VendorConsent(id uint16) bool {
 		return false
 	}
 
+	if len(p.index) > 0 {
+		// Find the first range which ends at or after the vendor.
+		low, high := 0, len(p.index)
+		for low < high {
+			mid := int(uint(low+high) >> 1)
+			if p.index[mid].endID < id {
+				low = mid + 1
+			} else {
+				high = mid
+			}
+		}
+		return low < len(p.index) && p.index[low].Contains(id)
+	}
+
 	for i := range p.consents {
 		if p.consents[i].Contains(id) {
 			return true
@@ -54,3 +54,59 @@ func TestInvalidRangeEdgeCase(t *testing.T) {
 	data = data[:31]
 	assertInvalidBytes(t, data[:31], "ParseUInt16 expected a 16-bit int to start at bit 243, but the consent string was only 31 bytes long")
 }
+
+func TestRangeSectionIndex(t *testing.T) {
+	section := &rangeSection{
+		maxVendorID: 100,
+		consents: []rangeConsent{
+			{startID: 50, endID: 60},
+			{startID: 3, endID: 3},
+			{startID: 55, endID: 70},
+			{startID: 4, endID: 10},
+			{startID: 90, endID: 90},
+			{startID: 58, endID: 59},
+			{startID: 100, endID: 100},
+		},
+	}
+	section.buildIndex(0)
+	if len(section.index) != 4 {
+		t.Fatalf("Expected 4 merged ranges. Got %v", section.index)
+	}
+
+	for i := uint16(0); i <= 101; i++ {
+		expected := false
+		for _, entry := range section.consents {
+			expected = expected || entry.Contains(i)
+		}
+		assertBoolsEqual(t, expected, section.VendorConsent(i))
+	}
+
+	section.buildIndex(len(section.consents))
+	if len(section.index) != 0 {
+		t.Errorf("Sections at the threshold shouldn't be indexed. Got %v", section.index)
+	}
+}
+
+func TestIndexedRangeSectionConsent(t *testing.T) {
+	consent, err := Parse(decode(t, "COyfVVoOyfVVoADACHENAwCAAAAAAAAAAAAAE5QBgALgAqgD8AQACSwEygJyAnSAMABgAFkAgQCDASeAmYBOgAA"))
+	assertNilError(t, err)
+	indexRangeSections(t, consent.(ConsentMetadata))
+	vendorsWithConsent := buildMap(23, 42, 126, 127, 128, 587, 613, 626)
+	for i := uint16(1); i <= consent.MaxVendorID(); i++ {
+		_, expected := vendorsWithConsent[uint(i)]
+		assertBoolsEqual(t, expected, consent.VendorConsent(i))
+	}
+}
+
+// indexRangeSections indexes the RangeSections of the consent whatever their size, since the ones in test
+// strings are too small to be indexed when they're parsed.
+func indexRangeSections(t *testing.T, consent ConsentMetadata) {
+	t.Helper()
+	buffers := &consent.buffers
+	for _, section := range []*rangeSection{&buffers.vendorConsentRanges, &buffers.legitInterestRanges, &buffers.disclosedRanges} {
+		section.buildIndex(0)
+	}
+	if len(buffers.vendorConsentRanges.index) == 0 {
+		t.Fatal("The vendor consents should be an indexed RangeSection")
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !gdpr_unsafe
+
+package vendorconsent
+
+// segmentBytes copies the segment string into the start of buf, so that decodeSegment can decode it in
+// place. Build with the gdpr_unsafe tag to skip the copy.
+func segmentBytes(segment string, buf []byte) []byte {
+	return buf[:copy(buf, segment)]
+}
@@ -0,0 +1,12 @@
+//go:build gdpr_unsafe
+
+package vendorconsent
+
+import "unsafe"
+
+// segmentBytes returns the bytes of the segment string without copying them. base64 only reads them and
+// decodeSegment decodes them into its own buffer, so nothing refers to the string's memory once parsing
+// is done.
+func segmentBytes(segment string, _ []byte) []byte {
+	return unsafe.Slice(unsafe.StringData(segment), len(segment))
+}
@@ -0,0 +1,172 @@
+package vendorconsent
+
+import (
+	"math/bits"
+
+	"github.com/prebid/go-gdpr/bitutils"
+)
+
+// VendorSet is a set of vendor IDs held as 64-bit words, so that sets can be combined and counted a word at
+// a time rather than by probing each vendor. The zero value is an empty set.
+type VendorSet struct {
+	words []uint64
+}
+
+// NewVendorSet returns a set of the given vendors.
+func NewVendorSet(vendorIDs ...uint16) VendorSet {
+	var set VendorSet
+	for _, id := range vendorIDs {
+		set.add(id)
+	}
+	return set
+}
+
+// Has returns true if the vendor is in the set.
+func (s VendorSet) Has(vendorID uint16) bool {
+	word := int(vendorID / 64)
+	return word < len(s.words) && s.words[word]&(1<<(vendorID%64)) != 0
+}
+
+// Count returns the number of vendors in the set.
+func (s VendorSet) Count() int {
+	n := 0
+	for _, word := range s.words {
+		n += bits.OnesCount64(word)
+	}
+	return n
+}
+
+// VendorIDs returns the vendors in the set, in ascending order.
+func (s VendorSet) VendorIDs() []uint16 {
+	ids := make([]uint16, 0, s.Count())
+	for i, word := range s.words {
+		for word != 0 {
+			ids = append(ids, uint16(i*64+bits.TrailingZeros64(word)))
+			word &= word - 1
+		}
+	}
+	return ids
+}
+
+// And returns the vendors which are in both sets.
+func (s VendorSet) And(other VendorSet) VendorSet {
+	words := make([]uint64, min(len(s.words), len(other.words)))
+	for i := range words {
+		words[i] = s.words[i] & other.words[i]
+	}
+	return VendorSet{words: words}
+}
+
+// Or returns the vendors which are in either set.
+func (s VendorSet) Or(other VendorSet) VendorSet {
+	longer, shorter := s.words, other.words
+	if len(shorter) > len(longer) {
+		longer, shorter = shorter, longer
+	}
+	words := append([]uint64(nil), longer...)
+	for i, word := range shorter {
+		words[i] |= word
+	}
+	return VendorSet{words: words}
+}
+
+// AndNot returns the vendors which are in s but not in other.
+func (s VendorSet) AndNot(other VendorSet) VendorSet {
+	words := append([]uint64(nil), s.words...)
+	for i := range min(len(words), len(other.words)) {
+		words[i] &^= other.words[i]
+	}
+	return VendorSet{words: words}
+}
+
+func (s *VendorSet) add(vendorID uint16) {
+	s.grow(vendorID)
+	s.words[vendorID/64] |= 1 << (vendorID % 64)
+}
+
+// addRange adds the vendors from start to end, inclusive.
+func (s *VendorSet) addRange(start, end uint16) {
+	s.grow(end)
+	for word := start / 64; word <= end/64; word++ {
+		mask := ^uint64(0)
+		if word == start/64 {
+			mask &= ^uint64(0) << (start % 64)
+		}
+		if word == end/64 {
+			mask &= ^uint64(0) >> (63 - end%64)
+		}
+		s.words[word] |= mask
+	}
+}
+
+// grow makes room for vendors up to maxVendorID.
+func (s *VendorSet) grow(maxVendorID uint16) {
+	if n := int(maxVendorID/64) + 1; n > len(s.words) {
+		s.words = append(s.words, make([]uint64, n-len(s.words))...)
+	}
+}
+
+// MaterializedVendors holds the vendor sections of a consent string as VendorSets, for bulk filtering and
+// set arithmetic.
+type MaterializedVendors struct {
+	Consents            VendorSet
+	LegitimateInterests VendorSet
+	// Disclosed is empty if the consent string has no Disclosed Vendors segment.
+	Disclosed VendorSet
+}
+
+// Materialize converts the vendor consents, legitimate interests and disclosed vendors of the consent
+// string into VendorSets. It's worth doing when a request checks many vendors against the same string.
+func (c ConsentMetadata) Materialize() MaterializedVendors {
+	return MaterializedVendors{
+		Consents:            vendorSetOf(c.vendorConsents),
+		LegitimateInterests: vendorSetOf(c.vendorLegitimateInterests),
+		Disclosed:           vendorSetOf(c.disclosedVendors),
+	}
+}
+
+// VendorConsentOrLegitInterest returns true if the vendor has the user's consent or legitimate interest
+// established, which is what most callers need to know. It takes a single lookup if the consent string was
+// parsed with Options.MergeVendorLegalBases.
+func (c ConsentMetadata) VendorConsentOrLegitInterest(id uint16) bool {
+	if c.legalBases != nil {
+		return c.legalBases.Has(id)
+	}
+	return c.vendorConsents.VendorConsent(id) || c.vendorLegitimateInterests.VendorConsent(id)
+}
+
+// vendorSetOf returns the vendors in the section.
+func vendorSetOf(section VendorSection) VendorSet {
+	var set VendorSet
+	set.addSection(section)
+	return set
+}
+
+// addSection adds the vendors in the section. A nil section has no vendors.
+func (s *VendorSet) addSection(section VendorSection) {
+	switch section := section.(type) {
+	case nil:
+	case *consentBitField:
+		if section == nil || section.maxVendorID == 0 {
+			break
+		}
+		s.grow(section.maxVendorID)
+		// Parsing checked that the data holds the whole BitField, so this can't fail.
+		bitutils.ForEachSetBit(section.data, section.startbit, uint(section.maxVendorID), func(index uint) {
+			s.words[(index+1)/64] |= 1 << ((index + 1) % 64)
+		})
+	case *rangeSection:
+		if section == nil {
+			break
+		}
+		for _, entry := range section.consents {
+			s.addRange(entry.startID, entry.endID)
+		}
+	default:
+		for id := uint16(1); id <= section.MaxVendorID() && id != 0; id++ {
+			if section.VendorConsent(id) {
+				s.add(id)
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package vendorconsent
+
+import (
+	"encoding/base64"
+	"reflect"
+	"testing"
+)
+
+func TestVendorSetOperations(t *testing.T) {
+	a := NewVendorSet(1, 2, 64, 200)
+	b := NewVendorSet(2, 3, 64)
+
+	assertBoolsEqual(t, true, a.Has(64))
+	assertBoolsEqual(t, false, a.Has(65))
+	assertBoolsEqual(t, false, a.Has(1000))
+	assertIntsEqual(t, 4, a.Count())
+
+	assertUInt16SlicesEqual(t, []uint16{2, 64}, a.And(b).VendorIDs())
+	assertUInt16SlicesEqual(t, []uint16{1, 2, 3, 64, 200}, a.Or(b).VendorIDs())
+	assertUInt16SlicesEqual(t, []uint16{1, 200}, a.AndNot(b).VendorIDs())
+	assertUInt16SlicesEqual(t, []uint16{3}, b.AndNot(a).VendorIDs())
+	assertIntsEqual(t, 0, VendorSet{}.Count())
+}
+
+func TestVendorSetAddRange(t *testing.T) {
+	var set VendorSet
+	set.addRange(60, 130)
+	set.addRange(5, 5)
+	expected := []uint16{5}
+	for id := uint16(60); id <= 130; id++ {
+		expected = append(expected, id)
+	}
+	assertUInt16SlicesEqual(t, expected, set.VendorIDs())
+}
+
+func TestMaterialize(t *testing.T) {
+	// The core string encodes its vendor consents as a BitField and its legitimate interests as a RangeSection.
+	disclosed := base64.RawURLEncoding.EncodeToString([]byte{0x20, 0x01, 0x4a, 0x80}) // vendors 1, 3 and 5
+	parsed, err := ParseString("COyfVVoOyfVVoADACHENAwCAAAAAAAAAAAAAE5QBgALgAqgD8AQACSwEygJyAnSAMABgAFkAgQCDASeAmYBOgAA." + disclosed)
+	assertNilError(t, err)
+	consent := parsed.(ConsentMetadata)
+	vendors := consent.Materialize()
+
+	assertUInt16SlicesEqual(t, []uint16{23, 42, 126, 127, 128, 587, 613, 626}, vendors.Consents.VendorIDs())
+	assertUInt16SlicesEqual(t, []uint16{24, 44, 129, 130, 131, 591, 614, 628}, vendors.LegitimateInterests.VendorIDs())
+	assertUInt16SlicesEqual(t, []uint16{1, 3, 5}, vendors.Disclosed.VendorIDs())
+
+	parsed, err = ParseString("COwGVJOOwGVJOADACHENAOCAAO6as_-AAAhoAFNLAAoAAAA")
+	assertNilError(t, err)
+	vendors = parsed.(ConsentMetadata).Materialize()
+	assertUInt16SlicesEqual(t, []uint16{1, 2, 4, 7, 9, 10}, vendors.Consents.VendorIDs())
+	assertIntsEqual(t, 0, vendors.Disclosed.Count())
+}
+
+func assertUInt16SlicesEqual(t *testing.T, expected []uint16, actual []uint16) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Vendor IDs were not equal. Expected %v, actual %v", expected, actual)
+	}
+}
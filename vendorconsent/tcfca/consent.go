@@ -0,0 +1,125 @@
+package vendorconsent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prebid/go-gdpr/bitutils"
+	tcf2 "github.com/prebid/go-gdpr/vendorconsent/tcf2"
+)
+
+// ErrUnsupportedVersion means the consent string encoded a Version other than 1, the only one this
+// package understands.
+var ErrUnsupportedVersion = errors.New("the tcfca consent string's version is not supported")
+
+const (
+	segmentSeparator = "."
+
+	// SegmentTypePublisherPurposes is the type of the optional publisher purposes segment.
+	SegmentTypePublisherPurposes = 3
+)
+
+// ParseString parses a TCF Canada consent string: a base64 URL encoded (unpadded) core segment, optionally
+// followed by segments separated by '.'. Segments other than the publisher purposes segment are ignored.
+//
+// The format shares its vendor sections with TCF 2, but has its own purposes, with express and implied
+// consent in place of consent and legitimate interest. For technical details, see
+// https://github.com/InteractiveAdvertisingBureau/Global-Privacy-Platform/blob/main/Sections/Canada/GPP%20Extension%3A%20IAB%20Canada%20TCF.md
+func ParseString(consent string) (ConsentMetadata, error) {
+	if consent == "" {
+		return ConsentMetadata{}, fmt.Errorf("tcfca consent string cannot be empty")
+	}
+
+	segments := strings.Split(consent, segmentSeparator)
+	data, err := bitutils.DecodeSegment(segments[0])
+	if err != nil {
+		return ConsentMetadata{}, err
+	}
+	metadata, err := parseCore(data)
+	if err != nil {
+		return ConsentMetadata{}, err
+	}
+
+	for _, segment := range segments[1:] {
+		if err := metadata.parsePublisherPurposes(segment); err != nil {
+			return ConsentMetadata{}, err
+		}
+	}
+	return metadata, nil
+}
+
+func parseCore(data []byte) (ConsentMetadata, error) {
+	f := bitutils.NewFieldReader(data)
+	metadata := ConsentMetadata{
+		version:                      uint8(f.Read(6)),
+		created:                      readTimestamp(f),
+		lastUpdated:                  readTimestamp(f),
+		cmpID:                        uint16(f.Read(12)),
+		cmpVersion:                   uint16(f.Read(12)),
+		consentScreen:                uint8(f.Read(6)),
+		consentLanguage:              readLanguage(f),
+		vendorListVersion:            uint16(f.Read(12)),
+		tcfPolicyVersion:             uint8(f.Read(6)),
+		useNonStandardStacks:         f.Read(1) == 1,
+		specialFeatureExpressConsent: f.Read(12),
+		purposesExpressConsent:       f.Read(24),
+		purposesImpliedConsent:       f.Read(24),
+	}
+	if f.Err() != nil {
+		return ConsentMetadata{}, fmt.Errorf("invalid tcfca core segment: %v", f.Err())
+	}
+	if metadata.version != 1 {
+		return ConsentMetadata{}, fmt.Errorf("%w: the consent string encoded a Version of %d, but this value must be 1", ErrUnsupportedVersion, metadata.version)
+	}
+
+	// The vendor sections share their layout with the TC string, so the TCF 2 parser does the work.
+	var vendorsEnd uint
+	var err error
+	if metadata.vendorExpressConsent, vendorsEnd, err = tcf2.ParseVendorSection(data, f.Position()); err != nil {
+		return ConsentMetadata{}, fmt.Errorf("invalid tcfca VendorExpressConsent: %v", err)
+	}
+	if metadata.vendorImpliedConsent, metadata.coreBits, err = tcf2.ParseVendorSection(data, vendorsEnd); err != nil {
+		return ConsentMetadata{}, fmt.Errorf("invalid tcfca VendorImpliedConsent: %v", err)
+	}
+	return metadata, nil
+}
+
+// parsePublisherPurposes parses the publisher purposes segment. Segments of other types are ignored.
+func (c *ConsentMetadata) parsePublisherPurposes(segment string) error {
+	data, err := bitutils.DecodeSegment(segment)
+	if err != nil {
+		return err
+	}
+
+	f := bitutils.NewFieldReader(data)
+	segmentType := f.Read(3)
+	if f.Err() != nil {
+		return fmt.Errorf("invalid tcfca segment: %v", f.Err())
+	}
+	if segmentType != SegmentTypePublisherPurposes {
+		return nil
+	}
+	c.pubPurposesExpressConsent = f.Read(24)
+	c.pubPurposesImpliedConsent = f.Read(24)
+	c.numCustomPurposes = uint8(f.Read(6))
+	c.customPurposesExpressConsent = f.Read(uint(c.numCustomPurposes))
+	c.customPurposesImpliedConsent = f.Read(uint(c.numCustomPurposes))
+	if f.Err() != nil {
+		return fmt.Errorf("invalid tcfca publisher purposes segment: %v", f.Err())
+	}
+	c.hasPublisherPurposes = true
+	return nil
+}
+
+// readTimestamp reads a 36-bit timestamp, stored as deciseconds since the epoch.
+func readTimestamp(f *bitutils.FieldReader) time.Time {
+	deciseconds := int64(f.Read(36))
+	return time.Unix(deciseconds/10, (deciseconds%10)*int64(100*time.Millisecond))
+}
+
+// readLanguage reads a two-letter language code, stored as two 6-bit letters with A=0.
+func readLanguage(f *bitutils.FieldReader) string {
+	return string([]byte{byte(f.Read(6)) + 'A', byte(f.Read(6)) + 'A'})
+}
@@ -0,0 +1,131 @@
+package vendorconsent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testCore              = "BPk6AIAPk6AJkEsACBENAyBQAcAAADAAAAAUoEAFIAUABQAIAAo"
+	testPublisherPurposes = "cAAACAAAAdQ"
+)
+
+func TestParseString(t *testing.T) {
+	consent, err := ParseString(testCore + "." + testPublisherPurposes)
+	assert.NoError(t, err)
+
+	assert.Equal(t, uint8(1), consent.Version())
+	assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), consent.Created().UTC())
+	assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 10, 0, time.UTC), consent.LastUpdated().UTC())
+	assert.Equal(t, uint16(300), consent.CmpID())
+	assert.Equal(t, uint16(2), consent.CmpVersion())
+	assert.Equal(t, uint8(1), consent.ConsentScreen())
+	assert.Equal(t, "EN", consent.ConsentLanguage())
+	assert.Equal(t, uint16(50), consent.VendorListVersion())
+	assert.Equal(t, uint8(1), consent.TCFPolicyVersion())
+	assert.False(t, consent.UseNonStandardStacks())
+
+	assert.True(t, consent.SpecialFeatureExpressConsent(1))
+	assert.False(t, consent.SpecialFeatureExpressConsent(2))
+	assert.False(t, consent.SpecialFeatureExpressConsent(0))
+	assert.False(t, consent.SpecialFeatureExpressConsent(13))
+
+	for purpose := consentconstants.Purpose(1); purpose <= 24; purpose++ {
+		assert.Equal(t, purpose <= 3, consent.PurposeExpressConsent(purpose), "express consent for purpose %d", purpose)
+		assert.Equal(t, purpose == 4 || purpose == 5, consent.PurposeImpliedConsent(purpose), "implied consent for purpose %d", purpose)
+	}
+
+	// VendorExpressConsent is a BitField, VendorImpliedConsent a RangeSection.
+	assert.Equal(t, uint16(10), consent.MaxVendorID())
+	for vendor := uint16(1); vendor <= 20; vendor++ {
+		assert.Equal(t, vendor == 1 || vendor == 3 || vendor == 10, consent.VendorExpressConsent(vendor), "express consent for vendor %d", vendor)
+		assert.Equal(t, (vendor >= 5 && vendor <= 8) || vendor == 20, consent.VendorImpliedConsent(vendor), "implied consent for vendor %d", vendor)
+	}
+	assert.Equal(t, uint(305), consent.CoreSegmentBits())
+
+	assert.True(t, consent.HasPublisherPurposes())
+	assert.True(t, consent.PubPurposeExpressConsent(1))
+	assert.False(t, consent.PubPurposeExpressConsent(2))
+	assert.True(t, consent.PubPurposeImpliedConsent(2))
+	assert.Equal(t, uint8(3), consent.NumCustomPurposes())
+	assert.True(t, consent.CustomPurposeExpressConsent(1))
+	assert.False(t, consent.CustomPurposeExpressConsent(2))
+	assert.True(t, consent.CustomPurposeExpressConsent(3))
+	assert.False(t, consent.CustomPurposeExpressConsent(4))
+	assert.True(t, consent.CustomPurposeImpliedConsent(2))
+}
+
+func TestParseStringCoreOnly(t *testing.T) {
+	consent, err := ParseString(testCore)
+	assert.NoError(t, err)
+	assert.True(t, consent.PurposeExpressConsent(1))
+	assert.False(t, consent.HasPublisherPurposes())
+	assert.False(t, consent.PubPurposeExpressConsent(1))
+	assert.Equal(t, uint8(0), consent.NumCustomPurposes())
+}
+
+func TestParseStringIgnoresUnknownSegments(t *testing.T) {
+	// "IA" is a segment of type 1.
+	consent, err := ParseString(testCore + ".IA")
+	assert.NoError(t, err)
+	assert.False(t, consent.HasPublisherPurposes())
+}
+
+func TestParseStringInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		consent       string
+		expectedError string
+	}{
+		{
+			name:          "empty",
+			consent:       "",
+			expectedError: "tcfca consent string cannot be empty",
+		},
+		{
+			name:          "bad_base64",
+			consent:       "BPk6AIAPk6AJ!",
+			expectedError: "failed to decode segment: illegal base64 data at input byte 12",
+		},
+		{
+			name:          "version_0",
+			consent:       "APk6AIAPk6AJkEsACBENAyBQAcAAADAAAAAUoEAFIAUABQAIAAo",
+			expectedError: "the tcfca consent string's version is not supported: the consent string encoded a Version of 0, but this value must be 1",
+		},
+		{
+			name:          "version_2",
+			consent:       "CPk6AIAPk6AJkEsACBENAyBQAcAAADAAAAAUoEAFIAUABQAIAAo",
+			expectedError: "the tcfca consent string's version is not supported: the consent string encoded a Version of 2, but this value must be 1",
+		},
+		{
+			name:          "truncated_core",
+			consent:       "BPk6AIAPk6AJkEsACBENAyBQ",
+			expectedError: "invalid tcfca core segment: ReadBits expected 12 bits to start at bit 139, but the data was only 18 bytes long",
+		},
+		{
+			name:          "truncated_vendors",
+			consent:       "BPk6AIAPk6AJkEsACBENAyBQAcAAADAAAAAUoEAFIAU",
+			expectedError: "invalid tcfca VendorImpliedConsent: ParseUInt16 expected a 16-bit int to start at bit 256, but the consent string was only 33 bytes long",
+		},
+		{
+			name:          "truncated_publisher_purposes",
+			consent:       testCore + ".cAAACA",
+			expectedError: "invalid tcfca publisher purposes segment: ReadBits expected 24 bits to start at bit 27, but the data was only 6 bytes long",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseString(tt.consent)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestParseStringUnsupportedVersion(t *testing.T) {
+	_, err := ParseString("CPk6AIAPk6AJkEsACBENAyBQAcAAADAAAAAUoEAFIAUABQAIAAo")
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+}
@@ -0,0 +1,159 @@
+package vendorconsent
+
+import (
+	"time"
+
+	"github.com/prebid/go-gdpr/consentconstants"
+	tcf2 "github.com/prebid/go-gdpr/vendorconsent/tcf2"
+)
+
+// ConsentMetadata is a parsed TCF Canada consent string.
+type ConsentMetadata struct {
+	version                      uint8
+	created                      time.Time
+	lastUpdated                  time.Time
+	cmpID                        uint16
+	cmpVersion                   uint16
+	consentScreen                uint8
+	consentLanguage              string
+	vendorListVersion            uint16
+	tcfPolicyVersion             uint8
+	useNonStandardStacks         bool
+	specialFeatureExpressConsent uint64
+	purposesExpressConsent       uint64
+	purposesImpliedConsent       uint64
+	vendorExpressConsent         tcf2.VendorSection
+	vendorImpliedConsent         tcf2.VendorSection
+	coreBits                     uint
+
+	hasPublisherPurposes         bool
+	pubPurposesExpressConsent    uint64
+	pubPurposesImpliedConsent    uint64
+	numCustomPurposes            uint8
+	customPurposesExpressConsent uint64
+	customPurposesImpliedConsent uint64
+}
+
+// Version returns the version of the consent string.
+func (c ConsentMetadata) Version() uint8 {
+	return c.version
+}
+
+// Created returns the time that the consent string was first created.
+func (c ConsentMetadata) Created() time.Time {
+	return c.created
+}
+
+// LastUpdated returns the time that the consent string was last updated.
+func (c ConsentMetadata) LastUpdated() time.Time {
+	return c.lastUpdated
+}
+
+// CmpID returns the ID of the CMP used to update the consent string.
+func (c ConsentMetadata) CmpID() uint16 {
+	return c.cmpID
+}
+
+// CmpVersion returns the version of the CMP used to update the consent string.
+func (c ConsentMetadata) CmpVersion() uint16 {
+	return c.cmpVersion
+}
+
+// ConsentScreen returns the number of the CMP screen where consent was given.
+func (c ConsentMetadata) ConsentScreen() uint8 {
+	return c.consentScreen
+}
+
+// ConsentLanguage returns the two-letter ISO639-1 language code used by the CMP, in uppercase.
+func (c ConsentMetadata) ConsentLanguage() string {
+	return c.consentLanguage
+}
+
+// VendorListVersion returns the version of the vendor list needed to interpret the consent string.
+func (c ConsentMetadata) VendorListVersion() uint16 {
+	return c.vendorListVersion
+}
+
+// TCFPolicyVersion returns the TCF Canada policy version needed to interpret the consent string.
+func (c ConsentMetadata) TCFPolicyVersion() uint8 {
+	return c.tcfPolicyVersion
+}
+
+// UseNonStandardStacks returns true if the CMP used non-IAB standard stacks.
+func (c ConsentMetadata) UseNonStandardStacks() bool {
+	return c.useNonStandardStacks
+}
+
+// SpecialFeatureExpressConsent returns true if the user gave express consent to the given special feature (1 to 12).
+func (c ConsentMetadata) SpecialFeatureExpressConsent(id consentconstants.SpecialFeature) bool {
+	return flagSet(c.specialFeatureExpressConsent, 12, uint(id))
+}
+
+// PurposeExpressConsent returns true if the user gave express consent to the given purpose (1 to 24).
+func (c ConsentMetadata) PurposeExpressConsent(id consentconstants.Purpose) bool {
+	return flagSet(c.purposesExpressConsent, 24, uint(id))
+}
+
+// PurposeImpliedConsent returns true if implied consent was established for the given purpose (1 to 24).
+func (c ConsentMetadata) PurposeImpliedConsent(id consentconstants.Purpose) bool {
+	return flagSet(c.purposesImpliedConsent, 24, uint(id))
+}
+
+// VendorExpressConsent returns true if the user gave express consent to the given vendor.
+func (c ConsentMetadata) VendorExpressConsent(id uint16) bool {
+	return c.vendorExpressConsent.VendorConsent(id)
+}
+
+// VendorImpliedConsent returns true if implied consent was established for the given vendor.
+func (c ConsentMetadata) VendorImpliedConsent(id uint16) bool {
+	return c.vendorImpliedConsent.VendorConsent(id)
+}
+
+// MaxVendorID returns the highest vendor ID encoded in the VendorExpressConsent field.
+func (c ConsentMetadata) MaxVendorID() uint16 {
+	return c.vendorExpressConsent.MaxVendorID()
+}
+
+// CoreSegmentBits returns the number of bits used by the core segment's fields. Any bits after them
+// only pad the segment out to a whole base64 character.
+func (c ConsentMetadata) CoreSegmentBits() uint {
+	return c.coreBits
+}
+
+// HasPublisherPurposes returns true if the consent string included the publisher purposes segment.
+func (c ConsentMetadata) HasPublisherPurposes() bool {
+	return c.hasPublisherPurposes
+}
+
+// PubPurposeExpressConsent returns true if the user gave express consent to the publisher for the given purpose.
+func (c ConsentMetadata) PubPurposeExpressConsent(id consentconstants.Purpose) bool {
+	return flagSet(c.pubPurposesExpressConsent, 24, uint(id))
+}
+
+// PubPurposeImpliedConsent returns true if the publisher established implied consent for the given purpose.
+func (c ConsentMetadata) PubPurposeImpliedConsent(id consentconstants.Purpose) bool {
+	return flagSet(c.pubPurposesImpliedConsent, 24, uint(id))
+}
+
+// NumCustomPurposes returns the number of custom purposes defined by the publisher.
+func (c ConsentMetadata) NumCustomPurposes() uint8 {
+	return c.numCustomPurposes
+}
+
+// CustomPurposeExpressConsent returns true if the user gave express consent to the given custom purpose.
+func (c ConsentMetadata) CustomPurposeExpressConsent(id uint8) bool {
+	return flagSet(c.customPurposesExpressConsent, uint(c.numCustomPurposes), uint(id))
+}
+
+// CustomPurposeImpliedConsent returns true if implied consent was established for the given custom purpose.
+func (c ConsentMetadata) CustomPurposeImpliedConsent(id uint8) bool {
+	return flagSet(c.customPurposesImpliedConsent, uint(c.numCustomPurposes), uint(id))
+}
+
+// flagSet returns true if the 1-based index'th flag of a width-bit field is set.
+func flagSet(field uint64, width uint, index uint) bool {
+	if index < 1 || index > width {
+		return false
+	}
+	return field&(1<<(width-index)) != 0
+}
@@ -0,0 +1,194 @@
+package vendorconsent
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/cmplist"
+	"github.com/prebid/go-gdpr/consentconstants"
+	tcf2 "github.com/prebid/go-gdpr/vendorconsent/tcf2"
+)
+
+// FindingCode identifies a kind of disagreement between a consent string and a vendor list.
+type FindingCode string
+
+const (
+	// FindingVendorListVersionMismatch means the list isn't the version the consent string was written for.
+	FindingVendorListVersionMismatch FindingCode = "vendor_list_version_mismatch"
+	// FindingMaxVendorIDBeyondList means the consent string's MaxVendorID is higher than any vendor in the list.
+	FindingMaxVendorIDBeyondList FindingCode = "max_vendor_id_beyond_list"
+	// FindingUnknownVendorConsent means the consent string gives consent to a vendor the list doesn't have.
+	FindingUnknownVendorConsent FindingCode = "unknown_vendor_consent"
+	// FindingDeletedVendorConsent means the consent string gives consent to a vendor which was deleted from
+	// the list before the string was created.
+	FindingDeletedVendorConsent FindingCode = "deleted_vendor_consent"
+	// FindingUnknownVendorLegitimateInterest means the consent string establishes legitimate interest for a
+	// vendor the list doesn't have.
+	FindingUnknownVendorLegitimateInterest FindingCode = "unknown_vendor_legitimate_interest"
+	// FindingRestrictionOnUndeclaredPurpose means a publisher restriction targets a vendor for a purpose
+	// the vendor never declared.
+	FindingRestrictionOnUndeclaredPurpose FindingCode = "restriction_on_undeclared_purpose"
+	// FindingUnregisteredCMP means the consent string's CmpID isn't in the CMP list.
+	FindingUnregisteredCMP FindingCode = "unregistered_cmp"
+	// FindingDeletedCMP means the consent string was last updated by a CMP whose registration had already
+	// been revoked.
+	FindingDeletedCMP FindingCode = "deleted_cmp"
+	// FindingPolicyVersionBelowMinimum means the consent string's TCFPolicyVersion is lower than required.
+	FindingPolicyVersionBelowMinimum FindingCode = "policy_version_below_minimum"
+)
+
+// Finding is a single disagreement found by ValidateAgainstVendorList.
+type Finding struct {
+	Code FindingCode
+	// VendorID is the vendor involved, or 0 if the finding isn't about one vendor.
+	VendorID uint16
+	// PurposeID is the purpose involved, or 0 if the finding isn't about a purpose.
+	PurposeID consentconstants.Purpose
+	Message   string
+}
+
+// legitimateInterests is implemented by consent strings with a vendor legitimate interest section.
+type legitimateInterests interface {
+	VendorLegitInterest(id uint16) bool
+	VendorLegitInterestMaxID() uint16
+}
+
+// publisherRestrictions is implemented by consent strings with publisher restrictions.
+type publisherRestrictions interface {
+	PublisherRestrictions() []tcf2.PublisherRestriction
+}
+
+// ValidateAgainstVendorList compares a consent string with the vendor list it refers to, and reports
+// anything the list can't account for. A well-behaved CMP produces no findings, so they're best used to
+// monitor CMPs rather than to reject requests.
+//
+// The MaxVendorID check needs a list which implements api.VendorIDLister, as every list in this module
+// does. It is skipped for other lists. The legitimate interest and publisher restriction checks
+// only apply to TCF 2 consent strings.
+func ValidateAgainstVendorList(consent api.VendorConsents, list api.VendorList) []Finding {
+	var findings []Finding
+	if consent.VendorListVersion() != list.Version() {
+		findings = append(findings, Finding{
+			Code: FindingVendorListVersionMismatch,
+			Message: fmt.Sprintf("the consent string needs vendor list version %d, but was checked against version %d",
+				consent.VendorListVersion(), list.Version()),
+		})
+	}
+
+	if listMax, ok := maxVendorID(list); ok {
+		if consent.MaxVendorID() > listMax {
+			findings = append(findings, Finding{
+				Code: FindingMaxVendorIDBeyondList,
+				Message: fmt.Sprintf("the consent string has a MaxVendorID of %d, but the highest vendor in the list is %d",
+					consent.MaxVendorID(), listMax),
+			})
+		}
+	}
+
+	for id := uint16(1); id <= consent.MaxVendorID() && id != 0; id++ {
+		if !consent.VendorConsent(id) {
+			continue
+		}
+		vendor := list.Vendor(id)
+		if vendor == nil {
+			findings = append(findings, Finding{
+				Code:     FindingUnknownVendorConsent,
+				VendorID: id,
+				Message:  fmt.Sprintf("vendor %d has consent, but isn't in the vendor list", id),
+			})
+		} else if !vendorValid(consent, list, id) {
+			// Only deleted vendors are invalid once they're in the list.
+			deletedDate, _ := vendor.(api.VendorDeletion).DeletedDate()
+			findings = append(findings, Finding{
+				Code:     FindingDeletedVendorConsent,
+				VendorID: id,
+				Message: fmt.Sprintf("vendor %d has consent, but was deleted from the vendor list on %s, before the consent string was created",
+					id, deletedDate.Format(time.RFC3339)),
+			})
+		}
+	}
+
+	if li, ok := consent.(legitimateInterests); ok {
+		for id := uint16(1); id <= li.VendorLegitInterestMaxID() && id != 0; id++ {
+			if li.VendorLegitInterest(id) && list.Vendor(id) == nil {
+				findings = append(findings, Finding{
+					Code:     FindingUnknownVendorLegitimateInterest,
+					VendorID: id,
+					Message:  fmt.Sprintf("vendor %d has legitimate interest established, but isn't in the vendor list", id),
+				})
+			}
+		}
+	}
+
+	if restrictions, ok := consent.(publisherRestrictions); ok {
+		for _, restriction := range restrictions.PublisherRestrictions() {
+			findings = append(findings, checkRestriction(restriction, list)...)
+		}
+	}
+	return findings
+}
+
+// checkRestriction reports the vendors in the list which a restriction targets for a purpose they never
+// declared. Restrictions are often written as ranges spanning IDs the list doesn't use, so vendors
+// missing from the list are ignored.
+func checkRestriction(restriction tcf2.PublisherRestriction, list api.VendorList) []Finding {
+	var findings []Finding
+	purpose := consentconstants.Purpose(restriction.PurposeID)
+	for _, vendors := range restriction.Vendors {
+		for id := vendors.Start; id <= vendors.End && id != 0; id++ {
+			vendor := list.Vendor(id)
+			if vendor == nil || vendor.PurposeStrict(purpose) || vendor.LegitimateInterestStrict(purpose) {
+				continue
+			}
+			if flexible, ok := vendor.(api.FlexiblePurposes); ok && flexible.FlexiblePurpose(purpose) {
+				continue
+			}
+			findings = append(findings, Finding{
+				Code:      FindingRestrictionOnUndeclaredPurpose,
+				VendorID:  id,
+				PurposeID: purpose,
+				Message: fmt.Sprintf("a publisher restriction of type %d targets vendor %d for purpose %d, which the vendor never declared",
+					restriction.RestrictType, id, purpose),
+			})
+		}
+	}
+	return findings
+}
+
+// ValidateCMP checks that the CMP which last updated the consent string is registered in the CMP list,
+// and that its registration wasn't revoked before then. Strings from unregistered CMPs are worth
+// monitoring, but like ValidateAgainstVendorList's findings, they're best not used to reject requests,
+// since a CMP may be used before the list someone fetched includes it.
+func ValidateCMP(consent api.VendorConsents, list *cmplist.List) []Finding {
+	cmp, ok := list.CMP(consent.CmpID())
+	if !ok {
+		return []Finding{{
+			Code:    FindingUnregisteredCMP,
+			Message: fmt.Sprintf("the consent string was last updated by CMP %d, which isn't in the CMP list", consent.CmpID()),
+		}}
+	}
+	if cmp.Deleted() && !LastChanged(consent).Before(cmp.DeletedAt) {
+		return []Finding{{
+			Code: FindingDeletedCMP,
+			Message: fmt.Sprintf("the consent string was last updated by CMP %d, which was deleted from the CMP list on %s",
+				cmp.ID, cmp.DeletedAt.Format(time.RFC3339)),
+		}}
+	}
+	return nil
+}
+
+// ValidatePolicyVersion checks that the consent string was written under TCF policy version minimum or
+// later. Policy version 4 is TCF 2.2, and version 5 is TCF 2.3. Strings written under older policies may
+// not meet the current rules, such as the ban on legitimate interest for purposes 3 to 6 from version 4.
+// TCF v1 strings have no policy version, so they're reported for any minimum above 0.
+func ValidatePolicyVersion(consent api.VendorConsents, minimum uint8) []Finding {
+	if consent.TCFPolicyVersion() >= minimum {
+		return nil
+	}
+	return []Finding{{
+		Code: FindingPolicyVersionBelowMinimum,
+		Message: fmt.Sprintf("the consent string has TCF policy version %d, but at least version %d is required",
+			consent.TCFPolicyVersion(), minimum),
+	}}
+}
@@ -0,0 +1,173 @@
+package vendorconsent
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prebid/go-gdpr/cmplist"
+	"github.com/prebid/go-gdpr/vendorlist2"
+	"github.com/prebid/go-gdpr/vendorlist3"
+)
+
+// validateTCString has vendor list version 15, consent and legitimate interest for vendors 1 to 10,
+// and a publisher restriction of type 1 on purpose 7 for vendor 32.
+const validateTCString = "COwAdDhOwAdDhN4ABAENAPCgAAQAAv___wAAAFP_AAp_4AI6ACACAA"
+
+func TestValidateAgainstVendorList(t *testing.T) {
+	consent, err := ParseString(validateTCString)
+	assertNilError(t, err)
+
+	list, err := vendorlist3.ParseEagerly([]byte(`{
+		"gvlSpecificationVersion": 3,
+		"vendorListVersion": 15,
+		"vendors": {
+			"1": {"id": 1}, "2": {"id": 2}, "3": {"id": 3}, "4": {"id": 4},
+			"5": {"id": 5}, "6": {"id": 6}, "7": {"id": 7}, "8": {"id": 8},
+			"32": {"id": 32, "purposes": [1], "legIntPurposes": [2], "flexiblePurposes": [3]}
+		}
+	}`))
+	assertNilError(t, err)
+
+	expected := []Finding{
+		{Code: FindingUnknownVendorConsent, VendorID: 9, Message: "vendor 9 has consent, but isn't in the vendor list"},
+		{Code: FindingUnknownVendorConsent, VendorID: 10, Message: "vendor 10 has consent, but isn't in the vendor list"},
+		{Code: FindingUnknownVendorLegitimateInterest, VendorID: 9, Message: "vendor 9 has legitimate interest established, but isn't in the vendor list"},
+		{Code: FindingUnknownVendorLegitimateInterest, VendorID: 10, Message: "vendor 10 has legitimate interest established, but isn't in the vendor list"},
+		{
+			Code:      FindingRestrictionOnUndeclaredPurpose,
+			VendorID:  32,
+			PurposeID: 7,
+			Message:   "a publisher restriction of type 1 targets vendor 32 for purpose 7, which the vendor never declared",
+		},
+	}
+	assertFindingsEqual(t, expected, ValidateAgainstVendorList(consent, list))
+}
+
+func TestValidateAgainstVendorListMaxVendorID(t *testing.T) {
+	consent, err := ParseString(validateTCString)
+	assertNilError(t, err)
+
+	list, err := vendorlist2.ParseEagerly([]byte(`{
+		"gvlSpecificationVersion": 2,
+		"vendorListVersion": 14,
+		"vendors": {
+			"1": {"id": 1}, "2": {"id": 2}, "3": {"id": 3}, "4": {"id": 4}, "5": {"id": 5},
+			"6": {"id": 6}, "7": {"id": 7}, "8": {"id": 8}, "9": {"id": 9}
+		}
+	}`))
+	assertNilError(t, err)
+
+	expected := []Finding{
+		{Code: FindingVendorListVersionMismatch, Message: "the consent string needs vendor list version 15, but was checked against version 14"},
+		{Code: FindingMaxVendorIDBeyondList, Message: "the consent string has a MaxVendorID of 10, but the highest vendor in the list is 9"},
+		{Code: FindingUnknownVendorConsent, VendorID: 10, Message: "vendor 10 has consent, but isn't in the vendor list"},
+		{Code: FindingUnknownVendorLegitimateInterest, VendorID: 10, Message: "vendor 10 has legitimate interest established, but isn't in the vendor list"},
+	}
+	assertFindingsEqual(t, expected, ValidateAgainstVendorList(consent, list))
+}
+
+func TestValidateAgainstVendorListClean(t *testing.T) {
+	consent, err := ParseString(validateTCString)
+	assertNilError(t, err)
+
+	list, err := vendorlist3.ParseEagerly([]byte(`{
+		"gvlSpecificationVersion": 3,
+		"vendorListVersion": 15,
+		"vendors": {
+			"1": {"id": 1}, "2": {"id": 2}, "3": {"id": 3}, "4": {"id": 4}, "5": {"id": 5},
+			"6": {"id": 6}, "7": {"id": 7}, "8": {"id": 8}, "9": {"id": 9}, "10": {"id": 10},
+			"32": {"id": 32, "flexiblePurposes": [7]}
+		}
+	}`))
+	assertNilError(t, err)
+
+	assertFindingsEqual(t, nil, ValidateAgainstVendorList(consent, list))
+}
+
+func TestValidateAgainstVendorListDeletedVendor(t *testing.T) {
+	// validateTCString was created on 2020-03-09.
+	consent, err := ParseString(validateTCString)
+	assertNilError(t, err)
+
+	list, err := vendorlist3.ParseEagerly([]byte(`{
+		"gvlSpecificationVersion": 3,
+		"vendorListVersion": 15,
+		"vendors": {
+			"1": {"id": 1}, "2": {"id": 2, "deletedDate": "2020-01-01T00:00:00Z"}, "3": {"id": 3},
+			"4": {"id": 4}, "5": {"id": 5}, "6": {"id": 6}, "7": {"id": 7}, "8": {"id": 8}, "9": {"id": 9},
+			"10": {"id": 10, "deletedDate": "2021-01-01T00:00:00Z"}, "32": {"id": 32, "purposes": [7]}
+		}
+	}`))
+	assertNilError(t, err)
+
+	expected := []Finding{{
+		Code:     FindingDeletedVendorConsent,
+		VendorID: 2,
+		Message:  "vendor 2 has consent, but was deleted from the vendor list on 2020-01-01T00:00:00Z, before the consent string was created",
+	}}
+	assertFindingsEqual(t, expected, ValidateAgainstVendorList(consent, list))
+}
+
+func TestValidateCMP(t *testing.T) {
+	// validateTCString was last updated on 2020-03-09 by CMP 888.
+	consent, err := ParseString(validateTCString)
+	assertNilError(t, err)
+
+	tests := []struct {
+		description string
+		cmps        string
+		expected    []Finding
+	}{
+		{
+			description: "Registered CMP",
+			cmps:        `{"888": {"id": 888, "name": "CMP"}}`,
+		},
+		{
+			description: "CMP deleted after the string was updated",
+			cmps:        `{"888": {"id": 888, "name": "CMP", "deletedAt": "2021-01-01T00:00:00Z"}}`,
+		},
+		{
+			description: "Unregistered CMP",
+			cmps:        `{"1": {"id": 1, "name": "CMP"}}`,
+			expected: []Finding{{
+				Code:    FindingUnregisteredCMP,
+				Message: "the consent string was last updated by CMP 888, which isn't in the CMP list",
+			}},
+		},
+		{
+			description: "CMP deleted before the string was updated",
+			cmps:        `{"888": {"id": 888, "name": "CMP", "deletedAt": "2020-01-01T00:00:00Z"}}`,
+			expected: []Finding{{
+				Code:    FindingDeletedCMP,
+				Message: "the consent string was last updated by CMP 888, which was deleted from the CMP list on 2020-01-01T00:00:00Z",
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			list, err := cmplist.Parse([]byte(`{"lastUpdated": "2024-01-01T00:00:00Z", "cmps": ` + test.cmps + `}`))
+			assertNilError(t, err)
+			assertFindingsEqual(t, test.expected, ValidateCMP(consent, list))
+		})
+	}
+}
+
+func TestValidatePolicyVersion(t *testing.T) {
+	// validateTCString has TCF policy version 2.
+	consent, err := ParseString(validateTCString)
+	assertNilError(t, err)
+
+	assertFindingsEqual(t, nil, ValidatePolicyVersion(consent, 2))
+	assertFindingsEqual(t, []Finding{{
+		Code:    FindingPolicyVersionBelowMinimum,
+		Message: "the consent string has TCF policy version 2, but at least version 4 is required",
+	}}, ValidatePolicyVersion(consent, 4))
+}
+
+func assertFindingsEqual(t *testing.T, expected []Finding, actual []Finding) {
+	t.Helper()
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Findings were not equal. Expected %+v, actual %+v", expected, actual)
+	}
+}
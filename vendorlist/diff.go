@@ -0,0 +1,123 @@
+package vendorlist
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+)
+
+// maxPurposeID is the highest purpose ID a TC string can hold.
+const maxPurposeID = 24
+
+// Changes describes how a vendor list changed between two versions. Every list is sorted by vendor ID.
+type Changes struct {
+	// Added holds the vendors in the new list but not the old one.
+	Added []uint16
+	// Removed holds the vendors in the old list but not the new one.
+	Removed []uint16
+	// Deleted holds the vendors which gained a deletedDate in the new list.
+	Deleted []uint16
+	// Changed holds the vendors in both lists whose declared purposes or legal bases changed.
+	Changed []VendorChange
+}
+
+// VendorChange describes how the purposes a vendor declared changed between two versions of a list.
+// Purposes are compared strictly, ignoring flexible purposes.
+type VendorChange struct {
+	VendorID uint16
+
+	PurposesAdded   []consentconstants.Purpose
+	PurposesRemoved []consentconstants.Purpose
+
+	LegitimateInterestsAdded   []consentconstants.Purpose
+	LegitimateInterestsRemoved []consentconstants.Purpose
+}
+
+// Diff compares two versions of a vendor list. It returns an error if either list doesn't implement
+// api.VendorIDLister.
+func Diff(old, new api.VendorList) (Changes, error) {
+	oldIDs, err := vendorIDs(old)
+	if err != nil {
+		return Changes{}, err
+	}
+	newIDs, err := vendorIDs(new)
+	if err != nil {
+		return Changes{}, err
+	}
+
+	inOld := make(map[uint16]struct{}, len(oldIDs))
+	for _, id := range oldIDs {
+		inOld[id] = struct{}{}
+	}
+	inNew := make(map[uint16]struct{}, len(newIDs))
+	for _, id := range newIDs {
+		inNew[id] = struct{}{}
+	}
+
+	var changes Changes
+	for _, id := range oldIDs {
+		if _, ok := inNew[id]; !ok {
+			changes.Removed = append(changes.Removed, id)
+		}
+	}
+	for _, id := range newIDs {
+		if _, ok := inOld[id]; !ok {
+			changes.Added = append(changes.Added, id)
+			continue
+		}
+		oldVendor, newVendor := old.Vendor(id), new.Vendor(id)
+		if isDeleted(newVendor) && !isDeleted(oldVendor) {
+			changes.Deleted = append(changes.Deleted, id)
+		}
+		if change, ok := diffVendor(id, oldVendor, newVendor); ok {
+			changes.Changed = append(changes.Changed, change)
+		}
+	}
+	return changes, nil
+}
+
+func vendorIDs(list api.VendorList) ([]uint16, error) {
+	lister, ok := list.(api.VendorIDLister)
+	if !ok {
+		return nil, fmt.Errorf("vendor list version %d can't enumerate its vendors", list.Version())
+	}
+	ids := lister.VendorIDs()
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids, nil
+}
+
+func isDeleted(vendor api.Vendor) bool {
+	deletion, ok := vendor.(api.VendorDeletion)
+	if !ok {
+		return false
+	}
+	_, deleted := deletion.DeletedDate()
+	return deleted
+}
+
+func diffVendor(id uint16, old, new api.Vendor) (VendorChange, bool) {
+	change := VendorChange{VendorID: id}
+	for purpose := consentconstants.Purpose(1); purpose <= maxPurposeID; purpose++ {
+		change.PurposesAdded, change.PurposesRemoved = diffFlag(
+			purpose, old.PurposeStrict(purpose), new.PurposeStrict(purpose), change.PurposesAdded, change.PurposesRemoved)
+		change.LegitimateInterestsAdded, change.LegitimateInterestsRemoved = diffFlag(
+			purpose, old.LegitimateInterestStrict(purpose), new.LegitimateInterestStrict(purpose), change.LegitimateInterestsAdded, change.LegitimateInterestsRemoved)
+	}
+	changed := len(change.PurposesAdded) > 0 || len(change.PurposesRemoved) > 0 ||
+		len(change.LegitimateInterestsAdded) > 0 || len(change.LegitimateInterestsRemoved) > 0
+	return change, changed
+}
+
+func diffFlag(purpose consentconstants.Purpose, old, new bool, added, removed []consentconstants.Purpose) ([]consentconstants.Purpose, []consentconstants.Purpose) {
+	switch {
+	case new && !old:
+		added = append(added, purpose)
+	case old && !new:
+		removed = append(removed, purpose)
+	}
+	return added, removed
+}
@@ -0,0 +1,89 @@
+package vendorlist
+
+import (
+	"testing"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/prebid/go-gdpr/vendorlist2"
+	"github.com/prebid/go-gdpr/vendorlist3"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	diffOldList = `{
+		"gvlSpecificationVersion": 3,
+		"vendorListVersion": 41,
+		"vendors": {
+			"1": {"id": 1, "purposes": [1, 2], "legIntPurposes": [7]},
+			"2": {"id": 2, "purposes": [1], "legIntPurposes": [2, 7], "flexiblePurposes": [2]},
+			"3": {"id": 3, "purposes": [1]},
+			"4": {"id": 4, "purposes": [1]}
+		}
+	}`
+	diffNewList = `{
+		"gvlSpecificationVersion": 3,
+		"vendorListVersion": 42,
+		"vendors": {
+			"1": {"id": 1, "purposes": [1, 2], "legIntPurposes": [7]},
+			"2": {"id": 2, "purposes": [1, 2], "legIntPurposes": [7, 8], "flexiblePurposes": [2]},
+			"4": {"id": 4, "purposes": [1], "deletedDate": "2023-06-01T00:00:00Z"},
+			"5": {"id": 5, "purposes": [1]}
+		}
+	}`
+)
+
+func TestDiff(t *testing.T) {
+	oldList, err := vendorlist3.ParseEagerly([]byte(diffOldList))
+	assert.NoError(t, err)
+	newList, err := vendorlist3.ParseEagerly([]byte(diffNewList))
+	assert.NoError(t, err)
+
+	changes, err := Diff(oldList, newList)
+	assert.NoError(t, err)
+	assert.Equal(t, Changes{
+		Added:   []uint16{5},
+		Removed: []uint16{3},
+		Deleted: []uint16{4},
+		Changed: []VendorChange{{
+			VendorID:                   2,
+			PurposesAdded:              []consentconstants.Purpose{2},
+			LegitimateInterestsAdded:   []consentconstants.Purpose{8},
+			LegitimateInterestsRemoved: []consentconstants.Purpose{2},
+		}},
+	}, changes)
+}
+
+func TestDiffAcrossPackages(t *testing.T) {
+	oldList := vendorlist2.ParseLazily([]byte(diffOldList))
+	newList, err := vendorlist3.ParseEagerly([]byte(diffNewList))
+	assert.NoError(t, err)
+
+	changes, err := Diff(oldList, newList)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint16{5}, changes.Added)
+	assert.Equal(t, []uint16{3}, changes.Removed)
+	assert.Equal(t, []uint16{4}, changes.Deleted)
+	assert.Len(t, changes.Changed, 1)
+}
+
+func TestDiffSameList(t *testing.T) {
+	list, err := vendorlist3.ParseEagerly([]byte(diffNewList))
+	assert.NoError(t, err)
+
+	changes, err := Diff(list, list)
+	assert.NoError(t, err)
+	assert.Equal(t, Changes{}, changes)
+}
+
+func TestDiffUnsupportedList(t *testing.T) {
+	// Embedding only the api.VendorList interface hides the VendorIDs method.
+	oldList := struct{ api.VendorList }{ParseLazily([]byte(`{"vendorListVersion": 5, "vendors": []}`))}
+	newList, err := vendorlist3.ParseEagerly([]byte(diffNewList))
+	assert.NoError(t, err)
+
+	for _, lists := range [][2]api.VendorList{{oldList, newList}, {newList, oldList}} {
+		_, err := Diff(lists[0], lists[1])
+		assert.EqualError(t, err, "vendor list version 5 can't enumerate its vendors")
+	}
+}
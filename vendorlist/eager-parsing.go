@@ -38,6 +38,7 @@ func ParseEagerly(data []byte) (api.VendorList, error) {
 	for i := 0; i < len(contract.Vendors); i++ {
 		thisVendor := contract.Vendors[i]
 		parsedList.vendors[thisVendor.ID] = parseVendor(thisVendor)
+		parsedList.maxVendorID = max(parsedList.maxVendorID, thisVendor.ID)
 	}
 
 	return parsedList, nil
@@ -65,6 +66,7 @@ type parsedVendorList struct {
 	specVersion uint16
 	version     uint16
 	vendors     map[uint16]parsedVendor
+	maxVendorID uint16
 }
 
 func (l parsedVendorList) SpecVersion() uint16 {
@@ -75,6 +77,20 @@ func (l parsedVendorList) Version() uint16 {
 	return l.version
 }
 
+// VendorIDs returns the IDs of every vendor in the list, in no particular order.
+func (l parsedVendorList) VendorIDs() []uint16 {
+	ids := make([]uint16, 0, len(l.vendors))
+	for id := range l.vendors {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// MaxVendorID returns the highest vendor ID in the list, which was found while parsing it.
+func (l parsedVendorList) MaxVendorID() uint16 {
+	return l.maxVendorID
+}
+
 func (l parsedVendorList) Vendor(vendorID uint16) api.Vendor {
 	vendor, ok := l.vendors[vendorID]
 	if ok {
@@ -52,6 +52,17 @@ func (l lazyVendorList) Vendor(vendorID uint16) api.Vendor {
 	return nil
 }
 
+// VendorIDs returns the IDs of every vendor in the list, in list order. Malformed entries are skipped.
+func (l lazyVendorList) VendorIDs() []uint16 {
+	var ids []uint16
+	jsonparser.ArrayEach(l, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		if val, ok := lazyParseInt(value, "id"); ok {
+			ids = append(ids, uint16(val))
+		}
+	}, "vendors")
+	return ids
+}
+
 type lazyVendor []byte
 
 func (l lazyVendor) Purpose(purposeID consentconstants.Purpose) bool {
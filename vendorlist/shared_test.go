@@ -9,6 +9,17 @@ import (
 func AssertVendorlistCorrectness(t *testing.T, parser func(data []byte) api.VendorList) {
 	t.Run("TestVendorList", vendorListTester(parser))
 	t.Run("TestVendor", vendorTester(parser))
+	t.Run("TestVendorIDs", vendorIDsTester(parser))
+}
+
+func vendorIDsTester(parser func(data []byte) api.VendorList) func(*testing.T) {
+	return func(t *testing.T) {
+		lister, ok := parser([]byte(testData)).(api.VendorIDLister)
+		assertBoolsEqual(t, true, ok)
+		ids := lister.VendorIDs()
+		assertIntsEqual(t, 1, len(ids))
+		assertIntsEqual(t, 32, int(ids[0]))
+	}
 }
 
 func vendorListTester(parser func(data []byte) api.VendorList) func(*testing.T) {
@@ -0,0 +1,71 @@
+package vendorlist
+
+import (
+	"testing"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/vendorlist2"
+	"github.com/prebid/go-gdpr/vendorlist3"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGenerations checks that lists of every generation can be used through the api interfaces alone,
+// and that older generations report nothing for what they can't declare.
+func TestGenerations(t *testing.T) {
+	const (
+		v1 = `{"vendorListVersion": 5, "vendors": [{"id": 8, "purposeIds": [1], "legIntPurposeIds": [2]}]}`
+		v2 = `{"gvlSpecificationVersion": 2, "vendorListVersion": 5, "vendors": {"8": {"id": 8, "purposes": [1], "legIntPurposes": [2]}}}`
+		v3 = `{"gvlSpecificationVersion": 3, "vendorListVersion": 5, "vendors": {"8": {"id": 8, "purposes": [1], "legIntPurposes": [2]}}}`
+	)
+	eagerly := func(parse func([]byte) (api.VendorList, error), data string) func(*testing.T) api.VendorList {
+		return func(t *testing.T) api.VendorList {
+			list, err := parse([]byte(data))
+			assert.NoError(t, err)
+			return list
+		}
+	}
+	lazily := func(parse func([]byte) api.VendorList, data string) func(*testing.T) api.VendorList {
+		return func(t *testing.T) api.VendorList {
+			return parse([]byte(data))
+		}
+	}
+
+	tests := []struct {
+		name  string
+		parse func(*testing.T) api.VendorList
+	}{
+		{name: "v1_eager", parse: eagerly(ParseEagerly, v1)},
+		{name: "v1_lazy", parse: lazily(ParseLazily, v1)},
+		{name: "v2_eager", parse: eagerly(vendorlist2.ParseEagerly, v2)},
+		{name: "v2_lazy", parse: lazily(vendorlist2.ParseLazily, v2)},
+		{name: "v3_eager", parse: eagerly(func(data []byte) (api.VendorList, error) { return vendorlist3.ParseEagerly(data) }, v3)},
+		{name: "v3_streaming", parse: eagerly(func(data []byte) (api.VendorList, error) { return vendorlist3.ParseStreaming(data) }, v3)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := tt.parse(t)
+			assert.Equal(t, uint16(5), list.Version())
+
+			lister, ok := list.(api.VendorIDLister)
+			if assert.True(t, ok) {
+				assert.Equal(t, []uint16{8}, lister.VendorIDs())
+			}
+
+			vendor := list.Vendor(8)
+			assert.True(t, vendor.Purpose(1))
+			assert.True(t, vendor.PurposeStrict(1))
+			assert.True(t, vendor.LegitimateInterest(2))
+			assert.True(t, vendor.LegitimateInterestStrict(2))
+			if flexible, ok := vendor.(api.FlexiblePurposes); ok {
+				assert.False(t, flexible.FlexiblePurpose(1))
+			}
+			assert.False(t, vendor.SpecialPurpose(1))
+			assert.False(t, vendor.SpecialFeature(1))
+			if deletion, ok := vendor.(api.VendorDeletion); ok {
+				_, deleted := deletion.DeletedDate()
+				assert.False(t, deleted)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package vendorlist2
 import (
 	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/prebid/go-gdpr/api"
 	"github.com/prebid/go-gdpr/consentconstants"
@@ -34,6 +35,7 @@ func ParseEagerly(data []byte) (api.VendorList, error) {
 
 	for _, v := range contract.Vendors {
 		parsedList.vendors[v.ID] = parseVendor(v)
+		parsedList.maxVendorID = max(parsedList.maxVendorID, v.ID)
 	}
 
 	return parsedList, nil
@@ -46,6 +48,17 @@ func parseVendor(contract vendorListVendorContract) parsedVendor {
 		flexiblePurposes:    mapifyPurpose(contract.FlexiblePurposes),
 		specialPurposes:     mapifyPurpose(contract.SpecialPurposes),
 		specialFeatures:     mapifySpecialFeature(contract.SpecialFeatures),
+
+		usesCookies:                contract.UsesCookies,
+		cookieMaxAgeSeconds:        contract.CookieMaxAgeSeconds,
+		cookieRefresh:              contract.CookieRefresh,
+		usesNonCookieAccess:        contract.UsesNonCookieAccess,
+		deviceStorageDisclosureURL: contract.DeviceStorageDisclosureURL,
+	}
+	// Like the lazy parser, treat a deletedDate which isn't RFC3339 as "not deleted"
+	// rather than rejecting the whole list over a single vendor.
+	if deletedDate, err := time.Parse(time.RFC3339, contract.DeletedDate); err == nil {
+		parsed.deletedDate = deletedDate
 	}
 
 	return parsed
@@ -73,6 +86,7 @@ type parsedVendorList struct {
 	specVersion uint16
 	version     uint16
 	vendors     map[uint16]parsedVendor
+	maxVendorID uint16
 }
 
 func (l parsedVendorList) SpecVersion() uint16 {
@@ -83,6 +97,20 @@ func (l parsedVendorList) Version() uint16 {
 	return l.version
 }
 
+// VendorIDs returns the IDs of every vendor in the list, in no particular order.
+func (l parsedVendorList) VendorIDs() []uint16 {
+	ids := make([]uint16, 0, len(l.vendors))
+	for id := range l.vendors {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// MaxVendorID returns the highest vendor ID in the list, which was found while parsing it.
+func (l parsedVendorList) MaxVendorID() uint16 {
+	return l.maxVendorID
+}
+
 func (l parsedVendorList) Vendor(vendorID uint16) api.Vendor {
 	vendor, ok := l.vendors[vendorID]
 	if ok {
@@ -97,6 +125,13 @@ type parsedVendor struct {
 	flexiblePurposes    map[consentconstants.Purpose]struct{}
 	specialPurposes     map[consentconstants.Purpose]struct{}
 	specialFeatures     map[consentconstants.SpecialFeature]struct{}
+	deletedDate         time.Time
+
+	usesCookies                bool
+	cookieMaxAgeSeconds        int64
+	cookieRefresh              bool
+	usesNonCookieAccess        bool
+	deviceStorageDisclosureURL string
 }
 
 func (l parsedVendor) Purpose(purposeID consentconstants.Purpose) (hasPurpose bool) {
@@ -131,6 +166,12 @@ func (l parsedVendor) LegitimateInterestStrict(purposeID consentconstants.Purpos
 	return
 }
 
+// FlexiblePurpose returns true if this vendor declared the given purpose as flexible
+func (l parsedVendor) FlexiblePurpose(purposeID consentconstants.Purpose) (isFlexible bool) {
+	_, isFlexible = l.flexiblePurposes[purposeID]
+	return
+}
+
 // SpecialPurpose returns true if this vendor claims a need for the given special purpose
 func (l parsedVendor) SpecialPurpose(purposeID consentconstants.Purpose) (hasSpecialPurpose bool) {
 	_, hasSpecialPurpose = l.specialPurposes[purposeID]
@@ -143,6 +184,36 @@ func (l parsedVendor) SpecialFeature(featureID consentconstants.SpecialFeature)
 	return
 }
 
+// DeletedDate returns the time this vendor was deleted from the list, if it was
+func (l parsedVendor) DeletedDate() (deletedDate time.Time, isDeleted bool) {
+	return l.deletedDate, !l.deletedDate.IsZero()
+}
+
+// UsesCookies returns true if this vendor stores data in cookies
+func (l parsedVendor) UsesCookies() bool {
+	return l.usesCookies
+}
+
+// CookieMaxAgeSeconds returns the longest lifetime of this vendor's cookies, in seconds
+func (l parsedVendor) CookieMaxAgeSeconds() int64 {
+	return l.cookieMaxAgeSeconds
+}
+
+// CookieRefresh returns true if this vendor's cookies may be refreshed
+func (l parsedVendor) CookieRefresh() bool {
+	return l.cookieRefresh
+}
+
+// UsesNonCookieAccess returns true if this vendor accesses the device by means other than cookies
+func (l parsedVendor) UsesNonCookieAccess() bool {
+	return l.usesNonCookieAccess
+}
+
+// DeviceStorageDisclosureURL returns the URL of this vendor's device storage disclosure
+func (l parsedVendor) DeviceStorageDisclosureURL() string {
+	return l.deviceStorageDisclosureURL
+}
+
 type vendorListContract struct {
 	GVLSpecificationVersion uint16                              `json:"gvlSpecificationVersion"`
 	Version                 uint16                              `json:"vendorListVersion"`
@@ -156,4 +227,11 @@ type vendorListVendorContract struct {
 	FlexiblePurposes    []uint8 `json:"flexiblePurposes"`
 	SpecialPurposes     []uint8 `json:"specialPurposes"`
 	SpecialFeatures     []uint8 `json:"specialFeatures"`
+	DeletedDate         string  `json:"deletedDate"`
+
+	UsesCookies                bool   `json:"usesCookies"`
+	CookieMaxAgeSeconds        int64  `json:"cookieMaxAgeSeconds"`
+	CookieRefresh              bool   `json:"cookieRefresh"`
+	UsesNonCookieAccess        bool   `json:"usesNonCookieAccess"`
+	DeviceStorageDisclosureURL string `json:"deviceStorageDisclosureUrl"`
 }
@@ -2,7 +2,9 @@ package vendorlist2
 
 import (
 	"testing"
+	"time"
 
+	"github.com/prebid/go-gdpr/api"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -72,3 +74,48 @@ func TestParseEagerlyEmptyVendorList(t *testing.T) {
 		})
 	}
 }
+
+func TestParseEagerlyVendorIDs(t *testing.T) {
+	parsedGVL, err := ParseEagerly([]byte(testDataSpecVersion3))
+	assert.NoError(t, err)
+	lister, ok := parsedGVL.(interface{ VendorIDs() []uint16 })
+	if assert.True(t, ok) {
+		assert.ElementsMatch(t, []uint16{8, 80}, lister.VendorIDs())
+	}
+}
+
+func TestParseEagerlyMaxVendorID(t *testing.T) {
+	parsedGVL, err := ParseEagerly([]byte(testDataSpecVersion3))
+	assert.NoError(t, err)
+	reporter, ok := parsedGVL.(api.MaxVendorIDReporter)
+	if assert.True(t, ok) {
+		assert.Equal(t, uint16(80), reporter.MaxVendorID())
+	}
+}
+
+func TestParseEagerlyDeletedDate(t *testing.T) {
+	parsedGVL, err := ParseEagerly([]byte(testDataDeletedVendor))
+	assert.NoError(t, err)
+
+	deletedDate, deleted := parsedGVL.Vendor(8).(api.VendorDeletion).DeletedDate()
+	assert.True(t, deleted)
+	assert.Equal(t, time.Date(2020, 6, 28, 0, 0, 0, 0, time.UTC), deletedDate)
+	_, deleted = parsedGVL.Vendor(80).(api.VendorDeletion).DeletedDate()
+	assert.False(t, deleted)
+}
+
+func TestParseEagerlyMalformedDeletedDate(t *testing.T) {
+	parsedGVL, err := ParseEagerly([]byte(testDataMalformedDeletedDate))
+	assert.NoError(t, err)
+
+	_, deleted := parsedGVL.Vendor(8).(api.VendorDeletion).DeletedDate()
+	assert.False(t, deleted)
+	_, deleted = parsedGVL.Vendor(80).(api.VendorDeletion).DeletedDate()
+	assert.False(t, deleted)
+}
+
+func TestParseEagerlyDeviceStorage(t *testing.T) {
+	parsedGVL, err := ParseEagerly([]byte(testDataDeviceStorage))
+	assert.NoError(t, err)
+	AssertDeviceStorageCorrectness(t, parsedGVL)
+}
@@ -2,6 +2,7 @@ package vendorlist2
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/buger/jsonparser"
 	"github.com/prebid/go-gdpr/api"
@@ -44,6 +45,18 @@ func (l lazyVendorList) Vendor(vendorID uint16) api.Vendor {
 	return nil
 }
 
+// VendorIDs returns the IDs of every vendor in the list, in list order. Malformed entries are skipped.
+func (l lazyVendorList) VendorIDs() []uint16 {
+	var ids []uint16
+	jsonparser.ObjectEach(l, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
+		if id, err := strconv.ParseUint(string(key), 10, 16); err == nil {
+			ids = append(ids, uint16(id))
+		}
+		return nil
+	}, "vendors")
+	return ids
+}
+
 type lazyVendor []byte
 
 func (l lazyVendor) Purpose(purposeID consentconstants.Purpose) bool {
@@ -72,6 +85,11 @@ func (l lazyVendor) LegitimateInterestStrict(purposeID consentconstants.Purpose)
 	return idExists(l, int(purposeID), "legIntPurposes")
 }
 
+// FlexiblePurpose returns true if this vendor declared the given purpose as flexible
+func (l lazyVendor) FlexiblePurpose(purposeID consentconstants.Purpose) (isFlexible bool) {
+	return idExists(l, int(purposeID), "flexiblePurposes")
+}
+
 // SpecialPurpose returns true if this vendor claims a need for the given special purpose
 func (l lazyVendor) SpecialPurpose(purposeID consentconstants.Purpose) (hasSpecialPurpose bool) {
 	return idExists(l, int(purposeID), "specialPurposes")
@@ -82,6 +100,48 @@ func (l lazyVendor) SpecialFeature(featureID consentconstants.SpecialFeature) (h
 	return idExists(l, int(featureID), "specialFeatures")
 }
 
+// DeletedDate returns the time this vendor was deleted from the list, if it was
+func (l lazyVendor) DeletedDate() (deletedDate time.Time, isDeleted bool) {
+	value, err := jsonparser.GetString(l, "deletedDate")
+	if err != nil {
+		return time.Time{}, false
+	}
+	if deletedDate, err = time.Parse(time.RFC3339, value); err != nil {
+		return time.Time{}, false
+	}
+	return deletedDate, true
+}
+
+// UsesCookies returns true if this vendor stores data in cookies
+func (l lazyVendor) UsesCookies() bool {
+	value, _ := jsonparser.GetBoolean(l, "usesCookies")
+	return value
+}
+
+// CookieMaxAgeSeconds returns the longest lifetime of this vendor's cookies, in seconds
+func (l lazyVendor) CookieMaxAgeSeconds() int64 {
+	value, _ := jsonparser.GetInt(l, "cookieMaxAgeSeconds")
+	return value
+}
+
+// CookieRefresh returns true if this vendor's cookies may be refreshed
+func (l lazyVendor) CookieRefresh() bool {
+	value, _ := jsonparser.GetBoolean(l, "cookieRefresh")
+	return value
+}
+
+// UsesNonCookieAccess returns true if this vendor accesses the device by means other than cookies
+func (l lazyVendor) UsesNonCookieAccess() bool {
+	value, _ := jsonparser.GetBoolean(l, "usesNonCookieAccess")
+	return value
+}
+
+// DeviceStorageDisclosureURL returns the URL of this vendor's device storage disclosure
+func (l lazyVendor) DeviceStorageDisclosureURL() string {
+	value, _ := jsonparser.GetString(l, "deviceStorageDisclosureUrl")
+	return value
+}
+
 // Returns false unless "id" exists in an array located at "data.key".
 func idExists(data []byte, id int, key string) bool {
 	hasID := false
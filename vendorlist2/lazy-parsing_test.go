@@ -2,7 +2,9 @@ package vendorlist2
 
 import (
 	"testing"
+	"time"
 
+	"github.com/prebid/go-gdpr/api"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -70,3 +72,59 @@ func TestParseLazilyEmptyVendorList(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLazilyVendorIDs(t *testing.T) {
+	tests := []struct {
+		name       string
+		vendorList string
+		expected   []uint16
+	}{
+		{
+			name:       "vendors",
+			vendorList: testDataSpecVersion3,
+			expected:   []uint16{8, 80},
+		},
+		{
+			name:       "no_vendors",
+			vendorList: testDataSpecVersion3Empty,
+			expected:   nil,
+		},
+		{
+			name:       "malformed_ids",
+			vendorList: `{"vendors": {"8": {"id": 8}, "x": {}, "70000": {}}}`,
+			expected:   []uint16{8},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lister, ok := ParseLazily([]byte(tt.vendorList)).(interface{ VendorIDs() []uint16 })
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.expected, lister.VendorIDs())
+			}
+		})
+	}
+}
+
+func TestParseLazilyDeletedDate(t *testing.T) {
+	parsedGVL := ParseLazily([]byte(testDataDeletedVendor))
+
+	deletedDate, deleted := parsedGVL.Vendor(8).(api.VendorDeletion).DeletedDate()
+	assert.True(t, deleted)
+	assert.Equal(t, time.Date(2020, 6, 28, 0, 0, 0, 0, time.UTC), deletedDate)
+	_, deleted = parsedGVL.Vendor(80).(api.VendorDeletion).DeletedDate()
+	assert.False(t, deleted)
+}
+
+func TestParseLazilyMalformedDeletedDate(t *testing.T) {
+	parsedGVL := ParseLazily([]byte(testDataMalformedDeletedDate))
+
+	_, deleted := parsedGVL.Vendor(8).(api.VendorDeletion).DeletedDate()
+	assert.False(t, deleted)
+	_, deleted = parsedGVL.Vendor(80).(api.VendorDeletion).DeletedDate()
+	assert.False(t, deleted)
+}
+
+func TestParseLazilyDeviceStorage(t *testing.T) {
+	AssertDeviceStorageCorrectness(t, ParseLazily([]byte(testDataDeviceStorage)))
+}
@@ -28,6 +28,11 @@ func AssertVendorListCorrectness(t *testing.T, gvl api.VendorList) {
 	assertBoolsEqual(t, false, v.LegitimateInterest(3))
 	assertBoolsEqual(t, false, v.LegitimateInterestStrict(3))
 
+	flexible := v.(api.FlexiblePurposes)
+	assertBoolsEqual(t, false, flexible.FlexiblePurpose(1))
+	assertBoolsEqual(t, true, flexible.FlexiblePurpose(2))
+	assertBoolsEqual(t, true, flexible.FlexiblePurpose(9))
+
 	assertBoolsEqual(t, true, v.SpecialPurpose(1))
 	assertBoolsEqual(t, true, v.SpecialPurpose(2))
 	assertBoolsEqual(t, false, v.SpecialPurpose(3)) // Does not exist yet
@@ -57,6 +62,11 @@ func AssertVendorListCorrectness(t *testing.T, gvl api.VendorList) {
 	assertBoolsEqual(t, false, v.LegitimateInterest(3))
 	assertBoolsEqual(t, false, v.LegitimateInterestStrict(3))
 
+	flexible = v.(api.FlexiblePurposes)
+	assertBoolsEqual(t, false, flexible.FlexiblePurpose(1))
+	assertBoolsEqual(t, true, flexible.FlexiblePurpose(2))
+	assertBoolsEqual(t, false, flexible.FlexiblePurpose(3))
+
 	assertBoolsEqual(t, false, v.SpecialPurpose(1))
 	assertBoolsEqual(t, false, v.SpecialPurpose(2))
 	assertBoolsEqual(t, false, v.SpecialPurpose(3)) // Does not exist yet
@@ -186,6 +196,69 @@ const testDataSpecVersion3Empty = `
 }
 `
 
+const testDataDeletedVendor = `
+{
+	"gvlSpecificationVersion": 2,
+	"vendorListVersion": 28,
+	"vendors": {
+		"8": {"id": 8, "purposes": [1], "deletedDate": "2020-06-28T00:00:00Z"},
+		"80": {"id": 80, "purposes": [1]}
+	}
+}
+`
+
+const testDataMalformedDeletedDate = `
+{
+	"gvlSpecificationVersion": 2,
+	"vendorListVersion": 28,
+	"vendors": {
+		"8": {"id": 8, "purposes": [1], "deletedDate": "2020-06-28"},
+		"80": {"id": 80, "purposes": [1], "deletedDate": ""}
+	}
+}
+`
+
+const testDataDeviceStorage = `
+{
+	"gvlSpecificationVersion": 2,
+	"vendorListVersion": 28,
+	"vendors": {
+		"8": {
+			"id": 8,
+			"purposes": [1],
+			"usesCookies": true,
+			"cookieMaxAgeSeconds": 31536000,
+			"cookieRefresh": true,
+			"usesNonCookieAccess": true,
+			"deviceStorageDisclosureUrl": "https://www.emerse.com/devicestorage.json"
+		},
+		"80": {"id": 80, "purposes": [1]}
+	}
+}
+`
+
+func AssertDeviceStorageCorrectness(t *testing.T, gvl api.VendorList) {
+	storage, ok := gvl.Vendor(8).(api.DeviceStorage)
+	assertBoolsEqual(t, true, ok)
+	assertBoolsEqual(t, true, storage.UsesCookies())
+	assertIntsEqual(t, 31536000, int(storage.CookieMaxAgeSeconds()))
+	assertBoolsEqual(t, true, storage.CookieRefresh())
+	assertBoolsEqual(t, true, storage.UsesNonCookieAccess())
+	if url := storage.DeviceStorageDisclosureURL(); url != "https://www.emerse.com/devicestorage.json" {
+		t.Errorf("Wrong device storage disclosure URL: %s", url)
+	}
+
+	storage, ok = gvl.Vendor(80).(api.DeviceStorage)
+	assertBoolsEqual(t, true, ok)
+	assertBoolsEqual(t, false, storage.UsesCookies())
+	assertIntsEqual(t, 0, int(storage.CookieMaxAgeSeconds()))
+	assertBoolsEqual(t, false, storage.CookieRefresh())
+	assertBoolsEqual(t, false, storage.UsesNonCookieAccess())
+	if url := storage.DeviceStorageDisclosureURL(); url != "" {
+		t.Errorf("Expected no device storage disclosure URL, got %s", url)
+	}
+}
+
 func assertIntsEqual(t *testing.T, expected int, actual int) {
 	t.Helper()
 	if actual != expected {
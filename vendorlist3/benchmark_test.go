@@ -0,0 +1,90 @@
+package vendorlist3
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// benchmarkVendor is a vendor of typical size. Its ID is filled in by fullSizeVendorList.
+const benchmarkVendor = `{
+	"id": %ID%,
+	"name": "Vendor %ID%",
+	"purposes": [1, 3, 4, 5, 6],
+	"legIntPurposes": [2, 7, 8, 9, 10],
+	"flexiblePurposes": [2, 7, 9, 10],
+	"specialPurposes": [1, 2],
+	"features": [1, 2, 3],
+	"specialFeatures": [1],
+	"usesCookies": true,
+	"cookieMaxAgeSeconds": 31536000,
+	"cookieRefresh": true,
+	"usesNonCookieAccess": true,
+	"deviceStorageDisclosureUrl": "https://vendor.example.com/devicestorage.json",
+	"dataRetention": {"stdRetention": 365, "purposes": {"1": 30, "3": 90}, "specialPurposes": {"2": 730}},
+	"dataDeclaration": [1, 2, 3, 4, 6, 8, 10, 11],
+	"urls": [
+		{"langId": "en", "privacy": "https://vendor.example.com/privacy", "legIntClaim": "https://vendor.example.com/li"},
+		{"langId": "fr", "privacy": "https://vendor.example.com/fr/privacy", "legIntClaim": "https://vendor.example.com/fr/li"}
+	]
+}`
+
+// fullSizeVendorList builds a list with about as many vendors as the live Global Vendor List.
+func fullSizeVendorList() []byte {
+	var b strings.Builder
+	b.WriteString(`{"gvlSpecificationVersion": 3, "vendorListVersion": 42, "tcfPolicyVersion": 4, "lastUpdated": "2023-05-18T16:07:14Z", "vendors": {`)
+	for id := 1; id <= 1000; id++ {
+		if id > 1 {
+			b.WriteString(",")
+		}
+		idString := strconv.Itoa(id)
+		b.WriteString(`"` + idString + `": `)
+		b.WriteString(strings.ReplaceAll(benchmarkVendor, "%ID%", idString))
+	}
+	b.WriteString("}}")
+	return []byte(b.String())
+}
+
+func BenchmarkParseEagerly(b *testing.B) {
+	data := fullSizeVendorList()
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseEagerly(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseStreaming(b *testing.B) {
+	data := fullSizeVendorList()
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseStreaming(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalBinary(b *testing.B) {
+	parsed, err := ParseEagerly(fullSizeVendorList())
+	if err != nil {
+		b.Fatal(err)
+	}
+	data, err := parsed.MarshalBinary()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var list VendorList
+		if err := list.UnmarshalBinary(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,454 @@
+package vendorlist3
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prebid/go-gdpr/consentconstants"
+)
+
+// binaryMagic starts every list written by MarshalBinary, followed by binaryFormatVersion.
+const binaryMagic = "GVL3"
+
+// binaryFormatVersion is bumped whenever the layout written by MarshalBinary changes. UnmarshalBinary
+// rejects other versions, so stale caches get rebuilt from JSON rather than misread.
+const binaryFormatVersion = 2
+
+// MarshalBinary encodes the list in a compact binary format, so a service can cache a parsed list on
+// disk and load it with UnmarshalBinary at startup instead of parsing the JSON again. The output is
+// deterministic: the same list always gives the same bytes.
+//
+// The format is specific to this package, and may change between releases. Treat it as a cache, and
+// keep the JSON around to rebuild it.
+func (l *VendorList) MarshalBinary() ([]byte, error) {
+	w := &binaryWriter{buf: make([]byte, 0, 64*(len(l.vendors)+1))}
+	w.buf = append(w.buf, binaryMagic...)
+	w.buf = append(w.buf, binaryFormatVersion)
+
+	w.uint(uint64(l.specVersion))
+	w.uint(uint64(l.version))
+	w.uint(uint64(l.tcfPolicyVersion))
+	w.time(l.lastUpdated)
+	for _, declarations := range l.declarationMaps() {
+		w.declarations(*declarations)
+	}
+	w.stacks(l.stacks)
+
+	ids := l.VendorIDs()
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	w.uint(uint64(len(ids)))
+	for _, id := range ids {
+		w.vendor(l.vendors[id])
+	}
+	return w.buf, nil
+}
+
+// UnmarshalBinary replaces the list with one encoded by MarshalBinary.
+func (l *VendorList) UnmarshalBinary(data []byte) error {
+	if len(data) < len(binaryMagic)+1 || string(data[:len(binaryMagic)]) != binaryMagic {
+		return errors.New("data isn't a vendor list written by MarshalBinary")
+	}
+	if version := data[len(binaryMagic)]; version != binaryFormatVersion {
+		return fmt.Errorf("binary vendor list format version %d isn't supported", version)
+	}
+
+	r := &binaryReader{data: data, pos: len(binaryMagic) + 1}
+	var parsed VendorList
+	parsed.specVersion = uint16(r.uint(0xffff))
+	parsed.version = uint16(r.uint(0xffff))
+	parsed.tcfPolicyVersion = uint8(r.uint(0xff))
+	parsed.lastUpdated = r.time()
+	for _, declarations := range parsed.declarationMaps() {
+		*declarations = r.declarations()
+	}
+	parsed.stacks = r.stacks()
+
+	count := r.length()
+	parsed.vendors = make(map[uint16]*Vendor, count)
+	for i := 0; i < count; i++ {
+		vendor := r.vendor()
+		parsed.vendors[vendor.id] = vendor
+	}
+
+	if r.err == nil && r.pos != len(data) {
+		r.fail("unexpected data after the vendor list")
+	}
+	if r.err != nil {
+		return fmt.Errorf("failed to read the binary vendor list: %v", r.err)
+	}
+	parsed.index()
+	*l = parsed
+	return nil
+}
+
+// declarationMaps returns the declarations in the order MarshalBinary writes them.
+func (l *VendorList) declarationMaps() []*map[int]Declaration {
+	return []*map[int]Declaration{&l.purposes, &l.specialPurposes, &l.features, &l.specialFeatures, &l.dataCategories}
+}
+
+type binaryWriter struct {
+	buf []byte
+}
+
+func (w *binaryWriter) uint(value uint64) {
+	w.buf = binary.AppendUvarint(w.buf, value)
+}
+
+func (w *binaryWriter) int(value int64) {
+	w.buf = binary.AppendVarint(w.buf, value)
+}
+
+func (w *binaryWriter) bool(value bool) {
+	if value {
+		w.buf = append(w.buf, 1)
+	} else {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+func (w *binaryWriter) string(value string) {
+	w.uint(uint64(len(value)))
+	w.buf = append(w.buf, value...)
+}
+
+func (w *binaryWriter) time(value time.Time) {
+	encoded, _ := value.MarshalBinary()
+	w.uint(uint64(len(encoded)))
+	w.buf = append(w.buf, encoded...)
+}
+
+// nilable writes whether a slice is nil, so the reader can tell it apart from an empty one.
+func (w *binaryWriter) nilable(isNil bool, length int) {
+	if isNil {
+		w.uint(0)
+	} else {
+		w.uint(uint64(length) + 1)
+	}
+}
+
+func (w *binaryWriter) declarations(declarations map[int]Declaration) {
+	ids := make([]int, 0, len(declarations))
+	for id := range declarations {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	w.uint(uint64(len(ids)))
+	for _, id := range ids {
+		declaration := declarations[id]
+		w.int(int64(declaration.ID))
+		w.string(declaration.Name)
+		w.string(declaration.Description)
+		w.nilable(declaration.Illustrations == nil, len(declaration.Illustrations))
+		for _, illustration := range declaration.Illustrations {
+			w.string(illustration)
+		}
+	}
+}
+
+func (w *binaryWriter) stacks(stacks map[int]Stack) {
+	ids := make([]int, 0, len(stacks))
+	for id := range stacks {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	w.uint(uint64(len(ids)))
+	for _, id := range ids {
+		stack := stacks[id]
+		w.int(int64(stack.ID))
+		w.string(stack.Name)
+		w.string(stack.Description)
+		// Stacks list their purposes and special features in order, so these aren't sorted like the
+		// vendors' sets are.
+		w.uint(uint64(len(stack.Purposes)))
+		for _, purpose := range stack.Purposes {
+			w.buf = append(w.buf, uint8(purpose))
+		}
+		w.uint(uint64(len(stack.SpecialFeatures)))
+		for _, feature := range stack.SpecialFeatures {
+			w.buf = append(w.buf, uint8(feature))
+		}
+	}
+}
+
+func (w *binaryWriter) vendor(vendor *Vendor) {
+	w.uint(uint64(vendor.id))
+	w.string(vendor.name)
+	w.time(vendor.deletedDate)
+
+	for _, purposes := range []map[consentconstants.Purpose]struct{}{vendor.purposes, vendor.legitimateInterests, vendor.flexiblePurposes, vendor.specialPurposes} {
+		ids := make([]uint8, 0, len(purposes))
+		for id := range purposes {
+			ids = append(ids, uint8(id))
+		}
+		w.uint8s(ids)
+	}
+	features := make([]uint8, 0, len(vendor.features))
+	for id := range vendor.features {
+		features = append(features, uint8(id))
+	}
+	w.uint8s(features)
+	specialFeatures := make([]uint8, 0, len(vendor.specialFeatures))
+	for id := range vendor.specialFeatures {
+		specialFeatures = append(specialFeatures, uint8(id))
+	}
+	w.uint8s(specialFeatures)
+
+	w.nilable(vendor.dataDeclaration == nil, len(vendor.dataDeclaration))
+	for _, id := range vendor.dataDeclaration {
+		w.int(int64(id))
+	}
+	w.int(int64(vendor.dataRetention.StdRetention))
+	w.retentionPeriods(vendor.dataRetention.Purposes)
+	w.retentionPeriods(vendor.dataRetention.SpecialPurposes)
+
+	w.uint(uint64(len(vendor.urls)))
+	for _, url := range vendor.urls {
+		w.string(url.Language)
+		w.string(url.Privacy)
+		w.string(url.LegIntClaim)
+	}
+
+	w.bool(vendor.usesCookies)
+	w.int(vendor.cookieMaxAgeSeconds)
+	w.bool(vendor.cookieRefresh)
+	w.bool(vendor.usesNonCookieAccess)
+	w.string(vendor.deviceStorageDisclosureURL)
+}
+
+// uint8s writes a set of IDs, sorted so the output is deterministic.
+func (w *binaryWriter) uint8s(ids []uint8) {
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	w.uint(uint64(len(ids)))
+	w.buf = append(w.buf, ids...)
+}
+
+func (w *binaryWriter) retentionPeriods(periods map[consentconstants.Purpose]int) {
+	ids := make([]uint8, 0, len(periods))
+	for id := range periods {
+		ids = append(ids, uint8(id))
+	}
+	w.uint8s(ids)
+	for _, id := range ids {
+		w.int(int64(periods[consentconstants.Purpose(id)]))
+	}
+}
+
+// binaryReader reads what binaryWriter writes. Like decoder, its errors are sticky.
+type binaryReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *binaryReader) fail(format string, args ...interface{}) {
+	if r.err == nil {
+		r.err = fmt.Errorf("offset %d: %s", r.pos, fmt.Sprintf(format, args...))
+	}
+}
+
+// uint reads an unsigned integer, and fails if it's bigger than max.
+func (r *binaryReader) uint(max uint64) uint64 {
+	if r.err != nil {
+		return 0
+	}
+	value, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		r.fail("truncated or invalid integer")
+		return 0
+	}
+	r.pos += n
+	if value > max {
+		r.fail("%d is out of range", value)
+		return 0
+	}
+	return value
+}
+
+func (r *binaryReader) int() int64 {
+	if r.err != nil {
+		return 0
+	}
+	value, n := binary.Varint(r.data[r.pos:])
+	if n <= 0 {
+		r.fail("truncated or invalid integer")
+		return 0
+	}
+	r.pos += n
+	return value
+}
+
+func (r *binaryReader) bool() bool {
+	return r.uint(1) == 1
+}
+
+// length reads the length of something which follows, and fails if there's too little data left to
+// hold it.
+func (r *binaryReader) length() int {
+	length := int(r.uint(uint64(len(r.data))))
+	if r.err == nil && length > len(r.data)-r.pos {
+		r.fail("length %d is longer than the remaining data", length)
+		return 0
+	}
+	return length
+}
+
+func (r *binaryReader) bytes() []byte {
+	length := r.length()
+	if r.err != nil {
+		return nil
+	}
+	value := r.data[r.pos : r.pos+length]
+	r.pos += length
+	return value
+}
+
+func (r *binaryReader) string() string {
+	return string(r.bytes())
+}
+
+func (r *binaryReader) time() time.Time {
+	var value time.Time
+	if encoded := r.bytes(); r.err == nil {
+		if err := value.UnmarshalBinary(encoded); err != nil {
+			r.fail("%v", err)
+		}
+	}
+	return value
+}
+
+// nilable reads what binaryWriter.nilable writes.
+func (r *binaryReader) nilable() (isNil bool, length int) {
+	encoded := int(r.uint(uint64(len(r.data)) + 1))
+	if encoded == 0 {
+		return true, 0
+	}
+	if encoded-1 > len(r.data)-r.pos {
+		r.fail("length %d is longer than the remaining data", encoded-1)
+		return true, 0
+	}
+	return false, encoded - 1
+}
+
+func (r *binaryReader) declarations() map[int]Declaration {
+	count := r.length()
+	declarations := make(map[int]Declaration, count)
+	for i := 0; i < count; i++ {
+		declaration := Declaration{
+			ID:          int(r.int()),
+			Name:        r.string(),
+			Description: r.string(),
+		}
+		if isNil, length := r.nilable(); !isNil {
+			declaration.Illustrations = make([]string, length)
+			for j := range declaration.Illustrations {
+				declaration.Illustrations[j] = r.string()
+			}
+		}
+		declarations[declaration.ID] = declaration
+	}
+	return declarations
+}
+
+func (r *binaryReader) stacks() map[int]Stack {
+	count := r.length()
+	stacks := make(map[int]Stack, count)
+	for i := 0; i < count; i++ {
+		stack := Stack{
+			ID:          int(r.int()),
+			Name:        r.string(),
+			Description: r.string(),
+		}
+		purposes := r.bytes()
+		stack.Purposes = make([]consentconstants.Purpose, len(purposes))
+		for j, id := range purposes {
+			stack.Purposes[j] = consentconstants.Purpose(id)
+		}
+		features := r.bytes()
+		stack.SpecialFeatures = make([]consentconstants.SpecialFeature, len(features))
+		for j, id := range features {
+			stack.SpecialFeatures[j] = consentconstants.SpecialFeature(id)
+		}
+		stacks[stack.ID] = stack
+	}
+	return stacks
+}
+
+func (r *binaryReader) vendor() *Vendor {
+	vendor := &Vendor{
+		id:                  uint16(r.uint(0xffff)),
+		name:                r.string(),
+		deletedDate:         r.time(),
+		purposes:            r.purposes(),
+		legitimateInterests: r.purposes(),
+		flexiblePurposes:    r.purposes(),
+		specialPurposes:     r.purposes(),
+	}
+
+	features := r.bytes()
+	vendor.features = make(map[int]struct{}, len(features))
+	for _, id := range features {
+		vendor.features[int(id)] = struct{}{}
+	}
+	specialFeatures := r.bytes()
+	vendor.specialFeatures = make(map[consentconstants.SpecialFeature]struct{}, len(specialFeatures))
+	for _, id := range specialFeatures {
+		vendor.specialFeatures[consentconstants.SpecialFeature(id)] = struct{}{}
+	}
+
+	if isNil, length := r.nilable(); !isNil {
+		vendor.dataDeclaration = make([]int, length)
+		for i := range vendor.dataDeclaration {
+			vendor.dataDeclaration[i] = int(r.int())
+		}
+	}
+	vendor.dataRetention = DataRetention{
+		StdRetention:    int(r.int()),
+		Purposes:        r.retentionPeriods(),
+		SpecialPurposes: r.retentionPeriods(),
+	}
+
+	if count := r.length(); count > 0 {
+		vendor.urls = make([]URL, count)
+		for i := range vendor.urls {
+			vendor.urls[i] = URL{
+				Language:    r.string(),
+				Privacy:     r.string(),
+				LegIntClaim: r.string(),
+			}
+		}
+	}
+
+	vendor.usesCookies = r.bool()
+	vendor.cookieMaxAgeSeconds = r.int()
+	vendor.cookieRefresh = r.bool()
+	vendor.usesNonCookieAccess = r.bool()
+	vendor.deviceStorageDisclosureURL = r.string()
+	return vendor
+}
+
+func (r *binaryReader) purposes() map[consentconstants.Purpose]struct{} {
+	ids := r.bytes()
+	purposes := make(map[consentconstants.Purpose]struct{}, len(ids))
+	for _, id := range ids {
+		purposes[consentconstants.Purpose(id)] = struct{}{}
+	}
+	return purposes
+}
+
+func (r *binaryReader) retentionPeriods() map[consentconstants.Purpose]int {
+	ids := r.bytes()
+	periods := make(map[consentconstants.Purpose]int, len(ids))
+	for _, id := range ids {
+		periods[consentconstants.Purpose(id)] = int(r.int())
+	}
+	return periods
+}
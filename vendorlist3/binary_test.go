@@ -0,0 +1,93 @@
+package vendorlist3
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "test_data", data: []byte(testData)},
+		{name: "empty", data: []byte(`{"gvlSpecificationVersion": 3, "vendorListVersion": 1, "vendors": {}}`)},
+		{name: "full_size", data: fullSizeVendorList()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := ParseEagerly(tt.data)
+			assert.NoError(t, err)
+
+			encoded, err := parsed.MarshalBinary()
+			assert.NoError(t, err)
+			var decoded VendorList
+			assert.NoError(t, decoded.UnmarshalBinary(encoded))
+			assert.Equal(t, parsed, &decoded)
+
+			reencoded, err := decoded.MarshalBinary()
+			assert.NoError(t, err)
+			assert.Equal(t, encoded, reencoded)
+		})
+	}
+}
+
+func TestUnmarshalBinaryInvalid(t *testing.T) {
+	parsed, err := ParseEagerly([]byte(testData))
+	assert.NoError(t, err)
+	encoded, err := parsed.MarshalBinary()
+	assert.NoError(t, err)
+
+	wrongVersion := append([]byte{}, encoded...)
+	wrongVersion[len(binaryMagic)] = binaryFormatVersion + 1
+
+	tests := []struct {
+		name          string
+		data          []byte
+		expectedError string
+	}{
+		{
+			name:          "empty",
+			data:          nil,
+			expectedError: "data isn't a vendor list written by MarshalBinary",
+		},
+		{
+			name:          "json",
+			data:          []byte(testData),
+			expectedError: "data isn't a vendor list written by MarshalBinary",
+		},
+		{
+			name:          "wrong_format_version",
+			data:          wrongVersion,
+			expectedError: fmt.Sprintf("binary vendor list format version %d isn't supported", binaryFormatVersion+1),
+		},
+		{
+			name:          "trailing_data",
+			data:          append(append([]byte{}, encoded...), 0),
+			expectedError: fmt.Sprintf("failed to read the binary vendor list: offset %d: unexpected data after the vendor list", len(encoded)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := VendorList{version: 7}
+			assert.EqualError(t, list.UnmarshalBinary(tt.data), tt.expectedError)
+			assert.Equal(t, uint16(7), list.Version())
+		})
+	}
+}
+
+func TestUnmarshalBinaryTruncated(t *testing.T) {
+	parsed, err := ParseEagerly([]byte(testData))
+	assert.NoError(t, err)
+	encoded, err := parsed.MarshalBinary()
+	assert.NoError(t, err)
+
+	for length := len(binaryMagic) + 1; length < len(encoded); length++ {
+		var list VendorList
+		assert.Error(t, list.UnmarshalBinary(encoded[:length]), "length %d", length)
+	}
+}
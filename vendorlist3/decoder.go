@@ -0,0 +1,254 @@
+package vendorlist3
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/buger/jsonparser"
+)
+
+// decoder reads JSON values from the front of data, one token at a time. It never looks at a byte
+// twice and doesn't build anything the caller doesn't ask for, which is what makes ParseStreaming fast.
+//
+// Errors are sticky: once one happens, every method returns a zero value, and loops built on more end.
+type decoder struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (d *decoder) fail(format string, args ...interface{}) {
+	if d.err == nil {
+		d.err = fmt.Errorf("offset %d: %s", d.pos, fmt.Sprintf(format, args...))
+	}
+}
+
+func (d *decoder) skipSpace() {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ' ', '\t', '\n', '\r':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next byte which isn't whitespace, or 0 at the end of the data.
+func (d *decoder) peek() byte {
+	d.skipSpace()
+	if d.err != nil || d.pos >= len(d.data) {
+		return 0
+	}
+	return d.data[d.pos]
+}
+
+func (d *decoder) expect(c byte) bool {
+	if d.peek() != c {
+		d.fail("expected %q", c)
+		return false
+	}
+	d.pos++
+	return true
+}
+
+// more consumes the separator before element i of an object or array, and returns true if there is
+// such an element. Loop with:
+//
+//	if d.expect('{') {
+//		for i := 0; d.more('}', i); i++ { ... }
+//	}
+func (d *decoder) more(closing byte, i int) bool {
+	if d.peek() == closing {
+		d.pos++
+		return false
+	}
+	if d.err != nil {
+		return false
+	}
+	return i == 0 || d.expect(',')
+}
+
+// null consumes a null and returns true if it's the next value.
+func (d *decoder) null() bool {
+	if d.peek() != 'n' {
+		return false
+	}
+	d.literal("null")
+	return true
+}
+
+func (d *decoder) literal(value string) {
+	if len(d.data)-d.pos < len(value) || string(d.data[d.pos:d.pos+len(value)]) != value {
+		d.fail("expected %s", value)
+		return
+	}
+	d.pos += len(value)
+}
+
+// rawString returns the contents of the next string, without unescaping them. The bool is true if
+// there are escape sequences.
+func (d *decoder) rawString() ([]byte, bool) {
+	if !d.expect('"') {
+		return nil, false
+	}
+	start := d.pos
+	for {
+		end := bytes.IndexByte(d.data[d.pos:], '"')
+		if end < 0 {
+			d.pos = len(d.data)
+			d.fail("unterminated string")
+			return nil, false
+		}
+		end += d.pos
+		d.pos = end + 1
+
+		// The quote is escaped if an odd number of backslashes come before it.
+		backslashes := 0
+		for i := end - 1; i >= start && d.data[i] == '\\'; i-- {
+			backslashes++
+		}
+		if backslashes%2 == 0 {
+			raw := d.data[start:end]
+			return raw, bytes.IndexByte(raw, '\\') >= 0
+		}
+	}
+}
+
+// key reads an object key and the colon after it. Keys with escape sequences are returned as written.
+func (d *decoder) key() []byte {
+	key, _ := d.rawString()
+	d.expect(':')
+	return key
+}
+
+func (d *decoder) string() string {
+	raw, escaped := d.rawString()
+	if !escaped {
+		return string(raw)
+	}
+	value, err := jsonparser.ParseString(raw)
+	if err != nil {
+		d.fail("invalid string: %v", err)
+	}
+	return value
+}
+
+func (d *decoder) bool() bool {
+	switch d.peek() {
+	case 't':
+		d.literal("true")
+		return true
+	case 'f':
+		d.literal("false")
+		return false
+	}
+	d.fail("expected a boolean")
+	return false
+}
+
+// int reads an integer. Numbers with fractions or exponents are rejected, as encoding/json does when
+// decoding into an integer.
+func (d *decoder) int() int64 {
+	return d.intFrom(d.number())
+}
+
+func (d *decoder) intFrom(raw []byte) int64 {
+	if d.err != nil {
+		return 0
+	}
+	value, err := jsonparser.ParseInt(raw)
+	if err != nil {
+		d.fail("%q isn't an integer", raw)
+		return 0
+	}
+	return value
+}
+
+func (d *decoder) number() []byte {
+	d.skipSpace()
+	start := d.pos
+	for d.pos < len(d.data) {
+		switch c := d.data[d.pos]; {
+		case c >= '0' && c <= '9', c == '-', c == '+', c == '.', c == 'e', c == 'E':
+			d.pos++
+			continue
+		}
+		break
+	}
+	if start == d.pos {
+		d.fail("expected a number")
+	}
+	return d.data[start:d.pos]
+}
+
+func (d *decoder) uint8() uint8 {
+	return uint8(d.ranged(d.int(), 0xff))
+}
+
+func (d *decoder) uint16() uint16 {
+	return uint16(d.ranged(d.int(), 0xffff))
+}
+
+func (d *decoder) ranged(value int64, max int64) int64 {
+	if value < 0 || value > max {
+		d.fail("%d is out of range", value)
+		return 0
+	}
+	return value
+}
+
+func (d *decoder) time() time.Time {
+	raw, _ := d.rawString()
+	if d.err != nil {
+		return time.Time{}
+	}
+	value, err := time.Parse(time.RFC3339, string(raw))
+	if err != nil {
+		d.fail("%v", err)
+	}
+	return value
+}
+
+// skip consumes the next value, whatever it is.
+func (d *decoder) skip() {
+	switch d.peek() {
+	case '"':
+		d.rawString()
+	case '{', '[':
+		depth := 0
+		for d.pos < len(d.data) {
+			switch d.data[d.pos] {
+			case '"':
+				d.rawString()
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					d.pos++
+					return
+				}
+			}
+			d.pos++
+		}
+		d.fail("unterminated value")
+	case 't':
+		d.literal("true")
+	case 'f':
+		d.literal("false")
+	case 'n':
+		d.literal("null")
+	default:
+		d.number()
+	}
+}
+
+// end checks that nothing but whitespace is left.
+func (d *decoder) end() {
+	if d.peek() != 0 {
+		d.fail("unexpected data after the vendor list")
+	}
+}
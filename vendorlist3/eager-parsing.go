@@ -0,0 +1,196 @@
+package vendorlist3
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prebid/go-gdpr/consentconstants"
+)
+
+// ParseEagerly interprets and validates the Vendor List data up front, before returning it.
+// The returned object can be shared safely between goroutines.
+//
+// It returns an error if the data isn't a version 3 vendor list. Version 2 lists are handled by the
+// vendorlist2 package.
+func ParseEagerly(data []byte) (*VendorList, error) {
+	var contract vendorListContract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		return nil, err
+	}
+	if contract.GVLSpecificationVersion != 3 {
+		return nil, fmt.Errorf("data.gvlSpecificationVersion was %d, but only version 3 is supported", contract.GVLSpecificationVersion)
+	}
+	if contract.Version == 0 {
+		return nil, errors.New("data.vendorListVersion was 0 or undefined. Versions should start at 1")
+	}
+
+	parsedList := &VendorList{
+		specVersion:      contract.GVLSpecificationVersion,
+		version:          contract.Version,
+		tcfPolicyVersion: contract.TCFPolicyVersion,
+		lastUpdated:      contract.LastUpdated,
+		declarations: declarations{
+			purposes:        parseDeclarations(contract.Purposes),
+			specialPurposes: parseDeclarations(contract.SpecialPurposes),
+			features:        parseDeclarations(contract.Features),
+			specialFeatures: parseDeclarations(contract.SpecialFeatures),
+			dataCategories:  parseDeclarations(contract.DataCategories),
+			stacks:          parseStacks(contract.Stacks),
+		},
+		vendors: make(map[uint16]*Vendor, len(contract.Vendors)),
+	}
+	for _, v := range contract.Vendors {
+		parsedList.vendors[v.ID] = parseVendor(v)
+	}
+	parsedList.index()
+	return parsedList, nil
+}
+
+func parseDeclarations(contracts map[string]declarationContract) map[int]Declaration {
+	declarations := make(map[int]Declaration, len(contracts))
+	for _, contract := range contracts {
+		declarations[contract.ID] = Declaration(contract)
+	}
+	return declarations
+}
+
+func parseStacks(contracts map[string]stackContract) map[int]Stack {
+	stacks := make(map[int]Stack, len(contracts))
+	for _, contract := range contracts {
+		stack := Stack{
+			ID:              contract.ID,
+			Name:            contract.Name,
+			Description:     contract.Description,
+			Purposes:        make([]consentconstants.Purpose, len(contract.Purposes)),
+			SpecialFeatures: make([]consentconstants.SpecialFeature, len(contract.SpecialFeatures)),
+		}
+		for i, purpose := range contract.Purposes {
+			stack.Purposes[i] = consentconstants.Purpose(purpose)
+		}
+		for i, feature := range contract.SpecialFeatures {
+			stack.SpecialFeatures[i] = consentconstants.SpecialFeature(feature)
+		}
+		stacks[stack.ID] = stack
+	}
+	return stacks
+}
+
+func parseVendor(contract vendorContract) *Vendor {
+	parsed := &Vendor{
+		id:                         contract.ID,
+		name:                       contract.Name,
+		purposes:                   mapifyPurpose(contract.Purposes),
+		legitimateInterests:        mapifyPurpose(contract.LegitimateInterests),
+		flexiblePurposes:           mapifyPurpose(contract.FlexiblePurposes),
+		specialPurposes:            mapifyPurpose(contract.SpecialPurposes),
+		features:                   make(map[int]struct{}, len(contract.Features)),
+		specialFeatures:            make(map[consentconstants.SpecialFeature]struct{}, len(contract.SpecialFeatures)),
+		dataDeclaration:            contract.DataDeclaration,
+		usesCookies:                contract.UsesCookies,
+		cookieMaxAgeSeconds:        contract.CookieMaxAgeSeconds,
+		cookieRefresh:              contract.CookieRefresh,
+		usesNonCookieAccess:        contract.UsesNonCookieAccess,
+		deviceStorageDisclosureURL: contract.DeviceStorageDisclosureURL,
+		dataRetention: DataRetention{
+			StdRetention:    contract.DataRetention.StdRetention,
+			Purposes:        mapifyRetention(contract.DataRetention.Purposes),
+			SpecialPurposes: mapifyRetention(contract.DataRetention.SpecialPurposes),
+		},
+	}
+	if contract.DeletedDate != nil {
+		parsed.deletedDate = *contract.DeletedDate
+	}
+	for _, feature := range contract.Features {
+		parsed.features[int(feature)] = struct{}{}
+	}
+	for _, feature := range contract.SpecialFeatures {
+		parsed.specialFeatures[consentconstants.SpecialFeature(feature)] = struct{}{}
+	}
+	for _, url := range contract.URLs {
+		parsed.urls = append(parsed.urls, URL{
+			Language:    url.LangID,
+			Privacy:     url.Privacy,
+			LegIntClaim: url.LegIntClaim,
+		})
+	}
+	return parsed
+}
+
+func mapifyPurpose(input []uint8) map[consentconstants.Purpose]struct{} {
+	m := make(map[consentconstants.Purpose]struct{}, len(input))
+	for _, value := range input {
+		m[consentconstants.Purpose(value)] = struct{}{}
+	}
+	return m
+}
+
+func mapifyRetention(input map[uint8]int) map[consentconstants.Purpose]int {
+	m := make(map[consentconstants.Purpose]int, len(input))
+	for purpose, days := range input {
+		m[consentconstants.Purpose(purpose)] = days
+	}
+	return m
+}
+
+type vendorListContract struct {
+	GVLSpecificationVersion uint16                         `json:"gvlSpecificationVersion"`
+	Version                 uint16                         `json:"vendorListVersion"`
+	TCFPolicyVersion        uint8                          `json:"tcfPolicyVersion"`
+	LastUpdated             time.Time                      `json:"lastUpdated"`
+	Purposes                map[string]declarationContract `json:"purposes"`
+	SpecialPurposes         map[string]declarationContract `json:"specialPurposes"`
+	Features                map[string]declarationContract `json:"features"`
+	SpecialFeatures         map[string]declarationContract `json:"specialFeatures"`
+	DataCategories          map[string]declarationContract `json:"dataCategories"`
+	Stacks                  map[string]stackContract       `json:"stacks"`
+	Vendors                 map[string]vendorContract      `json:"vendors"`
+}
+
+type declarationContract struct {
+	ID            int      `json:"id"`
+	Name          string   `json:"name"`
+	Description   string   `json:"description"`
+	Illustrations []string `json:"illustrations"`
+}
+
+type stackContract struct {
+	ID              int     `json:"id"`
+	Name            string  `json:"name"`
+	Description     string  `json:"description"`
+	Purposes        []uint8 `json:"purposes"`
+	SpecialFeatures []uint8 `json:"specialFeatures"`
+}
+
+type vendorContract struct {
+	ID                         uint16                `json:"id"`
+	Name                       string                `json:"name"`
+	Purposes                   []uint8               `json:"purposes"`
+	LegitimateInterests        []uint8               `json:"legIntPurposes"`
+	FlexiblePurposes           []uint8               `json:"flexiblePurposes"`
+	SpecialPurposes            []uint8               `json:"specialPurposes"`
+	Features                   []uint8               `json:"features"`
+	SpecialFeatures            []uint8               `json:"specialFeatures"`
+	DataDeclaration            []int                 `json:"dataDeclaration"`
+	DataRetention              dataRetentionContract `json:"dataRetention"`
+	URLs                       []urlContract         `json:"urls"`
+	UsesCookies                bool                  `json:"usesCookies"`
+	CookieMaxAgeSeconds        int64                 `json:"cookieMaxAgeSeconds"`
+	CookieRefresh              bool                  `json:"cookieRefresh"`
+	UsesNonCookieAccess        bool                  `json:"usesNonCookieAccess"`
+	DeviceStorageDisclosureURL string                `json:"deviceStorageDisclosureUrl"`
+	DeletedDate                *time.Time            `json:"deletedDate"`
+}
+
+type dataRetentionContract struct {
+	StdRetention    int           `json:"stdRetention"`
+	Purposes        map[uint8]int `json:"purposes"`
+	SpecialPurposes map[uint8]int `json:"specialPurposes"`
+}
+
+type urlContract struct {
+	LangID      string `json:"langId"`
+	Privacy     string `json:"privacy"`
+	LegIntClaim string `json:"legIntClaim"`
+}
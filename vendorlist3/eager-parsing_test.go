@@ -0,0 +1,62 @@
+package vendorlist3
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEagerly(t *testing.T) {
+	parsed, err := ParseEagerly([]byte(testData))
+	assert.NoError(t, err)
+
+	var gvl api.VendorList = parsed
+	assert.Equal(t, uint16(3), gvl.SpecVersion())
+	assert.Equal(t, uint16(42), gvl.Version())
+	assert.Equal(t, uint8(4), parsed.TCFPolicyVersion())
+	assert.Equal(t, time.Date(2023, 5, 18, 16, 7, 14, 0, time.UTC), parsed.LastUpdated())
+	assert.NotNil(t, gvl.Vendor(8))
+	assert.NotNil(t, gvl.Vendor(80))
+	assert.Nil(t, gvl.Vendor(9))
+}
+
+func TestParseEagerlyEmpty(t *testing.T) {
+	parsed, err := ParseEagerly([]byte(`{"gvlSpecificationVersion": 3, "vendorListVersion": 1, "vendors": {}}`))
+	assert.NoError(t, err)
+	assert.Nil(t, parsed.Vendor(8))
+	_, ok := parsed.Purpose(1)
+	assert.False(t, ok)
+}
+
+func TestParseEagerlyInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		vendorList    string
+		expectedError string
+	}{
+		{
+			name:          "malformed",
+			vendorList:    `{"vendors": [`,
+			expectedError: "unexpected end of JSON input",
+		},
+		{
+			name:          "spec_version_2",
+			vendorList:    `{"gvlSpecificationVersion": 2, "vendorListVersion": 28, "vendors": {}}`,
+			expectedError: "data.gvlSpecificationVersion was 2, but only version 3 is supported",
+		},
+		{
+			name:          "no_version",
+			vendorList:    `{"gvlSpecificationVersion": 3, "vendors": {}}`,
+			expectedError: "data.vendorListVersion was 0 or undefined. Versions should start at 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseEagerly([]byte(tt.vendorList))
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
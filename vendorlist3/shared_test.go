@@ -0,0 +1,122 @@
+package vendorlist3
+
+const testData = `
+{
+	"gvlSpecificationVersion": 3,
+	"vendorListVersion": 42,
+	"tcfPolicyVersion": 4,
+	"lastUpdated": "2023-05-18T16:07:14Z",
+	"purposes": {
+		"1": {
+			"id": 1,
+			"name": "Store and/or access information on a device",
+			"description": "Cookies, device or similar online identifiers can be stored on or read from your device.",
+			"illustrations": []
+		},
+		"2": {
+			"id": 2,
+			"name": "Use limited data to select advertising",
+			"description": "Advertising presented to you can be based on limited data.",
+			"illustrations": ["A car manufacturer wants to promote its electric vehicles to environmentally conscious users."]
+		}
+	},
+	"specialPurposes": {
+		"1": {
+			"id": 1,
+			"name": "Ensure security, prevent and detect fraud, and fix errors",
+			"description": "Your data can be used to monitor for and prevent unusual and possibly fraudulent activity.",
+			"illustrations": []
+		}
+	},
+	"features": {
+		"1": {
+			"id": 1,
+			"name": "Match and combine data from other data sources",
+			"description": "Information about your activity on this service may be matched and combined with other information.",
+			"illustrations": []
+		}
+	},
+	"specialFeatures": {
+		"1": {
+			"id": 1,
+			"name": "Use precise geolocation data",
+			"description": "With your acceptance, your precise location can be used.",
+			"illustrations": []
+		}
+	},
+	"dataCategories": {
+		"1": {
+			"id": 1,
+			"name": "IP addresses",
+			"description": "Your IP address is a number assigned by your Internet Service Provider."
+		}
+	},
+	"stacks": {
+		"1": {
+			"id": 1,
+			"purposes": [],
+			"specialFeatures": [1, 2],
+			"name": "Precise geolocation data, and identification through device scanning",
+			"description": "Precise geolocation and information about device characteristics can be used."
+		},
+		"2": {
+			"id": 2,
+			"purposes": [2, 7],
+			"specialFeatures": [],
+			"name": "Advertising based on limited data and advertising measurement",
+			"description": "Advertising can be presented based on limited data. Advertising performance can be measured."
+		}
+	},
+	"vendors": {
+		"8": {
+			"id": 8,
+			"name": "Emerse Sverige AB",
+			"purposes": [1, 3, 4],
+			"legIntPurposes": [2, 7, 8, 9],
+			"flexiblePurposes": [2, 9],
+			"specialPurposes": [1, 2],
+			"features": [1, 2],
+			"specialFeatures": [1],
+			"usesCookies": true,
+			"cookieMaxAgeSeconds": 31536000,
+			"cookieRefresh": true,
+			"usesNonCookieAccess": false,
+			"deviceStorageDisclosureUrl": "https://www.emerse.com/devicestorage.json",
+			"dataRetention": {
+				"stdRetention": 30,
+				"purposes": { "9": 180 },
+				"specialPurposes": { "2": 365 }
+			},
+			"dataDeclaration": [1, 2, 4, 6],
+			"urls": [
+				{
+					"langId": "en",
+					"privacy": "https://www.emerse.com/privacy-policy/",
+					"legIntClaim": "https://www.emerse.com/privacy-policy/#li"
+				},
+				{
+					"langId": "fr",
+					"privacy": "https://www.emerse.com/fr/privacy-policy/",
+					"legIntClaim": "https://www.emerse.com/fr/privacy-policy/#li"
+				}
+			]
+		},
+		"80": {
+			"id": 80,
+			"name": "Sharethrough, Inc",
+			"purposes": [1, 2, 4, 7, 9, 10],
+			"legIntPurposes": [],
+			"flexiblePurposes": [2, 4, 7, 9, 10],
+			"specialPurposes": [],
+			"features": [],
+			"specialFeatures": [],
+			"dataRetention": {
+				"stdRetention": 90
+			},
+			"dataDeclaration": [],
+			"urls": [],
+			"deletedDate": "2023-06-01T00:00:00Z"
+		}
+	}
+}
+`
@@ -0,0 +1,100 @@
+package vendorlist3
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStreaming(t *testing.T) {
+	eager, err := ParseEagerly([]byte(testData))
+	assert.NoError(t, err)
+	streamed, err := ParseStreaming([]byte(testData))
+	assert.NoError(t, err)
+
+	assert.Equal(t, eager, streamed)
+}
+
+func TestParseStreamingEscapes(t *testing.T) {
+	data := `{"gvlSpecificationVersion": 3, "vendorListVersion": 1, "vendors": {"1": {"id": 1, "name": "Café \"Ads\"", "unknown": {"a": ["}", "\\\"]"], "b": [true, false, null, -1.5e3]}}}}`
+	eager, err := ParseEagerly([]byte(data))
+	assert.NoError(t, err)
+	streamed, err := ParseStreaming([]byte(data))
+	assert.NoError(t, err)
+
+	assert.Equal(t, eager, streamed)
+	vendor, _ := streamed.LookupVendor(1)
+	assert.Equal(t, `Café "Ads"`, vendor.Name())
+}
+
+func TestParseStreamingEmpty(t *testing.T) {
+	data := `{"gvlSpecificationVersion": 3, "vendorListVersion": 1, "vendors": {}}`
+	eager, err := ParseEagerly([]byte(data))
+	assert.NoError(t, err)
+	streamed, err := ParseStreaming([]byte(data))
+	assert.NoError(t, err)
+
+	assert.Equal(t, eager, streamed)
+}
+
+func TestParseStreamingInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		vendorList    string
+		expectedError string
+	}{
+		{
+			name:          "malformed",
+			vendorList:    `{"vendors": [`,
+			expectedError: "failed to parse the vendor list: offset 12: expected '{'",
+		},
+		{
+			name:          "truncated",
+			vendorList:    `{"gvlSpecificationVersion": 3, "vendorListVersion": 1, "vendors": {"8": {"id": 8`,
+			expectedError: "failed to parse the vendor list: offset 80: expected ','",
+		},
+		{
+			name:          "trailing_data",
+			vendorList:    `{"gvlSpecificationVersion": 3, "vendorListVersion": 1, "vendors": {}} {}`,
+			expectedError: "failed to parse the vendor list: offset 70: unexpected data after the vendor list",
+		},
+		{
+			name:          "fractional_number",
+			vendorList:    `{"gvlSpecificationVersion": 3, "vendorListVersion": 1.5, "vendors": {}}`,
+			expectedError: `failed to parse the vendor list: offset 55: "1.5" isn't an integer`,
+		},
+		{
+			name:          "spec_version_2",
+			vendorList:    `{"gvlSpecificationVersion": 2, "vendorListVersion": 28, "vendors": {}}`,
+			expectedError: "data.gvlSpecificationVersion was 2, but only version 3 is supported",
+		},
+		{
+			name:          "no_version",
+			vendorList:    `{"gvlSpecificationVersion": 3, "vendors": {}}`,
+			expectedError: "data.vendorListVersion was 0 or undefined. Versions should start at 1",
+		},
+		{
+			name:          "version_out_of_range",
+			vendorList:    `{"gvlSpecificationVersion": 3, "vendorListVersion": 70000, "vendors": {}}`,
+			expectedError: "failed to parse the vendor list: offset 57: 70000 is out of range",
+		},
+		{
+			name:          "purpose_out_of_range",
+			vendorList:    `{"gvlSpecificationVersion": 3, "vendorListVersion": 1, "vendors": {"8": {"id": 8, "purposes": [1, 256]}}}`,
+			expectedError: "failed to parse the vendor list: offset 101: 256 is out of range",
+		},
+		{
+			name:          "bad_deleted_date",
+			vendorList:    `{"gvlSpecificationVersion": 3, "vendorListVersion": 1, "vendors": {"8": {"id": 8, "deletedDate": "yesterday"}}}`,
+			expectedError: `failed to parse the vendor list: offset 108: parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := ParseStreaming([]byte(tt.vendorList))
+			assert.Nil(t, parsed)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
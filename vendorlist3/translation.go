@@ -0,0 +1,88 @@
+package vendorlist3
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Translation holds the purposes, special purposes, features, special features, data categories and
+// stacks from one of the IAB's purposes translation files, such as
+// https://vendor-list.consensu.org/v3/purposes-fr.json. Their IDs match the ones in the vendor list, but
+// the names, descriptions and illustrations are in the translation's language.
+type Translation struct {
+	language string
+	version  uint16
+
+	declarations
+}
+
+// ParseTranslation parses a version 3 purposes translation file. The files don't say which language
+// they're in, so the caller passes it in, usually from the file name.
+func ParseTranslation(language string, data []byte) (*Translation, error) {
+	if language == "" {
+		return nil, errors.New("a translation needs a language")
+	}
+	var contract vendorListContract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		return nil, err
+	}
+	if contract.GVLSpecificationVersion != 3 {
+		return nil, fmt.Errorf("data.gvlSpecificationVersion was %d, but only version 3 is supported", contract.GVLSpecificationVersion)
+	}
+
+	return &Translation{
+		language: strings.ToLower(language),
+		version:  contract.Version,
+		declarations: declarations{
+			purposes:        parseDeclarations(contract.Purposes),
+			specialPurposes: parseDeclarations(contract.SpecialPurposes),
+			features:        parseDeclarations(contract.Features),
+			specialFeatures: parseDeclarations(contract.SpecialFeatures),
+			dataCategories:  parseDeclarations(contract.DataCategories),
+			stacks:          parseStacks(contract.Stacks),
+		},
+	}, nil
+}
+
+// Language returns the language of the translation, in lower case, such as "fr".
+func (t *Translation) Language() string {
+	return t.language
+}
+
+// Version returns the version of the vendor list the translation was published with.
+func (t *Translation) Version() uint16 {
+	return t.version
+}
+
+// Translations holds translations for several languages. It can be shared safely between goroutines.
+type Translations struct {
+	byLanguage map[string]*Translation
+}
+
+// NewTranslations collects the translations by language. If two have the same language, the last wins.
+func NewTranslations(translations ...*Translation) *Translations {
+	byLanguage := make(map[string]*Translation, len(translations))
+	for _, translation := range translations {
+		byLanguage[translation.language] = translation
+	}
+	return &Translations{byLanguage: byLanguage}
+}
+
+// Translation returns the translation for the given language, such as "fr". The match ignores case.
+func (t *Translations) Translation(language string) (*Translation, bool) {
+	translation, ok := t.byLanguage[strings.ToLower(language)]
+	return translation, ok
+}
+
+// Languages returns the languages there are translations for, sorted.
+func (t *Translations) Languages() []string {
+	languages := make([]string, 0, len(t.byLanguage))
+	for language := range t.byLanguage {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
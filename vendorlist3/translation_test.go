@@ -0,0 +1,118 @@
+package vendorlist3
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testTranslationFR = `
+{
+	"gvlSpecificationVersion": 3,
+	"vendorListVersion": 42,
+	"tcfPolicyVersion": 4,
+	"lastUpdated": "2023-05-18T16:07:14Z",
+	"purposes": {
+		"1": {
+			"id": 1,
+			"name": "Stocker et/ou accéder à des informations sur un appareil",
+			"description": "Les cookies, appareils ou identifiants en ligne similaires peuvent être stockés sur votre appareil ou y être lus.",
+			"illustrations": []
+		}
+	},
+	"specialPurposes": {
+		"1": {
+			"id": 1,
+			"name": "Assurer la sécurité, prévenir et détecter la fraude et réparer les erreurs",
+			"description": "Vos données peuvent être utilisées pour surveiller et prévenir les activités frauduleuses.",
+			"illustrations": []
+		}
+	},
+	"features": {
+		"1": {
+			"id": 1,
+			"name": "Mettre en correspondance et combiner des données d’autres sources de données",
+			"description": "Des informations sur votre activité sur ce service peuvent être associées à d’autres informations.",
+			"illustrations": []
+		}
+	},
+	"specialFeatures": {},
+	"dataCategories": {}
+}
+`
+
+func TestParseTranslation(t *testing.T) {
+	translation, err := ParseTranslation("FR", []byte(testTranslationFR))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "fr", translation.Language())
+	assert.Equal(t, uint16(42), translation.Version())
+
+	purpose, ok := translation.Purpose(1)
+	assert.True(t, ok)
+	assert.Equal(t, 1, purpose.ID)
+	assert.Equal(t, "Stocker et/ou accéder à des informations sur un appareil", purpose.Name)
+	_, ok = translation.Purpose(2)
+	assert.False(t, ok)
+
+	specialPurpose, ok := translation.SpecialPurpose(1)
+	assert.True(t, ok)
+	assert.Equal(t, "Assurer la sécurité, prévenir et détecter la fraude et réparer les erreurs", specialPurpose.Name)
+
+	feature, ok := translation.Feature(1)
+	assert.True(t, ok)
+	assert.Equal(t, "Mettre en correspondance et combiner des données d’autres sources de données", feature.Name)
+
+	_, ok = translation.SpecialFeature(1)
+	assert.False(t, ok)
+}
+
+func TestParseTranslationInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		language      string
+		data          string
+		expectedError string
+	}{
+		{
+			name:          "no_language",
+			data:          testTranslationFR,
+			expectedError: "a translation needs a language",
+		},
+		{
+			name:          "malformed",
+			language:      "fr",
+			data:          `{"purposes": [`,
+			expectedError: "unexpected end of JSON input",
+		},
+		{
+			name:          "spec_version_2",
+			language:      "fr",
+			data:          `{"gvlSpecificationVersion": 2, "purposes": {}}`,
+			expectedError: "data.gvlSpecificationVersion was 2, but only version 3 is supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTranslation(tt.language, []byte(tt.data))
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestTranslations(t *testing.T) {
+	fr, err := ParseTranslation("fr", []byte(testTranslationFR))
+	assert.NoError(t, err)
+	de, err := ParseTranslation("de", []byte(`{"gvlSpecificationVersion": 3, "vendorListVersion": 42}`))
+	assert.NoError(t, err)
+
+	translations := NewTranslations(fr, de)
+	assert.Equal(t, []string{"de", "fr"}, translations.Languages())
+
+	translation, ok := translations.Translation("Fr")
+	assert.True(t, ok)
+	assert.Same(t, fr, translation)
+	_, ok = translations.Translation("es")
+	assert.False(t, ok)
+}
@@ -0,0 +1,395 @@
+// Package vendorlist3 parses version 3 of the IAB Global Vendor List specification, which goes with TCF
+// policy version 4 and later.
+//
+// The parsed list implements api.VendorList, so it can be used wherever the other vendor list packages
+// are. Version 3 adds data the api interfaces don't describe, such as data retention periods and
+// per-language URLs. Use VendorList.LookupVendor, or type assert an api.Vendor to *Vendor, to reach it.
+//
+// The IAB publishes the purposes and features in other languages as separate translation files. Parse
+// them with ParseTranslation.
+package vendorlist3
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+)
+
+// Declaration describes one of the purposes, special purposes, features, special features or data
+// categories which the vendor list defines.
+type Declaration struct {
+	ID            int
+	Name          string
+	Description   string
+	Illustrations []string
+}
+
+// Stack is a combination of purposes and special features which a CMP may show to the user as one item.
+type Stack struct {
+	ID              int
+	Name            string
+	Description     string
+	Purposes        []consentconstants.Purpose
+	SpecialFeatures []consentconstants.SpecialFeature
+}
+
+// URL holds the links a vendor publishes for one language.
+type URL struct {
+	// Language is the language code, such as "en".
+	Language    string
+	Privacy     string
+	LegIntClaim string
+}
+
+// DataRetention holds how long, in days, a vendor retains data.
+type DataRetention struct {
+	// StdRetention applies to every purpose and special purpose without its own period.
+	StdRetention    int
+	Purposes        map[consentconstants.Purpose]int
+	SpecialPurposes map[consentconstants.Purpose]int
+}
+
+// Purpose returns the number of days data is retained for the given purpose.
+func (r DataRetention) Purpose(id consentconstants.Purpose) int {
+	if days, ok := r.Purposes[id]; ok {
+		return days
+	}
+	return r.StdRetention
+}
+
+// SpecialPurpose returns the number of days data is retained for the given special purpose.
+func (r DataRetention) SpecialPurpose(id consentconstants.Purpose) int {
+	if days, ok := r.SpecialPurposes[id]; ok {
+		return days
+	}
+	return r.StdRetention
+}
+
+// declarations holds the purposes, special purposes, features, special features, data categories and
+// stacks defined by a vendor list or one of its translations.
+type declarations struct {
+	purposes        map[int]Declaration
+	specialPurposes map[int]Declaration
+	features        map[int]Declaration
+	specialFeatures map[int]Declaration
+	dataCategories  map[int]Declaration
+	stacks          map[int]Stack
+}
+
+// Purpose returns the declaration of the given purpose.
+func (d declarations) Purpose(id consentconstants.Purpose) (Declaration, bool) {
+	declaration, ok := d.purposes[int(id)]
+	return declaration, ok
+}
+
+// SpecialPurpose returns the declaration of the given special purpose.
+func (d declarations) SpecialPurpose(id consentconstants.Purpose) (Declaration, bool) {
+	declaration, ok := d.specialPurposes[int(id)]
+	return declaration, ok
+}
+
+// Feature returns the declaration of the given feature.
+func (d declarations) Feature(id int) (Declaration, bool) {
+	declaration, ok := d.features[id]
+	return declaration, ok
+}
+
+// SpecialFeature returns the declaration of the given special feature.
+func (d declarations) SpecialFeature(id consentconstants.SpecialFeature) (Declaration, bool) {
+	declaration, ok := d.specialFeatures[int(id)]
+	return declaration, ok
+}
+
+// DataCategory returns the declaration of the given data category.
+func (d declarations) DataCategory(id int) (Declaration, bool) {
+	declaration, ok := d.dataCategories[id]
+	return declaration, ok
+}
+
+// Stack returns the stack with the given ID.
+func (d declarations) Stack(id int) (Stack, bool) {
+	stack, ok := d.stacks[id]
+	return stack, ok
+}
+
+// Stacks returns every stack, sorted by ID.
+func (d declarations) Stacks() []Stack {
+	stacks := make([]Stack, 0, len(d.stacks))
+	for _, stack := range d.stacks {
+		stacks = append(stacks, stack)
+	}
+	sort.Slice(stacks, func(i, j int) bool {
+		return stacks[i].ID < stacks[j].ID
+	})
+	return stacks
+}
+
+// VendorList is a parsed version 3 Global Vendor List. It can be shared safely between goroutines.
+type VendorList struct {
+	specVersion      uint16
+	version          uint16
+	tcfPolicyVersion uint8
+	lastUpdated      time.Time
+
+	declarations
+	vendors map[uint16]*Vendor
+
+	// vendorsByPurpose and vendorsByLegitimateInterest are built by index.
+	vendorsByPurpose            map[consentconstants.Purpose][]uint16
+	vendorsByLegitimateInterest map[consentconstants.Purpose][]uint16
+	vendorsByName               map[string]*Vendor
+	maxVendorID                 uint16
+}
+
+// SpecVersion returns the version of the vendor list specification, which is always 3.
+func (l *VendorList) SpecVersion() uint16 {
+	return l.specVersion
+}
+
+// Version returns the version of the vendor list.
+func (l *VendorList) Version() uint16 {
+	return l.version
+}
+
+// TCFPolicyVersion returns the TCF policy version the vendor list was written for.
+func (l *VendorList) TCFPolicyVersion() uint8 {
+	return l.tcfPolicyVersion
+}
+
+// LastUpdated returns the time the vendor list was last updated.
+func (l *VendorList) LastUpdated() time.Time {
+	return l.lastUpdated
+}
+
+// Vendor returns the vendor with the given ID, or nil if it isn't in the list.
+func (l *VendorList) Vendor(vendorID uint16) api.Vendor {
+	if vendor, ok := l.vendors[vendorID]; ok {
+		return vendor
+	}
+	return nil
+}
+
+// VendorIDs returns the IDs of every vendor in the list, in no particular order.
+func (l *VendorList) VendorIDs() []uint16 {
+	ids := make([]uint16, 0, len(l.vendors))
+	for id := range l.vendors {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// MaxVendorID returns the highest vendor ID in the list, including deleted vendors, or 0 if it has none.
+func (l *VendorList) MaxVendorID() uint16 {
+	return l.maxVendorID
+}
+
+// VendorsForPurpose returns the IDs of the vendors which declared the given purpose under consent, sorted.
+// Like PurposeStrict, it ignores flexible purposes. Deleted vendors are included.
+//
+// The IDs are worked out when the list is parsed, and the slice is shared between calls, so it must
+// not be modified.
+func (l *VendorList) VendorsForPurpose(purposeID consentconstants.Purpose) []uint16 {
+	return l.vendorsByPurpose[purposeID]
+}
+
+// VendorsForLegIntPurpose returns the IDs of the vendors which declared a legitimate interest for the
+// given purpose, sorted. It works like VendorsForPurpose.
+func (l *VendorList) VendorsForLegIntPurpose(purposeID consentconstants.Purpose) []uint16 {
+	return l.vendorsByLegitimateInterest[purposeID]
+}
+
+// VendorByName returns the vendor with the given name. The match ignores case, punctuation and extra
+// whitespace, so "sharethrough inc" finds "Sharethrough, Inc". If several vendors match, the one with
+// the lowest ID is returned.
+func (l *VendorList) VendorByName(name string) (*Vendor, bool) {
+	vendor, ok := l.vendorsByName[normalizeName(name)]
+	return vendor, ok
+}
+
+// normalizeName lowercases the name, and turns each run of punctuation and whitespace into one space.
+func normalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// index builds the lookups behind VendorsForPurpose, VendorsForLegIntPurpose and VendorByName, and finds
+// the MaxVendorID. Every parser calls it once the vendors are in place.
+func (l *VendorList) index() {
+	l.vendorsByPurpose = make(map[consentconstants.Purpose][]uint16)
+	l.vendorsByLegitimateInterest = make(map[consentconstants.Purpose][]uint16)
+	l.vendorsByName = make(map[string]*Vendor, len(l.vendors))
+
+	ids := l.VendorIDs()
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	if len(ids) > 0 {
+		l.maxVendorID = ids[len(ids)-1]
+	}
+	for _, id := range ids {
+		vendor := l.vendors[id]
+		if name := normalizeName(vendor.name); name != "" {
+			if _, ok := l.vendorsByName[name]; !ok {
+				l.vendorsByName[name] = vendor
+			}
+		}
+		for purpose := range vendor.purposes {
+			l.vendorsByPurpose[purpose] = append(l.vendorsByPurpose[purpose], id)
+		}
+		for purpose := range vendor.legitimateInterests {
+			l.vendorsByLegitimateInterest[purpose] = append(l.vendorsByLegitimateInterest[purpose], id)
+		}
+	}
+}
+
+// LookupVendor returns the vendor with the given ID. The bool is false if it isn't in the list.
+func (l *VendorList) LookupVendor(vendorID uint16) (*Vendor, bool) {
+	vendor, ok := l.vendors[vendorID]
+	return vendor, ok
+}
+
+// Vendor describes a vendor in a version 3 Global Vendor List. It implements api.Vendor,
+// api.FlexiblePurposes, api.VendorDeletion and api.DeviceStorage.
+type Vendor struct {
+	id          uint16
+	name        string
+	deletedDate time.Time
+
+	purposes            map[consentconstants.Purpose]struct{}
+	legitimateInterests map[consentconstants.Purpose]struct{}
+	flexiblePurposes    map[consentconstants.Purpose]struct{}
+	specialPurposes     map[consentconstants.Purpose]struct{}
+	features            map[int]struct{}
+	specialFeatures     map[consentconstants.SpecialFeature]struct{}
+
+	dataDeclaration []int
+	dataRetention   DataRetention
+	urls            []URL
+
+	usesCookies                bool
+	cookieMaxAgeSeconds        int64
+	cookieRefresh              bool
+	usesNonCookieAccess        bool
+	deviceStorageDisclosureURL string
+}
+
+// ID returns the ID of the vendor.
+func (v *Vendor) ID() uint16 {
+	return v.id
+}
+
+// Name returns the name of the vendor.
+func (v *Vendor) Name() string {
+	return v.name
+}
+
+// DeletedDate returns the time the vendor was deleted from the list. The bool is false if it wasn't.
+// Deleted vendors are kept in the list, but must not be given consent by new TC strings.
+func (v *Vendor) DeletedDate() (time.Time, bool) {
+	return v.deletedDate, !v.deletedDate.IsZero()
+}
+
+// Purpose returns true if the vendor declared the given purpose, under consent or as a flexible purpose.
+func (v *Vendor) Purpose(purposeID consentconstants.Purpose) bool {
+	return hasPurpose(v.purposes, purposeID) || hasPurpose(v.flexiblePurposes, purposeID)
+}
+
+// PurposeStrict checks only for the primary purpose, not considering flex purposes.
+func (v *Vendor) PurposeStrict(purposeID consentconstants.Purpose) bool {
+	return hasPurpose(v.purposes, purposeID)
+}
+
+// LegitimateInterest returns true if the vendor declared a legitimate interest for the given purpose,
+// directly or as a flexible purpose.
+func (v *Vendor) LegitimateInterest(purposeID consentconstants.Purpose) bool {
+	return hasPurpose(v.legitimateInterests, purposeID) || hasPurpose(v.flexiblePurposes, purposeID)
+}
+
+// LegitimateInterestStrict checks only for the primary legitimate interest, not considering flex purposes.
+func (v *Vendor) LegitimateInterestStrict(purposeID consentconstants.Purpose) bool {
+	return hasPurpose(v.legitimateInterests, purposeID)
+}
+
+// FlexiblePurpose returns true if the vendor declared the given purpose as flexible, meaning a publisher
+// restriction may switch its legal basis between consent and legitimate interest.
+func (v *Vendor) FlexiblePurpose(purposeID consentconstants.Purpose) bool {
+	return hasPurpose(v.flexiblePurposes, purposeID)
+}
+
+// SpecialPurpose returns true if the vendor declared the given special purpose.
+func (v *Vendor) SpecialPurpose(purposeID consentconstants.Purpose) bool {
+	return hasPurpose(v.specialPurposes, purposeID)
+}
+
+// SpecialFeature returns true if the vendor declared the given special feature.
+func (v *Vendor) SpecialFeature(featureID consentconstants.SpecialFeature) bool {
+	_, ok := v.specialFeatures[featureID]
+	return ok
+}
+
+// Feature returns true if the vendor declared the given feature.
+func (v *Vendor) Feature(featureID int) bool {
+	_, ok := v.features[featureID]
+	return ok
+}
+
+// DataDeclaration returns the IDs of the data categories the vendor collects, in list order.
+func (v *Vendor) DataDeclaration() []int {
+	return v.dataDeclaration
+}
+
+// DataRetention returns the vendor's data retention periods.
+func (v *Vendor) DataRetention() DataRetention {
+	return v.dataRetention
+}
+
+// URLs returns the vendor's URLs for every language it published them in, in list order.
+func (v *Vendor) URLs() []URL {
+	return v.urls
+}
+
+// URL returns the vendor's URLs for the given language, such as "en". The match ignores case.
+func (v *Vendor) URL(language string) (URL, bool) {
+	for _, url := range v.urls {
+		if strings.EqualFold(url.Language, language) {
+			return url, true
+		}
+	}
+	return URL{}, false
+}
+
+// UsesCookies returns true if the vendor stores data in cookies.
+func (v *Vendor) UsesCookies() bool {
+	return v.usesCookies
+}
+
+// CookieMaxAgeSeconds returns the longest lifetime of the vendor's cookies, in seconds.
+func (v *Vendor) CookieMaxAgeSeconds() int64 {
+	return v.cookieMaxAgeSeconds
+}
+
+// CookieRefresh returns true if the vendor's cookies may be refreshed.
+func (v *Vendor) CookieRefresh() bool {
+	return v.cookieRefresh
+}
+
+// UsesNonCookieAccess returns true if the vendor accesses the device by means other than cookies.
+func (v *Vendor) UsesNonCookieAccess() bool {
+	return v.usesNonCookieAccess
+}
+
+// DeviceStorageDisclosureURL returns the URL of the vendor's device storage disclosure.
+func (v *Vendor) DeviceStorageDisclosureURL() string {
+	return v.deviceStorageDisclosureURL
+}
+
+func hasPurpose(purposes map[consentconstants.Purpose]struct{}, id consentconstants.Purpose) bool {
+	_, ok := purposes[id]
+	return ok
+}
@@ -0,0 +1,188 @@
+package vendorlist3
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/consentconstants"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseTestData(t *testing.T) *VendorList {
+	t.Helper()
+	parsed, err := ParseEagerly([]byte(testData))
+	if err != nil {
+		t.Fatalf("failed to parse the test vendor list: %v", err)
+	}
+	return parsed
+}
+
+func TestDeclarations(t *testing.T) {
+	gvl := parseTestData(t)
+
+	purpose, ok := gvl.Purpose(2)
+	assert.True(t, ok)
+	assert.Equal(t, 2, purpose.ID)
+	assert.Equal(t, "Use limited data to select advertising", purpose.Name)
+	assert.Len(t, purpose.Illustrations, 1)
+	_, ok = gvl.Purpose(3)
+	assert.False(t, ok)
+
+	specialPurpose, ok := gvl.SpecialPurpose(1)
+	assert.True(t, ok)
+	assert.Equal(t, "Ensure security, prevent and detect fraud, and fix errors", specialPurpose.Name)
+
+	feature, ok := gvl.Feature(1)
+	assert.True(t, ok)
+	assert.Equal(t, "Match and combine data from other data sources", feature.Name)
+
+	specialFeature, ok := gvl.SpecialFeature(1)
+	assert.True(t, ok)
+	assert.Equal(t, "Use precise geolocation data", specialFeature.Name)
+
+	category, ok := gvl.DataCategory(1)
+	assert.True(t, ok)
+	assert.Equal(t, "IP addresses", category.Name)
+}
+
+func TestStacks(t *testing.T) {
+	gvl := parseTestData(t)
+
+	stack, ok := gvl.Stack(2)
+	assert.True(t, ok)
+	assert.Equal(t, "Advertising based on limited data and advertising measurement", stack.Name)
+	assert.Equal(t, []consentconstants.Purpose{2, 7}, stack.Purposes)
+	assert.Empty(t, stack.SpecialFeatures)
+	_, ok = gvl.Stack(3)
+	assert.False(t, ok)
+
+	stacks := gvl.Stacks()
+	assert.Len(t, stacks, 2)
+	assert.Equal(t, 1, stacks[0].ID)
+	assert.Empty(t, stacks[0].Purposes)
+	assert.Equal(t, []consentconstants.SpecialFeature{1, 2}, stacks[0].SpecialFeatures)
+	assert.Equal(t, 2, stacks[1].ID)
+}
+
+func TestVendorPurposes(t *testing.T) {
+	v := parseTestData(t).Vendor(8)
+
+	assert.True(t, v.Purpose(1))
+	assert.True(t, v.PurposeStrict(1))
+	assert.True(t, v.Purpose(2))
+	assert.False(t, v.PurposeStrict(2))
+	assert.False(t, v.Purpose(5))
+
+	assert.False(t, v.LegitimateInterest(1))
+	assert.True(t, v.LegitimateInterest(2))
+	assert.True(t, v.LegitimateInterestStrict(2))
+	assert.False(t, v.LegitimateInterestStrict(10))
+
+	flexible := v.(api.FlexiblePurposes)
+	assert.True(t, flexible.FlexiblePurpose(2))
+	assert.True(t, flexible.FlexiblePurpose(9))
+	assert.False(t, flexible.FlexiblePurpose(1))
+
+	assert.True(t, v.SpecialPurpose(1))
+	assert.True(t, v.SpecialPurpose(2))
+	assert.False(t, v.SpecialPurpose(3))
+
+	assert.True(t, v.SpecialFeature(1))
+	assert.False(t, v.SpecialFeature(2))
+}
+
+func TestVendorDetails(t *testing.T) {
+	gvl := parseTestData(t)
+
+	v, ok := gvl.LookupVendor(8)
+	assert.True(t, ok)
+	assert.Equal(t, uint16(8), v.ID())
+	assert.Equal(t, "Emerse Sverige AB", v.Name())
+	assert.True(t, v.Feature(2))
+	assert.False(t, v.Feature(3))
+	assert.Equal(t, []int{1, 2, 4, 6}, v.DataDeclaration())
+	assert.True(t, v.UsesCookies())
+	assert.Equal(t, int64(31536000), v.CookieMaxAgeSeconds())
+	assert.True(t, v.CookieRefresh())
+	assert.False(t, v.UsesNonCookieAccess())
+	assert.Equal(t, "https://www.emerse.com/devicestorage.json", v.DeviceStorageDisclosureURL())
+	_, deleted := v.DeletedDate()
+	assert.False(t, deleted)
+
+	retention := v.DataRetention()
+	assert.Equal(t, 180, retention.Purpose(9))
+	assert.Equal(t, 30, retention.Purpose(1))
+	assert.Equal(t, 365, retention.SpecialPurpose(2))
+	assert.Equal(t, 30, retention.SpecialPurpose(1))
+
+	assert.Len(t, v.URLs(), 2)
+	url, ok := v.URL("FR")
+	assert.True(t, ok)
+	assert.Equal(t, URL{
+		Language:    "fr",
+		Privacy:     "https://www.emerse.com/fr/privacy-policy/",
+		LegIntClaim: "https://www.emerse.com/fr/privacy-policy/#li",
+	}, url)
+	_, ok = v.URL("de")
+	assert.False(t, ok)
+
+	v, ok = gvl.LookupVendor(80)
+	assert.True(t, ok)
+	deletedDate, deleted := v.DeletedDate()
+	assert.True(t, deleted)
+	assert.Equal(t, time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), deletedDate)
+	assert.Equal(t, 90, v.DataRetention().Purpose(consentconstants.Purpose(1)))
+
+	_, ok = gvl.LookupVendor(9)
+	assert.False(t, ok)
+}
+
+func TestVendorsForPurpose(t *testing.T) {
+	gvl := parseTestData(t)
+
+	assert.Equal(t, []uint16{8, 80}, gvl.VendorsForPurpose(1))
+	assert.Equal(t, []uint16{80}, gvl.VendorsForPurpose(2))
+	assert.Empty(t, gvl.VendorsForPurpose(5))
+
+	assert.Equal(t, []uint16{8}, gvl.VendorsForLegIntPurpose(2))
+	assert.Empty(t, gvl.VendorsForLegIntPurpose(1))
+}
+
+func TestVendorByName(t *testing.T) {
+	gvl := parseTestData(t)
+
+	for _, name := range []string{"Sharethrough, Inc", "sharethrough inc", "  SHARETHROUGH,   INC. "} {
+		vendor, ok := gvl.VendorByName(name)
+		if assert.True(t, ok, name) {
+			assert.Equal(t, uint16(80), vendor.ID())
+		}
+	}
+
+	vendor, ok := gvl.VendorByName("emerse sverige ab")
+	assert.True(t, ok)
+	assert.Equal(t, uint16(8), vendor.ID())
+
+	_, ok = gvl.VendorByName("Sharethrough")
+	assert.False(t, ok)
+	_, ok = gvl.VendorByName("")
+	assert.False(t, ok)
+}
+
+func TestVendorTypeAssertion(t *testing.T) {
+	v, ok := parseTestData(t).Vendor(8).(*Vendor)
+	assert.True(t, ok)
+	assert.Equal(t, "Emerse Sverige AB", v.Name())
+}
+
+func TestVendorIDs(t *testing.T) {
+	assert.ElementsMatch(t, []uint16{8, 80}, parseTestData(t).VendorIDs())
+}
+
+func TestMaxVendorID(t *testing.T) {
+	assert.Equal(t, uint16(80), parseTestData(t).MaxVendorID())
+
+	empty, err := ParseEagerly([]byte(`{"gvlSpecificationVersion": 3, "vendorListVersion": 1, "vendors": {}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(0), empty.MaxVendorID())
+}
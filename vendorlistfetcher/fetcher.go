@@ -0,0 +1,301 @@
+// Package vendorlistfetcher downloads Global Vendor Lists over HTTP and caches the parsed results.
+package vendorlistfetcher
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/vendorlist2"
+	"github.com/prebid/go-gdpr/vendorlist3"
+)
+
+// DefaultBaseURL is where IAB Europe publishes the Global Vendor Lists.
+const DefaultBaseURL = "https://vendor-list.consensu.org"
+
+// LatestVersion can be passed to Fetch as the list version to get the latest vendor list.
+const LatestVersion uint16 = 0
+
+// Fetcher downloads vendor lists and caches them by specification and list version.
+// It is safe for concurrent use.
+//
+// Numbered versions never change once published, so a cached one is returned without any request.
+// The latest list is revalidated on every Fetch with If-None-Match and If-Modified-Since, and is only
+// downloaded and parsed again if the server says it changed.
+//
+// A Fetcher made with NewWithStore also looks for numbered versions in its VendorListStore before
+// downloading them, and stores every list it downloads. The store is treated as a cache: if it fails,
+// or holds data which doesn't parse, the Fetcher downloads the list instead.
+//
+// Use SetRetryPolicy to retry failed downloads, and to limit how long each attempt may take. Every
+// Fetch also stops as soon as its context is done.
+//
+// Use SetVerification to reject lists which aren't served over https, are too large, or don't match a
+// known checksum.
+type Fetcher struct {
+	client       *http.Client
+	baseURL      string
+	store        VendorListStore
+	metrics      Metrics
+	verification Verification
+	retry        RetryPolicy
+
+	mu    sync.Mutex
+	cache map[cacheKey]cacheEntry
+}
+
+type cacheKey struct {
+	specVersion uint16
+	listVersion uint16
+}
+
+type cacheEntry struct {
+	list         api.VendorList
+	etag         string
+	lastModified string
+}
+
+// New returns a Fetcher which downloads vendor lists from baseURL, such as DefaultBaseURL, using the
+// given client. If client is nil, http.DefaultClient is used.
+func New(baseURL string, client *http.Client) *Fetcher {
+	return NewWithStore(baseURL, client, nil)
+}
+
+// NewWithStore works like New, but backs the Fetcher with a VendorListStore. If store is nil, it's the
+// same as New.
+func NewWithStore(baseURL string, client *http.Client, store VendorListStore) *Fetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Fetcher{
+		client:  client,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		store:   store,
+		metrics: NopMetrics{},
+		cache:   make(map[cacheKey]cacheEntry),
+	}
+}
+
+// SetMetrics sends the Fetcher's instrumentation to metrics. Call it before using the Fetcher.
+func (f *Fetcher) SetMetrics(metrics Metrics) {
+	if metrics == nil {
+		metrics = NopMetrics{}
+	}
+	f.metrics = metrics
+}
+
+// URL returns the address of a vendor list: {baseURL}/v{specVersion}/archives/vendor-list-v{listVersion}.json,
+// or {baseURL}/v{specVersion}/vendor-list.json for the latest list.
+func (f *Fetcher) URL(specVersion uint16, listVersion uint16) string {
+	if listVersion == LatestVersion {
+		return fmt.Sprintf("%s/v%d/vendor-list.json", f.baseURL, specVersion)
+	}
+	return fmt.Sprintf("%s/v%d/archives/vendor-list-v%d.json", f.baseURL, specVersion, listVersion)
+}
+
+// Fetch returns the given version of the vendor list, downloading it if needed. Use LatestVersion
+// for the latest list. Specification versions 2 and 3 are supported; version 3 lists are returned as
+// a *vendorlist3.VendorList.
+func (f *Fetcher) Fetch(ctx context.Context, specVersion uint16, listVersion uint16) (api.VendorList, error) {
+	if specVersion != 2 && specVersion != 3 {
+		return nil, fmt.Errorf("vendor list specification version %d isn't supported", specVersion)
+	}
+
+	key := cacheKey{specVersion: specVersion, listVersion: listVersion}
+	f.mu.Lock()
+	cached, ok := f.cache[key]
+	f.mu.Unlock()
+	if ok && listVersion != LatestVersion {
+		f.metrics.CacheHit(specVersion)
+		return cached.list, nil
+	}
+	if !ok && listVersion != LatestVersion {
+		if list, ok := f.load(ctx, specVersion, listVersion); ok {
+			f.metrics.CacheHit(specVersion)
+			return list, nil
+		}
+	}
+
+	url := f.URL(specVersion, listVersion)
+	var conditional *cacheEntry
+	if ok {
+		conditional = &cached
+	}
+	data, header, notModified, err := f.downloadWithRetries(ctx, specVersion, url, conditional)
+	if err != nil {
+		return nil, err
+	}
+	if notModified {
+		f.metrics.CacheHit(specVersion)
+		return cached.list, nil
+	}
+	f.metrics.CacheMiss(specVersion)
+
+	list, err := parse(specVersion, data)
+	if err != nil {
+		f.metrics.ParseError(specVersion)
+		return nil, fmt.Errorf("failed to parse vendor list %s: %v", url, err)
+	}
+	if listVersion != LatestVersion && list.Version() != listVersion {
+		return nil, fmt.Errorf("vendor list %s has version %d, but version %d was requested", url, list.Version(), listVersion)
+	}
+	if err := f.verification.checkData(specVersion, list.Version(), data); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	f.cache[key] = cacheEntry{
+		list:         list,
+		etag:         header.Get("ETag"),
+		lastModified: header.Get("Last-Modified"),
+	}
+	if listVersion == LatestVersion {
+		// The latest list is also a numbered version, which later Fetch calls may ask for by number.
+		f.cache[cacheKey{specVersion: specVersion, listVersion: list.Version()}] = cacheEntry{list: list}
+	}
+	f.mu.Unlock()
+
+	if f.store != nil {
+		// Errors are ignored: the list is still good, and the store is only a cache.
+		_ = f.store.Put(ctx, specVersion, list.Version(), data)
+	}
+	return list, nil
+}
+
+// downloadWithRetries downloads the list at url, retrying according to the Fetcher's RetryPolicy. If
+// cached isn't nil, the request is conditional on the list having changed since it was cached, and the
+// bool is true if it hasn't.
+func (f *Fetcher) downloadWithRetries(ctx context.Context, specVersion uint16, url string, cached *cacheEntry) ([]byte, http.Header, bool, error) {
+	for attempt := 1; ; attempt++ {
+		data, header, notModified, err := f.attempt(ctx, specVersion, url, cached)
+		if err == nil || !isRetryable(err) || attempt >= f.retry.attempts() || !wait(ctx, f.retry.backoff(attempt)) {
+			return data, header, notModified, err
+		}
+	}
+}
+
+// attempt makes one request for the list at url, within the RetryPolicy's AttemptTimeout.
+func (f *Fetcher) attempt(ctx context.Context, specVersion uint16, url string, cached *cacheEntry) ([]byte, http.Header, bool, error) {
+	ctx, cancel := f.retry.attemptContext(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to build request for vendor list %s: %v", url, err)
+	}
+	if err := f.verification.checkRequest(req); err != nil {
+		return nil, nil, false, err
+	}
+	if cached != nil {
+		if cached.etag != "" {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	start := time.Now()
+	data, header, notModified, err := f.download(req, url, cached != nil)
+	f.metrics.ObserveFetch(specVersion, time.Since(start), err)
+	return data, header, notModified, err
+}
+
+// download sends the request and reads the response. The bool is true if the server answered 304 Not
+// Modified to a conditional request. Errors which another attempt might not hit are retryableErrors.
+func (f *Fetcher) download(req *http.Request, url string, conditional bool) ([]byte, http.Header, bool, error) {
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, nil, false, retryableError{fmt.Errorf("failed to fetch vendor list %s: %v", url, err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && conditional {
+		return nil, resp.Header, true, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("vendor list %s returned status %d", url, resp.StatusCode)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return nil, nil, false, retryableError{err}
+		}
+		return nil, nil, false, err
+	}
+	if err := f.verification.checkResponse(resp, url); err != nil {
+		return nil, nil, false, err
+	}
+
+	body := io.Reader(resp.Body)
+	if f.verification.MaxSize > 0 {
+		// Read one byte too many, so that a body without a Content-Length can still be rejected.
+		body = io.LimitReader(resp.Body, f.verification.MaxSize+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, nil, false, retryableError{fmt.Errorf("failed to read vendor list %s: %v", url, err)}
+	}
+	if f.verification.MaxSize > 0 && int64(len(data)) > f.verification.MaxSize {
+		return nil, nil, false, fmt.Errorf("vendor list %s rejected: it's larger than %d bytes", url, f.verification.MaxSize)
+	}
+	return data, resp.Header, false, nil
+}
+
+// load returns a list from the store, and caches it. The bool is false if the store doesn't have a
+// usable copy, including one which fails verification.
+func (f *Fetcher) load(ctx context.Context, specVersion uint16, listVersion uint16) (api.VendorList, bool) {
+	if f.store == nil {
+		return nil, false
+	}
+	data, ok, err := f.store.Get(ctx, specVersion, listVersion)
+	if err != nil || !ok {
+		return nil, false
+	}
+	list, err := parse(specVersion, data)
+	if err != nil || list.Version() != listVersion {
+		return nil, false
+	}
+	if err := f.verification.checkData(specVersion, listVersion, data); err != nil {
+		return nil, false
+	}
+
+	f.mu.Lock()
+	f.cache[cacheKey{specVersion: specVersion, listVersion: listVersion}] = cacheEntry{list: list}
+	f.mu.Unlock()
+	return list, true
+}
+
+// ParseSnapshot parses a vendor list saved from DefaultBaseURL, using the package which matches its
+// gvlSpecificationVersion. It's meant for a list embedded in the binary as RefresherConfig.Fallback:
+//
+//	//go:embed vendor-list.json
+//	var snapshot []byte
+//
+//	fallback, err := vendorlistfetcher.ParseSnapshot(snapshot)
+//
+// Keep the embedded list reasonably recent, since vendors added after it have no consent under it.
+func ParseSnapshot(data []byte) (api.VendorList, error) {
+	specVersion, err := jsonparser.GetInt(data, "gvlSpecificationVersion")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the snapshot's gvlSpecificationVersion: %v", err)
+	}
+	if specVersion != 2 && specVersion != 3 {
+		return nil, fmt.Errorf("vendor list specification version %d isn't supported", specVersion)
+	}
+	return parse(uint16(specVersion), data)
+}
+
+func parse(specVersion uint16, data []byte) (api.VendorList, error) {
+	if specVersion == 3 {
+		list, err := vendorlist3.ParseStreaming(data)
+		if err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+	return vendorlist2.ParseEagerly(data)
+}
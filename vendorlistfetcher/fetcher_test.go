@@ -0,0 +1,174 @@
+package vendorlistfetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prebid/go-gdpr/vendorlist3"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testListV2 = `{"gvlSpecificationVersion": 2, "vendorListVersion": 28, "vendors": {"8": {"id": 8, "purposes": [1]}}}`
+	testListV3 = `{"gvlSpecificationVersion": 3, "vendorListVersion": 42, "vendors": {"8": {"id": 8, "name": "Emerse", "purposes": [1]}}}`
+)
+
+// testServer serves testListV2 and testListV3 at their archive paths and as the latest v3 list, and
+// testListV3 under the wrong version too.
+// It counts the requests, and answers conditional requests for the latest list with 304.
+func testServer(t *testing.T, requests *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		switch r.URL.Path {
+		case "/v2/archives/vendor-list-v28.json":
+			w.Write([]byte(testListV2))
+		case "/v3/archives/vendor-list-v42.json":
+			w.Write([]byte(testListV3))
+		case "/v3/vendor-list.json":
+			if r.Header.Get("If-None-Match") == `"v42"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v42"`)
+			w.Write([]byte(testListV3))
+		case "/v3/archives/vendor-list-v43.json":
+			w.Write([]byte(`{"gvlSpecificationVersion": 3}`))
+		case "/v3/archives/vendor-list-v44.json":
+			w.Write([]byte(testListV3))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetch(t *testing.T) {
+	var requests int32
+	fetcher := New(testServer(t, &requests).URL, nil)
+
+	list, err := fetcher.Fetch(context.Background(), 2, 28)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(28), list.Version())
+	assert.True(t, list.Vendor(8).Purpose(1))
+
+	list, err = fetcher.Fetch(context.Background(), 3, 42)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(42), list.Version())
+	v3, ok := list.(*vendorlist3.VendorList)
+	if assert.True(t, ok) {
+		vendor, _ := v3.LookupVendor(8)
+		assert.Equal(t, "Emerse", vendor.Name())
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// Numbered versions are served from the cache.
+	_, err = fetcher.Fetch(context.Background(), 3, 42)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestFetchLatest(t *testing.T) {
+	var requests int32
+	fetcher := New(testServer(t, &requests).URL+"/", nil)
+
+	first, err := fetcher.Fetch(context.Background(), 3, LatestVersion)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(42), first.Version())
+
+	// The latest list is revalidated, and the cached copy kept when the server answers 304.
+	second, err := fetcher.Fetch(context.Background(), 3, LatestVersion)
+	assert.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// The latest list is also cached under its own version.
+	numbered, err := fetcher.Fetch(context.Background(), 3, 42)
+	assert.NoError(t, err)
+	assert.Same(t, first, numbered)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestFetchErrors(t *testing.T) {
+	var requests int32
+	server := testServer(t, &requests)
+	fetcher := New(server.URL, nil)
+
+	tests := []struct {
+		name          string
+		specVersion   uint16
+		listVersion   uint16
+		expectedError string
+	}{
+		{
+			name:          "unsupported_spec_version",
+			specVersion:   1,
+			listVersion:   5,
+			expectedError: "vendor list specification version 1 isn't supported",
+		},
+		{
+			name:          "not_found",
+			specVersion:   3,
+			listVersion:   7,
+			expectedError: "vendor list " + server.URL + "/v3/archives/vendor-list-v7.json returned status 404",
+		},
+		{
+			name:          "invalid_list",
+			specVersion:   3,
+			listVersion:   43,
+			expectedError: "failed to parse vendor list " + server.URL + "/v3/archives/vendor-list-v43.json: data.vendorListVersion was 0 or undefined. Versions should start at 1",
+		},
+		{
+			name:          "wrong_version",
+			specVersion:   3,
+			listVersion:   44,
+			expectedError: "vendor list " + server.URL + "/v3/archives/vendor-list-v44.json has version 42, but version 44 was requested",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fetcher.Fetch(context.Background(), tt.specVersion, tt.listVersion)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestFetchCanceled(t *testing.T) {
+	var requests int32
+	fetcher := New(testServer(t, &requests).URL, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := fetcher.Fetch(ctx, 3, 42)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "context canceled")
+	}
+}
+
+func TestParseSnapshot(t *testing.T) {
+	list, err := ParseSnapshot([]byte(testListV3))
+	assert.NoError(t, err)
+	assert.IsType(t, &vendorlist3.VendorList{}, list)
+	assert.Equal(t, uint16(42), list.Version())
+
+	list, err = ParseSnapshot([]byte(testListV2))
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(2), list.SpecVersion())
+	assert.Equal(t, uint16(28), list.Version())
+
+	_, err = ParseSnapshot([]byte(`{"vendorListVersion": 1}`))
+	assert.EqualError(t, err, "failed to read the snapshot's gvlSpecificationVersion: Key path not found")
+	_, err = ParseSnapshot([]byte(`{"gvlSpecificationVersion": 1, "vendorListVersion": 1}`))
+	assert.EqualError(t, err, "vendor list specification version 1 isn't supported")
+}
+
+func TestURL(t *testing.T) {
+	fetcher := New(DefaultBaseURL, nil)
+	assert.Equal(t, "https://vendor-list.consensu.org/v3/archives/vendor-list-v42.json", fetcher.URL(3, 42))
+	assert.Equal(t, "https://vendor-list.consensu.org/v2/vendor-list.json", fetcher.URL(2, LatestVersion))
+}
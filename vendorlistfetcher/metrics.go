@@ -0,0 +1,40 @@
+package vendorlistfetcher
+
+import "time"
+
+// Metrics receives instrumentation from a Fetcher and a Refresher. Wire it to Prometheus, or whatever
+// metrics system the service uses. Methods are called synchronously, so they should be quick.
+//
+// Embed NopMetrics to implement only the methods you need.
+type Metrics interface {
+	// ObserveFetch is called after each request for a vendor list, with how long it took to receive
+	// the whole response. err is nil if the request succeeded, including with 304 Not Modified.
+	ObserveFetch(specVersion uint16, duration time.Duration, err error)
+	// CacheHit is called when a list is returned without downloading it, from memory, from a
+	// VendorListStore or because the server answered 304 Not Modified.
+	CacheHit(specVersion uint16)
+	// CacheMiss is called when a list has to be downloaded.
+	CacheMiss(specVersion uint16)
+	// ParseError is called when a downloaded list can't be parsed.
+	ParseError(specVersion uint16)
+	// ActiveListVersion is called by a Refresher when the version returned by Latest changes.
+	ActiveListVersion(specVersion uint16, listVersion uint16)
+}
+
+// NopMetrics is a Metrics which does nothing.
+type NopMetrics struct{}
+
+// ObserveFetch implements Metrics.
+func (NopMetrics) ObserveFetch(specVersion uint16, duration time.Duration, err error) {}
+
+// CacheHit implements Metrics.
+func (NopMetrics) CacheHit(specVersion uint16) {}
+
+// CacheMiss implements Metrics.
+func (NopMetrics) CacheMiss(specVersion uint16) {}
+
+// ParseError implements Metrics.
+func (NopMetrics) ParseError(specVersion uint16) {}
+
+// ActiveListVersion implements Metrics.
+func (NopMetrics) ActiveListVersion(specVersion uint16, listVersion uint16) {}
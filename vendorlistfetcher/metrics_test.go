@@ -0,0 +1,87 @@
+package vendorlistfetcher
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingMetrics records each call as a string, leaving out durations.
+type recordingMetrics struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (m *recordingMetrics) record(format string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, fmt.Sprintf(format, args...))
+}
+
+func (m *recordingMetrics) ObserveFetch(specVersion uint16, duration time.Duration, err error) {
+	m.record("fetch v%d error=%t", specVersion, err != nil)
+}
+
+func (m *recordingMetrics) CacheHit(specVersion uint16) {
+	m.record("hit v%d", specVersion)
+}
+
+func (m *recordingMetrics) CacheMiss(specVersion uint16) {
+	m.record("miss v%d", specVersion)
+}
+
+func (m *recordingMetrics) ParseError(specVersion uint16) {
+	m.record("parse error v%d", specVersion)
+}
+
+func (m *recordingMetrics) ActiveListVersion(specVersion uint16, listVersion uint16) {
+	m.record("active v%d list %d", specVersion, listVersion)
+}
+
+func TestFetcherMetrics(t *testing.T) {
+	var requests int32
+	fetcher := New(testServer(t, &requests).URL, nil)
+	metrics := &recordingMetrics{}
+	fetcher.SetMetrics(metrics)
+
+	ctx := context.Background()
+	fetcher.Fetch(ctx, 3, 42)
+	fetcher.Fetch(ctx, 3, 42)
+	fetcher.Fetch(ctx, 3, LatestVersion)
+	fetcher.Fetch(ctx, 3, LatestVersion)
+	fetcher.Fetch(ctx, 3, 43)
+	fetcher.Fetch(ctx, 2, 1)
+
+	assert.Equal(t, []string{
+		"fetch v3 error=false", "miss v3",
+		"hit v3",
+		"fetch v3 error=false", "miss v3",
+		"fetch v3 error=false", "hit v3",
+		"fetch v3 error=false", "miss v3", "parse error v3",
+		"fetch v2 error=true",
+	}, metrics.calls)
+}
+
+func TestRefresherMetrics(t *testing.T) {
+	metrics := &recordingMetrics{}
+	refresher := NewRefresher(&fakeSource{versions: []uint16{2, 2, 3}}, RefresherConfig{
+		SpecVersion: 3,
+		Fallback:    fakeList(1),
+		Metrics:     metrics,
+	})
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, refresher.Refresh(context.Background()))
+	}
+
+	assert.Equal(t, []string{"active v3 list 1", "active v3 list 2", "active v3 list 3"}, metrics.calls)
+}
+
+func TestNopMetrics(t *testing.T) {
+	fetcher := New("http://example.com", nil)
+	fetcher.SetMetrics(nil)
+	assert.Equal(t, NopMetrics{}, fetcher.metrics)
+}
@@ -0,0 +1,169 @@
+package vendorlistfetcher
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prebid/go-gdpr/api"
+)
+
+// DefaultRefreshInterval is the interval a Refresher uses if its config doesn't give a positive one.
+const DefaultRefreshInterval = time.Hour
+
+// Source fetches vendor lists. *Fetcher implements it.
+type Source interface {
+	Fetch(ctx context.Context, specVersion uint16, listVersion uint16) (api.VendorList, error)
+}
+
+// RefresherConfig configures a Refresher.
+type RefresherConfig struct {
+	// SpecVersion is the vendor list specification version to track.
+	SpecVersion uint16
+	// Interval is the time between checks for a new list. If it isn't positive, DefaultRefreshInterval is
+	// used instead.
+	Interval time.Duration
+	// Jitter adds a random duration between 0 and Jitter to each interval, so that a fleet of servers
+	// doesn't hit the vendor list host at the same moment.
+	Jitter time.Duration
+	// OnError, if set, is called with each error from a failed check. The previous list is kept.
+	OnError func(error)
+	// Fallback, if set, is returned by Latest until a check succeeds, so consent can still be evaluated
+	// during a cold start while the vendor list host is unreachable. It's usually a snapshot embedded in
+	// the binary and parsed with ParseSnapshot.
+	Fallback api.VendorList
+	// Metrics, if set, is told the version of the list returned by Latest each time it changes.
+	Metrics Metrics
+}
+
+// Refresher keeps the latest vendor list up to date in the background.
+//
+// The current list is swapped atomically, so Latest never blocks and callers holding an older list can
+// keep using it. Subscribers are notified, in the refresh goroutine, each time the list version changes.
+type Refresher struct {
+	source Source
+	config RefresherConfig
+
+	latest atomic.Pointer[api.VendorList]
+
+	mu          sync.Mutex
+	subscribers []func(api.VendorList)
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewRefresher returns a Refresher which fetches lists from source. Call Start to begin refreshing.
+func NewRefresher(source Source, config RefresherConfig) *Refresher {
+	if config.Metrics == nil {
+		config.Metrics = NopMetrics{}
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultRefreshInterval
+	}
+	if config.Fallback != nil {
+		config.Metrics.ActiveListVersion(config.SpecVersion, config.Fallback.Version())
+	}
+	return &Refresher{
+		source: source,
+		config: config,
+		done:   make(chan struct{}),
+	}
+}
+
+// Latest returns the most recently fetched list. If no check has succeeded yet, it returns the
+// configured Fallback, which may be nil.
+func (r *Refresher) Latest() api.VendorList {
+	if list := r.latest.Load(); list != nil {
+		return *list
+	}
+	return r.config.Fallback
+}
+
+// UsingFallback returns true if Latest is returning the configured Fallback, because no check has
+// succeeded yet. Services may want to report it, since the fallback can be several versions old.
+func (r *Refresher) UsingFallback() bool {
+	return r.latest.Load() == nil && r.config.Fallback != nil
+}
+
+// Subscribe registers fn to be called with the new list each time its version changes.
+func (r *Refresher) Subscribe(fn func(api.VendorList)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, fn)
+}
+
+// Refresh checks for a new list once, and notifies the subscribers if its version changed.
+func (r *Refresher) Refresh(ctx context.Context) error {
+	list, err := r.source.Fetch(ctx, r.config.SpecVersion, LatestVersion)
+	if err != nil {
+		return err
+	}
+	previous := r.latest.Swap(&list)
+	if previous != nil && (*previous).Version() == list.Version() {
+		return nil
+	}
+	r.config.Metrics.ActiveListVersion(r.config.SpecVersion, list.Version())
+
+	r.mu.Lock()
+	subscribers := make([]func(api.VendorList), len(r.subscribers))
+	copy(subscribers, r.subscribers)
+	r.mu.Unlock()
+	for _, fn := range subscribers {
+		fn(list)
+	}
+	return nil
+}
+
+// Start checks for a new list right away, and then after every interval until Stop is called.
+// Calling it more than once has no effect.
+func (r *Refresher) Start() {
+	r.startOnce.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		r.cancel = cancel
+		go r.run(ctx)
+	})
+}
+
+// Stop stops the refresher, cancelling any check in progress, and waits for it to finish.
+// It is safe to call more than once, and before Start.
+func (r *Refresher) Stop() {
+	r.stopOnce.Do(func() {
+		r.startOnce.Do(func() {
+			close(r.done)
+		})
+		if r.cancel != nil {
+			r.cancel()
+		}
+		<-r.done
+	})
+}
+
+func (r *Refresher) run(ctx context.Context) {
+	defer close(r.done)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		if err := r.Refresh(ctx); err != nil && ctx.Err() == nil && r.config.OnError != nil {
+			r.config.OnError(err)
+		}
+		timer.Reset(r.nextInterval())
+	}
+}
+
+func (r *Refresher) nextInterval() time.Duration {
+	if r.config.Jitter <= 0 {
+		return r.config.Interval
+	}
+	return r.config.Interval + time.Duration(rand.Int63n(int64(r.config.Jitter)))
+}
@@ -0,0 +1,134 @@
+package vendorlistfetcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeList uint16
+
+func (l fakeList) SpecVersion() uint16               { return 3 }
+func (l fakeList) Version() uint16                   { return uint16(l) }
+func (l fakeList) Vendor(vendorID uint16) api.Vendor { return nil }
+
+// fakeSource returns the queued versions in order, repeating the last one. A zero version is an error.
+type fakeSource struct {
+	mu       sync.Mutex
+	versions []uint16
+	calls    int
+}
+
+func (s *fakeSource) Fetch(ctx context.Context, specVersion uint16, listVersion uint16) (api.VendorList, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	version := s.versions[len(s.versions)-1]
+	if s.calls < len(s.versions) {
+		version = s.versions[s.calls]
+	}
+	s.calls++
+	if version == 0 {
+		return nil, errors.New("fetch failed")
+	}
+	return fakeList(version), nil
+}
+
+func TestNewRefresherDefaultInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		refresher := NewRefresher(&fakeSource{versions: []uint16{1}}, RefresherConfig{Interval: interval})
+		assert.Equal(t, DefaultRefreshInterval, refresher.nextInterval())
+	}
+	refresher := NewRefresher(&fakeSource{versions: []uint16{1}}, RefresherConfig{Interval: time.Minute})
+	assert.Equal(t, time.Minute, refresher.nextInterval())
+}
+
+func TestRefresh(t *testing.T) {
+	refresher := NewRefresher(&fakeSource{versions: []uint16{1, 1, 0, 2}}, RefresherConfig{SpecVersion: 3})
+	var notified []uint16
+	refresher.Subscribe(func(list api.VendorList) {
+		notified = append(notified, list.Version())
+	})
+	assert.Nil(t, refresher.Latest())
+
+	assert.NoError(t, refresher.Refresh(context.Background()))
+	assert.Equal(t, uint16(1), refresher.Latest().Version())
+	assert.NoError(t, refresher.Refresh(context.Background()))
+	assert.EqualError(t, refresher.Refresh(context.Background()), "fetch failed")
+	assert.Equal(t, uint16(1), refresher.Latest().Version())
+	assert.NoError(t, refresher.Refresh(context.Background()))
+	assert.Equal(t, uint16(2), refresher.Latest().Version())
+
+	assert.Equal(t, []uint16{1, 2}, notified)
+}
+
+func TestRefresherFallback(t *testing.T) {
+	refresher := NewRefresher(&fakeSource{versions: []uint16{0, 3}}, RefresherConfig{SpecVersion: 3, Fallback: fakeList(1)})
+	assert.True(t, refresher.UsingFallback())
+	assert.Equal(t, uint16(1), refresher.Latest().Version())
+
+	assert.Error(t, refresher.Refresh(context.Background()))
+	assert.True(t, refresher.UsingFallback())
+	assert.Equal(t, uint16(1), refresher.Latest().Version())
+
+	assert.NoError(t, refresher.Refresh(context.Background()))
+	assert.False(t, refresher.UsingFallback())
+	assert.Equal(t, uint16(3), refresher.Latest().Version())
+}
+
+func TestRefresherWithoutFallback(t *testing.T) {
+	refresher := NewRefresher(&fakeSource{versions: []uint16{1}}, RefresherConfig{SpecVersion: 3})
+	assert.False(t, refresher.UsingFallback())
+	assert.Nil(t, refresher.Latest())
+}
+
+func TestRefresherStartStop(t *testing.T) {
+	errs := make(chan error, 10)
+	refresher := NewRefresher(&fakeSource{versions: []uint16{1, 0, 2}}, RefresherConfig{
+		SpecVersion: 3,
+		Interval:    time.Millisecond,
+		Jitter:      time.Millisecond,
+		OnError: func(err error) {
+			errs <- err
+		},
+	})
+	updates := make(chan uint16, 10)
+	refresher.Subscribe(func(list api.VendorList) {
+		updates <- list.Version()
+	})
+
+	refresher.Start()
+	refresher.Start()
+	assert.Equal(t, uint16(1), receive(t, updates))
+	assert.EqualError(t, receive(t, errs), "fetch failed")
+	assert.Equal(t, uint16(2), receive(t, updates))
+
+	refresher.Stop()
+	refresher.Stop()
+	assert.Equal(t, uint16(2), refresher.Latest().Version())
+}
+
+func TestRefresherStopBeforeStart(t *testing.T) {
+	source := &fakeSource{versions: []uint16{1}}
+	refresher := NewRefresher(source, RefresherConfig{Interval: time.Millisecond})
+	refresher.Stop()
+	refresher.Start()
+	assert.Nil(t, refresher.Latest())
+	assert.Equal(t, 0, source.calls)
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case value := <-ch:
+		return value
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the refresher")
+		var zero T
+		return zero
+	}
+}
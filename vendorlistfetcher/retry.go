@@ -0,0 +1,102 @@
+package vendorlistfetcher
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures how a Fetcher retries a failed download. The zero value makes one attempt
+// with no timeout besides the context's.
+//
+// Network errors, timeouts, and 429 and 5xx responses are retried. Other errors, such as a 404 or a
+// list which doesn't parse, are returned straight away, since another attempt would fail the same way.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts, including the first. Values below 1 mean 1.
+	MaxAttempts int
+	// InitialBackoff is the wait before the second attempt. It doubles for each attempt after that.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts. Zero means no cap.
+	MaxBackoff time.Duration
+	// Jitter adds a random duration between 0 and Jitter to each wait, so that a fleet of servers
+	// doesn't retry at the same moment.
+	Jitter time.Duration
+	// AttemptTimeout limits each attempt, including reading the response. Zero means no limit besides
+	// the context's.
+	AttemptTimeout time.Duration
+}
+
+// DefaultRetryPolicy returns a policy which suits fetching lists during startup: up to 4 attempts of at
+// most 10 seconds each, with waits of 500ms, 1s and 2s between them.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Jitter:         250 * time.Millisecond,
+		AttemptTimeout: 10 * time.Second,
+	}
+}
+
+// SetRetryPolicy makes the Fetcher retry failed downloads according to policy. Call it before using
+// the Fetcher.
+func (f *Fetcher) SetRetryPolicy(policy RetryPolicy) {
+	f.retry = policy
+}
+
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// backoff returns the wait after the given attempt, counting from 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.InitialBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || wait < p.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		wait += time.Duration(rand.Int63n(int64(p.Jitter)))
+	}
+	return wait
+}
+
+// attemptContext returns the context for one attempt.
+func (p RetryPolicy) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.AttemptTimeout > 0 {
+		return context.WithTimeout(ctx, p.AttemptTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// retryableError marks an error which another attempt might not hit.
+type retryableError struct {
+	error
+}
+
+func (e retryableError) Unwrap() error {
+	return e.error
+}
+
+func isRetryable(err error) bool {
+	var retryable retryableError
+	return errors.As(err, &retryable)
+}
+
+// wait sleeps for d, or until ctx is done. It returns false in the latter case.
+func wait(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,132 @@
+package vendorlistfetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyServer serves testListV3 at its archive path, after answering the first failures requests with
+// the given status. A status of 0 makes those requests hang until the client gives up instead.
+func flakyServer(t *testing.T, requests *int32, failures int32, status int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(requests, 1) <= failures {
+			if status == 0 {
+				<-r.Context().Done()
+				return
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(testListV3))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name             string
+		failures         int32
+		status           int
+		expectedRequests int32
+		expectedError    string
+	}{
+		{
+			name:             "recovers",
+			failures:         2,
+			status:           http.StatusServiceUnavailable,
+			expectedRequests: 3,
+		},
+		{
+			name:             "rate_limited",
+			failures:         1,
+			status:           http.StatusTooManyRequests,
+			expectedRequests: 2,
+		},
+		{
+			name:             "gives_up",
+			failures:         5,
+			status:           http.StatusInternalServerError,
+			expectedRequests: 3,
+			expectedError:    "/v3/archives/vendor-list-v42.json returned status 500",
+		},
+		{
+			name:             "not_retryable",
+			failures:         1,
+			status:           http.StatusNotFound,
+			expectedRequests: 1,
+			expectedError:    "/v3/archives/vendor-list-v42.json returned status 404",
+		},
+		{
+			name:             "attempt_timeout",
+			failures:         1,
+			expectedRequests: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := flakyServer(t, &requests, tt.failures, tt.status)
+			fetcher := New(server.URL, nil)
+			fetcher.SetRetryPolicy(RetryPolicy{
+				MaxAttempts:    3,
+				InitialBackoff: time.Millisecond,
+				AttemptTimeout: 100 * time.Millisecond,
+			})
+
+			list, err := fetcher.Fetch(context.Background(), 3, 42)
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, uint16(42), list.Version())
+			} else {
+				assert.EqualError(t, err, "vendor list "+server.URL+tt.expectedError)
+			}
+			assert.Equal(t, tt.expectedRequests, atomic.LoadInt32(&requests))
+		})
+	}
+}
+
+func TestRetryPolicyStopsWithContext(t *testing.T) {
+	var requests int32
+	fetcher := New(flakyServer(t, &requests, 5, http.StatusServiceUnavailable).URL, nil)
+	fetcher.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := fetcher.Fetch(ctx, 3, 42)
+	assert.Error(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(time.Minute))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, wait := range expected {
+		assert.Equal(t, wait, policy.backoff(i+1))
+	}
+
+	policy.MaxBackoff = 0
+	assert.Equal(t, 1600*time.Millisecond, policy.backoff(5))
+
+	policy.Jitter = 50 * time.Millisecond
+	for i := 0; i < 10; i++ {
+		wait := policy.backoff(1)
+		assert.True(t, wait >= 100*time.Millisecond && wait < 150*time.Millisecond, "unexpected wait %v", wait)
+	}
+}
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	assert.Equal(t, 4, policy.attempts())
+	assert.Equal(t, 1, RetryPolicy{}.attempts())
+}
@@ -0,0 +1,102 @@
+package vendorlistfetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// VendorListStore keeps the raw JSON of vendor lists, so a Fetcher can load them without a request.
+// Implement it to share downloaded lists between instances of a service, with S3 or Redis for example.
+//
+// Implementations must be safe for concurrent use. Only numbered versions are stored, never
+// LatestVersion.
+type VendorListStore interface {
+	// Get returns the stored data for the given version. The bool is false if there is none.
+	Get(ctx context.Context, specVersion uint16, listVersion uint16) ([]byte, bool, error)
+	// Put stores the data for the given version, replacing anything stored before.
+	Put(ctx context.Context, specVersion uint16, listVersion uint16, data []byte) error
+}
+
+// MemoryStore is a VendorListStore which keeps lists in memory. It's mostly useful in tests.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	lists map[cacheKey][]byte
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{lists: make(map[cacheKey][]byte)}
+}
+
+// Get implements VendorListStore.
+func (s *MemoryStore) Get(ctx context.Context, specVersion uint16, listVersion uint16) ([]byte, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.lists[cacheKey{specVersion: specVersion, listVersion: listVersion}]
+	return data, ok, nil
+}
+
+// Put implements VendorListStore. It keeps its own copy of data.
+func (s *MemoryStore) Put(ctx context.Context, specVersion uint16, listVersion uint16, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lists[cacheKey{specVersion: specVersion, listVersion: listVersion}] = append([]byte(nil), data...)
+	return nil
+}
+
+// FileStore is a VendorListStore which keeps lists as files in a directory, laid out like the IAB's
+// archive: {dir}/v{specVersion}/vendor-list-v{listVersion}.json.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a FileStore which keeps lists under dir. The directory is created when the
+// first list is stored.
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Path returns the file a list is stored in.
+func (s *FileStore) Path(specVersion uint16, listVersion uint16) string {
+	return filepath.Join(s.dir, fmt.Sprintf("v%d", specVersion), fmt.Sprintf("vendor-list-v%d.json", listVersion))
+}
+
+// Get implements VendorListStore.
+func (s *FileStore) Get(ctx context.Context, specVersion uint16, listVersion uint16) ([]byte, bool, error) {
+	data, err := os.ReadFile(s.Path(specVersion, listVersion))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Put implements VendorListStore. The file is written to a temporary name and renamed into place, so
+// a concurrent Get never sees a partly written list.
+func (s *FileStore) Put(ctx context.Context, specVersion uint16, listVersion uint16, data []byte) error {
+	path := s.Path(specVersion, listVersion)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".vendor-list-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,103 @@
+package vendorlistfetcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStores(t *testing.T) {
+	stores := map[string]VendorListStore{
+		"memory": NewMemoryStore(),
+		"file":   NewFileStore(t.TempDir()),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			_, ok, err := store.Get(ctx, 3, 42)
+			assert.NoError(t, err)
+			assert.False(t, ok)
+
+			assert.NoError(t, store.Put(ctx, 3, 42, []byte("first")))
+			assert.NoError(t, store.Put(ctx, 3, 42, []byte("second")))
+			assert.NoError(t, store.Put(ctx, 2, 42, []byte("other spec")))
+
+			data, ok, err := store.Get(ctx, 3, 42)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, "second", string(data))
+			_, ok, err = store.Get(ctx, 3, 43)
+			assert.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestMemoryStoreCopies(t *testing.T) {
+	store := NewMemoryStore()
+	data := []byte("list")
+	assert.NoError(t, store.Put(context.Background(), 3, 42, data))
+	data[0] = 'L'
+
+	stored, _, _ := store.Get(context.Background(), 3, 42)
+	assert.Equal(t, "list", string(stored))
+}
+
+func TestFileStoreLayout(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(dir)
+	assert.NoError(t, store.Put(context.Background(), 3, 42, []byte(testListV3)))
+
+	path := filepath.Join(dir, "v3", "vendor-list-v42.json")
+	assert.Equal(t, path, store.Path(3, 42))
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, testListV3, string(data))
+
+	entries, err := os.ReadDir(filepath.Join(dir, "v3"))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files should be cleaned up")
+}
+
+func TestFetchWithStore(t *testing.T) {
+	var requests int32
+	server := testServer(t, &requests)
+	store := NewMemoryStore()
+
+	list, err := NewWithStore(server.URL, nil, store).Fetch(context.Background(), 3, LatestVersion)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(42), list.Version())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// The latest list was stored under its number, so a new Fetcher doesn't need to download it.
+	list, err = NewWithStore(server.URL, nil, store).Fetch(context.Background(), 3, 42)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(42), list.Version())
+	assert.True(t, list.Vendor(8).Purpose(1))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestFetchWithUnusableStore(t *testing.T) {
+	var requests int32
+	server := testServer(t, &requests)
+	store := NewMemoryStore()
+	assert.NoError(t, store.Put(context.Background(), 3, 42, []byte("not json")))
+	assert.NoError(t, store.Put(context.Background(), 2, 28, []byte(testListV3)))
+
+	list, err := NewWithStore(server.URL, nil, store).Fetch(context.Background(), 3, 42)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(42), list.Version())
+	list, err = NewWithStore(server.URL, nil, store).Fetch(context.Background(), 2, 28)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(28), list.Version())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// The downloads replaced the unusable data.
+	data, _, _ := store.Get(context.Background(), 3, 42)
+	assert.Equal(t, testListV3, string(data))
+}
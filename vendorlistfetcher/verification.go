@@ -0,0 +1,93 @@
+package vendorlistfetcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ListID identifies a vendor list by its specification and list version.
+type ListID struct {
+	SpecVersion uint16
+	ListVersion uint16
+}
+
+// Verification configures the checks a Fetcher makes on vendor lists before using them. Vendor lists
+// decide which vendors may receive data, so services may want to reject anything unexpected rather
+// than trust whatever the host returns. The zero value checks nothing.
+type Verification struct {
+	// RequireHTTPS rejects lists from URLs which don't use https.
+	RequireHTTPS bool
+	// MaxSize is the largest list accepted, in bytes. Zero means no limit.
+	MaxSize int64
+	// ContentTypes lists the accepted media types, such as "application/json". If it's empty, any
+	// content type is accepted.
+	ContentTypes []string
+	// Checksums holds the hex encoded SHA-256 of known lists. A list with an entry here is rejected if
+	// its data doesn't match, whether it was downloaded or read from a VendorListStore.
+	Checksums map[ListID]string
+	// Verify, if set, is called with the data of each list before it's used, and the list is rejected
+	// if it returns an error. Use it to check a signature, for example.
+	Verify func(specVersion uint16, listVersion uint16, data []byte) error
+}
+
+// DefaultVerification returns the checks recommended for lists downloaded from DefaultBaseURL: https
+// only, JSON only, and at most 32 MiB, several times the size of a full version 3 list.
+func DefaultVerification() Verification {
+	return Verification{
+		RequireHTTPS: true,
+		MaxSize:      32 << 20,
+		ContentTypes: []string{"application/json"},
+	}
+}
+
+// SetVerification makes the Fetcher check lists with verification. Call it before using the Fetcher.
+func (f *Fetcher) SetVerification(verification Verification) {
+	f.verification = verification
+}
+
+// checkRequest rejects a request before it's sent.
+func (v *Verification) checkRequest(req *http.Request) error {
+	if v.RequireHTTPS && req.URL.Scheme != "https" {
+		return fmt.Errorf("vendor list %s rejected: it isn't served over https", req.URL)
+	}
+	return nil
+}
+
+// checkResponse rejects a response before its body is read.
+func (v *Verification) checkResponse(resp *http.Response, url string) error {
+	if v.MaxSize > 0 && resp.ContentLength > v.MaxSize {
+		return fmt.Errorf("vendor list %s rejected: it's larger than %d bytes", url, v.MaxSize)
+	}
+	if len(v.ContentTypes) == 0 {
+		return nil
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		for _, allowed := range v.ContentTypes {
+			if strings.EqualFold(mediaType, allowed) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("vendor list %s rejected: content type %q isn't allowed", url, contentType)
+}
+
+// checkData rejects a list whose data doesn't match its checksum, or fails the Verify hook.
+func (v *Verification) checkData(specVersion uint16, listVersion uint16, data []byte) error {
+	if expected, ok := v.Checksums[ListID{SpecVersion: specVersion, ListVersion: listVersion}]; ok {
+		sum := sha256.Sum256(data)
+		if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, expected) {
+			return fmt.Errorf("vendor list v%d/%d rejected: its SHA-256 is %s, not %s", specVersion, listVersion, actual, expected)
+		}
+	}
+	if v.Verify != nil {
+		if err := v.Verify(specVersion, listVersion, data); err != nil {
+			return fmt.Errorf("vendor list v%d/%d rejected: %v", specVersion, listVersion, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package vendorlistfetcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testTLSServer serves testListV3 over https as JSON at its archive path, and variations of it which
+// fail verification.
+func testTLSServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/archives/vendor-list-v42.json":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(testListV3))
+		case "/v3/archives/vendor-list-v43.json":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`{"gvlSpecificationVersion": 3, "vendorListVersion": 43, "vendors": {}}`))
+		case "/v3/archives/vendor-list-v44.json":
+			// Flushing before the body is written leaves the response without a Content-Length.
+			w.Header().Set("Content-Type", "application/json")
+			w.(http.Flusher).Flush()
+			w.Write([]byte(`{"gvlSpecificationVersion": 3, "vendorListVersion": 44, "vendors": {}}` + strings.Repeat(" ", 1<<10)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDefaultVerification(t *testing.T) {
+	server := testTLSServer(t)
+	fetcher := New(server.URL, server.Client())
+	fetcher.SetVerification(DefaultVerification())
+
+	list, err := fetcher.Fetch(context.Background(), 3, 42)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(42), list.Version())
+
+	_, err = fetcher.Fetch(context.Background(), 3, 43)
+	assert.EqualError(t, err, "vendor list "+server.URL+`/v3/archives/vendor-list-v43.json rejected: content type "text/html" isn't allowed`)
+}
+
+func TestVerificationRequiresHTTPS(t *testing.T) {
+	var requests int32
+	server := testServer(t, &requests)
+	fetcher := New(server.URL, nil)
+	fetcher.SetVerification(DefaultVerification())
+
+	_, err := fetcher.Fetch(context.Background(), 3, 42)
+	assert.EqualError(t, err, "vendor list "+server.URL+"/v3/archives/vendor-list-v42.json rejected: it isn't served over https")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+}
+
+func TestVerificationMaxSize(t *testing.T) {
+	server := testTLSServer(t)
+	fetcher := New(server.URL, server.Client())
+	fetcher.SetVerification(Verification{MaxSize: 1 << 10})
+
+	_, err := fetcher.Fetch(context.Background(), 3, 42)
+	assert.NoError(t, err)
+
+	// Without a Content-Length, the list is rejected once too much of it has been read.
+	_, err = fetcher.Fetch(context.Background(), 3, 44)
+	assert.EqualError(t, err, "vendor list "+server.URL+"/v3/archives/vendor-list-v44.json rejected: it's larger than 1024 bytes")
+
+	fetcher.SetVerification(Verification{MaxSize: 10})
+	_, err = fetcher.Fetch(context.Background(), 3, 43)
+	assert.EqualError(t, err, "vendor list "+server.URL+"/v3/archives/vendor-list-v43.json rejected: it's larger than 10 bytes")
+}
+
+func TestVerificationChecksums(t *testing.T) {
+	server := testTLSServer(t)
+	fetcher := New(server.URL, server.Client())
+	fetcher.SetVerification(Verification{
+		Checksums: map[ListID]string{
+			{SpecVersion: 3, ListVersion: 42}: strings.ToUpper(sha256Hex(testListV3)),
+			{SpecVersion: 3, ListVersion: 43}: sha256Hex("something else"),
+		},
+	})
+
+	_, err := fetcher.Fetch(context.Background(), 3, 42)
+	assert.NoError(t, err)
+
+	_, err = fetcher.Fetch(context.Background(), 3, 43)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "vendor list v3/43 rejected: its SHA-256 is ")
+	}
+}
+
+func TestVerificationHook(t *testing.T) {
+	server := testTLSServer(t)
+	fetcher := New(server.URL, server.Client())
+	var verified []uint16
+	fetcher.SetVerification(Verification{
+		Verify: func(specVersion uint16, listVersion uint16, data []byte) error {
+			verified = append(verified, listVersion)
+			if listVersion == 43 {
+				return errors.New("bad signature")
+			}
+			return nil
+		},
+	})
+
+	_, err := fetcher.Fetch(context.Background(), 3, 42)
+	assert.NoError(t, err)
+	_, err = fetcher.Fetch(context.Background(), 3, 43)
+	assert.EqualError(t, err, "vendor list v3/43 rejected: bad signature")
+	assert.Equal(t, []uint16{42, 43}, verified)
+}
+
+func TestVerificationOfStoredLists(t *testing.T) {
+	var requests int32
+	store := NewMemoryStore()
+	// A valid list, but not the one the checksum was taken from.
+	tampered := strings.Replace(testListV3, "Emerse", "Tampered", 1)
+	assert.NoError(t, store.Put(context.Background(), 3, 42, []byte(tampered)))
+
+	fetcher := NewWithStore(testServer(t, &requests).URL, nil, store)
+	fetcher.SetVerification(Verification{
+		Checksums: map[ListID]string{{SpecVersion: 3, ListVersion: 42}: sha256Hex(testListV3)},
+	})
+
+	_, err := fetcher.Fetch(context.Background(), 3, 42)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	data, _, _ := store.Get(context.Background(), 3, 42)
+	assert.Equal(t, testListV3, string(data))
+}
@@ -0,0 +1,103 @@
+package vendorlistfetcher
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/prebid/go-gdpr/api"
+)
+
+// VersionCache keeps several versions of the vendor list for one specification version, so that each
+// consent string can be interpreted with the list it was written for. It is safe for concurrent use.
+//
+// Keep it filled by subscribing it to a Refresher:
+//
+//	cache := vendorlistfetcher.NewVersionCache(10)
+//	refresher.Subscribe(cache.Add)
+//
+//	list, exact := cache.Resolve(consent)
+type VersionCache struct {
+	capacity int
+
+	mu     sync.RWMutex
+	lists  map[uint16]api.VendorList
+	newest api.VendorList
+}
+
+// NewVersionCache returns an empty VersionCache which keeps up to capacity lists. When it's full, the
+// lowest version is dropped to make room. If capacity isn't positive, every list is kept.
+func NewVersionCache(capacity int) *VersionCache {
+	return &VersionCache{
+		capacity: capacity,
+		lists:    make(map[uint16]api.VendorList),
+	}
+}
+
+// Add stores the list, replacing any stored list with the same version. Nil lists are ignored.
+func (c *VersionCache) Add(list api.VendorList) {
+	if list == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	version := list.Version()
+	c.lists[version] = list
+	if c.newest == nil || version >= c.newest.Version() {
+		c.newest = list
+	}
+	if c.capacity > 0 && len(c.lists) > c.capacity {
+		delete(c.lists, c.lowestVersion())
+	}
+}
+
+func (c *VersionCache) lowestVersion() uint16 {
+	first := true
+	var lowest uint16
+	for version := range c.lists {
+		if first || version < lowest {
+			lowest = version
+			first = false
+		}
+	}
+	return lowest
+}
+
+// Get returns the list with the given version. The bool is false if it isn't stored.
+func (c *VersionCache) Get(version uint16) (api.VendorList, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	list, ok := c.lists[version]
+	return list, ok
+}
+
+// Newest returns the stored list with the highest version, or nil if the cache is empty.
+func (c *VersionCache) Newest() api.VendorList {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.newest
+}
+
+// Versions returns the versions of the stored lists, in ascending order.
+func (c *VersionCache) Versions() []uint16 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	versions := make([]uint16, 0, len(c.lists))
+	for version := range c.lists {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions
+}
+
+// Resolve returns the list for consent.VendorListVersion(). If that version isn't stored, it returns
+// the newest list instead, and exact is false. list is nil if the cache is empty.
+//
+// The newest list is a safe fallback for a newer consent string, since vendors are never removed from
+// later lists, but it may declare different purposes than the list an older string was written for.
+func (c *VersionCache) Resolve(consent api.VendorConsents) (list api.VendorList, exact bool) {
+	if list, ok := c.Get(consent.VendorListVersion()); ok {
+		return list, true
+	}
+	return c.Newest(), false
+}
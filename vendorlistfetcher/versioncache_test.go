@@ -0,0 +1,74 @@
+package vendorlistfetcher
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prebid/go-gdpr/api"
+	"github.com/prebid/go-gdpr/vendorconsent"
+	"github.com/prebid/go-gdpr/vendorlist2"
+	"github.com/stretchr/testify/assert"
+)
+
+func testList(t *testing.T, version uint16) api.VendorList {
+	t.Helper()
+	list, err := vendorlist2.ParseEagerly([]byte(fmt.Sprintf(`{"gvlSpecificationVersion": 2, "vendorListVersion": %d, "vendors": {}}`, version)))
+	assert.NoError(t, err)
+	return list
+}
+
+func TestVersionCache(t *testing.T) {
+	cache := NewVersionCache(0)
+	assert.Nil(t, cache.Newest())
+	_, ok := cache.Get(15)
+	assert.False(t, ok)
+
+	cache.Add(testList(t, 16))
+	cache.Add(testList(t, 14))
+	cache.Add(testList(t, 15))
+	cache.Add(nil)
+
+	assert.Equal(t, []uint16{14, 15, 16}, cache.Versions())
+	assert.Equal(t, uint16(16), cache.Newest().Version())
+	list, ok := cache.Get(15)
+	assert.True(t, ok)
+	assert.Equal(t, uint16(15), list.Version())
+}
+
+func TestVersionCacheCapacity(t *testing.T) {
+	cache := NewVersionCache(2)
+	cache.Add(testList(t, 14))
+	cache.Add(testList(t, 16))
+	cache.Add(testList(t, 15))
+	assert.Equal(t, []uint16{15, 16}, cache.Versions())
+
+	// A list older than every stored one is dropped straight away.
+	cache.Add(testList(t, 13))
+	assert.Equal(t, []uint16{15, 16}, cache.Versions())
+
+	cache.Add(testList(t, 17))
+	assert.Equal(t, []uint16{16, 17}, cache.Versions())
+	assert.Equal(t, uint16(17), cache.Newest().Version())
+}
+
+func TestVersionCacheResolve(t *testing.T) {
+	// This string was written for vendor list version 15.
+	consent, err := vendorconsent.ParseString("COwAdDhOwAdDhN4ABAENAPCgAAQAAv___wAAAFP_AAp_4AI6ACACAA")
+	assert.NoError(t, err)
+
+	cache := NewVersionCache(0)
+	list, exact := cache.Resolve(consent)
+	assert.Nil(t, list)
+	assert.False(t, exact)
+
+	cache.Add(testList(t, 14))
+	cache.Add(testList(t, 16))
+	list, exact = cache.Resolve(consent)
+	assert.Equal(t, uint16(16), list.Version())
+	assert.False(t, exact)
+
+	cache.Add(testList(t, 15))
+	list, exact = cache.Resolve(consent)
+	assert.Equal(t, uint16(15), list.Version())
+	assert.True(t, exact)
+}